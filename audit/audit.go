@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"time"
+
+	"hexagon/errs"
+)
+
+var (
+	ErrEventTypeRequired = errs.Errorf(errs.EINVALID, "audit: event type is required")
+	ErrInvalidTimeRange  = errs.Errorf(errs.EINVALID, "audit: from must not be after to")
+)
+
+type EventType string
+
+const (
+	EventLoginSucceeded EventType = "login_succeeded"
+	// EventLoginFailed has a "reason" in its metadata and, when no account
+	// matched, the "email" that was tried.
+	EventLoginFailed     EventType = "login_failed"
+	EventAccountLocked   EventType = "account_locked"
+	EventTokenRefreshed  EventType = "token_refreshed"
+	EventPasswordReset   EventType = "password_reset"
+	EventPasswordChanged EventType = "password_changed"
+	EventEmailVerified   EventType = "email_verified"
+	EventEmailChanged    EventType = "email_changed"
+	EventOAuthLinked     EventType = "oauth_linked"
+	EventOAuthUnlinked   EventType = "oauth_unlinked"
+	EventUserDeactivated EventType = "user_deactivated"
+	EventUserReactivated EventType = "user_reactivated"
+	EventUserUnlocked    EventType = "user_unlocked"
+	// EventUserLoggedOut is an admin ending every session of the user.
+	EventUserLoggedOut EventType = "user_logged_out"
+	EventRoleChanged   EventType = "role_changed"
+	// EventSignInDenied is the user reporting a sign-in as not theirs.
+	EventSignInDenied EventType = "sign_in_denied"
+	EventDataExported EventType = "data_exported"
+	// EventAccountDeletionRequested has the "delete_at" time in its metadata.
+	EventAccountDeletionRequested EventType = "account_deletion_requested"
+	EventAccountDeletionCancelled EventType = "account_deletion_cancelled"
+	// EventAccountDeleted is the scheduled anonymisation of the account, so
+	// it has no actor.
+	EventAccountDeleted EventType = "account_deleted"
+)
+
+// Event is one entry of the audit log. ActorID is who acted and UserID the
+// account acted on; they differ when an admin acts on a user, and ActorID is
+// empty for anonymous requests such as a failed login.
+type Event struct {
+	ID        string
+	Type      EventType
+	ActorID   string
+	UserID    string
+	IPAddress string
+	UserAgent string
+	Metadata  map[string]string
+	CreatedAt time.Time
+}
+
+// Filter selects events; zero fields match everything. From and To bound
+// CreatedAt inclusively.
+type Filter struct {
+	ActorID   string
+	UserID    string
+	Type      EventType
+	IPAddress string
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Offset    int
+}
+
+// Page is one page of events, newest first, and the number of all events
+// matching the filter.
+type Page struct {
+	Events []Event
+	Total  int
+}
@@ -0,0 +1,27 @@
+package audit
+
+import "context"
+
+// Request describes the request an event happens in, for events whose
+// callers do not know it themselves.
+type Request struct {
+	ActorID   string
+	IPAddress string
+	UserAgent string
+}
+
+type requestContextKey struct{}
+
+func WithRequest(ctx context.Context, req Request) context.Context {
+	return context.WithValue(ctx, requestContextKey{}, req)
+}
+
+func requestFromContext(ctx context.Context) Request {
+	if ctx == nil {
+		return Request{}
+	}
+
+	req, _ := ctx.Value(requestContextKey{}).(Request)
+
+	return req
+}
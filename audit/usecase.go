@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+type Service interface {
+	ListEvents(ctx context.Context, filter Filter) (Page, error)
+}
+
+// Recorder is what other packages record events with.
+type Recorder interface {
+	Record(ctx context.Context, event Event) error
+}
+
+// Repository stores events. The log is append-only: there is no way to
+// change or delete an event.
+type Repository interface {
+	Append(ctx context.Context, event Event) error
+	List(ctx context.Context, filter Filter) (Page, error)
+}
+
+type Usecase struct {
+	repo Repository
+	now  func() time.Time
+}
+
+func NewUsecase(repo Repository) *Usecase {
+	return &Usecase{
+		repo: repo,
+		now: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+}
+
+// Record appends event. The actor, IP address and user agent default to
+// those of the request in ctx.
+func (uc *Usecase) Record(ctx context.Context, event Event) error {
+	if strings.TrimSpace(string(event.Type)) == "" {
+		return ErrEventTypeRequired
+	}
+
+	req := requestFromContext(ctx)
+	if event.ActorID == "" {
+		event.ActorID = req.ActorID
+	}
+
+	if event.IPAddress == "" {
+		event.IPAddress = req.IPAddress
+	}
+
+	if event.UserAgent == "" {
+		event.UserAgent = req.UserAgent
+	}
+
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = uc.now()
+	}
+
+	return uc.repo.Append(ctx, event)
+}
+
+// ListEvents returns the events matching filter, newest first. The limit
+// defaults to 20 and is capped at 100.
+func (uc *Usecase) ListEvents(ctx context.Context, filter Filter) (Page, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.From.After(filter.To) {
+		return Page{}, ErrInvalidTimeRange
+	}
+
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = defaultListLimit
+	case filter.Limit > maxListLimit:
+		filter.Limit = maxListLimit
+	}
+
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	return uc.repo.List(ctx, filter)
+}
@@ -0,0 +1,111 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hexagon/audit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRepository struct {
+	events []audit.Event
+	filter audit.Filter
+}
+
+func (m *memoryRepository) Append(ctx context.Context, event audit.Event) error {
+	m.events = append(m.events, event)
+
+	return nil
+}
+
+func (m *memoryRepository) List(ctx context.Context, filter audit.Filter) (audit.Page, error) {
+	m.filter = filter
+
+	return audit.Page{Events: m.events, Total: len(m.events)}, nil
+}
+
+func TestRecord_FillsRequestFromContext(t *testing.T) {
+	repo := &memoryRepository{}
+	uc := audit.NewUsecase(repo)
+
+	ctx := audit.WithRequest(context.Background(), audit.Request{
+		ActorID:   "admin-1",
+		IPAddress: "203.0.113.7",
+		UserAgent: "curl/8.0",
+	})
+
+	require.NoError(t, uc.Record(ctx, audit.Event{Type: audit.EventUserDeactivated, UserID: "u-1"}))
+
+	require.Len(t, repo.events, 1)
+	event := repo.events[0]
+	assert.Equal(t, "admin-1", event.ActorID)
+	assert.Equal(t, "u-1", event.UserID)
+	assert.Equal(t, "203.0.113.7", event.IPAddress)
+	assert.Equal(t, "curl/8.0", event.UserAgent)
+	assert.False(t, event.CreatedAt.IsZero())
+}
+
+func TestRecord_KeepsExplicitFields(t *testing.T) {
+	repo := &memoryRepository{}
+	uc := audit.NewUsecase(repo)
+
+	ctx := audit.WithRequest(context.Background(), audit.Request{ActorID: "admin-1", IPAddress: "203.0.113.7"})
+
+	require.NoError(t, uc.Record(ctx, audit.Event{
+		Type:      audit.EventLoginSucceeded,
+		ActorID:   "u-1",
+		IPAddress: "198.51.100.1",
+	}))
+
+	assert.Equal(t, "u-1", repo.events[0].ActorID)
+	assert.Equal(t, "198.51.100.1", repo.events[0].IPAddress)
+}
+
+func TestRecord_RequiresType(t *testing.T) {
+	repo := &memoryRepository{}
+	uc := audit.NewUsecase(repo)
+
+	err := uc.Record(context.Background(), audit.Event{UserID: "u-1"})
+
+	assert.ErrorIs(t, err, audit.ErrEventTypeRequired)
+	assert.Empty(t, repo.events)
+}
+
+func TestListEvents_ClampsLimitAndOffset(t *testing.T) {
+	tests := []struct {
+		name       string
+		filter     audit.Filter
+		wantLimit  int
+		wantOffset int
+	}{
+		{name: "defaults", filter: audit.Filter{}, wantLimit: 20, wantOffset: 0},
+		{name: "capped", filter: audit.Filter{Limit: 500, Offset: 40}, wantLimit: 100, wantOffset: 40},
+		{name: "negative offset", filter: audit.Filter{Limit: 10, Offset: -5}, wantLimit: 10, wantOffset: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryRepository{}
+			uc := audit.NewUsecase(repo)
+
+			_, err := uc.ListEvents(context.Background(), tt.filter)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLimit, repo.filter.Limit)
+			assert.Equal(t, tt.wantOffset, repo.filter.Offset)
+		})
+	}
+}
+
+func TestListEvents_RejectsInvertedTimeRange(t *testing.T) {
+	uc := audit.NewUsecase(&memoryRepository{})
+	now := time.Now()
+
+	_, err := uc.ListEvents(context.Background(), audit.Filter{From: now, To: now.Add(-time.Hour)})
+
+	assert.ErrorIs(t, err, audit.ErrInvalidTimeRange)
+}
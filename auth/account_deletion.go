@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"hexagon/audit"
+)
+
+var (
+	ErrAccountDeletionNotConfigured = errors.New("account deletion not configured")
+	ErrAccountDeletionNotRequested  = errors.New("account deletion was not requested")
+)
+
+const (
+	// DefaultAccountDeletionGracePeriod is how long a requested deletion
+	// can be cancelled.
+	DefaultAccountDeletionGracePeriod = 30 * 24 * time.Hour
+
+	accountDeletionBatchSize = 100
+)
+
+type AccountDeletionRepository interface {
+	ScheduleDeletion(ctx context.Context, userID string, deleteAt time.Time) error
+	CancelDeletion(ctx context.Context, userID string) error
+	// ListDueDeletions returns up to limit users whose deletion is scheduled
+	// at or before now.
+	ListDueDeletions(ctx context.Context, now time.Time, limit int) ([]string, error)
+	// Anonymize erases the personal data of the user, including client
+	// details and emails in audit events. The users row stays, anonymised,
+	// because refresh tokens, reset tokens and audit events still
+	// reference it.
+	Anonymize(ctx context.Context, userID string, deletedAt time.Time) error
+}
+
+// WithAccountDeletion lets users delete their account. A deletion runs
+// gracePeriod after it was requested, through DeleteDueAccounts; zero uses
+// DefaultAccountDeletionGracePeriod.
+func (uc *Usecase) WithAccountDeletion(repo AccountDeletionRepository, gracePeriod time.Duration) *Usecase {
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultAccountDeletionGracePeriod
+	}
+
+	uc.deletionRepo = repo
+	uc.deletionGrace = gracePeriod
+
+	return uc
+}
+
+// RequestAccountDeletion schedules the deletion of userID's account and
+// returns when it will run. Asking again keeps the first date.
+func (uc *Usecase) RequestAccountDeletion(ctx context.Context, userID string) (time.Time, error) {
+	if uc.deletionRepo == nil {
+		return time.Time{}, ErrAccountDeletionNotConfigured
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, strings.TrimSpace(userID))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if u.DeletionScheduledAt != nil {
+		return *u.DeletionScheduledAt, nil
+	}
+
+	deleteAt := uc.now().Add(uc.deletionGrace)
+	if err := uc.deletionRepo.ScheduleDeletion(ctx, u.ID, deleteAt); err != nil {
+		return time.Time{}, err
+	}
+
+	uc.recordUserAudit(ctx, u.ID, audit.EventAccountDeletionRequested, map[string]string{
+		"delete_at": deleteAt.Format(time.RFC3339),
+	})
+
+	return deleteAt, nil
+}
+
+// CancelAccountDeletion keeps the account of userID after all.
+func (uc *Usecase) CancelAccountDeletion(ctx context.Context, userID string) error {
+	if uc.deletionRepo == nil {
+		return ErrAccountDeletionNotConfigured
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, strings.TrimSpace(userID))
+	if err != nil {
+		return err
+	}
+
+	if u.DeletionScheduledAt == nil {
+		return ErrAccountDeletionNotRequested
+	}
+
+	if err := uc.deletionRepo.CancelDeletion(ctx, u.ID); err != nil {
+		return err
+	}
+
+	uc.recordUserAudit(ctx, u.ID, audit.EventAccountDeletionCancelled, nil)
+
+	return nil
+}
+
+// DeleteDueAccounts logs out and anonymises the accounts whose grace period
+// is over. It returns how many were deleted; one failing does not stop the
+// others.
+func (uc *Usecase) DeleteDueAccounts(ctx context.Context) (int, error) {
+	if uc.deletionRepo == nil {
+		return 0, nil
+	}
+
+	now := uc.now()
+
+	userIDs, err := uc.deletionRepo.ListDueDeletions(ctx, now, accountDeletionBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+
+	var errs []error
+
+	for _, userID := range userIDs {
+		if err := uc.deleteAccount(ctx, userID, now); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		deleted++
+	}
+
+	return deleted, errors.Join(errs...)
+}
+
+func (uc *Usecase) deleteAccount(ctx context.Context, userID string, now time.Time) error {
+	if err := uc.refreshRepo.RevokeAllByUserID(ctx, userID, now); err != nil {
+		return err
+	}
+
+	if err := uc.revokeUserAccessTokens(ctx, userID, now); err != nil {
+		return err
+	}
+
+	if err := uc.deletionRepo.Anonymize(ctx, userID, now); err != nil {
+		return err
+	}
+
+	uc.recordAudit(ctx, audit.Event{Type: audit.EventAccountDeleted, UserID: userID})
+
+	return nil
+}
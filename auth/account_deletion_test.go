@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"hexagon/audit"
+	"hexagon/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAccountDeletionRepo struct {
+	scheduled   map[string]time.Time
+	cancelled   []string
+	due         []string
+	anonymized  []string
+	anonymizeFn func(userID string) error
+}
+
+func (m *mockAccountDeletionRepo) ScheduleDeletion(ctx context.Context, userID string, deleteAt time.Time) error {
+	m.scheduled[userID] = deleteAt
+	return nil
+}
+
+func (m *mockAccountDeletionRepo) CancelDeletion(ctx context.Context, userID string) error {
+	m.cancelled = append(m.cancelled, userID)
+	return nil
+}
+
+func (m *mockAccountDeletionRepo) ListDueDeletions(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	return m.due, nil
+}
+
+func (m *mockAccountDeletionRepo) Anonymize(ctx context.Context, userID string, deletedAt time.Time) error {
+	if m.anonymizeFn != nil {
+		if err := m.anonymizeFn(userID); err != nil {
+			return err
+		}
+	}
+
+	m.anonymized = append(m.anonymized, userID)
+
+	return nil
+}
+
+func newAccountDeletionUsecaseForTest(u *mockUserRepo, r *mockRefreshRepo, repo *mockAccountDeletionRepo) *Usecase {
+	return NewUsecase(u, &mockOAuthRepo{}, r, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, nil, nil, "", "").
+		WithAccountDeletion(repo, 0)
+}
+
+func userRepoWithDeletion(scheduledAt *time.Time) *mockUserRepo {
+	return &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, DeletionScheduledAt: scheduledAt}, nil
+		},
+	}
+}
+
+func TestRequestAccountDeletion_SchedulesAfterGracePeriod(t *testing.T) {
+	now := time.Date(2026, 4, 15, 12, 0, 0, 0, time.UTC)
+	repo := &mockAccountDeletionRepo{scheduled: map[string]time.Time{}}
+	log := &mockAuditRecorder{}
+	uc := newAccountDeletionUsecaseForTest(userRepoWithDeletion(nil), &mockRefreshRepo{}, repo).WithAuditLog(log)
+	uc.setNowForTest(now)
+
+	deleteAt, err := uc.RequestAccountDeletion(context.Background(), "u1")
+
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(DefaultAccountDeletionGracePeriod), deleteAt)
+	assert.Equal(t, deleteAt, repo.scheduled["u1"])
+	assert.Equal(t, []audit.EventType{audit.EventAccountDeletionRequested}, log.types())
+}
+
+func TestRequestAccountDeletion_KeepsFirstDate(t *testing.T) {
+	scheduledAt := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	repo := &mockAccountDeletionRepo{scheduled: map[string]time.Time{}}
+	uc := newAccountDeletionUsecaseForTest(userRepoWithDeletion(&scheduledAt), &mockRefreshRepo{}, repo)
+
+	deleteAt, err := uc.RequestAccountDeletion(context.Background(), "u1")
+
+	require.NoError(t, err)
+	assert.Equal(t, scheduledAt, deleteAt)
+	assert.Empty(t, repo.scheduled)
+}
+
+func TestRequestAccountDeletion_NotConfigured(t *testing.T) {
+	uc := NewUsecase(userRepoWithDeletion(nil), &mockOAuthRepo{}, &mockRefreshRepo{}, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, nil, nil, "", "")
+
+	_, err := uc.RequestAccountDeletion(context.Background(), "u1")
+
+	assert.ErrorIs(t, err, ErrAccountDeletionNotConfigured)
+}
+
+func TestCancelAccountDeletion(t *testing.T) {
+	t.Run("scheduled", func(t *testing.T) {
+		scheduledAt := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+		repo := &mockAccountDeletionRepo{}
+		uc := newAccountDeletionUsecaseForTest(userRepoWithDeletion(&scheduledAt), &mockRefreshRepo{}, repo)
+
+		require.NoError(t, uc.CancelAccountDeletion(context.Background(), "u1"))
+		assert.Equal(t, []string{"u1"}, repo.cancelled)
+	})
+
+	t.Run("not scheduled", func(t *testing.T) {
+		repo := &mockAccountDeletionRepo{}
+		uc := newAccountDeletionUsecaseForTest(userRepoWithDeletion(nil), &mockRefreshRepo{}, repo)
+
+		err := uc.CancelAccountDeletion(context.Background(), "u1")
+
+		assert.ErrorIs(t, err, ErrAccountDeletionNotRequested)
+		assert.Empty(t, repo.cancelled)
+	})
+}
+
+func TestDeleteDueAccounts_RevokesSessionsBeforeAnonymizing(t *testing.T) {
+	now := time.Date(2026, 5, 15, 12, 0, 0, 0, time.UTC)
+	var steps []string
+
+	refresh := &mockRefreshRepo{
+		revokeAllByUserIDFn: func(ctx context.Context, userID string, revokedAt time.Time) error {
+			steps = append(steps, "revoke:"+userID)
+			return nil
+		},
+	}
+	revocations := newMockRevocationStore()
+	repo := &mockAccountDeletionRepo{
+		due: []string{"u1", "u2", "u3"},
+		anonymizeFn: func(userID string) error {
+			if userID == "u2" {
+				return errors.New("database down")
+			}
+
+			steps = append(steps, "anonymize:"+userID)
+
+			return nil
+		},
+	}
+	log := &mockAuditRecorder{}
+	uc := newAccountDeletionUsecaseForTest(&mockUserRepo{}, refresh, repo).
+		WithTokenRevocation(revocations).
+		WithAuditLog(log)
+	uc.setNowForTest(now)
+
+	deleted, err := uc.DeleteDueAccounts(context.Background())
+
+	require.Error(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.Equal(t, []string{"revoke:u1", "anonymize:u1", "revoke:u2", "revoke:u3", "anonymize:u3"}, steps)
+	assert.Equal(t, now, revocations.users["u1"])
+	assert.Equal(t, []string{"u1", "u3"}, repo.anonymized)
+	require.Len(t, log.events, 2)
+	assert.Equal(t, audit.EventAccountDeleted, log.events[0].Type)
+	assert.Empty(t, log.events[0].ActorID)
+}
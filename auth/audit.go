@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+
+	"hexagon/audit"
+)
+
+// Reasons of audit.EventLoginFailed.
+const (
+	loginFailedUnknownEmail         = "unknown_email"
+	loginFailedInvalidPassword      = "invalid_password"
+	loginFailedAccountLocked        = "account_locked"
+	loginFailedAccountInactive      = "account_inactive"
+	loginFailedInvalidTwoFactorCode = "invalid_two_factor_code"
+)
+
+// WithAuditLog records logins, lockouts, token refreshes and account
+// changes in log.
+func (uc *Usecase) WithAuditLog(log audit.Recorder) *Usecase {
+	uc.auditLog = log
+
+	return uc
+}
+
+// recordAudit is best-effort like recordSecurityEvent: an unavailable audit
+// log must not lock users out.
+func (uc *Usecase) recordAudit(ctx context.Context, event audit.Event) {
+	if uc.auditLog == nil {
+		return
+	}
+
+	info := clientInfoFromContext(ctx)
+	event.IPAddress = info.IPAddress
+	event.UserAgent = info.UserAgent
+	event.CreatedAt = uc.now()
+
+	_ = uc.auditLog.Record(ctx, event)
+}
+
+// recordUserAudit records an event the user did to their own account.
+func (uc *Usecase) recordUserAudit(ctx context.Context, userID string, eventType audit.EventType, metadata map[string]string) {
+	uc.recordAudit(ctx, audit.Event{
+		Type:     eventType,
+		ActorID:  userID,
+		UserID:   userID,
+		Metadata: metadata,
+	})
+}
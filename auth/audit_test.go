@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"hexagon/audit"
+	"hexagon/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAuditRecorder struct {
+	events []audit.Event
+}
+
+func (m *mockAuditRecorder) Record(ctx context.Context, event audit.Event) error {
+	m.events = append(m.events, event)
+
+	return nil
+}
+
+func (m *mockAuditRecorder) types() []audit.EventType {
+	types := make([]audit.EventType, len(m.events))
+	for i, event := range m.events {
+		types[i] = event.Type
+	}
+
+	return types
+}
+
+func TestLogin_AuditsUnknownEmail(t *testing.T) {
+	repo := &mockUserRepo{
+		getByEmailFn: func(ctx context.Context, email string) (user.User, error) {
+			return user.User{}, user.ErrUserNotFound
+		},
+	}
+	log := &mockAuditRecorder{}
+	uc := newUsecaseForTest(repo, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{}).WithAuditLog(log)
+	ctx := WithClientInfo(context.Background(), ClientInfo{IPAddress: "203.0.113.7", UserAgent: "ua"})
+
+	_, err := uc.Login(ctx, "nobody@example.com", "Password@123")
+
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	require.Len(t, log.events, 1)
+	event := log.events[0]
+	assert.Equal(t, audit.EventLoginFailed, event.Type)
+	assert.Empty(t, event.UserID)
+	assert.Equal(t, loginFailedUnknownEmail, event.Metadata["reason"])
+	assert.Equal(t, "nobody@example.com", event.Metadata["email"])
+	assert.Equal(t, "203.0.113.7", event.IPAddress)
+	assert.Equal(t, "ua", event.UserAgent)
+}
+
+func TestLogin_AuditsFailureAndLockout(t *testing.T) {
+	var uc *Usecase
+	repo := &mockUserRepo{
+		getByEmailFn: func(ctx context.Context, email string) (user.User, error) {
+			return user.User{
+				ID:                  "u1",
+				Email:               email,
+				PasswordHash:        "hashed-password",
+				Status:              user.UserStatusActive,
+				FailedLoginAttempts: uc.maxRetries - 1,
+			}, nil
+		},
+	}
+	hasher := &mockHasher{
+		compareFn: func(hashed, plain string) error {
+			return errors.New("mismatch")
+		},
+	}
+	log := &mockAuditRecorder{}
+	uc = newUsecaseForTest(repo, &mockRefreshRepo{}, &mockResetRepo{}, hasher, &mockTokenProvider{}).WithAuditLog(log)
+
+	_, err := uc.Login(context.Background(), "john@example.com", "wrong")
+
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Equal(t, []audit.EventType{audit.EventLoginFailed, audit.EventAccountLocked}, log.types())
+	assert.Equal(t, loginFailedInvalidPassword, log.events[0].Metadata["reason"])
+	assert.Equal(t, "u1", log.events[1].UserID)
+	assert.Empty(t, log.events[1].ActorID)
+	assert.NotEmpty(t, log.events[1].Metadata["locked_until"])
+}
+
+func TestLogin_AuditsLockedAccount(t *testing.T) {
+	lockUntil := time.Now().Add(time.Hour)
+	repo := &mockUserRepo{
+		getByEmailFn: func(ctx context.Context, email string) (user.User, error) {
+			return user.User{
+				ID:           "u1",
+				Email:        email,
+				PasswordHash: "hashed-password",
+				Status:       user.UserStatusLocked,
+				LockUntil:    &lockUntil,
+			}, nil
+		},
+	}
+	log := &mockAuditRecorder{}
+	uc := newUsecaseForTest(repo, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{}).WithAuditLog(log)
+
+	_, err := uc.Login(context.Background(), "john@example.com", "Password@123")
+
+	require.ErrorIs(t, err, ErrAccountLocked)
+	require.Len(t, log.events, 1)
+	assert.Equal(t, "u1", log.events[0].UserID)
+	assert.Equal(t, loginFailedAccountLocked, log.events[0].Metadata["reason"])
+}
+
+func TestLogin_AuditsSuccess(t *testing.T) {
+	verifiedAt := time.Now()
+	repo := &mockUserRepo{
+		getByEmailFn: func(ctx context.Context, email string) (user.User, error) {
+			return user.User{
+				ID:              "u1",
+				Email:           email,
+				PasswordHash:    "hashed-password",
+				Status:          user.UserStatusActive,
+				EmailVerifiedAt: &verifiedAt,
+			}, nil
+		},
+	}
+	log := &mockAuditRecorder{}
+	uc := newUsecaseForTest(repo, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, refreshingTokenProvider()).WithAuditLog(log)
+
+	_, err := uc.Login(context.Background(), "john@example.com", "Password@123")
+
+	require.NoError(t, err)
+	assert.Equal(t, []audit.EventType{audit.EventLoginSucceeded}, log.types())
+	assert.Equal(t, "u1", log.events[0].ActorID)
+	assert.Equal(t, "u1", log.events[0].UserID)
+	assert.Equal(t, "password", log.events[0].Metadata["method"])
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"hexagon/audit"
+	"hexagon/upload"
+	"hexagon/user"
+)
+
+// DataExport is what the user can download about themselves: the profile,
+// linked sign-in providers, active sessions, the audit and security events
+// about them and the files they uploaded. Secrets such as the password hash
+// are left out by the response built from it.
+type DataExport struct {
+	User             user.User
+	TwoFactorEnabled bool
+	OAuthAccounts    []OAuthAccount
+	Sessions         []Session
+	AuditEvents      []audit.Event
+	SecurityEvents   []SecurityEvent
+	Uploads          []upload.Record
+	ExportedAt       time.Time
+}
+
+// DataExportRepository reads the records about a user kept outside the
+// account itself, oldest first.
+type DataExportRepository interface {
+	// ListAuditEvents returns the events of userID and those userID made.
+	ListAuditEvents(ctx context.Context, userID string) ([]audit.Event, error)
+	ListSecurityEvents(ctx context.Context, userID string) ([]SecurityEvent, error)
+	ListUploads(ctx context.Context, ownerID string) ([]upload.Record, error)
+}
+
+// WithDataExport adds the user's events and uploads to ExportData. Without
+// it the export only holds the account.
+func (uc *Usecase) WithDataExport(repo DataExportRepository) *Usecase {
+	uc.exportRepo = repo
+
+	return uc
+}
+
+// ExportData collects the data stored about userID.
+func (uc *Usecase) ExportData(ctx context.Context, userID string) (DataExport, error) {
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return DataExport{}, err
+	}
+
+	twoFactorEnabled, err := uc.twoFactorEnabled(ctx, userID)
+	if err != nil {
+		return DataExport{}, err
+	}
+
+	accounts, err := uc.ListOAuthAccounts(ctx, userID)
+	if err != nil {
+		return DataExport{}, err
+	}
+
+	sessions, err := uc.ListSessions(ctx, userID, "")
+	if err != nil {
+		return DataExport{}, err
+	}
+
+	export := DataExport{
+		User:             u,
+		TwoFactorEnabled: twoFactorEnabled,
+		OAuthAccounts:    accounts,
+		Sessions:         sessions,
+	}
+
+	if uc.exportRepo != nil {
+		if export.AuditEvents, err = uc.exportRepo.ListAuditEvents(ctx, userID); err != nil {
+			return DataExport{}, err
+		}
+
+		if export.SecurityEvents, err = uc.exportRepo.ListSecurityEvents(ctx, userID); err != nil {
+			return DataExport{}, err
+		}
+
+		if export.Uploads, err = uc.exportRepo.ListUploads(ctx, userID); err != nil {
+			return DataExport{}, err
+		}
+	}
+
+	uc.recordUserAudit(ctx, userID, audit.EventDataExported, nil)
+
+	export.ExportedAt = uc.now()
+
+	return export, nil
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hexagon/audit"
+	"hexagon/upload"
+	"hexagon/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDataExportRepo struct {
+	auditEvents    []audit.Event
+	securityEvents []SecurityEvent
+	uploads        []upload.Record
+}
+
+func (m *mockDataExportRepo) ListAuditEvents(ctx context.Context, userID string) ([]audit.Event, error) {
+	return m.auditEvents, nil
+}
+
+func (m *mockDataExportRepo) ListSecurityEvents(ctx context.Context, userID string) ([]SecurityEvent, error) {
+	return m.securityEvents, nil
+}
+
+func (m *mockDataExportRepo) ListUploads(ctx context.Context, ownerID string) ([]upload.Record, error) {
+	return m.uploads, nil
+}
+
+func TestExportData_CollectsUserData(t *testing.T) {
+	now := time.Date(2026, 4, 15, 12, 0, 0, 0, time.UTC)
+	users := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, Name: "John", Email: "john@example.com"}, nil
+		},
+	}
+	oauth := &mockOAuthRepo{accounts: []OAuthAccount{
+		{UserID: "u1", Provider: "google", ProviderUserID: "g-1", ProviderEmail: "john@gmail.com"},
+		{UserID: "u2", Provider: "google", ProviderUserID: "g-2"},
+	}}
+	refresh := &mockRefreshRepo{
+		listSessionsFn: func(ctx context.Context, userID string, now time.Time) ([]Session, error) {
+			return []Session{{ID: "s1", UserAgent: "Mozilla/5.0 (Windows NT 10.0) Chrome/120.0"}}, nil
+		},
+	}
+	log := &mockAuditRecorder{}
+	history := &mockDataExportRepo{
+		auditEvents:    []audit.Event{{Type: audit.EventLoginSucceeded, UserID: "u1"}},
+		securityEvents: []SecurityEvent{{UserID: "u1", Type: SecurityEventTwoFactorEnabled}},
+		uploads:        []upload.Record{{OwnerID: "u1", URL: "https://cdn.example.com/a.jpg"}},
+	}
+	uc := NewUsecase(users, oauth, refresh, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, nil, nil, "", "").
+		WithTwoFactor(enabledTwoFactorRepo(), stubTOTP{}).
+		WithAuditLog(log).
+		WithDataExport(history)
+	uc.setNowForTest(now)
+
+	export, err := uc.ExportData(context.Background(), "u1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "john@example.com", export.User.Email)
+	assert.True(t, export.TwoFactorEnabled)
+	require.Len(t, export.OAuthAccounts, 1)
+	assert.Equal(t, "g-1", export.OAuthAccounts[0].ProviderUserID)
+	require.Len(t, export.Sessions, 1)
+	assert.Equal(t, "Chrome on Windows", export.Sessions[0].Device)
+	assert.Equal(t, history.auditEvents, export.AuditEvents)
+	assert.Equal(t, history.securityEvents, export.SecurityEvents)
+	assert.Equal(t, history.uploads, export.Uploads)
+	assert.Equal(t, now, export.ExportedAt)
+	assert.Equal(t, []audit.EventType{audit.EventDataExported}, log.types())
+}
+
+func TestExportData_UnknownUser(t *testing.T) {
+	uc := NewUsecase(&mockUserRepo{}, &mockOAuthRepo{}, &mockRefreshRepo{}, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, nil, nil, "", "")
+
+	_, err := uc.ExportData(context.Background(), "u1")
+
+	assert.ErrorIs(t, err, user.ErrUserNotFound)
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"hexagon/audit"
+	"hexagon/user"
+)
+
+var ErrEmailUnchanged = errors.New("new email is the current email")
+
+// RequestEmailChange starts moving userID to newEmail. The current address
+// is told about the request, and a verification token goes to the new one;
+// the email only changes once VerifyEmail redeems it. Accounts with a
+// password have to confirm it, and wrong passwords count towards the
+// account lock as in Login. sessionID is the session kept when the change
+// goes through.
+func (uc *Usecase) RequestEmailChange(ctx context.Context, userID, sessionID, newEmail, password string) error {
+	if uc.verifyTokenRepo == nil || uc.mailer == nil || uc.verifyBaseURL == "" {
+		return ErrMailerNotConfigured
+	}
+
+	newEmail = strings.TrimSpace(newEmail)
+	if _, err := mail.ParseAddress(newEmail); err != nil {
+		return user.ErrEmailInvalidFormat
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(u.Email, newEmail) {
+		return ErrEmailUnchanged
+	}
+
+	now := uc.now()
+
+	u, err = uc.handleLockState(ctx, u, now)
+	if err != nil {
+		return err
+	}
+
+	if u.PasswordHash != "" {
+		if err := uc.passwordHasher.Compare(u.PasswordHash, password); err != nil {
+			if err := uc.recordFailure(ctx, u, now); err != nil {
+				return err
+			}
+
+			return ErrInvalidCredentials
+		}
+
+		if err := uc.resetAuthState(ctx, u); err != nil {
+			return err
+		}
+	}
+
+	if err := uc.ensureEmailAvailable(ctx, u.ID, newEmail); err != nil {
+		return err
+	}
+
+	// Warn the current address first: if the session was hijacked, the
+	// owner hears of it before the new address can be confirmed.
+	if err := uc.mailer.SendEmailChangeNotice(ctx, u.Email, u.Name, newEmail); err != nil {
+		return err
+	}
+
+	verifyToken, err := generateRandomPassword(24)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.verifyTokenRepo.Save(ctx, EmailVerificationToken{
+		UserID:    u.ID,
+		TokenHash: hashToken(verifyToken),
+		ExpiresAt: now.Add(uc.verifyTTL),
+		NewEmail:  newEmail,
+		SessionID: strings.TrimSpace(sessionID),
+	}); err != nil {
+		return err
+	}
+
+	verifyURL, err := composeResetPasswordURL(uc.verifyBaseURL, verifyToken)
+	if err != nil {
+		return err
+	}
+
+	return uc.mailer.SendVerifyEmail(ctx, newEmail, u.Name, verifyURL)
+}
+
+// confirmEmailChange swaps in the address entry was sent to. Having
+// received the token proves it, so it is verified at once. Every other
+// session is logged out and access tokens issued so far, which carry the
+// old email, are revoked.
+func (uc *Usecase) confirmEmailChange(ctx context.Context, entry EmailVerificationToken, now time.Time) error {
+	if err := uc.ensureEmailAvailable(ctx, entry.UserID, entry.NewEmail); err != nil {
+		return err
+	}
+
+	if err := uc.verifyTokenRepo.MarkUsedByHash(ctx, entry.TokenHash, now); err != nil {
+		return ErrInvalidVerifyToken
+	}
+
+	if err := uc.userRepo.UpdateEmail(ctx, entry.UserID, entry.NewEmail, &now); err != nil {
+		return err
+	}
+
+	if entry.SessionID != "" {
+		if err := uc.refreshRepo.RevokeOtherSessions(ctx, entry.UserID, entry.SessionID, now); err != nil {
+			return err
+		}
+	} else if err := uc.refreshRepo.RevokeAllByUserID(ctx, entry.UserID, now); err != nil {
+		return err
+	}
+
+	if err := uc.revokeUserAccessTokens(ctx, entry.UserID, now); err != nil {
+		return err
+	}
+
+	uc.recordSecurityEvent(ctx, SecurityEvent{
+		UserID: entry.UserID,
+		Type:   SecurityEventEmailChanged,
+	})
+	uc.recordUserAudit(ctx, entry.UserID, audit.EventEmailChanged, map[string]string{"new_email": entry.NewEmail})
+
+	return nil
+}
+
+func (uc *Usecase) ensureEmailAvailable(ctx context.Context, userID, email string) error {
+	existing, err := uc.userRepo.GetByEmail(ctx, email)
+	switch {
+	case err == nil && existing.ID != userID:
+		return user.ErrEmailAlreadyExists
+	case err != nil && !errors.Is(err, user.ErrUserNotFound):
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"hexagon/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEmailChangeUsecaseForTest(u *mockUserRepo, r *mockRefreshRepo, v *mockVerifyRepo, mailer *mockMailer) *Usecase {
+	hasher := &mockHasher{compareFn: func(hashed, plain string) error {
+		if plain != "secret" {
+			return errors.New("mismatch")
+		}
+
+		return nil
+	}}
+
+	return NewUsecase(u, &mockOAuthRepo{}, r, &mockResetRepo{}, v, hasher, &mockTokenProvider{}, nil, mailer, "", "https://app.example.com/verify")
+}
+
+func emailChangeUserRepo(u user.User) *mockUserRepo {
+	return &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return u, nil
+		},
+		getByEmailFn: func(ctx context.Context, email string) (user.User, error) {
+			if email == "taken@example.com" {
+				return user.User{ID: "u2", Email: email}, nil
+			}
+
+			return user.User{}, user.ErrUserNotFound
+		},
+	}
+}
+
+func TestRequestEmailChange_NotifiesOldAndSendsTokenToNewAddress(t *testing.T) {
+	verifiedAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	repo := emailChangeUserRepo(user.User{ID: "u1", Email: "old@example.com", PasswordHash: "hash", EmailVerifiedAt: &verifiedAt})
+	verifyRepo := &mockVerifyRepo{}
+	mailer := &mockMailer{}
+	uc := newEmailChangeUsecaseForTest(repo, &mockRefreshRepo{}, verifyRepo, mailer)
+
+	err := uc.RequestEmailChange(context.Background(), "u1", "sid-1", " new@example.com ", "secret")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old@example.com -> new@example.com"}, mailer.changeNotices)
+	assert.Equal(t, []string{"new@example.com"}, mailer.verifyEmails)
+	require.Len(t, verifyRepo.saved, 1)
+	assert.Equal(t, "new@example.com", verifyRepo.saved[0].NewEmail)
+	assert.Equal(t, "sid-1", verifyRepo.saved[0].SessionID)
+}
+
+func TestRequestEmailChange_Rejects(t *testing.T) {
+	oauthOnly := user.User{ID: "u1", Email: "old@example.com"}
+	withPassword := user.User{ID: "u1", Email: "old@example.com", PasswordHash: "hash"}
+
+	tests := []struct {
+		name     string
+		user     user.User
+		newEmail string
+		password string
+		wantErr  error
+	}{
+		{name: "invalid address", user: withPassword, newEmail: "not-an-email", password: "secret", wantErr: user.ErrEmailInvalidFormat},
+		{name: "same address", user: withPassword, newEmail: "OLD@example.com", password: "secret", wantErr: ErrEmailUnchanged},
+		{name: "wrong password", user: withPassword, newEmail: "new@example.com", password: "wrong", wantErr: ErrInvalidCredentials},
+		{name: "address taken", user: oauthOnly, newEmail: "taken@example.com", wantErr: user.ErrEmailAlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mailer := &mockMailer{}
+			uc := newEmailChangeUsecaseForTest(emailChangeUserRepo(tt.user), &mockRefreshRepo{}, &mockVerifyRepo{}, mailer)
+
+			err := uc.RequestEmailChange(context.Background(), "u1", "sid-1", tt.newEmail, tt.password)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Empty(t, mailer.changeNotices)
+		})
+	}
+}
+
+func TestRequestEmailChange_WrongPasswordLocksAccount(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	var (
+		lockedUntil *time.Time
+		status      user.UserStatus
+	)
+
+	repo := emailChangeUserRepo(user.User{ID: "u1", Email: "old@example.com", PasswordHash: "hash", Status: user.UserStatusActive, FailedLoginAttempts: 4})
+	repo.updateAuthStateFn = func(ctx context.Context, id string, failed int, lockUntil *time.Time, level int, lastFailed *time.Time, s user.UserStatus) error {
+		lockedUntil = lockUntil
+		status = s
+
+		return nil
+	}
+	mailer := &mockMailer{}
+	uc := newEmailChangeUsecaseForTest(repo, &mockRefreshRepo{}, &mockVerifyRepo{}, mailer)
+	uc.setNowForTest(now)
+
+	err := uc.RequestEmailChange(context.Background(), "u1", "sid-1", "new@example.com", "wrong")
+
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Equal(t, user.UserStatusLocked, status)
+	require.NotNil(t, lockedUntil)
+	assert.Equal(t, now.Add(15*time.Minute), *lockedUntil)
+	assert.Empty(t, mailer.changeNotices)
+}
+
+func TestRequestEmailChange_LockedAccount(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	lockUntil := now.Add(time.Minute)
+	repo := emailChangeUserRepo(user.User{ID: "u1", Email: "old@example.com", PasswordHash: "hash", Status: user.UserStatusLocked, LockUntil: &lockUntil})
+	mailer := &mockMailer{}
+	uc := newEmailChangeUsecaseForTest(repo, &mockRefreshRepo{}, &mockVerifyRepo{}, mailer)
+	uc.setNowForTest(now)
+
+	err := uc.RequestEmailChange(context.Background(), "u1", "sid-1", "new@example.com", "secret")
+
+	assert.ErrorIs(t, err, ErrAccountLocked)
+	assert.Empty(t, mailer.changeNotices)
+}
+
+func TestVerifyEmail_SwapsEmailAndRevokesOtherSessions(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	repo := emailChangeUserRepo(user.User{ID: "u1", Email: "old@example.com"})
+
+	var (
+		updatedEmail string
+		verifiedAt   *time.Time
+		keptSession  string
+	)
+
+	repo.updateEmailFn = func(ctx context.Context, id, email string, at *time.Time) error {
+		updatedEmail = email
+		verifiedAt = at
+
+		return nil
+	}
+	repo.updateEmailVerifiedFn = func(ctx context.Context, id string, at *time.Time) error {
+		t.Fatal("an email change must not only mark the old address verified")
+		return nil
+	}
+	refreshRepo := &mockRefreshRepo{
+		revokeOthersFn: func(ctx context.Context, userID, keepSessionID string, revokedAt time.Time) error {
+			keptSession = keepSessionID
+			return nil
+		},
+	}
+	verifyRepo := &mockVerifyRepo{
+		getActiveByHashFn: func(ctx context.Context, tokenHash string) (EmailVerificationToken, error) {
+			return EmailVerificationToken{
+				UserID:    "u1",
+				TokenHash: tokenHash,
+				ExpiresAt: now.Add(time.Hour),
+				NewEmail:  "new@example.com",
+				SessionID: "sid-1",
+			}, nil
+		},
+	}
+	store := newMockRevocationStore()
+	uc := newEmailChangeUsecaseForTest(repo, refreshRepo, verifyRepo, &mockMailer{}).WithTokenRevocation(store)
+	uc.setNowForTest(now)
+
+	require.NoError(t, uc.VerifyEmail(context.Background(), "token"))
+	assert.Equal(t, "new@example.com", updatedEmail)
+	require.NotNil(t, verifiedAt)
+	assert.Equal(t, now, *verifiedAt, "the new address is verified by the token")
+	assert.Equal(t, "sid-1", keptSession)
+	assert.Equal(t, now, store.users["u1"], "access tokens carrying the old email are revoked")
+}
+
+func TestVerifyEmail_EmailChangeFailsWhenAddressWasTakenMeanwhile(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	markedUsed := false
+	verifyRepo := &mockVerifyRepo{
+		getActiveByHashFn: func(ctx context.Context, tokenHash string) (EmailVerificationToken, error) {
+			return EmailVerificationToken{UserID: "u1", ExpiresAt: now.Add(time.Hour), NewEmail: "taken@example.com"}, nil
+		},
+		markUsedFn: func(ctx context.Context, tokenHash string, usedAt time.Time) error {
+			markedUsed = true
+			return nil
+		},
+	}
+	uc := newEmailChangeUsecaseForTest(emailChangeUserRepo(user.User{ID: "u1"}), &mockRefreshRepo{}, verifyRepo, &mockMailer{})
+	uc.setNowForTest(now)
+
+	err := uc.VerifyEmail(context.Background(), "token")
+
+	assert.ErrorIs(t, err, user.ErrEmailAlreadyExists)
+	assert.False(t, markedUsed)
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"hexagon/user"
+)
+
+var (
+	ErrInvalidMagicLinkToken  = errors.New("invalid magic link token")
+	ErrMagicLinkNotConfigured = errors.New("magic link login not configured")
+	ErrAccountInactive        = errors.New("account is inactive")
+)
+
+const defaultMagicLinkTTL = 15 * time.Minute
+
+type MagicLinkTokenRepository interface {
+	Save(ctx context.Context, token MagicLinkToken) error
+	GetActiveByHash(ctx context.Context, tokenHash string) (MagicLinkToken, error)
+	MarkUsedByHash(ctx context.Context, tokenHash string, usedAt time.Time) error
+}
+
+type MagicLinkToken struct {
+	UserID    string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// WithMagicLink enables passwordless login. baseURL is the frontend page
+// that receives the token as its "token" query parameter.
+func (uc *Usecase) WithMagicLink(repo MagicLinkTokenRepository, baseURL string) *Usecase {
+	uc.magicLinkRepo = repo
+	uc.magicLinkBaseURL = strings.TrimSpace(baseURL)
+
+	return uc
+}
+
+// RequestMagicLink emails a single-use login link. Like ForgotPassword it
+// succeeds for unknown addresses so it cannot be used to probe accounts.
+func (uc *Usecase) RequestMagicLink(ctx context.Context, email string) error {
+	if uc.magicLinkRepo == nil || uc.mailer == nil || uc.magicLinkBaseURL == "" {
+		return ErrMagicLinkNotConfigured
+	}
+
+	email = strings.TrimSpace(email)
+	if _, err := mail.ParseAddress(email); err != nil {
+		return ErrMissingEmail
+	}
+
+	u, err := uc.userRepo.GetByEmail(ctx, email)
+	if err != nil || u.Status == user.UserStatusInactive {
+		return nil
+	}
+
+	loginToken, err := generateRandomPassword(24)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.magicLinkRepo.Save(ctx, MagicLinkToken{
+		UserID:    u.ID,
+		TokenHash: hashToken(loginToken),
+		ExpiresAt: uc.now().Add(uc.magicLinkTTL),
+	}); err != nil {
+		return err
+	}
+
+	loginURL, err := composeResetPasswordURL(uc.magicLinkBaseURL, loginToken)
+	if err != nil {
+		return err
+	}
+
+	return uc.mailer.SendMagicLinkEmail(ctx, u.Email, u.Name, loginURL)
+}
+
+// LoginWithMagicLink consumes a token from RequestMagicLink. Receiving the
+// link proves the user owns the address, so the email is marked verified.
+// The link replaces the password only: users with two-factor
+// authentication still get a challenge.
+func (uc *Usecase) LoginWithMagicLink(ctx context.Context, loginToken string) (LoginResult, error) {
+	if uc.magicLinkRepo == nil {
+		return LoginResult{}, ErrMagicLinkNotConfigured
+	}
+
+	loginToken = strings.TrimSpace(loginToken)
+	if loginToken == "" {
+		return LoginResult{}, ErrInvalidMagicLinkToken
+	}
+
+	tokenHash := hashToken(loginToken)
+	now := uc.now()
+
+	entry, err := uc.magicLinkRepo.GetActiveByHash(ctx, tokenHash)
+	if err != nil || entry.ExpiresAt.Before(now) {
+		return LoginResult{}, ErrInvalidMagicLinkToken
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, entry.UserID)
+	if err != nil {
+		return LoginResult{}, ErrInvalidMagicLinkToken
+	}
+
+	if u.Status == user.UserStatusInactive {
+		return LoginResult{}, ErrAccountInactive
+	}
+
+	// A locked account keeps its link, so it can still be used once the
+	// lock expires if it has not expired itself.
+	u, err = uc.handleLockState(ctx, u, now)
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	if u.MustResetPassword {
+		return LoginResult{}, ErrPasswordResetRequired
+	}
+
+	if err := uc.magicLinkRepo.MarkUsedByHash(ctx, tokenHash, now); err != nil {
+		return LoginResult{}, ErrInvalidMagicLinkToken
+	}
+
+	if u.EmailVerifiedAt == nil {
+		if err := uc.userRepo.UpdateEmailVerifiedAt(ctx, u.ID, &now); err != nil {
+			return LoginResult{}, err
+		}
+
+		u.EmailVerifiedAt = &now
+	}
+
+	twoFactor, err := uc.twoFactorEnabled(ctx, u.ID)
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	if !twoFactor {
+		if err := uc.resetAuthState(ctx, u); err != nil {
+			return LoginResult{}, err
+		}
+	}
+
+	return uc.completeLogin(ctx, u, twoFactor, map[string]string{"method": "magic_link"})
+}
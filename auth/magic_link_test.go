@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"hexagon/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockMagicLinkRepo struct {
+	saved  []MagicLinkToken
+	active map[string]MagicLinkToken
+	used   []string
+}
+
+func (m *mockMagicLinkRepo) Save(ctx context.Context, token MagicLinkToken) error {
+	m.saved = append(m.saved, token)
+	return nil
+}
+
+func (m *mockMagicLinkRepo) GetActiveByHash(ctx context.Context, tokenHash string) (MagicLinkToken, error) {
+	token, ok := m.active[tokenHash]
+	if !ok {
+		return MagicLinkToken{}, errors.New("not found")
+	}
+
+	return token, nil
+}
+
+func (m *mockMagicLinkRepo) MarkUsedByHash(ctx context.Context, tokenHash string, usedAt time.Time) error {
+	if _, ok := m.active[tokenHash]; !ok {
+		return errors.New("not found")
+	}
+
+	delete(m.active, tokenHash)
+	m.used = append(m.used, tokenHash)
+
+	return nil
+}
+
+type mockMailer struct {
+	magicLinkURLs []string
+	verifyEmails  []string
+	changeNotices []string
+	resetURLs     []string
+	signInAlerts  []string
+}
+
+func (m *mockMailer) SendResetPasswordEmail(ctx context.Context, toEmail, toName, resetURL string) error {
+	m.resetURLs = append(m.resetURLs, resetURL)
+	return nil
+}
+
+func (m *mockMailer) SendVerifyEmail(ctx context.Context, toEmail, toName, verifyURL string) error {
+	m.verifyEmails = append(m.verifyEmails, toEmail)
+	return nil
+}
+
+func (m *mockMailer) SendMagicLinkEmail(ctx context.Context, toEmail, toName, loginURL string) error {
+	m.magicLinkURLs = append(m.magicLinkURLs, loginURL)
+	return nil
+}
+
+func (m *mockMailer) SendEmailChangeNotice(ctx context.Context, toEmail, toName, newEmail string) error {
+	m.changeNotices = append(m.changeNotices, toEmail+" -> "+newEmail)
+	return nil
+}
+
+func (m *mockMailer) SendNewSignInAlert(ctx context.Context, toEmail, toName, userAgent, ipAddress, denyURL string) error {
+	m.signInAlerts = append(m.signInAlerts, denyURL)
+	return nil
+}
+
+func newMagicLinkUsecaseForTest(u *mockUserRepo, links *mockMagicLinkRepo, mailer *mockMailer) *Usecase {
+	return NewUsecase(u, &mockOAuthRepo{}, &mockRefreshRepo{}, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, nil, mailer, "", "").
+		WithMagicLink(links, "https://app.example.com/magic-login")
+}
+
+func TestRequestMagicLink_SendsSingleUseLink(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	repo := &mockUserRepo{
+		getByEmailFn: func(ctx context.Context, email string) (user.User, error) {
+			return user.User{ID: "u1", Email: email, Status: user.UserStatusActive}, nil
+		},
+	}
+	links := &mockMagicLinkRepo{}
+	mailer := &mockMailer{}
+	uc := newMagicLinkUsecaseForTest(repo, links, mailer)
+	uc.setNowForTest(now)
+
+	require.NoError(t, uc.RequestMagicLink(context.Background(), "john@example.com"))
+
+	require.Len(t, mailer.magicLinkURLs, 1)
+	loginURL, err := url.Parse(mailer.magicLinkURLs[0])
+	require.NoError(t, err)
+	assert.Equal(t, "app.example.com", loginURL.Host)
+
+	token := loginURL.Query().Get("token")
+	require.NotEmpty(t, token)
+	require.Len(t, links.saved, 1)
+	assert.Equal(t, hashToken(token), links.saved[0].TokenHash, "only the hash is stored")
+	assert.Equal(t, now.Add(defaultMagicLinkTTL), links.saved[0].ExpiresAt)
+}
+
+func TestRequestMagicLink_IgnoresUnknownAndInactiveUsers(t *testing.T) {
+	repo := &mockUserRepo{
+		getByEmailFn: func(ctx context.Context, email string) (user.User, error) {
+			if email == "gone@example.com" {
+				return user.User{ID: "u2", Email: email, Status: user.UserStatusInactive}, nil
+			}
+
+			return user.User{}, user.ErrUserNotFound
+		},
+	}
+	mailer := &mockMailer{}
+	uc := newMagicLinkUsecaseForTest(repo, &mockMagicLinkRepo{}, mailer)
+
+	require.NoError(t, uc.RequestMagicLink(context.Background(), "nobody@example.com"))
+	require.NoError(t, uc.RequestMagicLink(context.Background(), "gone@example.com"))
+	assert.Empty(t, mailer.magicLinkURLs)
+}
+
+func TestLoginWithMagicLink_IssuesTokensAndVerifiesEmail(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	var verifiedAt *time.Time
+
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, Email: "john@example.com", Status: user.UserStatusActive}, nil
+		},
+		updateEmailVerifiedFn: func(ctx context.Context, id string, at *time.Time) error {
+			verifiedAt = at
+			return nil
+		},
+	}
+	links := &mockMagicLinkRepo{active: map[string]MagicLinkToken{
+		hashToken("link-token"): {UserID: "u1", ExpiresAt: now.Add(time.Minute)},
+	}}
+	uc := newMagicLinkUsecaseForTest(repo, links, &mockMailer{})
+	uc.setNowForTest(now)
+
+	result, err := uc.LoginWithMagicLink(context.Background(), "link-token")
+
+	require.NoError(t, err)
+	assert.False(t, result.TwoFactorRequired())
+	assert.Equal(t, "access-token", result.Tokens.AccessToken)
+	require.NotNil(t, verifiedAt)
+	assert.Equal(t, now, *verifiedAt)
+
+	_, err = uc.LoginWithMagicLink(context.Background(), "link-token")
+	assert.ErrorIs(t, err, ErrInvalidMagicLinkToken, "the link works once")
+}
+
+func TestLoginWithMagicLink_RespectsAccountState(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	lockUntil := now.Add(time.Hour)
+	users := map[string]user.User{
+		"locked":   {ID: "locked", Status: user.UserStatusLocked, LockUntil: &lockUntil},
+		"inactive": {ID: "inactive", Status: user.UserStatusInactive},
+		"reset":    {ID: "reset", Status: user.UserStatusActive, MustResetPassword: true},
+	}
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return users[id], nil
+		},
+	}
+	links := &mockMagicLinkRepo{active: map[string]MagicLinkToken{
+		hashToken("locked-token"):   {UserID: "locked", ExpiresAt: now.Add(time.Minute)},
+		hashToken("inactive-token"): {UserID: "inactive", ExpiresAt: now.Add(time.Minute)},
+		hashToken("reset-token"):    {UserID: "reset", ExpiresAt: now.Add(time.Minute)},
+		hashToken("expired-token"):  {UserID: "locked", ExpiresAt: now.Add(-time.Minute)},
+	}}
+	uc := newMagicLinkUsecaseForTest(repo, links, &mockMailer{})
+	uc.setNowForTest(now)
+
+	_, err := uc.LoginWithMagicLink(context.Background(), "locked-token")
+	require.ErrorIs(t, err, ErrAccountLocked)
+	assert.Empty(t, links.used, "a locked account keeps its link")
+
+	_, err = uc.LoginWithMagicLink(context.Background(), "inactive-token")
+	assert.ErrorIs(t, err, ErrAccountInactive)
+
+	_, err = uc.LoginWithMagicLink(context.Background(), "reset-token")
+	assert.ErrorIs(t, err, ErrPasswordResetRequired, "a reported sign-in forces a password reset first")
+
+	_, err = uc.LoginWithMagicLink(context.Background(), "expired-token")
+	assert.ErrorIs(t, err, ErrInvalidMagicLinkToken)
+}
+
+func TestLoginWithMagicLink_RequiresSecondFactor(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, Status: user.UserStatusActive, EmailVerifiedAt: &now}, nil
+		},
+	}
+	links := &mockMagicLinkRepo{active: map[string]MagicLinkToken{
+		hashToken("link-token"): {UserID: "u1", ExpiresAt: now.Add(time.Minute)},
+	}}
+	uc := newMagicLinkUsecaseForTest(repo, links, &mockMailer{}).
+		WithTwoFactor(enabledTwoFactorRepo(), stubTOTP{})
+	uc.setNowForTest(now)
+
+	result, err := uc.LoginWithMagicLink(context.Background(), "link-token")
+
+	require.NoError(t, err)
+	assert.True(t, result.TwoFactorRequired())
+	assert.Empty(t, result.Tokens.AccessToken)
+}
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"hexagon/audit"
+	"hexagon/user"
+)
+
+var (
+	ErrInvalidSignInAlertToken   = errors.New("invalid sign-in alert token")
+	ErrSignInAlertsNotConfigured = errors.New("new sign-in alerts not configured")
+	ErrPasswordResetRequired     = errors.New("password must be reset before logging in")
+)
+
+const defaultSignInAlertTTL = 7 * 24 * time.Hour
+
+// SignInAlert is sent for a sign-in from a client the user never signed in
+// from. Its token lets the owner report the sign-in as not theirs.
+type SignInAlert struct {
+	UserID    string
+	TokenHash string
+	UserAgent string
+	IPAddress string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+type SignInAlertRepository interface {
+	// HasSignedInFrom reports whether userID ever got a refresh token from
+	// client, and whether it ever got one at all.
+	HasSignedInFrom(ctx context.Context, userID string, client ClientInfo) (fromClient, before bool, err error)
+	Save(ctx context.Context, alert SignInAlert) error
+	GetActiveByHash(ctx context.Context, tokenHash string) (SignInAlert, error)
+	MarkUsedByHash(ctx context.Context, tokenHash string, usedAt time.Time) error
+}
+
+// WithNewSignInAlerts emails users about sign-ins from new clients. denyURL
+// is the frontend page that receives the "this wasn't me" token as its
+// "token" query parameter.
+func (uc *Usecase) WithNewSignInAlerts(repo SignInAlertRepository, denyURL string) *Usecase {
+	uc.signInAlertRepo = repo
+	uc.signInDenyURL = strings.TrimSpace(denyURL)
+
+	return uc
+}
+
+func (uc *Usecase) signInAlertsEnabled() bool {
+	return uc.signInAlertRepo != nil && uc.mailer != nil && uc.signInDenyURL != ""
+}
+
+// isNewSignInClient reports whether u signs in from a client it never used.
+// A first sign-in is not new: there is nothing to compare it with. Call it
+// before issuing the tokens of the sign-in.
+func (uc *Usecase) isNewSignInClient(ctx context.Context, u user.User) bool {
+	if !uc.signInAlertsEnabled() {
+		return false
+	}
+
+	client := clientInfoFromContext(ctx)
+	if client.UserAgent == "" && client.IPAddress == "" {
+		return false
+	}
+
+	fromClient, before, err := uc.signInAlertRepo.HasSignedInFrom(ctx, u.ID, client)
+	if err != nil {
+		return false
+	}
+
+	return before && !fromClient
+}
+
+// sendSignInAlert is best-effort: the sign-in already succeeded and must
+// not fail because the email could not be sent.
+func (uc *Usecase) sendSignInAlert(ctx context.Context, u user.User) {
+	denyToken, err := generateRandomPassword(24)
+	if err != nil {
+		return
+	}
+
+	client := clientInfoFromContext(ctx)
+
+	if err := uc.signInAlertRepo.Save(ctx, SignInAlert{
+		UserID:    u.ID,
+		TokenHash: hashToken(denyToken),
+		UserAgent: client.UserAgent,
+		IPAddress: client.IPAddress,
+		ExpiresAt: uc.now().Add(defaultSignInAlertTTL),
+	}); err != nil {
+		return
+	}
+
+	denyURL, err := composeResetPasswordURL(uc.signInDenyURL, denyToken)
+	if err != nil {
+		return
+	}
+
+	_ = uc.mailer.SendNewSignInAlert(ctx, u.Email, u.Name, client.UserAgent, client.IPAddress, denyURL)
+}
+
+// DenySignIn handles the "this wasn't me" link of a sign-in alert: every
+// session of the user is logged out, and an account with a password has to
+// reset it before logging in with a password again. The reset link is
+// emailed right away.
+func (uc *Usecase) DenySignIn(ctx context.Context, denyToken string) error {
+	if !uc.signInAlertsEnabled() {
+		return ErrSignInAlertsNotConfigured
+	}
+
+	denyToken = strings.TrimSpace(denyToken)
+	if denyToken == "" {
+		return ErrInvalidSignInAlertToken
+	}
+
+	tokenHash := hashToken(denyToken)
+	now := uc.now()
+
+	alert, err := uc.signInAlertRepo.GetActiveByHash(ctx, tokenHash)
+	if err != nil || !now.Before(alert.ExpiresAt) {
+		return ErrInvalidSignInAlertToken
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, alert.UserID)
+	if err != nil {
+		return ErrInvalidSignInAlertToken
+	}
+
+	// Everything before MarkUsedByHash is safe to repeat, so a failed
+	// attempt can be retried with the same link.
+	if err := uc.refreshRepo.RevokeAllByUserID(ctx, u.ID, now); err != nil {
+		return err
+	}
+
+	if err := uc.revokeUserAccessTokens(ctx, u.ID, now); err != nil {
+		return err
+	}
+
+	resetPassword := strings.TrimSpace(u.PasswordHash) != ""
+	if resetPassword {
+		if err := uc.userRepo.RequirePasswordReset(ctx, u.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := uc.signInAlertRepo.MarkUsedByHash(ctx, tokenHash, now); err != nil {
+		return err
+	}
+
+	uc.recordUserAudit(ctx, u.ID, audit.EventSignInDenied, map[string]string{
+		"user_agent": alert.UserAgent,
+		"ip_address": alert.IPAddress,
+	})
+
+	if !resetPassword || uc.resetBaseURL == "" {
+		return nil
+	}
+
+	return uc.sendPasswordReset(ctx, u)
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"hexagon/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSignInAlertRepo struct {
+	clients map[string]bool
+	alerts  map[string]SignInAlert
+	used    []string
+}
+
+func (m *mockSignInAlertRepo) HasSignedInFrom(ctx context.Context, userID string, client ClientInfo) (bool, bool, error) {
+	return m.clients[client.UserAgent+"|"+client.IPAddress], len(m.clients) > 0, nil
+}
+
+func (m *mockSignInAlertRepo) Save(ctx context.Context, alert SignInAlert) error {
+	m.alerts[alert.TokenHash] = alert
+	return nil
+}
+
+func (m *mockSignInAlertRepo) GetActiveByHash(ctx context.Context, tokenHash string) (SignInAlert, error) {
+	alert, ok := m.alerts[tokenHash]
+	if !ok || alert.UsedAt != nil {
+		return SignInAlert{}, ErrInvalidSignInAlertToken
+	}
+
+	return alert, nil
+}
+
+func (m *mockSignInAlertRepo) MarkUsedByHash(ctx context.Context, tokenHash string, usedAt time.Time) error {
+	alert := m.alerts[tokenHash]
+	alert.UsedAt = &usedAt
+	m.alerts[tokenHash] = alert
+	m.used = append(m.used, tokenHash)
+
+	return nil
+}
+
+func newSignInUsecaseForTest(u *mockUserRepo, r *mockRefreshRepo, alerts *mockSignInAlertRepo, mailer *mockMailer) *Usecase {
+	return NewUsecase(u, &mockOAuthRepo{}, r, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, nil, mailer, "https://app.example.com/reset-password", "").
+		WithNewSignInAlerts(alerts, "https://app.example.com/sign-in/deny")
+}
+
+func verifiedUserRepo() *mockUserRepo {
+	verifiedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	return &mockUserRepo{
+		getByEmailFn: func(ctx context.Context, email string) (user.User, error) {
+			return user.User{
+				ID:              "u1",
+				Name:            "John",
+				Email:           email,
+				PasswordHash:    "hashed-password",
+				Status:          user.UserStatusActive,
+				EmailVerifiedAt: &verifiedAt,
+			}, nil
+		},
+	}
+}
+
+func TestLogin_AlertsOnNewClient(t *testing.T) {
+	tests := []struct {
+		name      string
+		clients   map[string]bool
+		wantAlert bool
+	}{
+		{name: "new client", clients: map[string]bool{"Firefox|198.51.100.1": true}, wantAlert: true},
+		{name: "known client", clients: map[string]bool{"Chrome|203.0.113.7": true}, wantAlert: false},
+		{name: "first sign-in", clients: map[string]bool{}, wantAlert: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := &mockSignInAlertRepo{clients: tt.clients, alerts: map[string]SignInAlert{}}
+			mailer := &mockMailer{}
+			uc := newSignInUsecaseForTest(verifiedUserRepo(), &mockRefreshRepo{}, alerts, mailer)
+			ctx := WithClientInfo(context.Background(), ClientInfo{UserAgent: "Chrome", IPAddress: "203.0.113.7"})
+
+			result, err := uc.Login(ctx, "john@example.com", "Password@123")
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, result.Tokens.AccessToken)
+
+			if !tt.wantAlert {
+				assert.Empty(t, mailer.signInAlerts)
+				assert.Empty(t, alerts.alerts)
+
+				return
+			}
+
+			require.Len(t, mailer.signInAlerts, 1)
+			assert.True(t, strings.HasPrefix(mailer.signInAlerts[0], "https://app.example.com/sign-in/deny?token="))
+			require.Len(t, alerts.alerts, 1)
+
+			for hash, alert := range alerts.alerts {
+				assert.NotContains(t, mailer.signInAlerts[0], hash)
+				assert.Equal(t, "u1", alert.UserID)
+				assert.Equal(t, "Chrome", alert.UserAgent)
+				assert.Equal(t, "203.0.113.7", alert.IPAddress)
+			}
+		})
+	}
+}
+
+func TestLogin_RequiresPasswordResetAfterDeniedSignIn(t *testing.T) {
+	repo := verifiedUserRepo()
+	getByEmail := repo.getByEmailFn
+	repo.getByEmailFn = func(ctx context.Context, email string) (user.User, error) {
+		u, err := getByEmail(ctx, email)
+		u.MustResetPassword = true
+
+		return u, err
+	}
+	uc := newUsecaseForTest(repo, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{})
+
+	_, err := uc.Login(context.Background(), "john@example.com", "Password@123")
+
+	assert.ErrorIs(t, err, ErrPasswordResetRequired)
+}
+
+func TestDenySignIn_LogsOutAndRequiresPasswordReset(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	alerts := &mockSignInAlertRepo{alerts: map[string]SignInAlert{
+		hashToken("deny-token"): {UserID: "u1", TokenHash: hashToken("deny-token"), ExpiresAt: now.Add(time.Hour)},
+	}}
+
+	var revokedUserID, resetUserID string
+
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, Email: "john@example.com", PasswordHash: "hashed-password"}, nil
+		},
+		requireResetFn: func(ctx context.Context, id string) error {
+			resetUserID = id
+			return nil
+		},
+	}
+	refresh := &mockRefreshRepo{
+		revokeAllByUserIDFn: func(ctx context.Context, userID string, revokedAt time.Time) error {
+			revokedUserID = userID
+			return nil
+		},
+	}
+	mailer := &mockMailer{}
+	uc := newSignInUsecaseForTest(repo, refresh, alerts, mailer)
+	uc.now = func() time.Time { return now }
+
+	require.NoError(t, uc.DenySignIn(context.Background(), "deny-token"))
+
+	assert.Equal(t, "u1", revokedUserID)
+	assert.Equal(t, "u1", resetUserID)
+	assert.Equal(t, []string{hashToken("deny-token")}, alerts.used)
+	require.Len(t, mailer.resetURLs, 1)
+	assert.True(t, strings.HasPrefix(mailer.resetURLs[0], "https://app.example.com/reset-password?token="))
+
+	assert.ErrorIs(t, uc.DenySignIn(context.Background(), "deny-token"), ErrInvalidSignInAlertToken)
+}
+
+func TestDenySignIn_KeepsOAuthOnlyAccountsWithoutPassword(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	alerts := &mockSignInAlertRepo{alerts: map[string]SignInAlert{
+		hashToken("deny-token"): {UserID: "u1", TokenHash: hashToken("deny-token"), ExpiresAt: now.Add(time.Hour)},
+	}}
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, Email: "john@example.com"}, nil
+		},
+		requireResetFn: func(ctx context.Context, id string) error {
+			return errors.New("must not require a reset")
+		},
+	}
+	mailer := &mockMailer{}
+	uc := newSignInUsecaseForTest(repo, &mockRefreshRepo{}, alerts, mailer)
+	uc.now = func() time.Time { return now }
+
+	require.NoError(t, uc.DenySignIn(context.Background(), "deny-token"))
+
+	assert.Empty(t, mailer.resetURLs)
+	assert.Len(t, alerts.used, 1)
+}
+
+func TestDenySignIn_RejectsExpiredToken(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	alerts := &mockSignInAlertRepo{alerts: map[string]SignInAlert{
+		hashToken("deny-token"): {UserID: "u1", TokenHash: hashToken("deny-token"), ExpiresAt: now.Add(-time.Minute)},
+	}}
+	uc := newSignInUsecaseForTest(&mockUserRepo{}, &mockRefreshRepo{}, alerts, &mockMailer{})
+	uc.now = func() time.Time { return now }
+
+	err := uc.DenySignIn(context.Background(), "deny-token")
+
+	assert.ErrorIs(t, err, ErrInvalidSignInAlertToken)
+	assert.Empty(t, alerts.used)
+}
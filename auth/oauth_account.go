@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"hexagon/audit"
+	"hexagon/user"
+)
+
+var (
+	ErrOAuthAccountNotLinked       = errors.New("oauth account not linked")
+	ErrOAuthAccountLinkedElsewhere = errors.New("oauth account is linked to another user")
+	ErrOAuthProviderAlreadyLinked  = errors.New("another account of this oauth provider is already linked")
+	ErrInvalidOAuthLinkState       = errors.New("invalid oauth link state")
+	ErrOAuthLinkRequired           = errors.New("an account with this email already exists; sign in and link the oauth provider")
+)
+
+// defaultTrustedEmailProviders are the providers whose verified emails are
+// trusted to sign in to an existing account with the same email.
+var defaultTrustedEmailProviders = []OAuthProvider{"google"}
+
+// WithTrustedEmailProviders replaces the providers, Google by default,
+// whose first sign-in is linked to the existing account with the same
+// email. Any other provider has to be linked by the signed-in user with
+// OAuthLinkURL, since an issuer that lets users pick their email could
+// otherwise take over accounts.
+func (uc *Usecase) WithTrustedEmailProviders(names ...OAuthProvider) *Usecase {
+	uc.linkByEmail = make(map[OAuthProvider]bool, len(names))
+	for _, name := range names {
+		uc.linkByEmail[name] = true
+	}
+
+	return uc
+}
+
+// OAuthAccount is an external identity linked to a user. A user has at most
+// one account per provider.
+type OAuthAccount struct {
+	UserID         string
+	Provider       OAuthProvider
+	ProviderUserID string
+	ProviderEmail  string
+	LinkedAt       time.Time
+}
+
+// ListOAuthAccounts returns the identities userID can sign in with.
+func (uc *Usecase) ListOAuthAccounts(ctx context.Context, userID string) ([]OAuthAccount, error) {
+	return uc.oauthRepo.ListByUserID(ctx, userID)
+}
+
+// OAuthLinkURL starts a flow that links provider to userID instead of
+// logging in. The state of the flow is a signed token naming the user, so
+// the callback, which carries no access token, knows whom to link and
+// cannot be pointed at someone else.
+func (uc *Usecase) OAuthLinkURL(ctx context.Context, userID string, name OAuthProvider) (string, OAuthFlow, error) {
+	provider, err := uc.oauthProvider(name)
+	if err != nil {
+		return "", OAuthFlow{}, err
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", OAuthFlow{}, err
+	}
+
+	flow, err := newOAuthFlow()
+	if err != nil {
+		return "", OAuthFlow{}, err
+	}
+
+	flow.State, err = uc.tokenProvider.GenerateOAuthLinkToken(u, string(name))
+	if err != nil {
+		return "", OAuthFlow{}, err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, flow)
+	if err != nil {
+		return "", OAuthFlow{}, err
+	}
+
+	return authURL, flow, nil
+}
+
+// LinkOAuthAccount completes a flow started by OAuthLinkURL. The provider
+// email does not have to match the user's: the user is signed in and asked
+// for the link.
+func (uc *Usecase) LinkOAuthAccount(ctx context.Context, name OAuthProvider, code string, flow OAuthFlow) (OAuthAccount, error) {
+	provider, err := uc.oauthProvider(name)
+	if err != nil {
+		return OAuthAccount{}, err
+	}
+
+	if strings.TrimSpace(code) == "" {
+		return OAuthAccount{}, ErrMissingCode
+	}
+
+	linkUser, linkProvider, err := uc.tokenProvider.ParseOAuthLinkToken(flow.State)
+	if err != nil || OAuthProvider(linkProvider) != name {
+		return OAuthAccount{}, ErrInvalidOAuthLinkState
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, linkUser.ID)
+	if err != nil {
+		return OAuthAccount{}, err
+	}
+
+	if u.Status == user.UserStatusInactive {
+		return OAuthAccount{}, ErrAccountInactive
+	}
+
+	oauthUser, err := provider.Exchange(ctx, code, flow)
+	if err != nil {
+		return OAuthAccount{}, err
+	}
+
+	oauthUser.Provider = name
+	oauthUser.ProviderUserID = strings.TrimSpace(oauthUser.ProviderUserID)
+
+	if oauthUser.ProviderUserID == "" {
+		return OAuthAccount{}, ErrInvalidOAuthUser
+	}
+
+	if err := uc.checkOAuthAccountLinkable(ctx, u.ID, oauthUser); err != nil {
+		return OAuthAccount{}, err
+	}
+
+	if err := uc.linkOAuthProvider(ctx, u.ID, oauthUser); err != nil {
+		return OAuthAccount{}, err
+	}
+
+	uc.recordSecurityEvent(ctx, SecurityEvent{
+		UserID: u.ID,
+		Type:   SecurityEventOAuthLinked,
+	})
+	uc.recordUserAudit(ctx, u.ID, audit.EventOAuthLinked, map[string]string{"provider": string(name)})
+
+	return OAuthAccount{
+		UserID:         u.ID,
+		Provider:       name,
+		ProviderUserID: oauthUser.ProviderUserID,
+		ProviderEmail:  oauthUser.Email,
+		LinkedAt:       uc.now(),
+	}, nil
+}
+
+// checkOAuthAccountLinkable refuses identities that belong to another user,
+// which linking would silently move, and a second account of a provider
+// the user already linked.
+func (uc *Usecase) checkOAuthAccountLinkable(ctx context.Context, userID string, oauthUser OAuthUser) error {
+	ownerID, err := uc.oauthRepo.GetUserIDByProvider(ctx, oauthUser.Provider, oauthUser.ProviderUserID)
+	switch {
+	case err == nil && ownerID != userID:
+		return ErrOAuthAccountLinkedElsewhere
+	case err != nil && !errors.Is(err, ErrOAuthAccountNotLinked):
+		return err
+	}
+
+	accounts, err := uc.oauthRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, account := range accounts {
+		if account.Provider == oauthUser.Provider && account.ProviderUserID != oauthUser.ProviderUserID {
+			return ErrOAuthProviderAlreadyLinked
+		}
+	}
+
+	return nil
+}
+
+// UnlinkOAuthAccount removes the provider identity of userID. An account
+// without a password keeps at least one identity to sign in with.
+func (uc *Usecase) UnlinkOAuthAccount(ctx context.Context, userID string, name OAuthProvider) error {
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	accounts, err := uc.oauthRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	linked := slices.ContainsFunc(accounts, func(account OAuthAccount) bool {
+		return account.Provider == name
+	})
+	if !linked {
+		return ErrOAuthAccountNotLinked
+	}
+
+	if u.PasswordHash == "" && len(accounts) == 1 {
+		return ErrPasswordAuthNotAvailable
+	}
+
+	if err := uc.oauthRepo.Delete(ctx, userID, name); err != nil {
+		return err
+	}
+
+	uc.recordSecurityEvent(ctx, SecurityEvent{
+		UserID: userID,
+		Type:   SecurityEventOAuthUnlinked,
+	})
+	uc.recordUserAudit(ctx, userID, audit.EventOAuthUnlinked, map[string]string{"provider": string(name)})
+
+	return nil
+}
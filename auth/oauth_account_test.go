@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"hexagon/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOAuthAccountUsecaseForTest(u user.User, oauthRepo *mockOAuthRepo, provider *mockOAuthLoginProvider) *Usecase {
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			if id != u.ID {
+				return user.User{}, user.ErrUserNotFound
+			}
+
+			return u, nil
+		},
+	}
+
+	return NewUsecase(repo, oauthRepo, &mockRefreshRepo{}, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, OAuthProviders{
+		"google": provider,
+	}, nil, "", "")
+}
+
+func TestLinkOAuthAccount_LinksProviderToUserOfState(t *testing.T) {
+	oauthRepo := &mockOAuthRepo{}
+	provider := &mockOAuthLoginProvider{user: OAuthUser{ProviderUserID: "google-1", Email: "other@example.com"}}
+	uc := newOAuthAccountUsecaseForTest(user.User{ID: "u1", PasswordHash: "hash"}, oauthRepo, provider)
+
+	authURL, flow, err := uc.OAuthLinkURL(context.Background(), "u1", "google")
+	require.NoError(t, err)
+	assert.Contains(t, authURL, flow.State)
+
+	account, err := uc.LinkOAuthAccount(context.Background(), "google", "code", flow)
+
+	require.NoError(t, err)
+	assert.Equal(t, "u1", account.UserID)
+	assert.Equal(t, "other@example.com", account.ProviderEmail, "the provider email may differ from the user's")
+	assert.Equal(t, []OAuthProvider{"google"}, oauthRepo.linked)
+}
+
+func TestLinkOAuthAccount_RejectsStateOfLogin(t *testing.T) {
+	uc := newOAuthAccountUsecaseForTest(user.User{ID: "u1"}, &mockOAuthRepo{}, &mockOAuthLoginProvider{})
+
+	_, flow, err := uc.OAuthAuthURL(context.Background(), "google")
+	require.NoError(t, err)
+
+	_, err = uc.LinkOAuthAccount(context.Background(), "google", "code", flow)
+	assert.ErrorIs(t, err, ErrInvalidOAuthLinkState)
+
+	_, err = uc.LinkOAuthAccount(context.Background(), "google", "code", OAuthFlow{State: "link.u1.okta"})
+	assert.ErrorIs(t, err, ErrInvalidOAuthLinkState, "a state issued for another provider is rejected")
+}
+
+func TestLinkOAuthAccount_RefusesIdentityOfAnotherUser(t *testing.T) {
+	oauthRepo := &mockOAuthRepo{accounts: []OAuthAccount{{UserID: "u2", Provider: "google", ProviderUserID: "google-1"}}}
+	provider := &mockOAuthLoginProvider{user: OAuthUser{ProviderUserID: "google-1"}}
+	uc := newOAuthAccountUsecaseForTest(user.User{ID: "u1"}, oauthRepo, provider)
+
+	_, err := uc.LinkOAuthAccount(context.Background(), "google", "code", OAuthFlow{State: "link.u1.google"})
+
+	assert.ErrorIs(t, err, ErrOAuthAccountLinkedElsewhere)
+	assert.Empty(t, oauthRepo.linked)
+}
+
+func TestLinkOAuthAccount_RefusesSecondAccountOfProvider(t *testing.T) {
+	oauthRepo := &mockOAuthRepo{accounts: []OAuthAccount{{UserID: "u1", Provider: "google", ProviderUserID: "google-1"}}}
+	provider := &mockOAuthLoginProvider{user: OAuthUser{ProviderUserID: "google-2"}}
+	uc := newOAuthAccountUsecaseForTest(user.User{ID: "u1"}, oauthRepo, provider)
+
+	_, err := uc.LinkOAuthAccount(context.Background(), "google", "code", OAuthFlow{State: "link.u1.google"})
+
+	assert.ErrorIs(t, err, ErrOAuthProviderAlreadyLinked)
+}
+
+func TestUnlinkOAuthAccount(t *testing.T) {
+	google := OAuthAccount{UserID: "u1", Provider: "google", ProviderUserID: "google-1"}
+	corp := OAuthAccount{UserID: "u1", Provider: "corp", ProviderUserID: "corp-1"}
+
+	tests := []struct {
+		name     string
+		user     user.User
+		accounts []OAuthAccount
+		provider OAuthProvider
+		wantErr  error
+	}{
+		{name: "with password", user: user.User{ID: "u1", PasswordHash: "hash"}, accounts: []OAuthAccount{google}, provider: "google"},
+		{name: "another provider left", user: user.User{ID: "u1"}, accounts: []OAuthAccount{google, corp}, provider: "google"},
+		{name: "last sign-in method", user: user.User{ID: "u1"}, accounts: []OAuthAccount{google}, provider: "google", wantErr: ErrPasswordAuthNotAvailable},
+		{name: "not linked", user: user.User{ID: "u1", PasswordHash: "hash"}, accounts: []OAuthAccount{corp}, provider: "google", wantErr: ErrOAuthAccountNotLinked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oauthRepo := &mockOAuthRepo{accounts: tt.accounts}
+			uc := newOAuthAccountUsecaseForTest(tt.user, oauthRepo, &mockOAuthLoginProvider{})
+
+			err := uc.UnlinkOAuthAccount(context.Background(), "u1", tt.provider)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, oauthRepo.deleted)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []OAuthProvider{tt.provider}, oauthRepo.deleted)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	ErrPhoneVerificationNotConfigured = errors.New("phone verification not configured")
+	ErrPhoneMissing                   = errors.New("account has no phone number")
+	ErrPhoneAlreadyVerified           = errors.New("phone number is already verified")
+	ErrPhoneCodeRecentlySent          = errors.New("a phone verification code was sent recently")
+	ErrInvalidPhoneCode               = errors.New("invalid phone verification code")
+	ErrTooManyPhoneCodeAttempts       = errors.New("too many phone verification attempts")
+)
+
+const (
+	phoneCodeDigits = 6
+	phoneCodeTTL    = 10 * time.Minute
+	// phoneCodeResendAfter keeps a client from sending texts in a loop.
+	phoneCodeResendAfter = time.Minute
+	maxPhoneCodeAttempts = 5
+)
+
+// SMSSender delivers text messages, e.g. through an SMS gateway.
+type SMSSender interface {
+	SendSMS(ctx context.Context, toPhone, message string) error
+}
+
+// PhoneVerificationCode is a pending code for the phone number it was sent
+// to. Only its hash is stored.
+type PhoneVerificationCode struct {
+	UserID    string
+	Phone     string
+	CodeHash  string
+	Attempts  int
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// PhoneVerificationRepository keeps at most one pending code per user.
+type PhoneVerificationRepository interface {
+	// Save replaces the pending code of the user.
+	Save(ctx context.Context, code PhoneVerificationCode) error
+	// Get returns ErrInvalidPhoneCode when the user has no pending code.
+	Get(ctx context.Context, userID string) (PhoneVerificationCode, error)
+	// IncrementAttempts returns the number of attempts including this one.
+	IncrementAttempts(ctx context.Context, userID string) (int, error)
+	Delete(ctx context.Context, userID string) error
+}
+
+// WithPhoneVerification enables verifying phone numbers with codes sent by
+// sender.
+func (uc *Usecase) WithPhoneVerification(repo PhoneVerificationRepository, sender SMSSender) *Usecase {
+	uc.phoneCodeRepo = repo
+	uc.smsSender = sender
+
+	return uc
+}
+
+// SendPhoneVerification texts a code to the user's phone number. A new code
+// replaces the pending one.
+func (uc *Usecase) SendPhoneVerification(ctx context.Context, userID string) error {
+	if uc.phoneCodeRepo == nil || uc.smsSender == nil {
+		return ErrPhoneVerificationNotConfigured
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	phone := strings.TrimSpace(u.Phone)
+	if phone == "" {
+		return ErrPhoneMissing
+	}
+
+	if u.PhoneVerifiedAt != nil {
+		return ErrPhoneAlreadyVerified
+	}
+
+	now := uc.now()
+
+	pending, err := uc.phoneCodeRepo.Get(ctx, userID)
+	if err == nil && pending.Phone == phone && now.Sub(pending.CreatedAt) < phoneCodeResendAfter {
+		return ErrPhoneCodeRecentlySent
+	}
+
+	code, err := generatePhoneCode()
+	if err != nil {
+		return err
+	}
+
+	if err := uc.phoneCodeRepo.Save(ctx, PhoneVerificationCode{
+		UserID:    userID,
+		Phone:     phone,
+		CodeHash:  hashPhoneCode(userID, phone, code),
+		ExpiresAt: now.Add(phoneCodeTTL),
+		CreatedAt: now,
+	}); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Your Hexagon verification code is %s. It expires in %d minutes.", code, int(phoneCodeTTL.Minutes()))
+
+	return uc.smsSender.SendSMS(ctx, phone, message)
+}
+
+// VerifyPhone marks the user's phone number verified. A code only works for
+// the number it was sent to and is dropped after maxPhoneCodeAttempts wrong
+// guesses.
+func (uc *Usecase) VerifyPhone(ctx context.Context, userID, code string) error {
+	if uc.phoneCodeRepo == nil || uc.smsSender == nil {
+		return ErrPhoneVerificationNotConfigured
+	}
+
+	pending, err := uc.phoneCodeRepo.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	now := uc.now()
+	if !now.Before(pending.ExpiresAt) {
+		return uc.dropPhoneCode(ctx, userID, ErrInvalidPhoneCode)
+	}
+
+	// Count the attempt before checking it, so parallel guesses are counted
+	// too.
+	attempts, err := uc.phoneCodeRepo.IncrementAttempts(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if attempts > maxPhoneCodeAttempts {
+		return uc.dropPhoneCode(ctx, userID, ErrTooManyPhoneCodeAttempts)
+	}
+
+	hash := hashPhoneCode(userID, pending.Phone, strings.TrimSpace(code))
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(pending.CodeHash)) != 1 {
+		if attempts == maxPhoneCodeAttempts {
+			return uc.dropPhoneCode(ctx, userID, ErrTooManyPhoneCodeAttempts)
+		}
+
+		return ErrInvalidPhoneCode
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(u.Phone) != pending.Phone {
+		// The number changed after the code was sent.
+		return uc.dropPhoneCode(ctx, userID, ErrInvalidPhoneCode)
+	}
+
+	if err := uc.userRepo.UpdatePhoneVerifiedAt(ctx, userID, pending.Phone, now); err != nil {
+		return err
+	}
+
+	return uc.phoneCodeRepo.Delete(ctx, userID)
+}
+
+func (uc *Usecase) dropPhoneCode(ctx context.Context, userID string, cause error) error {
+	if err := uc.phoneCodeRepo.Delete(ctx, userID); err != nil {
+		return err
+	}
+
+	return cause
+}
+
+func generatePhoneCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", phoneCodeDigits, n.Int64()), nil
+}
+
+// hashPhoneCode binds the code to the user and number it was sent to.
+func hashPhoneCode(userID, phone, code string) string {
+	return hashToken(userID + ":" + phone + ":" + code)
+}
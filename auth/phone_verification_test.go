@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"hexagon/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPhoneCodeRepo struct {
+	codes map[string]PhoneVerificationCode
+}
+
+func newMockPhoneCodeRepo() *mockPhoneCodeRepo {
+	return &mockPhoneCodeRepo{codes: map[string]PhoneVerificationCode{}}
+}
+
+func (m *mockPhoneCodeRepo) Save(ctx context.Context, code PhoneVerificationCode) error {
+	m.codes[code.UserID] = code
+	return nil
+}
+
+func (m *mockPhoneCodeRepo) Get(ctx context.Context, userID string) (PhoneVerificationCode, error) {
+	code, ok := m.codes[userID]
+	if !ok {
+		return PhoneVerificationCode{}, ErrInvalidPhoneCode
+	}
+
+	return code, nil
+}
+
+func (m *mockPhoneCodeRepo) IncrementAttempts(ctx context.Context, userID string) (int, error) {
+	code, ok := m.codes[userID]
+	if !ok {
+		return 0, ErrInvalidPhoneCode
+	}
+
+	code.Attempts++
+	m.codes[userID] = code
+
+	return code.Attempts, nil
+}
+
+func (m *mockPhoneCodeRepo) Delete(ctx context.Context, userID string) error {
+	delete(m.codes, userID)
+	return nil
+}
+
+type mockSMSSender struct {
+	phones   []string
+	messages []string
+}
+
+func (m *mockSMSSender) SendSMS(ctx context.Context, toPhone, message string) error {
+	m.phones = append(m.phones, toPhone)
+	m.messages = append(m.messages, message)
+
+	return nil
+}
+
+var phoneCodePattern = regexp.MustCompile(`\b\d{6}\b`)
+
+func (m *mockSMSSender) lastCode(t *testing.T) string {
+	t.Helper()
+	require.NotEmpty(t, m.messages)
+
+	code := phoneCodePattern.FindString(m.messages[len(m.messages)-1])
+	require.NotEmpty(t, code)
+
+	return code
+}
+
+func newPhoneVerificationUsecaseForTest(u *user.User, codes *mockPhoneCodeRepo, sender *mockSMSSender) *Usecase {
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return *u, nil
+		},
+	}
+	repo.updatePhoneVerifiedFn = func(ctx context.Context, id, phone string, verifiedAt time.Time) error {
+		if u.Phone != phone {
+			return user.ErrUserNotFound
+		}
+
+		u.PhoneVerifiedAt = &verifiedAt
+
+		return nil
+	}
+
+	return NewUsecase(repo, &mockOAuthRepo{}, &mockRefreshRepo{}, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, nil, nil, "", "").
+		WithPhoneVerification(codes, sender)
+}
+
+func TestVerifyPhone_MarksPhoneVerifiedWithSentCode(t *testing.T) {
+	now := time.Date(2026, 4, 7, 10, 0, 0, 0, time.UTC)
+	u := &user.User{ID: "u1", Phone: "0912345678"}
+	codes := newMockPhoneCodeRepo()
+	sender := &mockSMSSender{}
+	uc := newPhoneVerificationUsecaseForTest(u, codes, sender)
+	uc.setNowForTest(now)
+
+	require.NoError(t, uc.SendPhoneVerification(context.Background(), "u1"))
+	assert.Equal(t, []string{"0912345678"}, sender.phones)
+
+	code := sender.lastCode(t)
+	assert.NotEqual(t, code, codes.codes["u1"].CodeHash, "only the hash of the code is stored")
+
+	require.NoError(t, uc.VerifyPhone(context.Background(), "u1", code))
+	require.NotNil(t, u.PhoneVerifiedAt)
+	assert.Equal(t, now, *u.PhoneVerifiedAt)
+	assert.Empty(t, codes.codes, "a used code is deleted")
+}
+
+func TestSendPhoneVerification_Rejects(t *testing.T) {
+	verifiedAt := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		user    user.User
+		wantErr error
+	}{
+		{name: "no phone", user: user.User{ID: "u1"}, wantErr: ErrPhoneMissing},
+		{name: "already verified", user: user.User{ID: "u1", Phone: "0912345678", PhoneVerifiedAt: &verifiedAt}, wantErr: ErrPhoneAlreadyVerified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &mockSMSSender{}
+			uc := newPhoneVerificationUsecaseForTest(&tt.user, newMockPhoneCodeRepo(), sender)
+
+			err := uc.SendPhoneVerification(context.Background(), "u1")
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Empty(t, sender.messages)
+		})
+	}
+}
+
+func TestSendPhoneVerification_RateLimitsResend(t *testing.T) {
+	now := time.Date(2026, 4, 7, 10, 0, 0, 0, time.UTC)
+	sender := &mockSMSSender{}
+	uc := newPhoneVerificationUsecaseForTest(&user.User{ID: "u1", Phone: "0912345678"}, newMockPhoneCodeRepo(), sender)
+	uc.setNowForTest(now)
+
+	require.NoError(t, uc.SendPhoneVerification(context.Background(), "u1"))
+	assert.ErrorIs(t, uc.SendPhoneVerification(context.Background(), "u1"), ErrPhoneCodeRecentlySent)
+
+	uc.setNowForTest(now.Add(phoneCodeResendAfter))
+	require.NoError(t, uc.SendPhoneVerification(context.Background(), "u1"))
+	assert.Len(t, sender.messages, 2)
+}
+
+func TestVerifyPhone_DropsCodeAfterTooManyAttempts(t *testing.T) {
+	now := time.Date(2026, 4, 7, 10, 0, 0, 0, time.UTC)
+	codes := newMockPhoneCodeRepo()
+	sender := &mockSMSSender{}
+	uc := newPhoneVerificationUsecaseForTest(&user.User{ID: "u1", Phone: "0912345678"}, codes, sender)
+	uc.setNowForTest(now)
+
+	require.NoError(t, uc.SendPhoneVerification(context.Background(), "u1"))
+	code := sender.lastCode(t)
+
+	for range maxPhoneCodeAttempts - 1 {
+		assert.ErrorIs(t, uc.VerifyPhone(context.Background(), "u1", "wrong"), ErrInvalidPhoneCode)
+	}
+
+	assert.ErrorIs(t, uc.VerifyPhone(context.Background(), "u1", "wrong"), ErrTooManyPhoneCodeAttempts)
+	assert.ErrorIs(t, uc.VerifyPhone(context.Background(), "u1", code), ErrInvalidPhoneCode, "the code is gone")
+}
+
+func TestVerifyPhone_RejectsExpiredCode(t *testing.T) {
+	now := time.Date(2026, 4, 7, 10, 0, 0, 0, time.UTC)
+	codes := newMockPhoneCodeRepo()
+	sender := &mockSMSSender{}
+	uc := newPhoneVerificationUsecaseForTest(&user.User{ID: "u1", Phone: "0912345678"}, codes, sender)
+	uc.setNowForTest(now)
+
+	require.NoError(t, uc.SendPhoneVerification(context.Background(), "u1"))
+
+	uc.setNowForTest(now.Add(phoneCodeTTL))
+	assert.ErrorIs(t, uc.VerifyPhone(context.Background(), "u1", sender.lastCode(t)), ErrInvalidPhoneCode)
+	assert.Empty(t, codes.codes)
+}
+
+func TestVerifyPhone_RejectsCodeAfterPhoneChanged(t *testing.T) {
+	u := &user.User{ID: "u1", Phone: "0912345678"}
+	sender := &mockSMSSender{}
+	uc := newPhoneVerificationUsecaseForTest(u, newMockPhoneCodeRepo(), sender)
+
+	require.NoError(t, uc.SendPhoneVerification(context.Background(), "u1"))
+	u.Phone = "0987654321"
+
+	err := uc.VerifyPhone(context.Background(), "u1", sender.lastCode(t))
+
+	assert.ErrorIs(t, err, ErrInvalidPhoneCode)
+	assert.Nil(t, u.PhoneVerifiedAt)
+}
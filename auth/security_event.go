@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+type SecurityEventType string
+
+const (
+	// SecurityEventRefreshTokenReuse is recorded when a refresh token that was
+	// already rotated or revoked is presented again. It usually means the
+	// token was stolen, so its whole family is revoked.
+	SecurityEventRefreshTokenReuse SecurityEventType = "refresh_token_reuse"
+	SecurityEventTwoFactorEnabled  SecurityEventType = "two_factor_enabled"
+	SecurityEventTwoFactorDisabled SecurityEventType = "two_factor_disabled"
+	// SecurityEventRecoveryCodeUsed is recorded so users can spot a recovery
+	// code they did not use themselves.
+	SecurityEventRecoveryCodeUsed SecurityEventType = "recovery_code_used"
+	SecurityEventOAuthLinked      SecurityEventType = "oauth_linked"
+	SecurityEventOAuthUnlinked    SecurityEventType = "oauth_unlinked"
+	SecurityEventEmailChanged     SecurityEventType = "email_changed"
+)
+
+type SecurityEvent struct {
+	UserID    string
+	Type      SecurityEventType
+	FamilyID  string
+	UserAgent string
+	IPAddress string
+	CreatedAt time.Time
+}
+
+type SecurityEventRepository interface {
+	Save(ctx context.Context, event SecurityEvent) error
+}
+
+// WithSecurityEvents records security events such as refresh token reuse.
+// Without it the events are only acted on, not stored.
+func (uc *Usecase) WithSecurityEvents(repo SecurityEventRepository) *Usecase {
+	uc.securityEvents = repo
+	return uc
+}
+
+// recordSecurityEvent is best-effort: failing to store the event must not
+// undo or block the protective action that triggered it.
+func (uc *Usecase) recordSecurityEvent(ctx context.Context, event SecurityEvent) {
+	if uc.securityEvents == nil {
+		return
+	}
+
+	info := clientInfoFromContext(ctx)
+	event.UserAgent = info.UserAgent
+	event.IPAddress = info.IPAddress
+	event.CreatedAt = uc.now()
+
+	_ = uc.securityEvents.Save(ctx, event)
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session is one login as the user sees it: a refresh token family, named by
+// its FamilyID. CreatedAt is the login time and LastUsedAt the last rotation.
+type Session struct {
+	ID         string
+	UserAgent  string
+	Device     string
+	IPAddress  string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	Current    bool
+}
+
+// ListSessions returns the user's sessions that still hold a usable refresh
+// token, most recently used first. currentSessionID marks the caller's own.
+func (uc *Usecase) ListSessions(ctx context.Context, userID, currentSessionID string) ([]Session, error) {
+	sessions, err := uc.refreshRepo.ListActiveSessions(ctx, userID, uc.now())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Device = deviceLabel(sessions[i].UserAgent)
+		sessions[i].Current = currentSessionID != "" && sessions[i].ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession logs one of the user's sessions out, revoking its refresh
+// tokens and the access tokens already issued to it.
+func (uc *Usecase) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return ErrSessionNotFound
+	}
+
+	if err := uc.refreshRepo.RevokeSession(ctx, userID, sessionID, uc.now()); err != nil {
+		return err
+	}
+
+	return uc.revokeSessionAccessTokens(ctx, sessionID)
+}
+
+// RevokeOtherSessions logs the user out everywhere except currentSessionID.
+func (uc *Usecase) RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) error {
+	if strings.TrimSpace(currentSessionID) == "" {
+		// Without a session id every session would be revoked, including
+		// the caller's; tokens issued before sessions existed must log in again.
+		return ErrSessionNotFound
+	}
+
+	now := uc.now()
+
+	sessions, err := uc.refreshRepo.ListActiveSessions(ctx, userID, now)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.refreshRepo.RevokeOtherSessions(ctx, userID, currentSessionID, now); err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.ID == currentSessionID {
+			continue
+		}
+
+		if err := uc.revokeSessionAccessTokens(ctx, session.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deviceLabel turns a User-Agent into a short "Browser on OS" label. Unknown
+// agents are shown as they are.
+func deviceLabel(userAgent string) string {
+	browser := firstMatch(userAgent, [][2]string{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"okhttp", "Android app"},
+		{"CFNetwork", "iOS app"},
+	})
+	os := firstMatch(userAgent, [][2]string{
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	})
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	default:
+		return userAgent
+	}
+}
+
+func firstMatch(s string, candidates [][2]string) string {
+	for _, c := range candidates {
+		if strings.Contains(s, c[0]) {
+			return c[1]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSessions_MarksCurrentAndLabelsDevice(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	refreshRepo := &mockRefreshRepo{
+		listSessionsFn: func(ctx context.Context, userID string, at time.Time) ([]Session, error) {
+			assert.Equal(t, "u1", userID)
+			assert.Equal(t, now, at)
+
+			return []Session{
+				{ID: "s-1", UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Safari/605.1.15"},
+				{ID: "s-2", UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36 Edg/120.0"},
+			}, nil
+		},
+	}
+	uc := newUsecaseForTest(&mockUserRepo{}, refreshRepo, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{})
+	uc.setNowForTest(now)
+
+	sessions, err := uc.ListSessions(context.Background(), "u1", "s-2")
+
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "Safari on macOS", sessions[0].Device)
+	assert.False(t, sessions[0].Current)
+	assert.Equal(t, "Edge on Windows", sessions[1].Device)
+	assert.True(t, sessions[1].Current)
+}
+
+func TestRevokeSession_RevokesItsAccessTokens(t *testing.T) {
+	const sessionID = "6f1c2b9e-3d4a-4c8b-9a1e-2f3d4c5b6a7e"
+
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	store := newMockRevocationStore()
+	refreshRepo := &mockRefreshRepo{
+		revokeSessionFn: func(ctx context.Context, userID, id string, revokedAt time.Time) error {
+			if id != sessionID {
+				return ErrSessionNotFound
+			}
+
+			return nil
+		},
+	}
+	uc := newUsecaseForTest(&mockUserRepo{}, refreshRepo, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{}).
+		WithTokenRevocation(store)
+	uc.setNowForTest(now)
+
+	err := uc.RevokeSession(context.Background(), "u1", "8a7b6c5d-4e3f-4a1b-8c9d-0e1f2a3b4c5d")
+	require.ErrorIs(t, err, ErrSessionNotFound)
+	assert.Empty(t, store.sessions, "another user's session is left alone")
+
+	require.NoError(t, uc.RevokeSession(context.Background(), "u1", sessionID))
+	require.Contains(t, store.sessions, sessionID)
+	assert.True(t, store.sessions[sessionID].After(now))
+}
+
+func TestRevokeOtherSessions_KeepsCurrent(t *testing.T) {
+	var kept string
+
+	store := newMockRevocationStore()
+	refreshRepo := &mockRefreshRepo{
+		listSessionsFn: func(ctx context.Context, userID string, at time.Time) ([]Session, error) {
+			return []Session{{ID: "s-1"}, {ID: "s-2"}, {ID: "s-3"}}, nil
+		},
+		revokeOthersFn: func(ctx context.Context, userID, keepSessionID string, revokedAt time.Time) error {
+			kept = keepSessionID
+			return nil
+		},
+	}
+	uc := newUsecaseForTest(&mockUserRepo{}, refreshRepo, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{}).
+		WithTokenRevocation(store)
+
+	require.NoError(t, uc.RevokeOtherSessions(context.Background(), "u1", "s-1"))
+	assert.Equal(t, "s-1", kept)
+	assert.NotContains(t, store.sessions, "s-1")
+	assert.Contains(t, store.sessions, "s-2")
+	assert.Contains(t, store.sessions, "s-3")
+
+	err := uc.RevokeOtherSessions(context.Background(), "u1", "")
+	assert.ErrorIs(t, err, ErrSessionNotFound, "tokens without a session id cannot keep their own session")
+}
+
+func TestDeviceLabel(t *testing.T) {
+	assert.Equal(t, "Chrome on Android", deviceLabel("Mozilla/5.0 (Linux; Android 14) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36"))
+	assert.Equal(t, "Firefox on Linux", deviceLabel("Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0"))
+	assert.Equal(t, "Safari on iOS", deviceLabel("Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Version/17.0 Mobile/15E148 Safari/604.1"))
+	assert.Equal(t, "curl/8.0", deviceLabel("curl/8.0"))
+}
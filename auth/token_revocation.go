@@ -0,0 +1,255 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenRevocationStore records access tokens that must be rejected before
+// they expire: single tokens by jti, every token of a session by sid, and
+// every token of a user issued up to a cutoff.
+type TokenRevocationStore interface {
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	// RevokeSessionTokens can forget the session once expiresAt has
+	// passed, when no access token issued to it is left.
+	RevokeSessionTokens(ctx context.Context, sessionID string, expiresAt time.Time) error
+	RevokeUserTokens(ctx context.Context, userID string, before time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	IsSessionRevoked(ctx context.Context, sessionID string) (bool, error)
+	// UserTokensRevokedBefore returns the zero time when the user has no
+	// cutoff.
+	UserTokensRevokedBefore(ctx context.Context, userID string) (time.Time, error)
+}
+
+// AccessTokenClaims are the claims of an access token revocation works on.
+type AccessTokenClaims struct {
+	ID        string
+	UserID    string
+	SessionID string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// WithTokenRevocation makes logout and password resets revoke access tokens
+// and enables IsAccessTokenRevoked.
+func (uc *Usecase) WithTokenRevocation(store TokenRevocationStore) *Usecase {
+	uc.tokenRevocations = store
+
+	return uc
+}
+
+// IsAccessTokenRevoked reports whether token was revoked by jti or with its
+// session, or issued before its user's tokens were revoked. Tokens without a jti or iat cannot
+// be checked and count as revoked. Always false without a store.
+func (uc *Usecase) IsAccessTokenRevoked(ctx context.Context, token AccessTokenClaims) (bool, error) {
+	if uc.tokenRevocations == nil {
+		return false, nil
+	}
+
+	if strings.TrimSpace(token.ID) == "" || token.IssuedAt.IsZero() {
+		return true, nil
+	}
+
+	revoked, err := uc.tokenRevocations.IsTokenRevoked(ctx, token.ID)
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	if strings.TrimSpace(token.SessionID) != "" {
+		revoked, err := uc.tokenRevocations.IsSessionRevoked(ctx, token.SessionID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	before, err := uc.tokenRevocations.UserTokensRevokedBefore(ctx, token.UserID)
+	if err != nil || before.IsZero() {
+		return false, err
+	}
+
+	// iat has second precision, so a token issued in the same second as
+	// the cutoff is kept; otherwise the login that follows a password reset
+	// or a role change could be rejected at once.
+	return token.IssuedAt.Unix() < before.Unix(), nil
+}
+
+func (uc *Usecase) revokeAccessToken(ctx context.Context, token AccessTokenClaims) error {
+	if uc.tokenRevocations == nil || strings.TrimSpace(token.ID) == "" {
+		return nil
+	}
+
+	return uc.tokenRevocations.RevokeToken(ctx, token.ID, token.ExpiresAt)
+}
+
+// revokeSessionAccessTokens revokes the access tokens issued to sessionID.
+// Its refresh tokens must be revoked already, so no newer ones can follow.
+func (uc *Usecase) revokeSessionAccessTokens(ctx context.Context, sessionID string) error {
+	if uc.tokenRevocations == nil || strings.TrimSpace(sessionID) == "" {
+		return nil
+	}
+
+	return uc.tokenRevocations.RevokeSessionTokens(ctx, sessionID, uc.now().Add(uc.tokenProviderAccessTTL()))
+}
+
+func (uc *Usecase) revokeUserAccessTokens(ctx context.Context, userID string, before time.Time) error {
+	if uc.tokenRevocations == nil {
+		return nil
+	}
+
+	return uc.tokenRevocations.RevokeUserTokens(ctx, userID, before)
+}
+
+// TokenRevocationCache keeps lookups of a TokenRevocationStore in memory, as
+// they run on every authenticated request. Revocations made through the
+// cache apply at once; those made by other instances within ttl.
+type TokenRevocationCache struct {
+	store TokenRevocationStore
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu        sync.Mutex
+	tokens    map[string]cachedRevocation
+	sessions  map[string]cachedRevocation
+	users     map[string]cachedRevocation
+	lastPurge time.Time
+}
+
+type cachedRevocation struct {
+	revoked  bool
+	before   time.Time
+	loadedAt time.Time
+}
+
+func NewTokenRevocationCache(store TokenRevocationStore, ttl time.Duration) *TokenRevocationCache {
+	return &TokenRevocationCache{
+		store:    store,
+		ttl:      ttl,
+		now:      time.Now,
+		tokens:   make(map[string]cachedRevocation),
+		sessions: make(map[string]cachedRevocation),
+		users:    make(map[string]cachedRevocation),
+	}
+}
+
+func (c *TokenRevocationCache) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if err := c.store.RevokeToken(ctx, jti, expiresAt); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokens[jti] = cachedRevocation{revoked: true, loadedAt: c.now()}
+
+	return nil
+}
+
+func (c *TokenRevocationCache) RevokeSessionTokens(ctx context.Context, sessionID string, expiresAt time.Time) error {
+	if err := c.store.RevokeSessionTokens(ctx, sessionID, expiresAt); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sessions[sessionID] = cachedRevocation{revoked: true, loadedAt: c.now()}
+
+	return nil
+}
+
+func (c *TokenRevocationCache) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	if err := c.store.RevokeUserTokens(ctx, userID, before); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The store keeps the latest cutoff; drop ours rather than guess it.
+	delete(c.users, userID)
+
+	return nil
+}
+
+func (c *TokenRevocationCache) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	if entry, ok := c.lookup(c.tokens, jti); ok {
+		return entry.revoked, nil
+	}
+
+	revoked, err := c.store.IsTokenRevoked(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+
+	c.remember(c.tokens, jti, cachedRevocation{revoked: revoked})
+
+	return revoked, nil
+}
+
+func (c *TokenRevocationCache) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	if entry, ok := c.lookup(c.sessions, sessionID); ok {
+		return entry.revoked, nil
+	}
+
+	revoked, err := c.store.IsSessionRevoked(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	c.remember(c.sessions, sessionID, cachedRevocation{revoked: revoked})
+
+	return revoked, nil
+}
+
+func (c *TokenRevocationCache) UserTokensRevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	if entry, ok := c.lookup(c.users, userID); ok {
+		return entry.before, nil
+	}
+
+	before, err := c.store.UserTokensRevokedBefore(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	c.remember(c.users, userID, cachedRevocation{before: before})
+
+	return before, nil
+}
+
+func (c *TokenRevocationCache) lookup(entries map[string]cachedRevocation, key string) (cachedRevocation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := entries[key]
+	if !ok || c.now().Sub(entry.loadedAt) >= c.ttl {
+		return cachedRevocation{}, false
+	}
+
+	return entry, true
+}
+
+func (c *TokenRevocationCache) remember(entries map[string]cachedRevocation, key string, entry cachedRevocation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	entry.loadedAt = now
+	entries[key] = entry
+
+	// Drop stale entries now and then so tokens seen once do not pile up.
+	if now.Sub(c.lastPurge) < c.ttl {
+		return
+	}
+
+	c.lastPurge = now
+
+	for _, m := range []map[string]cachedRevocation{c.tokens, c.sessions, c.users} {
+		for k, e := range m {
+			if now.Sub(e.loadedAt) >= c.ttl {
+				delete(m, k)
+			}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRevocationStore struct {
+	tokens   map[string]time.Time
+	sessions map[string]time.Time
+	users    map[string]time.Time
+	lookups  int
+}
+
+func newMockRevocationStore() *mockRevocationStore {
+	return &mockRevocationStore{tokens: map[string]time.Time{}, sessions: map[string]time.Time{}, users: map[string]time.Time{}}
+}
+
+func (m *mockRevocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	m.tokens[jti] = expiresAt
+	return nil
+}
+
+func (m *mockRevocationStore) RevokeSessionTokens(ctx context.Context, sessionID string, expiresAt time.Time) error {
+	m.sessions[sessionID] = expiresAt
+	return nil
+}
+
+func (m *mockRevocationStore) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	m.users[userID] = before
+	return nil
+}
+
+func (m *mockRevocationStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	m.lookups++
+	_, ok := m.tokens[jti]
+
+	return ok, nil
+}
+
+func (m *mockRevocationStore) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	m.lookups++
+	_, ok := m.sessions[sessionID]
+
+	return ok, nil
+}
+
+func (m *mockRevocationStore) UserTokensRevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	m.lookups++
+	return m.users[userID], nil
+}
+
+func TestIsAccessTokenRevoked(t *testing.T) {
+	issued := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	store := newMockRevocationStore()
+	uc := newUsecaseForTest(&mockUserRepo{}, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{})
+
+	token := AccessTokenClaims{ID: "jti-1", UserID: "u1", IssuedAt: issued}
+
+	revoked, err := uc.IsAccessTokenRevoked(context.Background(), token)
+	require.NoError(t, err)
+	assert.False(t, revoked, "nothing is revoked without a store")
+
+	uc.WithTokenRevocation(store)
+
+	revoked, err = uc.IsAccessTokenRevoked(context.Background(), token)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	revoked, err = uc.IsAccessTokenRevoked(context.Background(), AccessTokenClaims{UserID: "u1", IssuedAt: issued})
+	require.NoError(t, err)
+	assert.True(t, revoked, "a token without jti cannot be checked")
+
+	store.users["u1"] = issued.Add(time.Second)
+
+	revoked, err = uc.IsAccessTokenRevoked(context.Background(), token)
+	require.NoError(t, err)
+	assert.True(t, revoked, "issued before the cutoff")
+
+	token.IssuedAt = issued.Add(time.Second)
+
+	revoked, err = uc.IsAccessTokenRevoked(context.Background(), token)
+	require.NoError(t, err)
+	assert.False(t, revoked, "issued after the cutoff")
+
+	store.tokens["jti-1"] = issued.Add(time.Hour)
+
+	revoked, err = uc.IsAccessTokenRevoked(context.Background(), token)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	token = AccessTokenClaims{ID: "jti-2", UserID: "u1", SessionID: "s-1", IssuedAt: issued.Add(time.Second)}
+	store.sessions["s-1"] = issued.Add(time.Hour)
+
+	revoked, err = uc.IsAccessTokenRevoked(context.Background(), token)
+	require.NoError(t, err)
+	assert.True(t, revoked, "revoked with its session")
+}
+
+func TestIsAccessTokenRevoked_KeepsTokenIssuedInSecondOfCutoff(t *testing.T) {
+	cutoff := time.Date(2026, 3, 2, 10, 0, 0, 300*int(time.Millisecond), time.UTC)
+	store := newMockRevocationStore()
+	uc := newUsecaseForTest(&mockUserRepo{}, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{}).
+		WithTokenRevocation(store)
+
+	require.NoError(t, uc.revokeUserAccessTokens(context.Background(), "u1", cutoff))
+
+	// A login right after the reset, at 10:00:00.800, carries iat 10:00:00.
+	fresh := AccessTokenClaims{ID: "jti-1", UserID: "u1", IssuedAt: cutoff.Truncate(time.Second)}
+
+	revoked, err := uc.IsAccessTokenRevoked(context.Background(), fresh)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	old := AccessTokenClaims{ID: "jti-2", UserID: "u1", IssuedAt: cutoff.Truncate(time.Second).Add(-time.Second)}
+
+	revoked, err = uc.IsAccessTokenRevoked(context.Background(), old)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestLogout_RevokesAccessTokenEvenWithInvalidRefreshToken(t *testing.T) {
+	store := newMockRevocationStore()
+	uc := newUsecaseForTest(&mockUserRepo{}, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{}).
+		WithTokenRevocation(store)
+	expiresAt := time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC)
+
+	err := uc.Logout(context.Background(), "bad-refresh-token", AccessTokenClaims{ID: "jti-1", UserID: "u1", ExpiresAt: expiresAt})
+
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	assert.Equal(t, map[string]time.Time{"jti-1": expiresAt}, store.tokens)
+}
+
+func TestTokenRevocationCache(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	store := newMockRevocationStore()
+	cache := NewTokenRevocationCache(store, 30*time.Second)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	revoked, err := cache.IsTokenRevoked(ctx, "jti-1")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	_, err = cache.IsTokenRevoked(ctx, "jti-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.lookups, "the second lookup is cached")
+
+	require.NoError(t, cache.RevokeToken(ctx, "jti-1", now.Add(time.Hour)))
+
+	revoked, err = cache.IsTokenRevoked(ctx, "jti-1")
+	require.NoError(t, err)
+	assert.True(t, revoked, "local revocations apply at once")
+
+	// Another instance revokes the user's tokens.
+	_, err = cache.UserTokensRevokedBefore(ctx, "u1")
+	require.NoError(t, err)
+
+	store.users["u1"] = now
+
+	before, err := cache.UserTokensRevokedBefore(ctx, "u1")
+	require.NoError(t, err)
+	assert.True(t, before.IsZero(), "still cached")
+
+	now = now.Add(30 * time.Second)
+
+	before, err = cache.UserTokensRevokedBefore(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, store.users["u1"], before, "picked up after the ttl")
+
+	require.NoError(t, cache.RevokeUserTokens(ctx, "u1", now))
+
+	before, err = cache.UserTokensRevokedBefore(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, now, before)
+}
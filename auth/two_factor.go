@@ -0,0 +1,381 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"hexagon/audit"
+	"hexagon/user"
+)
+
+var (
+	ErrTwoFactorNotConfigured  = errors.New("two-factor authentication is not configured")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor authentication is not enrolled")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrInvalidChallengeToken   = errors.New("invalid two-factor challenge token")
+)
+
+const (
+	recoveryCodeCount = 10
+	// recoveryCodeAlphabet leaves out characters that are easy to misread
+	// when copied from paper.
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+	recoveryCodeLength   = 8
+)
+
+type TOTPGenerator interface {
+	GenerateSecret() (string, error)
+	URI(accountName, secret string) string
+	// Validate reports the time step code matched, if any.
+	Validate(secret, code string, at time.Time) (int64, bool)
+}
+
+// TOTPEnrollment is a user's authenticator secret. It only protects logins
+// once EnabledAt is set, which happens after the user proves they can
+// generate codes with it.
+type TOTPEnrollment struct {
+	UserID       string
+	Secret       string
+	EnabledAt    *time.Time
+	LastUsedStep int64
+}
+
+type TwoFactorRepository interface {
+	// GetTOTP returns ErrTwoFactorNotEnrolled when the user has no secret.
+	GetTOTP(ctx context.Context, userID string) (TOTPEnrollment, error)
+	// SaveTOTP stores a pending secret, replacing any earlier pending one.
+	SaveTOTP(ctx context.Context, enrollment TOTPEnrollment) error
+	// EnableTOTP activates the enrolment, records step as used and replaces
+	// the user's recovery codes.
+	EnableTOTP(ctx context.Context, userID string, step int64, enabledAt time.Time, recoveryCodeHashes []string) error
+	// DisableTOTP removes the enrolment and every recovery code.
+	DisableTOTP(ctx context.Context, userID string) error
+	// MarkTOTPStepUsed returns ErrInvalidTwoFactorCode when step, or a later
+	// one, was already accepted, so a code cannot be replayed.
+	MarkTOTPStepUsed(ctx context.Context, userID string, step int64) error
+	// UseRecoveryCode returns ErrInvalidTwoFactorCode unless an unused code
+	// with codeHash exists.
+	UseRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) error
+}
+
+type TOTPSetup struct {
+	Secret string
+	URI    string
+}
+
+// LoginResult holds either tokens or, for users with two-factor
+// authentication, a ChallengeToken to exchange for tokens with
+// VerifyTwoFactorLogin.
+type LoginResult struct {
+	Tokens         TokenPair
+	ChallengeToken string
+}
+
+func (r LoginResult) TwoFactorRequired() bool {
+	return r.ChallengeToken != ""
+}
+
+// WithTwoFactor enables TOTP two-factor authentication.
+func (uc *Usecase) WithTwoFactor(repo TwoFactorRepository, totp TOTPGenerator) *Usecase {
+	uc.twoFactorRepo = repo
+	uc.totp = totp
+
+	return uc
+}
+
+func (uc *Usecase) twoFactorConfigured() bool {
+	return uc.twoFactorRepo != nil && uc.totp != nil
+}
+
+func (uc *Usecase) twoFactorEnabled(ctx context.Context, userID string) (bool, error) {
+	if !uc.twoFactorConfigured() {
+		return false, nil
+	}
+
+	enrollment, err := uc.twoFactorRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrTwoFactorNotEnrolled) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return enrollment.EnabledAt != nil, nil
+}
+
+// VerifyTwoFactorLogin completes a login that returned a challenge token.
+// code is either a current TOTP code or an unused recovery code. Wrong codes
+// count towards the same lockout as wrong passwords.
+func (uc *Usecase) VerifyTwoFactorLogin(ctx context.Context, challengeToken, code string) (TokenPair, error) {
+	if !uc.twoFactorConfigured() {
+		return TokenPair{}, ErrTwoFactorNotConfigured
+	}
+
+	tokenUser, err := uc.tokenProvider.ParseTwoFactorToken(challengeToken)
+	if err != nil {
+		return TokenPair{}, ErrInvalidChallengeToken
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, tokenUser.ID)
+	if err != nil {
+		return TokenPair{}, ErrInvalidChallengeToken
+	}
+
+	// The account may have been deactivated since the challenge was issued.
+	if u.Status == user.UserStatusInactive {
+		return TokenPair{}, ErrAccountInactive
+	}
+
+	now := uc.now()
+
+	u, err = uc.handleLockState(ctx, u, now)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	// The challenge may predate a reported sign-in.
+	if u.MustResetPassword {
+		return TokenPair{}, ErrPasswordResetRequired
+	}
+
+	enrollment, err := uc.twoFactorRepo.GetTOTP(ctx, u.ID)
+	if err != nil || enrollment.EnabledAt == nil {
+		return TokenPair{}, ErrInvalidChallengeToken
+	}
+
+	if err := uc.verifySecondFactor(ctx, enrollment, code, now); err != nil {
+		if !errors.Is(err, ErrInvalidTwoFactorCode) {
+			return TokenPair{}, err
+		}
+
+		uc.recordLoginFailure(ctx, u.ID, loginFailedInvalidTwoFactorCode)
+
+		if err := uc.recordFailure(ctx, u, now); err != nil {
+			return TokenPair{}, err
+		}
+
+		return TokenPair{}, ErrInvalidTwoFactorCode
+	}
+
+	if err := uc.resetAuthState(ctx, u); err != nil {
+		return TokenPair{}, err
+	}
+
+	newClient := uc.isNewSignInClient(ctx, u)
+
+	tokens, err := uc.issueTokens(ctx, u)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	uc.recordUserAudit(ctx, u.ID, audit.EventLoginSucceeded, map[string]string{"method": "two_factor"})
+
+	if newClient {
+		uc.sendSignInAlert(ctx, u)
+	}
+
+	return tokens, nil
+}
+
+// SetupTOTP starts enrolment with a fresh secret. Calling it again before
+// EnableTOTP replaces the pending secret.
+func (uc *Usecase) SetupTOTP(ctx context.Context, userID string) (TOTPSetup, error) {
+	if !uc.twoFactorConfigured() {
+		return TOTPSetup{}, ErrTwoFactorNotConfigured
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return TOTPSetup{}, err
+	}
+
+	enabled, err := uc.twoFactorEnabled(ctx, u.ID)
+	if err != nil {
+		return TOTPSetup{}, err
+	}
+
+	if enabled {
+		return TOTPSetup{}, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := uc.totp.GenerateSecret()
+	if err != nil {
+		return TOTPSetup{}, err
+	}
+
+	if err := uc.twoFactorRepo.SaveTOTP(ctx, TOTPEnrollment{
+		UserID: u.ID,
+		Secret: secret,
+	}); err != nil {
+		return TOTPSetup{}, err
+	}
+
+	return TOTPSetup{
+		Secret: secret,
+		URI:    uc.totp.URI(u.Email, secret),
+	}, nil
+}
+
+// EnableTOTP activates the pending secret once code proves the user's
+// authenticator is set up, and returns recovery codes. The codes are only
+// stored hashed, so this is the one time they can be shown.
+func (uc *Usecase) EnableTOTP(ctx context.Context, userID, code string) ([]string, error) {
+	if !uc.twoFactorConfigured() {
+		return nil, ErrTwoFactorNotConfigured
+	}
+
+	enrollment, err := uc.twoFactorRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if enrollment.EnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	now := uc.now()
+
+	step, ok := uc.totp.Validate(enrollment.Secret, code, now)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.twoFactorRepo.EnableTOTP(ctx, userID, step, now, hashes); err != nil {
+		return nil, err
+	}
+
+	uc.recordSecurityEvent(ctx, SecurityEvent{
+		UserID: userID,
+		Type:   SecurityEventTwoFactorEnabled,
+	})
+
+	return codes, nil
+}
+
+// DisableTOTP turns two-factor authentication off. It asks for a code so a
+// hijacked session alone cannot remove the second factor, and wrong codes
+// count towards the account lock like failed sign-ins do.
+func (uc *Usecase) DisableTOTP(ctx context.Context, userID, code string) error {
+	if !uc.twoFactorConfigured() {
+		return ErrTwoFactorNotConfigured
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	now := uc.now()
+
+	u, err = uc.handleLockState(ctx, u, now)
+	if err != nil {
+		return err
+	}
+
+	enrollment, err := uc.twoFactorRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if enrollment.EnabledAt == nil {
+		return ErrTwoFactorNotEnrolled
+	}
+
+	if err := uc.verifySecondFactor(ctx, enrollment, code, now); err != nil {
+		if !errors.Is(err, ErrInvalidTwoFactorCode) {
+			return err
+		}
+
+		if err := uc.recordFailure(ctx, u, now); err != nil {
+			return err
+		}
+
+		return ErrInvalidTwoFactorCode
+	}
+
+	if err := uc.resetAuthState(ctx, u); err != nil {
+		return err
+	}
+
+	if err := uc.twoFactorRepo.DisableTOTP(ctx, userID); err != nil {
+		return err
+	}
+
+	uc.recordSecurityEvent(ctx, SecurityEvent{
+		UserID: userID,
+		Type:   SecurityEventTwoFactorDisabled,
+	})
+
+	return nil
+}
+
+func (uc *Usecase) verifySecondFactor(ctx context.Context, enrollment TOTPEnrollment, code string, now time.Time) error {
+	if step, ok := uc.totp.Validate(enrollment.Secret, code, now); ok {
+		if step <= enrollment.LastUsedStep {
+			return ErrInvalidTwoFactorCode
+		}
+
+		return uc.twoFactorRepo.MarkTOTPStepUsed(ctx, enrollment.UserID, step)
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != recoveryCodeLength {
+		return ErrInvalidTwoFactorCode
+	}
+
+	if err := uc.twoFactorRepo.UseRecoveryCode(ctx, enrollment.UserID, hashToken(normalized), now); err != nil {
+		return err
+	}
+
+	uc.recordSecurityEvent(ctx, SecurityEvent{
+		UserID: enrollment.UserID,
+		Type:   SecurityEventRecoveryCodeUsed,
+	})
+
+	return nil
+}
+
+// generateRecoveryCodes returns codes formatted for display, e.g.
+// "abcd-2345", and the hashes of their normalized form.
+func generateRecoveryCodes(count int) ([]string, []string, error) {
+	codes := make([]string, 0, count)
+	hashes := make([]string, 0, count)
+	alphabetSize := big.NewInt(int64(len(recoveryCodeAlphabet)))
+
+	for range count {
+		buf := make([]byte, recoveryCodeLength)
+
+		for i := range buf {
+			n, err := rand.Int(rand.Reader, alphabetSize)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			buf[i] = recoveryCodeAlphabet[n.Int64()]
+		}
+
+		code := string(buf)
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		hashes = append(hashes, hashToken(code))
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+
+	return strings.ReplaceAll(code, " ", "")
+}
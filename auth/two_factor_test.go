@@ -0,0 +1,375 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hexagon/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTwoFactorRepo struct {
+	enrollment    *TOTPEnrollment
+	recoveryCodes map[string]bool
+	enabledHashes []string
+	markedStep    int64
+	disabled      bool
+}
+
+func (m *mockTwoFactorRepo) GetTOTP(ctx context.Context, userID string) (TOTPEnrollment, error) {
+	if m.enrollment == nil {
+		return TOTPEnrollment{}, ErrTwoFactorNotEnrolled
+	}
+
+	return *m.enrollment, nil
+}
+
+func (m *mockTwoFactorRepo) SaveTOTP(ctx context.Context, enrollment TOTPEnrollment) error {
+	m.enrollment = &enrollment
+	return nil
+}
+
+func (m *mockTwoFactorRepo) EnableTOTP(ctx context.Context, userID string, step int64, enabledAt time.Time, recoveryCodeHashes []string) error {
+	m.enrollment.EnabledAt = &enabledAt
+	m.enrollment.LastUsedStep = step
+	m.enabledHashes = recoveryCodeHashes
+
+	return nil
+}
+
+func (m *mockTwoFactorRepo) DisableTOTP(ctx context.Context, userID string) error {
+	m.disabled = true
+	return nil
+}
+
+func (m *mockTwoFactorRepo) MarkTOTPStepUsed(ctx context.Context, userID string, step int64) error {
+	m.markedStep = step
+	return nil
+}
+
+func (m *mockTwoFactorRepo) UseRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) error {
+	if !m.recoveryCodes[codeHash] {
+		return ErrInvalidTwoFactorCode
+	}
+
+	m.recoveryCodes[codeHash] = false
+
+	return nil
+}
+
+// stubTOTP accepts "123456" at step 100.
+type stubTOTP struct{}
+
+func (stubTOTP) GenerateSecret() (string, error) { return "SECRET", nil }
+
+func (stubTOTP) URI(accountName, secret string) string {
+	return "otpauth://totp/Hexagon:" + accountName + "?secret=" + secret
+}
+
+func (stubTOTP) Validate(secret, code string, at time.Time) (int64, bool) {
+	return 100, code == "123456"
+}
+
+func enabledTwoFactorRepo() *mockTwoFactorRepo {
+	enabledAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	return &mockTwoFactorRepo{
+		enrollment:    &TOTPEnrollment{UserID: "u1", Secret: "SECRET", EnabledAt: &enabledAt, LastUsedStep: 90},
+		recoveryCodes: map[string]bool{hashToken("abcd2345"): true},
+	}
+}
+
+func TestLogin_ReturnsChallengeWhenTwoFactorEnabled(t *testing.T) {
+	verifiedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	authStateUpdates := 0
+	repo := &mockUserRepo{
+		getByEmailFn: func(ctx context.Context, email string) (user.User, error) {
+			return user.User{ID: "u1", Email: email, PasswordHash: "hash", Status: user.UserStatusActive, EmailVerifiedAt: &verifiedAt, FailedLoginAttempts: 3}, nil
+		},
+		updateAuthStateFn: func(context.Context, string, int, *time.Time, int, *time.Time, user.UserStatus) error {
+			authStateUpdates++
+			return nil
+		},
+	}
+	refreshRepo := &mockRefreshRepo{
+		saveFn: func(ctx context.Context, token RefreshToken) error {
+			t.Fatal("tokens must not be issued before the second factor")
+			return nil
+		},
+	}
+	uc := newUsecaseForTest(repo, refreshRepo, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{}).
+		WithTwoFactor(enabledTwoFactorRepo(), stubTOTP{})
+
+	result, err := uc.Login(context.Background(), "john@example.com", "Password@123")
+
+	require.NoError(t, err)
+	assert.True(t, result.TwoFactorRequired())
+	assert.Equal(t, "challenge-token", result.ChallengeToken)
+	assert.Empty(t, result.Tokens.AccessToken)
+	assert.Zero(t, authStateUpdates, "failed attempts are kept until the second factor passes")
+}
+
+func TestLoginWithOAuth_ReturnsChallengeWhenTwoFactorEnabled(t *testing.T) {
+	verifiedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, Email: "john@example.com", Status: user.UserStatusActive, EmailVerifiedAt: &verifiedAt}, nil
+		},
+	}
+	oauthRepo := &mockOAuthRepo{accounts: []OAuthAccount{{UserID: "u1", Provider: "corp", ProviderUserID: "subject-1"}}}
+	refreshRepo := &mockRefreshRepo{
+		saveFn: func(ctx context.Context, token RefreshToken) error {
+			t.Fatal("tokens must not be issued before the second factor")
+			return nil
+		},
+	}
+	provider := &mockOAuthLoginProvider{user: OAuthUser{ProviderUserID: "subject-1", Email: "john@example.com", EmailVerified: true}}
+	uc := NewUsecase(repo, oauthRepo, refreshRepo, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, OAuthProviders{
+		"corp": provider,
+	}, nil, "", "").
+		WithTwoFactor(enabledTwoFactorRepo(), stubTOTP{})
+
+	result, err := uc.LoginWithOAuth(context.Background(), "corp", "code", OAuthFlow{State: "state"})
+
+	require.NoError(t, err)
+	assert.True(t, result.TwoFactorRequired())
+	assert.Equal(t, "challenge-token", result.ChallengeToken)
+	assert.Empty(t, result.Tokens.AccessToken)
+}
+
+func TestLoginWithOAuth_EnforcesLockAndRequiredReset(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	lockUntil := now.Add(time.Minute)
+	stored := user.User{ID: "u1", Email: "john@example.com", Status: user.UserStatusLocked, LockUntil: &lockUntil, EmailVerifiedAt: &now}
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return stored, nil
+		},
+	}
+	oauthRepo := &mockOAuthRepo{accounts: []OAuthAccount{{UserID: "u1", Provider: "corp", ProviderUserID: "subject-1"}}}
+	provider := &mockOAuthLoginProvider{user: OAuthUser{ProviderUserID: "subject-1", Email: "john@example.com", EmailVerified: true}}
+	uc := NewUsecase(repo, oauthRepo, &mockRefreshRepo{}, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, OAuthProviders{
+		"corp": provider,
+	}, nil, "", "")
+	uc.setNowForTest(now)
+
+	_, err := uc.LoginWithOAuth(context.Background(), "corp", "code", OAuthFlow{State: "state"})
+	require.ErrorIs(t, err, ErrAccountLocked)
+
+	stored = user.User{ID: "u1", Email: "john@example.com", Status: user.UserStatusActive, EmailVerifiedAt: &now, MustResetPassword: true}
+
+	_, err = uc.LoginWithOAuth(context.Background(), "corp", "code", OAuthFlow{State: "state"})
+	assert.ErrorIs(t, err, ErrPasswordResetRequired)
+}
+
+func TestVerifyTwoFactorLogin_IssuesTokensForValidCode(t *testing.T) {
+	twoFactor := enabledTwoFactorRepo()
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, Email: "john@example.com", Status: user.UserStatusActive}, nil
+		},
+	}
+	tokens := &mockTokenProvider{
+		parseTwoFactorFn: func(token string) (user.User, error) {
+			return user.User{ID: "u1"}, nil
+		},
+	}
+	uc := newUsecaseForTest(repo, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, tokens).
+		WithTwoFactor(twoFactor, stubTOTP{})
+
+	pair, err := uc.VerifyTwoFactorLogin(context.Background(), "challenge-token", "123456")
+
+	require.NoError(t, err)
+	assert.Equal(t, "access-token", pair.AccessToken)
+	assert.Equal(t, int64(100), twoFactor.markedStep)
+}
+
+func TestVerifyTwoFactorLogin_RejectsReplayedStep(t *testing.T) {
+	twoFactor := enabledTwoFactorRepo()
+	twoFactor.enrollment.LastUsedStep = 100
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, Status: user.UserStatusActive}, nil
+		},
+	}
+	tokens := &mockTokenProvider{
+		parseTwoFactorFn: func(token string) (user.User, error) {
+			return user.User{ID: "u1"}, nil
+		},
+	}
+	uc := newUsecaseForTest(repo, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, tokens).
+		WithTwoFactor(twoFactor, stubTOTP{})
+
+	_, err := uc.VerifyTwoFactorLogin(context.Background(), "challenge-token", "123456")
+
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+}
+
+func TestVerifyTwoFactorLogin_RejectsInactiveAccount(t *testing.T) {
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, Status: user.UserStatusInactive}, nil
+		},
+	}
+	tokens := &mockTokenProvider{
+		parseTwoFactorFn: func(token string) (user.User, error) {
+			return user.User{ID: "u1"}, nil
+		},
+	}
+	uc := newUsecaseForTest(repo, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, tokens).
+		WithTwoFactor(enabledTwoFactorRepo(), stubTOTP{})
+
+	_, err := uc.VerifyTwoFactorLogin(context.Background(), "challenge-token", "123456")
+
+	assert.ErrorIs(t, err, ErrAccountInactive)
+}
+
+func TestVerifyTwoFactorLogin_WrongCodeLocksAccount(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	var (
+		lockedUntil *time.Time
+		status      user.UserStatus
+	)
+
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, Status: user.UserStatusActive, FailedLoginAttempts: 4}, nil
+		},
+		updateAuthStateFn: func(ctx context.Context, id string, failed int, lockUntil *time.Time, level int, lastFailed *time.Time, s user.UserStatus) error {
+			lockedUntil = lockUntil
+			status = s
+
+			return nil
+		},
+	}
+	tokens := &mockTokenProvider{
+		parseTwoFactorFn: func(token string) (user.User, error) {
+			return user.User{ID: "u1"}, nil
+		},
+	}
+	uc := newUsecaseForTest(repo, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, tokens).
+		WithTwoFactor(enabledTwoFactorRepo(), stubTOTP{})
+	uc.setNowForTest(now)
+
+	_, err := uc.VerifyTwoFactorLogin(context.Background(), "challenge-token", "000000")
+
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	assert.Equal(t, user.UserStatusLocked, status)
+	require.NotNil(t, lockedUntil)
+	assert.Equal(t, now.Add(15*time.Minute), *lockedUntil)
+}
+
+func TestVerifyTwoFactorLogin_AcceptsRecoveryCodeOnce(t *testing.T) {
+	twoFactor := enabledTwoFactorRepo()
+	events := &mockSecurityEventRepo{}
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, Status: user.UserStatusActive}, nil
+		},
+	}
+	tokens := &mockTokenProvider{
+		parseTwoFactorFn: func(token string) (user.User, error) {
+			return user.User{ID: "u1"}, nil
+		},
+	}
+	uc := newUsecaseForTest(repo, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, tokens).
+		WithTwoFactor(twoFactor, stubTOTP{}).
+		WithSecurityEvents(events)
+
+	_, err := uc.VerifyTwoFactorLogin(context.Background(), "challenge-token", "ABCD-2345")
+	require.NoError(t, err)
+
+	require.Len(t, events.events, 1)
+	assert.Equal(t, SecurityEventRecoveryCodeUsed, events.events[0].Type)
+
+	_, err = uc.VerifyTwoFactorLogin(context.Background(), "challenge-token", "abcd-2345")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+}
+
+func TestVerifyTwoFactorLogin_RejectsInvalidChallenge(t *testing.T) {
+	uc := newUsecaseForTest(&mockUserRepo{}, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{}).
+		WithTwoFactor(enabledTwoFactorRepo(), stubTOTP{})
+
+	_, err := uc.VerifyTwoFactorLogin(context.Background(), "access-token", "123456")
+
+	assert.ErrorIs(t, err, ErrInvalidChallengeToken)
+}
+
+func TestEnableTOTP_ReturnsRecoveryCodes(t *testing.T) {
+	twoFactor := &mockTwoFactorRepo{}
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, Email: "john@example.com"}, nil
+		},
+	}
+	uc := newUsecaseForTest(repo, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{}).
+		WithTwoFactor(twoFactor, stubTOTP{})
+
+	setup, err := uc.SetupTOTP(context.Background(), "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "SECRET", setup.Secret)
+	assert.Equal(t, "otpauth://totp/Hexagon:john@example.com?secret=SECRET", setup.URI)
+
+	_, err = uc.EnableTOTP(context.Background(), "u1", "000000")
+	require.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	codes, err := uc.EnableTOTP(context.Background(), "u1", "123456")
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	assert.Regexp(t, `^[a-z2-9]{4}-[a-z2-9]{4}$`, codes[0])
+	require.Len(t, twoFactor.enabledHashes, recoveryCodeCount)
+	assert.Equal(t, hashToken(normalizeRecoveryCode(codes[0])), twoFactor.enabledHashes[0])
+	assert.NotNil(t, twoFactor.enrollment.EnabledAt)
+
+	_, err = uc.SetupTOTP(context.Background(), "u1")
+	assert.ErrorIs(t, err, ErrTwoFactorAlreadyEnabled)
+}
+
+func TestDisableTOTP_RequiresCode(t *testing.T) {
+	var failedAttempts []int
+
+	twoFactor := enabledTwoFactorRepo()
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, Status: user.UserStatusActive, FailedLoginAttempts: 1}, nil
+		},
+		updateAuthStateFn: func(ctx context.Context, id string, failed int, lockUntil *time.Time, level int, lastFailed *time.Time, s user.UserStatus) error {
+			failedAttempts = append(failedAttempts, failed)
+			return nil
+		},
+	}
+	uc := newUsecaseForTest(repo, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{}).
+		WithTwoFactor(twoFactor, stubTOTP{})
+
+	err := uc.DisableTOTP(context.Background(), "u1", "000000")
+	require.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	assert.False(t, twoFactor.disabled)
+	assert.Equal(t, []int{2}, failedAttempts, "a wrong code counts as a failure")
+
+	require.NoError(t, uc.DisableTOTP(context.Background(), "u1", "123456"))
+	assert.True(t, twoFactor.disabled)
+}
+
+func TestDisableTOTP_LockedAccount(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	lockUntil := now.Add(10 * time.Minute)
+
+	twoFactor := enabledTwoFactorRepo()
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, Status: user.UserStatusLocked, LockUntil: &lockUntil}, nil
+		},
+	}
+	uc := newUsecaseForTest(repo, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{}).
+		WithTwoFactor(twoFactor, stubTOTP{})
+	uc.setNowForTest(now)
+
+	err := uc.DisableTOTP(context.Background(), "u1", "123456")
+
+	assert.ErrorIs(t, err, ErrAccountLocked)
+	assert.False(t, twoFactor.disabled)
+}
@@ -7,12 +7,17 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"net/mail"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
+	"hexagon/audit"
 	"hexagon/user"
+
+	"github.com/google/uuid"
 )
 
 var (
@@ -20,6 +25,8 @@ var (
 	ErrInvalidAccessToken       = errors.New("invalid access token")
 	ErrAccountLocked            = errors.New("account temporarily locked")
 	ErrInvalidRefreshToken      = errors.New("invalid refresh token")
+	ErrRefreshTokenReused       = fmt.Errorf("%w: token was already used", ErrInvalidRefreshToken)
+	ErrRefreshTokenRevoked      = errors.New("refresh token is already revoked")
 	ErrInvalidVerifyToken       = errors.New("invalid verification token")
 	ErrEmailNotVerified         = errors.New("email is not verified")
 	ErrInvalidOAuthUser         = errors.New("invalid oauth user")
@@ -29,6 +36,7 @@ var (
 	ErrMissingEmail             = errors.New("missing oauth email")
 	ErrUnverifiedEmail          = errors.New("unverified oauth email")
 	ErrOAuthNotConfigured       = errors.New("oauth provider not configured")
+	ErrUnknownOAuthProvider     = errors.New("unknown oauth provider")
 	ErrMailerNotConfigured      = errors.New("password reset mailer not configured")
 	ErrPasswordAuthNotAvailable = errors.New("password authentication is not available for this account")
 	ErrEmailRegisteredWithOAuth = errors.New("email is already registered with oauth provider")
@@ -36,16 +44,40 @@ var (
 
 type Service interface {
 	Register(ctx context.Context, name, email, phone, password string) error
-	Login(ctx context.Context, email, password string) (TokenPair, error)
-	Logout(ctx context.Context, refreshToken string) error
+	Login(ctx context.Context, email, password string) (LoginResult, error)
+	VerifyTwoFactorLogin(ctx context.Context, challengeToken, code string) (TokenPair, error)
+	// Logout ends the session of refreshToken and revokes current, the
+	// access token of the request.
+	Logout(ctx context.Context, refreshToken string, current AccessTokenClaims) error
+	IsAccessTokenRevoked(ctx context.Context, token AccessTokenClaims) (bool, error)
 	Refresh(ctx context.Context, refreshToken string) (TokenPair, error)
 	SendEmailVerification(ctx context.Context, email string) error
 	VerifyEmail(ctx context.Context, verifyToken string) error
+	RequestEmailChange(ctx context.Context, userID, sessionID, newEmail, password string) error
+	SendPhoneVerification(ctx context.Context, userID string) error
+	VerifyPhone(ctx context.Context, userID, code string) error
 	ForgotPassword(ctx context.Context, email string) error
+	RequestMagicLink(ctx context.Context, email string) error
+	LoginWithMagicLink(ctx context.Context, loginToken string) (LoginResult, error)
 	ResetPassword(ctx context.Context, resetToken, newPassword string) error
+	DenySignIn(ctx context.Context, denyToken string) error
 	Me(ctx context.Context, accessToken string) (user.User, error)
-	GoogleAuthURL(state string) (string, error)
-	LoginWithGoogle(ctx context.Context, code string) (TokenPair, error)
+	OAuthProviders() []OAuthProvider
+	OAuthAuthURL(ctx context.Context, provider OAuthProvider) (string, OAuthFlow, error)
+	LoginWithOAuth(ctx context.Context, provider OAuthProvider, code string, flow OAuthFlow) (LoginResult, error)
+	ListOAuthAccounts(ctx context.Context, userID string) ([]OAuthAccount, error)
+	OAuthLinkURL(ctx context.Context, userID string, provider OAuthProvider) (string, OAuthFlow, error)
+	LinkOAuthAccount(ctx context.Context, provider OAuthProvider, code string, flow OAuthFlow) (OAuthAccount, error)
+	UnlinkOAuthAccount(ctx context.Context, userID string, provider OAuthProvider) error
+	ListSessions(ctx context.Context, userID, currentSessionID string) ([]Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) error
+	SetupTOTP(ctx context.Context, userID string) (TOTPSetup, error)
+	EnableTOTP(ctx context.Context, userID, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID, code string) error
+	ExportData(ctx context.Context, userID string) (DataExport, error)
+	RequestAccountDeletion(ctx context.Context, userID string) (time.Time, error)
+	CancelAccountDeletion(ctx context.Context, userID string) error
 }
 
 type OAuthProvider string
@@ -61,6 +93,13 @@ type UserRepository interface {
 	CreateUserTx(ctx context.Context, u user.User, fn func(ctx context.Context, created user.User) error) (user.User, error)
 	UpdatePasswordHash(ctx context.Context, id, passwordHash string) error
 	UpdateEmailVerifiedAt(ctx context.Context, id string, verifiedAt *time.Time) error
+	// UpdateEmail returns user.ErrEmailAlreadyExists when email is taken.
+	UpdateEmail(ctx context.Context, id, email string, verifiedAt *time.Time) error
+	// UpdatePhoneVerifiedAt marks phone verified, unless the user's phone
+	// is no longer phone.
+	UpdatePhoneVerifiedAt(ctx context.Context, id, phone string, verifiedAt time.Time) error
+	// RequirePasswordReset blocks password login until UpdatePasswordHash.
+	RequirePasswordReset(ctx context.Context, id string) error
 	UpdateAuthState(
 		ctx context.Context,
 		id string,
@@ -73,15 +112,32 @@ type UserRepository interface {
 }
 
 type OAuthProviderAccountRepository interface {
+	// GetUserIDByProvider returns ErrOAuthAccountNotLinked for unknown
+	// identities.
 	GetUserIDByProvider(ctx context.Context, provider OAuthProvider, providerUserID string) (string, error)
 	Upsert(ctx context.Context, userID string, provider OAuthProvider, providerUserID, providerEmail string) error
+	ListByUserID(ctx context.Context, userID string) ([]OAuthAccount, error)
+	// Delete returns ErrOAuthAccountNotLinked when nothing was deleted.
+	Delete(ctx context.Context, userID string, provider OAuthProvider) error
 }
 
 type RefreshTokenRepository interface {
 	Save(ctx context.Context, token RefreshToken) error
-	GetActiveByHash(ctx context.Context, tokenHash string) (RefreshToken, error)
+	// GetByHash returns the token whether or not it is revoked, so replays of
+	// rotated tokens can be detected.
+	GetByHash(ctx context.Context, tokenHash string) (RefreshToken, error)
+	// RevokeByHash returns ErrRefreshTokenRevoked when the token is revoked
+	// already, e.g. by a concurrent refresh with the same token.
 	RevokeByHash(ctx context.Context, tokenHash string, revokedAt time.Time) error
+	RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error
 	RevokeAllByUserID(ctx context.Context, userID string, revokedAt time.Time) error
+	// ListActiveSessions returns one Session per family of userID that has an
+	// unrevoked token expiring after now.
+	ListActiveSessions(ctx context.Context, userID string, now time.Time) ([]Session, error)
+	// RevokeSession returns ErrSessionNotFound unless userID has an active
+	// token in the family.
+	RevokeSession(ctx context.Context, userID, sessionID string, revokedAt time.Time) error
+	RevokeOtherSessions(ctx context.Context, userID, keepSessionID string, revokedAt time.Time) error
 }
 
 type PasswordResetTokenRepository interface {
@@ -96,8 +152,11 @@ type EmailVerificationTokenRepository interface {
 	MarkUsedByHash(ctx context.Context, tokenHash string, usedAt time.Time) error
 }
 
+// RefreshToken is one link of a rotation chain. Every token issued by
+// rotating another keeps its FamilyID, so a family is one login session.
 type RefreshToken struct {
 	UserID    string
+	FamilyID  string
 	TokenHash string
 	UserAgent string
 	IPAddress string
@@ -117,6 +176,11 @@ type EmailVerificationToken struct {
 	TokenHash string
 	ExpiresAt time.Time
 	UsedAt    *time.Time
+	// NewEmail is set on tokens of an email change: redeeming the token
+	// moves the user to it.
+	NewEmail string
+	// SessionID is the session that asked for the email change.
+	SessionID string
 }
 
 type PasswordHasher interface {
@@ -125,46 +189,99 @@ type PasswordHasher interface {
 }
 
 type TokenProvider interface {
-	GenerateAccessToken(u user.User) (string, error)
+	// GenerateAccessToken embeds sessionID so requests can tell which
+	// session they belong to.
+	GenerateAccessToken(u user.User, sessionID string) (string, error)
 	GenerateRefreshToken(u user.User) (string, error)
 	ParseAccessToken(accessToken string) (user.User, error)
 	ParseRefreshToken(refreshToken string) (user.User, error)
+	// GenerateTwoFactorToken issues the short-lived challenge token of a
+	// login that still needs its second factor.
+	GenerateTwoFactorToken(u user.User) (string, error)
+	ParseTwoFactorToken(token string) (user.User, error)
+	// GenerateOAuthLinkToken issues the state of an OAuth flow that links
+	// provider to u rather than logging in.
+	GenerateOAuthLinkToken(u user.User, provider string) (string, error)
+	ParseOAuthLinkToken(token string) (user.User, string, error)
 }
 
 type OAuthUser struct {
+	// Provider is set by the usecase from the registry key, not by adapters.
+	Provider       OAuthProvider
 	ProviderUserID string
 	Email          string
 	Name           string
 	EmailVerified  bool
 }
 
-type GoogleOAuthProvider interface {
-	AuthCodeURL(state string) string
-	Exchange(ctx context.Context, code string) (OAuthUser, error)
+// OAuthFlow holds the per-login values an authorization code has to be
+// redeemed with. The caller keeps it between the redirect to the provider
+// and the callback, e.g. in a cookie, and checks State itself.
+type OAuthFlow struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+// OAuthLoginProvider is an external identity provider, e.g. an OpenID
+// Connect adapter.
+type OAuthLoginProvider interface {
+	AuthCodeURL(ctx context.Context, flow OAuthFlow) (string, error)
+	// Exchange redeems code and returns the verified identity. Adapters
+	// check the nonce and PKCE verifier of flow.
+	Exchange(ctx context.Context, code string, flow OAuthFlow) (OAuthUser, error)
 }
 
+// OAuthProviders maps provider names, as used in routes and stored with
+// linked accounts, to their adapters.
+type OAuthProviders map[OAuthProvider]OAuthLoginProvider
+
 type Mailer interface {
 	SendResetPasswordEmail(ctx context.Context, toEmail, toName, resetURL string) error
 	SendVerifyEmail(ctx context.Context, toEmail, toName, verifyURL string) error
+	SendMagicLinkEmail(ctx context.Context, toEmail, toName, loginURL string) error
+	// SendEmailChangeNotice tells the current address that the account is
+	// being moved to newEmail.
+	SendEmailChangeNotice(ctx context.Context, toEmail, toName, newEmail string) error
+	// SendNewSignInAlert tells the user about a sign-in from a new client.
+	// denyURL reports it as not theirs.
+	SendNewSignInAlert(ctx context.Context, toEmail, toName, userAgent, ipAddress, denyURL string) error
 }
 
 type Usecase struct {
-	userRepo        UserRepository
-	oauthRepo       OAuthProviderAccountRepository
-	refreshRepo     RefreshTokenRepository
-	resetTokenRepo  PasswordResetTokenRepository
-	verifyTokenRepo EmailVerificationTokenRepository
-	passwordHasher  PasswordHasher
-	tokenProvider   TokenProvider
-	googleProvider  GoogleOAuthProvider
-	mailer          Mailer
-	resetBaseURL    string
-	verifyBaseURL   string
-	maxRetries      int
-	jailDuration    time.Duration
-	resetTTL        time.Duration
-	verifyTTL       time.Duration
-	now             func() time.Time
+	userRepo         UserRepository
+	oauthRepo        OAuthProviderAccountRepository
+	refreshRepo      RefreshTokenRepository
+	resetTokenRepo   PasswordResetTokenRepository
+	verifyTokenRepo  EmailVerificationTokenRepository
+	magicLinkRepo    MagicLinkTokenRepository
+	passwordHasher   PasswordHasher
+	passwordPolicy   *user.PasswordPolicy
+	tokenProvider    TokenProvider
+	oauthProviders   OAuthProviders
+	mailer           Mailer
+	linkByEmail      map[OAuthProvider]bool
+	securityEvents   SecurityEventRepository
+	auditLog         audit.Recorder
+	tokenRevocations TokenRevocationStore
+	twoFactorRepo    TwoFactorRepository
+	totp             TOTPGenerator
+	phoneCodeRepo    PhoneVerificationRepository
+	smsSender        SMSSender
+	signInAlertRepo  SignInAlertRepository
+	deletionRepo     AccountDeletionRepository
+	exportRepo       DataExportRepository
+	resetBaseURL     string
+	verifyBaseURL    string
+	magicLinkBaseURL string
+	signInDenyURL    string
+	maxRetries       int
+	jailDuration     time.Duration
+	resetTTL         time.Duration
+	verifyTTL        time.Duration
+	magicLinkTTL     time.Duration
+	deletionGrace    time.Duration
+	now              func() time.Time
 }
 
 type TokenPair struct {
@@ -185,20 +302,21 @@ func NewUsecase(
 	verifyTokenRepo EmailVerificationTokenRepository,
 	passwordHasher PasswordHasher,
 	tokenProvider TokenProvider,
-	googleProvider GoogleOAuthProvider,
+	oauthProviders OAuthProviders,
 	mailer Mailer,
 	resetBaseURL string,
 	verifyBaseURL string,
 ) *Usecase {
-	return &Usecase{
+	uc := &Usecase{
 		userRepo:        userRepo,
 		oauthRepo:       oauthRepo,
 		refreshRepo:     refreshRepo,
 		resetTokenRepo:  resetTokenRepo,
 		verifyTokenRepo: verifyTokenRepo,
 		passwordHasher:  passwordHasher,
+		passwordPolicy:  user.NewPasswordPolicy(passwordHasher),
 		tokenProvider:   tokenProvider,
-		googleProvider:  googleProvider,
+		oauthProviders:  oauthProviders,
 		mailer:          mailer,
 		resetBaseURL:    strings.TrimSpace(resetBaseURL),
 		verifyBaseURL:   strings.TrimSpace(verifyBaseURL),
@@ -206,10 +324,21 @@ func NewUsecase(
 		jailDuration:    15 * time.Minute,
 		resetTTL:        30 * time.Minute,
 		verifyTTL:       24 * time.Hour,
+		magicLinkTTL:    defaultMagicLinkTTL,
 		now: func() time.Time {
 			return time.Now().UTC()
 		},
 	}
+
+	return uc.WithTrustedEmailProviders(defaultTrustedEmailProviders...)
+}
+
+// WithPasswordPolicy replaces the default policy, which only applies
+// user.ValidatePassword, for registration and password resets.
+func (uc *Usecase) WithPasswordPolicy(policy *user.PasswordPolicy) *Usecase {
+	uc.passwordPolicy = policy
+
+	return uc
 }
 
 // nolint: funlen
@@ -231,6 +360,10 @@ func (uc *Usecase) Register(ctx context.Context, name, email, phone, password st
 		return err
 	}
 
+	if err := uc.passwordPolicy.Check(ctx, user.User{}, newUser.Password); err != nil {
+		return err
+	}
+
 	hashed, err := uc.passwordHasher.Hash(newUser.Password)
 	if err != nil {
 		return err
@@ -239,7 +372,9 @@ func (uc *Usecase) Register(ctx context.Context, name, email, phone, password st
 	newUser.Password = ""
 	newUser.PasswordHash = hashed
 
-	created, err := uc.userRepo.CreateUserTx(ctx, newUser, nil)
+	created, err := uc.userRepo.CreateUserTx(ctx, newUser, func(ctx context.Context, created user.User) error {
+		return uc.passwordPolicy.Remember(ctx, created.ID, hashed)
+	})
 	if err != nil {
 		return err
 	}
@@ -250,41 +385,103 @@ func (uc *Usecase) Register(ctx context.Context, name, email, phone, password st
 	return nil
 }
 
-func (uc *Usecase) Login(ctx context.Context, email, password string) (TokenPair, error) {
+func (uc *Usecase) Login(ctx context.Context, email, password string) (LoginResult, error) {
 	now := uc.now()
 
 	u, err := uc.userRepo.GetByEmail(ctx, email)
 	if err != nil {
-		return TokenPair{}, ErrInvalidCredentials
+		uc.recordAudit(ctx, audit.Event{
+			Type:     audit.EventLoginFailed,
+			Metadata: map[string]string{"reason": loginFailedUnknownEmail, "email": strings.TrimSpace(email)},
+		})
+
+		return LoginResult{}, ErrInvalidCredentials
 	}
 
 	if strings.TrimSpace(u.PasswordHash) == "" {
-		return TokenPair{}, ErrPasswordAuthNotAvailable
+		return LoginResult{}, ErrPasswordAuthNotAvailable
 	}
 
-	u, err = uc.handleLockState(ctx, u, now)
+	unlocked, err := uc.handleLockState(ctx, u, now)
 	if err != nil {
-		return TokenPair{}, err
+		if errors.Is(err, ErrAccountLocked) {
+			uc.recordLoginFailure(ctx, u.ID, loginFailedAccountLocked)
+		}
+
+		return LoginResult{}, err
 	}
 
+	u = unlocked
+
 	// Compare password
 	if err := uc.passwordHasher.Compare(u.PasswordHash, password); err != nil {
+		uc.recordLoginFailure(ctx, u.ID, loginFailedInvalidPassword)
+
 		if err := uc.recordFailure(ctx, u, now); err != nil {
-			return TokenPair{}, err
+			return LoginResult{}, err
 		}
 
-		return TokenPair{}, ErrInvalidCredentials
+		return LoginResult{}, ErrInvalidCredentials
 	}
 
-	if err := uc.resetAuthState(ctx, u); err != nil {
-		return TokenPair{}, err
+	// Checked after the password so the status is not told to guessers.
+	if u.Status == user.UserStatusInactive {
+		uc.recordLoginFailure(ctx, u.ID, loginFailedAccountInactive)
+		return LoginResult{}, ErrAccountInactive
+	}
+
+	if u.MustResetPassword {
+		return LoginResult{}, ErrPasswordResetRequired
+	}
+
+	twoFactor, err := uc.twoFactorEnabled(ctx, u.ID)
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	// With two-factor authentication the failed attempts are only cleared
+	// once the second factor passes, so knowing the password does not reset
+	// the lockout for guessed codes.
+	if !twoFactor {
+		if err := uc.resetAuthState(ctx, u); err != nil {
+			return LoginResult{}, err
+		}
 	}
 
 	if u.EmailVerifiedAt == nil {
-		return TokenPair{}, ErrEmailNotVerified
+		return LoginResult{}, ErrEmailNotVerified
 	}
 
-	return uc.issueTokens(ctx, u)
+	return uc.completeLogin(ctx, u, twoFactor, map[string]string{"method": "password"})
+}
+
+// completeLogin finishes a login whose first factor passed: users with
+// two-factor authentication get a challenge token, everyone else tokens.
+// loginAudit describes the first factor in the audit log.
+func (uc *Usecase) completeLogin(ctx context.Context, u user.User, twoFactor bool, loginAudit map[string]string) (LoginResult, error) {
+	if twoFactor {
+		challengeToken, err := uc.tokenProvider.GenerateTwoFactorToken(u)
+		if err != nil {
+			return LoginResult{}, err
+		}
+
+		return LoginResult{ChallengeToken: challengeToken}, nil
+	}
+
+	newClient := uc.isNewSignInClient(ctx, u)
+
+	tokens, err := uc.issueTokens(ctx, u)
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	uc.recordUserAudit(ctx, u.ID, audit.EventLoginSucceeded, loginAudit)
+
+	if newClient {
+		uc.sendSignInAlert(ctx, u)
+	}
+
+	return LoginResult{Tokens: tokens}, nil
 }
 
 func (uc *Usecase) handleLockState(ctx context.Context, u user.User, now time.Time) (user.User, error) {
@@ -329,7 +526,9 @@ func (uc *Usecase) resetAuthState(ctx context.Context, u user.User) error {
 }
 
 func (uc *Usecase) issueTokens(ctx context.Context, u user.User) (TokenPair, error) {
-	accessToken, err := uc.tokenProvider.GenerateAccessToken(u)
+	familyID := uuid.NewString()
+
+	accessToken, err := uc.tokenProvider.GenerateAccessToken(u, familyID)
 	if err != nil {
 		return TokenPair{}, err
 	}
@@ -339,14 +538,7 @@ func (uc *Usecase) issueTokens(ctx context.Context, u user.User) (TokenPair, err
 		return TokenPair{}, err
 	}
 
-	refreshExp := uc.now().Add(uc.tokenProviderRefreshTTL())
-	if err := uc.refreshRepo.Save(ctx, RefreshToken{
-		UserID:    u.ID,
-		TokenHash: hashToken(refreshToken),
-		UserAgent: clientInfoFromContext(ctx).UserAgent,
-		IPAddress: clientInfoFromContext(ctx).IPAddress,
-		ExpiresAt: refreshExp,
-	}); err != nil {
+	if err := uc.saveRefreshToken(ctx, u.ID, familyID, refreshToken); err != nil {
 		return TokenPair{}, err
 	}
 
@@ -364,11 +556,15 @@ func (uc *Usecase) Refresh(ctx context.Context, refreshToken string) (TokenPair,
 
 	tokenHash := hashToken(refreshToken)
 
-	stored, err := uc.refreshRepo.GetActiveByHash(ctx, tokenHash)
-	if err != nil {
+	stored, err := uc.refreshRepo.GetByHash(ctx, tokenHash)
+	if err != nil || stored.UserID != u.ID {
 		return TokenPair{}, ErrInvalidRefreshToken
 	}
 
+	if stored.RevokedAt != nil {
+		return TokenPair{}, uc.handleRefreshTokenReuse(ctx, stored)
+	}
+
 	if stored.ExpiresAt.Before(uc.now()) {
 		return TokenPair{}, ErrInvalidRefreshToken
 	}
@@ -377,7 +573,7 @@ func (uc *Usecase) Refresh(ctx context.Context, refreshToken string) (TokenPair,
 		return TokenPair{}, ErrInvalidRefreshToken
 	}
 
-	accessToken, err := uc.tokenProvider.GenerateAccessToken(u)
+	accessToken, err := uc.tokenProvider.GenerateAccessToken(u, stored.FamilyID)
 	if err != nil {
 		return TokenPair{}, err
 	}
@@ -387,28 +583,65 @@ func (uc *Usecase) Refresh(ctx context.Context, refreshToken string) (TokenPair,
 		return TokenPair{}, err
 	}
 
+	// Losing a race to rotate the token means it was presented twice.
 	now := uc.now()
 	if err := uc.refreshRepo.RevokeByHash(ctx, tokenHash, now); err != nil {
+		if errors.Is(err, ErrRefreshTokenRevoked) {
+			return TokenPair{}, uc.handleRefreshTokenReuse(ctx, stored)
+		}
+
 		return TokenPair{}, err
 	}
 
-	if err := uc.refreshRepo.Save(ctx, RefreshToken{
-		UserID:    u.ID,
-		TokenHash: hashToken(newRefreshToken),
-		UserAgent: clientInfoFromContext(ctx).UserAgent,
-		IPAddress: clientInfoFromContext(ctx).IPAddress,
-		ExpiresAt: now.Add(uc.tokenProviderRefreshTTL()),
-	}); err != nil {
+	if err := uc.saveRefreshToken(ctx, u.ID, stored.FamilyID, newRefreshToken); err != nil {
 		return TokenPair{}, err
 	}
 
+	uc.recordUserAudit(ctx, u.ID, audit.EventTokenRefreshed, map[string]string{"session_id": stored.FamilyID})
+
 	return TokenPair{
 		AccessToken:  accessToken,
 		RefreshToken: newRefreshToken,
 	}, nil
 }
 
-func (uc *Usecase) Logout(ctx context.Context, refreshToken string) error {
+// handleRefreshTokenReuse revokes every token of the family a replayed token
+// belongs to. Either the legitimate client or an attacker holds the newest
+// token, and there is no way to tell which, so both are logged out.
+func (uc *Usecase) handleRefreshTokenReuse(ctx context.Context, stored RefreshToken) error {
+	if err := uc.refreshRepo.RevokeFamily(ctx, stored.FamilyID, uc.now()); err != nil {
+		return err
+	}
+
+	uc.recordSecurityEvent(ctx, SecurityEvent{
+		UserID:   stored.UserID,
+		Type:     SecurityEventRefreshTokenReuse,
+		FamilyID: stored.FamilyID,
+	})
+
+	return ErrRefreshTokenReused
+}
+
+func (uc *Usecase) saveRefreshToken(ctx context.Context, userID, familyID, refreshToken string) error {
+	info := clientInfoFromContext(ctx)
+
+	return uc.refreshRepo.Save(ctx, RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		UserAgent: info.UserAgent,
+		IPAddress: info.IPAddress,
+		ExpiresAt: uc.now().Add(uc.tokenProviderRefreshTTL()),
+	})
+}
+
+func (uc *Usecase) Logout(ctx context.Context, refreshToken string, current AccessTokenClaims) error {
+	// The access token is the caller's own, so it is revoked even when the
+	// refresh token turns out to be invalid.
+	if err := uc.revokeAccessToken(ctx, current); err != nil {
+		return err
+	}
+
 	if strings.TrimSpace(refreshToken) == "" {
 		return ErrInvalidRefreshToken
 	}
@@ -417,7 +650,18 @@ func (uc *Usecase) Logout(ctx context.Context, refreshToken string) error {
 		return ErrInvalidRefreshToken
 	}
 
-	return uc.refreshRepo.RevokeByHash(ctx, hashToken(refreshToken), uc.now())
+	stored, err := uc.refreshRepo.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil || stored.RevokedAt != nil {
+		return ErrInvalidRefreshToken
+	}
+
+	// Revoke the whole session rather than the one token, in case a
+	// rotated copy of it is in someone else's hands.
+	if err := uc.refreshRepo.RevokeFamily(ctx, stored.FamilyID, uc.now()); err != nil {
+		return err
+	}
+
+	return uc.revokeSessionAccessTokens(ctx, stored.FamilyID)
 }
 
 func (uc *Usecase) SendEmailVerification(ctx context.Context, email string) error {
@@ -453,11 +697,21 @@ func (uc *Usecase) VerifyEmail(ctx context.Context, verifyToken string) error {
 	}
 
 	now := uc.now()
+	if entry.NewEmail != "" {
+		return uc.confirmEmailChange(ctx, entry, now)
+	}
+
 	if err := uc.userRepo.UpdateEmailVerifiedAt(ctx, entry.UserID, &now); err != nil {
 		return ErrInvalidVerifyToken
 	}
 
-	return uc.verifyTokenRepo.MarkUsedByHash(ctx, tokenHash, now)
+	if err := uc.verifyTokenRepo.MarkUsedByHash(ctx, tokenHash, now); err != nil {
+		return err
+	}
+
+	uc.recordUserAudit(ctx, entry.UserID, audit.EventEmailVerified, nil)
+
+	return nil
 }
 
 func (uc *Usecase) issueEmailVerification(ctx context.Context, u user.User) error {
@@ -506,6 +760,10 @@ func (uc *Usecase) ForgotPassword(ctx context.Context, email string) error {
 		return ErrMailerNotConfigured
 	}
 
+	return uc.sendPasswordReset(ctx, u)
+}
+
+func (uc *Usecase) sendPasswordReset(ctx context.Context, u user.User) error {
 	resetToken, err := generateRandomPassword(24)
 	if err != nil {
 		return err
@@ -561,6 +819,10 @@ func (uc *Usecase) ResetPassword(ctx context.Context, resetToken, newPassword st
 		return ErrPasswordAuthNotAvailable
 	}
 
+	if err := uc.passwordPolicy.Check(ctx, u, passCheck.Password); err != nil {
+		return err
+	}
+
 	hashed, err := uc.passwordHasher.Hash(passCheck.Password)
 	if err != nil {
 		return err
@@ -570,12 +832,26 @@ func (uc *Usecase) ResetPassword(ctx context.Context, resetToken, newPassword st
 		return err
 	}
 
+	if err := uc.passwordPolicy.Remember(ctx, u.ID, hashed); err != nil {
+		return err
+	}
+
 	now := uc.now()
 	if err := uc.refreshRepo.RevokeAllByUserID(ctx, u.ID, now); err != nil {
 		return err
 	}
 
-	return uc.resetTokenRepo.MarkUsedByHash(ctx, tokenHash, now)
+	if err := uc.revokeUserAccessTokens(ctx, u.ID, now); err != nil {
+		return err
+	}
+
+	if err := uc.resetTokenRepo.MarkUsedByHash(ctx, tokenHash, now); err != nil {
+		return err
+	}
+
+	uc.recordUserAudit(ctx, u.ID, audit.EventPasswordReset, nil)
+
+	return nil
 }
 
 func (uc *Usecase) Me(ctx context.Context, accessToken string) (user.User, error) {
@@ -596,59 +872,157 @@ func (uc *Usecase) Me(ctx context.Context, accessToken string) (user.User, error
 	return u, nil
 }
 
-func (uc *Usecase) GoogleAuthURL(state string) (string, error) {
-	if uc.googleProvider == nil {
-		return "", ErrOAuthNotConfigured
+// OAuthProviders returns the configured provider names, sorted.
+func (uc *Usecase) OAuthProviders() []OAuthProvider {
+	names := make([]OAuthProvider, 0, len(uc.oauthProviders))
+	for name := range uc.oauthProviders {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+func (uc *Usecase) oauthProvider(name OAuthProvider) (OAuthLoginProvider, error) {
+	if len(uc.oauthProviders) == 0 {
+		return nil, ErrOAuthNotConfigured
+	}
+
+	provider, ok := uc.oauthProviders[name]
+	if !ok || provider == nil {
+		return nil, ErrUnknownOAuthProvider
+	}
+
+	return provider, nil
+}
+
+// OAuthAuthURL starts a login with provider. It returns the URL to send the
+// user to and a fresh OAuthFlow to pass back to LoginWithOAuth.
+func (uc *Usecase) OAuthAuthURL(ctx context.Context, name OAuthProvider) (string, OAuthFlow, error) {
+	provider, err := uc.oauthProvider(name)
+	if err != nil {
+		return "", OAuthFlow{}, err
 	}
 
-	if strings.TrimSpace(state) == "" {
-		return "", ErrMissingState
+	flow, err := newOAuthFlow()
+	if err != nil {
+		return "", OAuthFlow{}, err
 	}
 
-	return uc.googleProvider.AuthCodeURL(state), nil
+	authURL, err := provider.AuthCodeURL(ctx, flow)
+	if err != nil {
+		return "", OAuthFlow{}, err
+	}
+
+	return authURL, flow, nil
 }
 
-func (uc *Usecase) LoginWithGoogle(ctx context.Context, code string) (TokenPair, error) {
-	if uc.googleProvider == nil {
-		return TokenPair{}, ErrOAuthNotConfigured
+// LoginWithOAuth signs in with an identity from provider name, creating the
+// account on first use. The identity replaces the password only: locks,
+// required password resets and two-factor authentication apply as for
+// Login.
+func (uc *Usecase) LoginWithOAuth(ctx context.Context, name OAuthProvider, code string, flow OAuthFlow) (LoginResult, error) {
+	provider, err := uc.oauthProvider(name)
+	if err != nil {
+		return LoginResult{}, err
 	}
 
 	if strings.TrimSpace(code) == "" {
-		return TokenPair{}, ErrMissingCode
+		return LoginResult{}, ErrMissingCode
+	}
+
+	if strings.TrimSpace(flow.State) == "" {
+		return LoginResult{}, ErrMissingState
 	}
 
-	oauthUser, err := uc.googleProvider.Exchange(ctx, code)
+	oauthUser, err := provider.Exchange(ctx, code, flow)
 	if err != nil {
-		return TokenPair{}, err
+		return LoginResult{}, err
 	}
 
+	oauthUser.Provider = name
+
 	if strings.TrimSpace(oauthUser.Email) == "" {
-		return TokenPair{}, ErrMissingEmail
+		return LoginResult{}, ErrMissingEmail
 	}
 
 	if !oauthUser.EmailVerified {
-		return TokenPair{}, ErrUnverifiedEmail
+		return LoginResult{}, ErrUnverifiedEmail
 	}
 
 	u, tokens, created, err := uc.getOrCreateUserFromOAuth(ctx, oauthUser)
 	if err != nil {
-		return TokenPair{}, err
+		return LoginResult{}, err
 	}
 
+	loginAudit := map[string]string{"method": "oauth", "provider": string(name)}
+
 	if created {
-		return tokens, nil
+		uc.recordUserAudit(ctx, u.ID, audit.EventLoginSucceeded, loginAudit)
+
+		return LoginResult{Tokens: tokens}, nil
+	}
+
+	if u.Status == user.UserStatusInactive {
+		uc.recordLoginFailure(ctx, u.ID, loginFailedAccountInactive)
+		return LoginResult{}, ErrAccountInactive
+	}
+
+	now := uc.now()
+
+	unlocked, err := uc.handleLockState(ctx, u, now)
+	if err != nil {
+		if errors.Is(err, ErrAccountLocked) {
+			uc.recordLoginFailure(ctx, u.ID, loginFailedAccountLocked)
+		}
+
+		return LoginResult{}, err
+	}
+
+	u = unlocked
+
+	if u.MustResetPassword {
+		return LoginResult{}, ErrPasswordResetRequired
 	}
 
 	if u.EmailVerifiedAt == nil {
-		now := uc.now()
 		if err := uc.userRepo.UpdateEmailVerifiedAt(ctx, u.ID, &now); err != nil {
-			return TokenPair{}, err
+			return LoginResult{}, err
 		}
 
 		u.EmailVerifiedAt = &now
 	}
 
-	return uc.issueTokens(ctx, u)
+	twoFactor, err := uc.twoFactorEnabled(ctx, u.ID)
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	if !twoFactor {
+		if err := uc.resetAuthState(ctx, u); err != nil {
+			return LoginResult{}, err
+		}
+	}
+
+	return uc.completeLogin(ctx, u, twoFactor, loginAudit)
+}
+
+// newOAuthFlow returns random state, nonce and PKCE verifier values. 32
+// random bytes encode to the 43 characters RFC 7636 asks of a verifier.
+func newOAuthFlow() (OAuthFlow, error) {
+	var values [3]string
+
+	for i := range values {
+		v, err := generateRandomPassword(32)
+		if err != nil {
+			return OAuthFlow{}, err
+		}
+
+		values[i] = v
+	}
+
+	return OAuthFlow{State: values[0], Nonce: values[1], CodeVerifier: values[2]}, nil
 }
 
 func (uc *Usecase) getOrCreateUserFromOAuth(ctx context.Context, oauthUser OAuthUser) (user.User, TokenPair, bool, error) {
@@ -678,7 +1052,7 @@ func (uc *Usecase) findOAuthUserByProvider(ctx context.Context, oauthUser OAuthU
 		return user.User{}, false, nil
 	}
 
-	userID, err := uc.oauthRepo.GetUserIDByProvider(ctx, OAuthProviderGoogle, providerUserID)
+	userID, err := uc.oauthRepo.GetUserIDByProvider(ctx, oauthUser.Provider, providerUserID)
 	if err != nil {
 		return user.User{}, false, nil
 	}
@@ -691,9 +1065,16 @@ func (uc *Usecase) findOAuthUserByProvider(ctx context.Context, oauthUser OAuthU
 	return u, true, nil
 }
 
+// findOrLinkOAuthUserByEmail links a trusted provider to the account with
+// the same email. For other providers an existing account is refused with
+// ErrOAuthLinkRequired.
 func (uc *Usecase) findOrLinkOAuthUserByEmail(ctx context.Context, oauthUser OAuthUser) (user.User, bool, error) {
 	u, err := uc.userRepo.GetByEmail(ctx, oauthUser.Email)
 	if err == nil {
+		if !uc.linkByEmail[oauthUser.Provider] {
+			return user.User{}, false, ErrOAuthLinkRequired
+		}
+
 		if err := uc.linkOAuthProvider(ctx, u.ID, oauthUser); err != nil {
 			return user.User{}, false, err
 		}
@@ -725,7 +1106,9 @@ func (uc *Usecase) createOAuthUser(ctx context.Context, oauthUser OAuthUser) (us
 	var tokens TokenPair
 
 	created, err := uc.userRepo.CreateUserTx(ctx, newUser, func(txCtx context.Context, created user.User) error {
-		accessToken, err := uc.tokenProvider.GenerateAccessToken(created)
+		familyID := uuid.NewString()
+
+		accessToken, err := uc.tokenProvider.GenerateAccessToken(created, familyID)
 		if err != nil {
 			return err
 		}
@@ -740,14 +1123,7 @@ func (uc *Usecase) createOAuthUser(ctx context.Context, oauthUser OAuthUser) (us
 			RefreshToken: refreshToken,
 		}
 
-		info := clientInfoFromContext(txCtx)
-		if err := uc.refreshRepo.Save(txCtx, RefreshToken{
-			UserID:    created.ID,
-			TokenHash: hashToken(tokens.RefreshToken),
-			UserAgent: info.UserAgent,
-			IPAddress: info.IPAddress,
-			ExpiresAt: uc.now().Add(uc.tokenProviderRefreshTTL()),
-		}); err != nil {
+		if err := uc.saveRefreshToken(txCtx, created.ID, familyID, tokens.RefreshToken); err != nil {
 			return err
 		}
 
@@ -779,7 +1155,7 @@ func (uc *Usecase) linkOAuthProvider(ctx context.Context, userID string, oauthUs
 		return nil
 	}
 
-	return uc.oauthRepo.Upsert(ctx, userID, OAuthProviderGoogle, providerUserID, oauthUser.Email)
+	return uc.oauthRepo.Upsert(ctx, userID, oauthUser.Provider, providerUserID, oauthUser.Email)
 }
 
 func (uc *Usecase) recordFailure(ctx context.Context, u user.User, now time.Time) error {
@@ -805,7 +1181,7 @@ func (uc *Usecase) recordFailure(ctx context.Context, u user.User, now time.Time
 		status = user.UserStatusLocked
 	}
 
-	return uc.userRepo.UpdateAuthState(
+	if err := uc.userRepo.UpdateAuthState(
 		ctx,
 		u.ID,
 		failedCount,
@@ -813,7 +1189,29 @@ func (uc *Usecase) recordFailure(ctx context.Context, u user.User, now time.Time
 		lockEscalationLevel,
 		&lastFailedLoginAt,
 		status,
-	)
+	); err != nil {
+		return err
+	}
+
+	if status == user.UserStatusLocked && u.Status != user.UserStatusLocked {
+		uc.recordAudit(ctx, audit.Event{
+			Type:     audit.EventAccountLocked,
+			UserID:   u.ID,
+			Metadata: map[string]string{"locked_until": lockUntil.Format(time.RFC3339)},
+		})
+	}
+
+	return nil
+}
+
+// recordLoginFailure records a failed login of a known account. The actor
+// stays empty: whoever failed has not proven to be the user.
+func (uc *Usecase) recordLoginFailure(ctx context.Context, userID, reason string) {
+	uc.recordAudit(ctx, audit.Event{
+		Type:     audit.EventLoginFailed,
+		UserID:   userID,
+		Metadata: map[string]string{"reason": reason},
+	})
 }
 
 func generateRandomPassword(length int) (string, error) {
@@ -834,6 +1232,16 @@ func hashToken(token string) string {
 	return hex.EncodeToString(sum[:])
 }
 
+// tokenProviderAccessTTL falls back to the refresh TTL, which no access
+// token outlives.
+func (uc *Usecase) tokenProviderAccessTTL() time.Duration {
+	if provider, ok := uc.tokenProvider.(interface{ GetAccessTTL() time.Duration }); ok {
+		return provider.GetAccessTTL()
+	}
+
+	return uc.tokenProviderRefreshTTL()
+}
+
 func (uc *Usecase) tokenProviderRefreshTTL() time.Duration {
 	if provider, ok := uc.tokenProvider.(interface{ GetRefreshTTL() time.Duration }); ok {
 		return provider.GetRefreshTTL()
@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,6 +19,9 @@ type mockUserRepo struct {
 	createUserTxFn        func(ctx context.Context, u user.User, fn func(ctx context.Context, created user.User) error) (user.User, error)
 	updatePasswordHashFn  func(ctx context.Context, id, passwordHash string) error
 	updateEmailVerifiedFn func(ctx context.Context, id string, verifiedAt *time.Time) error
+	updateEmailFn         func(ctx context.Context, id, email string, verifiedAt *time.Time) error
+	updatePhoneVerifiedFn func(ctx context.Context, id, phone string, verifiedAt time.Time) error
+	requireResetFn        func(ctx context.Context, id string) error
 	updateAuthStateFn     func(ctx context.Context, id string, failedLoginAttempts int, lockUntil *time.Time, lockEscalationLevel int, lastFailedLoginAt *time.Time, status user.UserStatus) error
 }
 
@@ -65,6 +69,30 @@ func (m *mockUserRepo) UpdateEmailVerifiedAt(ctx context.Context, id string, ver
 	return nil
 }
 
+func (m *mockUserRepo) UpdateEmail(ctx context.Context, id, email string, verifiedAt *time.Time) error {
+	if m.updateEmailFn != nil {
+		return m.updateEmailFn(ctx, id, email, verifiedAt)
+	}
+
+	return nil
+}
+
+func (m *mockUserRepo) UpdatePhoneVerifiedAt(ctx context.Context, id, phone string, verifiedAt time.Time) error {
+	if m.updatePhoneVerifiedFn != nil {
+		return m.updatePhoneVerifiedFn(ctx, id, phone, verifiedAt)
+	}
+
+	return nil
+}
+
+func (m *mockUserRepo) RequirePasswordReset(ctx context.Context, id string) error {
+	if m.requireResetFn != nil {
+		return m.requireResetFn(ctx, id)
+	}
+
+	return nil
+}
+
 func (m *mockUserRepo) UpdateAuthState(
 	ctx context.Context,
 	id string,
@@ -83,9 +111,13 @@ func (m *mockUserRepo) UpdateAuthState(
 
 type mockRefreshRepo struct {
 	saveFn              func(ctx context.Context, token RefreshToken) error
-	getActiveByHashFn   func(ctx context.Context, tokenHash string) (RefreshToken, error)
+	getByHashFn         func(ctx context.Context, tokenHash string) (RefreshToken, error)
 	revokeByHashFn      func(ctx context.Context, tokenHash string, revokedAt time.Time) error
+	revokeFamilyFn      func(ctx context.Context, familyID string, revokedAt time.Time) error
 	revokeAllByUserIDFn func(ctx context.Context, userID string, revokedAt time.Time) error
+	listSessionsFn      func(ctx context.Context, userID string, now time.Time) ([]Session, error)
+	revokeSessionFn     func(ctx context.Context, userID, sessionID string, revokedAt time.Time) error
+	revokeOthersFn      func(ctx context.Context, userID, keepSessionID string, revokedAt time.Time) error
 }
 
 func (m *mockRefreshRepo) Save(ctx context.Context, token RefreshToken) error {
@@ -96,9 +128,9 @@ func (m *mockRefreshRepo) Save(ctx context.Context, token RefreshToken) error {
 	return nil
 }
 
-func (m *mockRefreshRepo) GetActiveByHash(ctx context.Context, tokenHash string) (RefreshToken, error) {
-	if m.getActiveByHashFn != nil {
-		return m.getActiveByHashFn(ctx, tokenHash)
+func (m *mockRefreshRepo) GetByHash(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	if m.getByHashFn != nil {
+		return m.getByHashFn(ctx, tokenHash)
 	}
 
 	return RefreshToken{}, errors.New("not found")
@@ -112,6 +144,14 @@ func (m *mockRefreshRepo) RevokeByHash(ctx context.Context, tokenHash string, re
 	return nil
 }
 
+func (m *mockRefreshRepo) RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	if m.revokeFamilyFn != nil {
+		return m.revokeFamilyFn(ctx, familyID, revokedAt)
+	}
+
+	return nil
+}
+
 func (m *mockRefreshRepo) RevokeAllByUserID(ctx context.Context, userID string, revokedAt time.Time) error {
 	if m.revokeAllByUserIDFn != nil {
 		return m.revokeAllByUserIDFn(ctx, userID, revokedAt)
@@ -120,6 +160,30 @@ func (m *mockRefreshRepo) RevokeAllByUserID(ctx context.Context, userID string,
 	return nil
 }
 
+func (m *mockRefreshRepo) ListActiveSessions(ctx context.Context, userID string, now time.Time) ([]Session, error) {
+	if m.listSessionsFn != nil {
+		return m.listSessionsFn(ctx, userID, now)
+	}
+
+	return nil, nil
+}
+
+func (m *mockRefreshRepo) RevokeSession(ctx context.Context, userID, sessionID string, revokedAt time.Time) error {
+	if m.revokeSessionFn != nil {
+		return m.revokeSessionFn(ctx, userID, sessionID, revokedAt)
+	}
+
+	return nil
+}
+
+func (m *mockRefreshRepo) RevokeOtherSessions(ctx context.Context, userID, keepSessionID string, revokedAt time.Time) error {
+	if m.revokeOthersFn != nil {
+		return m.revokeOthersFn(ctx, userID, keepSessionID, revokedAt)
+	}
+
+	return nil
+}
+
 type mockResetRepo struct {
 	getActiveByHashFn func(ctx context.Context, tokenHash string) (PasswordResetToken, error)
 	markUsedFn        func(ctx context.Context, tokenHash string, usedAt time.Time) error
@@ -148,9 +212,11 @@ func (m *mockResetRepo) MarkUsedByHash(ctx context.Context, tokenHash string, us
 type mockVerifyRepo struct {
 	getActiveByHashFn func(ctx context.Context, tokenHash string) (EmailVerificationToken, error)
 	markUsedFn        func(ctx context.Context, tokenHash string, usedAt time.Time) error
+	saved             []EmailVerificationToken
 }
 
 func (m *mockVerifyRepo) Save(ctx context.Context, token EmailVerificationToken) error {
+	m.saved = append(m.saved, token)
 	return nil
 }
 
@@ -170,16 +236,58 @@ func (m *mockVerifyRepo) MarkUsedByHash(ctx context.Context, tokenHash string, u
 	return nil
 }
 
-type mockOAuthRepo struct{}
+type mockOAuthRepo struct {
+	linked   []OAuthProvider
+	accounts []OAuthAccount
+	deleted  []OAuthProvider
+}
 
 func (m *mockOAuthRepo) GetUserIDByProvider(ctx context.Context, provider OAuthProvider, providerUserID string) (string, error) {
-	return "", errors.New("not found")
+	for _, account := range m.accounts {
+		if account.Provider == provider && account.ProviderUserID == providerUserID {
+			return account.UserID, nil
+		}
+	}
+
+	return "", ErrOAuthAccountNotLinked
 }
 
 func (m *mockOAuthRepo) Upsert(ctx context.Context, userID string, provider OAuthProvider, providerUserID, providerEmail string) error {
+	m.linked = append(m.linked, provider)
+	return nil
+}
+
+func (m *mockOAuthRepo) ListByUserID(ctx context.Context, userID string) ([]OAuthAccount, error) {
+	var accounts []OAuthAccount
+
+	for _, account := range m.accounts {
+		if account.UserID == userID {
+			accounts = append(accounts, account)
+		}
+	}
+
+	return accounts, nil
+}
+
+func (m *mockOAuthRepo) Delete(ctx context.Context, userID string, provider OAuthProvider) error {
+	m.deleted = append(m.deleted, provider)
 	return nil
 }
 
+type mockOAuthLoginProvider struct {
+	user OAuthUser
+	flow OAuthFlow
+}
+
+func (m *mockOAuthLoginProvider) AuthCodeURL(ctx context.Context, flow OAuthFlow) (string, error) {
+	return "https://idp.example.com/authorize?state=" + flow.State, nil
+}
+
+func (m *mockOAuthLoginProvider) Exchange(ctx context.Context, code string, flow OAuthFlow) (OAuthUser, error) {
+	m.flow = flow
+	return m.user, nil
+}
+
 type mockHasher struct {
 	hashFn    func(password string) (string, error)
 	compareFn func(hashed, plain string) error
@@ -203,13 +311,14 @@ func (m *mockHasher) Hash(password string) (string, error) {
 
 type mockTokenProvider struct {
 	parseRefreshFn    func(refreshToken string) (user.User, error)
-	generateAccessFn  func(u user.User) (string, error)
+	generateAccessFn  func(u user.User, sessionID string) (string, error)
 	generateRefreshFn func(u user.User) (string, error)
+	parseTwoFactorFn  func(token string) (user.User, error)
 }
 
-func (m *mockTokenProvider) GenerateAccessToken(u user.User) (string, error) {
+func (m *mockTokenProvider) GenerateAccessToken(u user.User, sessionID string) (string, error) {
 	if m.generateAccessFn != nil {
-		return m.generateAccessFn(u)
+		return m.generateAccessFn(u, sessionID)
 	}
 
 	return "access-token", nil
@@ -235,6 +344,31 @@ func (m *mockTokenProvider) ParseRefreshToken(refreshToken string) (user.User, e
 	return user.User{}, errors.New("invalid")
 }
 
+func (m *mockTokenProvider) GenerateTwoFactorToken(u user.User) (string, error) {
+	return "challenge-token", nil
+}
+
+func (m *mockTokenProvider) ParseTwoFactorToken(token string) (user.User, error) {
+	if m.parseTwoFactorFn != nil {
+		return m.parseTwoFactorFn(token)
+	}
+
+	return user.User{}, errors.New("invalid")
+}
+
+func (m *mockTokenProvider) GenerateOAuthLinkToken(u user.User, provider string) (string, error) {
+	return "link." + u.ID + "." + provider, nil
+}
+
+func (m *mockTokenProvider) ParseOAuthLinkToken(token string) (user.User, string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != "link" {
+		return user.User{}, "", errors.New("invalid")
+	}
+
+	return user.User{ID: parts[1]}, parts[2], nil
+}
+
 func newUsecaseForTest(u *mockUserRepo, r *mockRefreshRepo, p *mockResetRepo, h *mockHasher, t *mockTokenProvider) *Usecase {
 	return NewUsecase(u, &mockOAuthRepo{}, r, p, &mockVerifyRepo{}, h, t, nil, nil, "", "")
 }
@@ -267,6 +401,26 @@ func TestLogin_ReturnsPasswordAuthNotAvailableForOAuthOnly(t *testing.T) {
 	assert.ErrorIs(t, err, ErrPasswordAuthNotAvailable)
 }
 
+func TestLogin_RejectsInactiveAccount(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	repo := &mockUserRepo{
+		getByEmailFn: func(ctx context.Context, email string) (user.User, error) {
+			return user.User{ID: "u1", Email: email, PasswordHash: "hashed-password", Status: user.UserStatusInactive, EmailVerifiedAt: &now}, nil
+		},
+	}
+	refreshRepo := &mockRefreshRepo{
+		saveFn: func(ctx context.Context, token RefreshToken) error {
+			t.Fatal("inactive accounts must not get tokens")
+			return nil
+		},
+	}
+	uc := newUsecaseForTest(repo, refreshRepo, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{})
+
+	_, err := uc.Login(context.Background(), "john@example.com", "Password@123")
+
+	assert.ErrorIs(t, err, ErrAccountInactive)
+}
+
 func TestLogin_ReturnsEmailNotVerifiedWhenEmailNotVerified(t *testing.T) {
 	repo := &mockUserRepo{
 		getByEmailFn: func(ctx context.Context, email string) (user.User, error) {
@@ -296,7 +450,7 @@ func TestLogin_ReturnsEmailNotVerifiedWhenEmailNotVerified(t *testing.T) {
 func TestRefresh_FailsWhenSessionClientInfoMismatches(t *testing.T) {
 	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
 	uc := NewUsecase(&mockUserRepo{}, &mockOAuthRepo{}, &mockRefreshRepo{
-		getActiveByHashFn: func(ctx context.Context, tokenHash string) (RefreshToken, error) {
+		getByHashFn: func(ctx context.Context, tokenHash string) (RefreshToken, error) {
 			return RefreshToken{
 				UserID:    "u1",
 				TokenHash: tokenHash,
@@ -372,7 +526,9 @@ func TestResetPassword_RevokesAllSessionsOnSuccess(t *testing.T) {
 			return "new-hash", nil
 		},
 	}
-	uc := NewUsecase(repo, &mockOAuthRepo{}, refreshRepo, resetRepo, &mockVerifyRepo{}, hasher, &mockTokenProvider{}, nil, nil, "", "")
+	revocations := newMockRevocationStore()
+	uc := NewUsecase(repo, &mockOAuthRepo{}, refreshRepo, resetRepo, &mockVerifyRepo{}, hasher, &mockTokenProvider{}, nil, nil, "", "").
+		WithTokenRevocation(revocations)
 	uc.setNowForTest(now)
 
 	err := uc.ResetPassword(context.Background(), "raw-reset-token", "NewPassword@123")
@@ -380,4 +536,323 @@ func TestResetPassword_RevokesAllSessionsOnSuccess(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, revokeAllCalled)
 	assert.True(t, markUsedCalled)
+	assert.Equal(t, map[string]time.Time{"u1": now}, revocations.users, "issued access tokens are revoked too")
+}
+
+type breachedPasswords map[string]bool
+
+func (b breachedPasswords) Contains(ctx context.Context, password string) (bool, error) {
+	return b[password], nil
+}
+
+func TestResetPassword_AppliesPasswordPolicy(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, PasswordHash: "old-hash"}, nil
+		},
+		updatePasswordHashFn: func(ctx context.Context, id, passwordHash string) error {
+			t.Fatal("a rejected password must not be stored")
+			return nil
+		},
+	}
+	resetRepo := &mockResetRepo{
+		getActiveByHashFn: func(ctx context.Context, tokenHash string) (PasswordResetToken, error) {
+			return PasswordResetToken{UserID: "u1", TokenHash: tokenHash, ExpiresAt: now.Add(10 * time.Minute)}, nil
+		},
+	}
+	hasher := &mockHasher{}
+	uc := NewUsecase(repo, &mockOAuthRepo{}, &mockRefreshRepo{}, resetRepo, &mockVerifyRepo{}, hasher, &mockTokenProvider{}, nil, nil, "", "").
+		WithPasswordPolicy(user.NewPasswordPolicy(hasher).WithBreachedPasswords(breachedPasswords{"Password@123": true}))
+	uc.setNowForTest(now)
+
+	err := uc.ResetPassword(context.Background(), "raw-reset-token", "Password@123")
+
+	assert.ErrorIs(t, err, user.ErrPasswordBreached)
+}
+
+type mockSecurityEventRepo struct {
+	events []SecurityEvent
+}
+
+func (m *mockSecurityEventRepo) Save(ctx context.Context, event SecurityEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func refreshingTokenProvider() *mockTokenProvider {
+	return &mockTokenProvider{
+		parseRefreshFn: func(refreshToken string) (user.User, error) {
+			return user.User{ID: "u1", Email: "u1@example.com", Role: user.UserRoleUser}, nil
+		},
+	}
+}
+
+func TestRefresh_RotatesWithinFamily(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	var saved RefreshToken
+
+	var revokedHash string
+
+	refreshRepo := &mockRefreshRepo{
+		getByHashFn: func(ctx context.Context, tokenHash string) (RefreshToken, error) {
+			return RefreshToken{UserID: "u1", FamilyID: "fam-1", TokenHash: tokenHash, ExpiresAt: now.Add(time.Hour)}, nil
+		},
+		revokeByHashFn: func(ctx context.Context, tokenHash string, revokedAt time.Time) error {
+			revokedHash = tokenHash
+			return nil
+		},
+		saveFn: func(ctx context.Context, token RefreshToken) error {
+			saved = token
+			return nil
+		},
+	}
+	uc := newUsecaseForTest(&mockUserRepo{}, refreshRepo, &mockResetRepo{}, &mockHasher{}, refreshingTokenProvider())
+	uc.setNowForTest(now)
+
+	tokens, err := uc.Refresh(context.Background(), "refresh-token")
+
+	require.NoError(t, err)
+	assert.Equal(t, "refresh-token-next", tokens.RefreshToken)
+	assert.Equal(t, hashToken("refresh-token"), revokedHash)
+	assert.Equal(t, "fam-1", saved.FamilyID)
+	assert.Equal(t, hashToken("refresh-token-next"), saved.TokenHash)
+}
+
+func TestRefresh_ReuseRevokesFamilyAndRecordsEvent(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	rotatedAt := now.Add(-time.Minute)
+
+	var revokedFamily string
+
+	refreshRepo := &mockRefreshRepo{
+		getByHashFn: func(ctx context.Context, tokenHash string) (RefreshToken, error) {
+			return RefreshToken{UserID: "u1", FamilyID: "fam-1", TokenHash: tokenHash, ExpiresAt: now.Add(time.Hour), RevokedAt: &rotatedAt}, nil
+		},
+		revokeFamilyFn: func(ctx context.Context, familyID string, revokedAt time.Time) error {
+			revokedFamily = familyID
+			return nil
+		},
+		saveFn: func(ctx context.Context, token RefreshToken) error {
+			t.Fatal("a replayed token must not be rotated")
+			return nil
+		},
+	}
+	events := &mockSecurityEventRepo{}
+	uc := newUsecaseForTest(&mockUserRepo{}, refreshRepo, &mockResetRepo{}, &mockHasher{}, refreshingTokenProvider()).
+		WithSecurityEvents(events)
+	uc.setNowForTest(now)
+
+	ctx := WithClientInfo(context.Background(), ClientInfo{UserAgent: "agent-x", IPAddress: "9.9.9.9"})
+	_, err := uc.Refresh(ctx, "refresh-token")
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	assert.Equal(t, "fam-1", revokedFamily)
+	require.Len(t, events.events, 1)
+	assert.Equal(t, SecurityEvent{
+		UserID:    "u1",
+		Type:      SecurityEventRefreshTokenReuse,
+		FamilyID:  "fam-1",
+		UserAgent: "agent-x",
+		IPAddress: "9.9.9.9",
+		CreatedAt: now,
+	}, events.events[0])
+}
+
+func TestRefresh_ConcurrentRotationRevokesFamily(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	var revokedFamily string
+
+	refreshRepo := &mockRefreshRepo{
+		getByHashFn: func(ctx context.Context, tokenHash string) (RefreshToken, error) {
+			return RefreshToken{UserID: "u1", FamilyID: "fam-1", TokenHash: tokenHash, ExpiresAt: now.Add(time.Hour)}, nil
+		},
+		// Another request rotated the token between the lookup and here.
+		revokeByHashFn: func(ctx context.Context, tokenHash string, revokedAt time.Time) error {
+			return ErrRefreshTokenRevoked
+		},
+		revokeFamilyFn: func(ctx context.Context, familyID string, revokedAt time.Time) error {
+			revokedFamily = familyID
+			return nil
+		},
+		saveFn: func(ctx context.Context, token RefreshToken) error {
+			t.Fatal("the losing request must not be rotated")
+			return nil
+		},
+	}
+	events := &mockSecurityEventRepo{}
+	uc := newUsecaseForTest(&mockUserRepo{}, refreshRepo, &mockResetRepo{}, &mockHasher{}, refreshingTokenProvider()).
+		WithSecurityEvents(events)
+	uc.setNowForTest(now)
+
+	_, err := uc.Refresh(context.Background(), "refresh-token")
+
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	assert.Equal(t, "fam-1", revokedFamily)
+	require.Len(t, events.events, 1)
+	assert.Equal(t, SecurityEventRefreshTokenReuse, events.events[0].Type)
+}
+
+func TestLogin_StartsNewFamily(t *testing.T) {
+	verifiedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	repo := &mockUserRepo{
+		getByEmailFn: func(ctx context.Context, email string) (user.User, error) {
+			return user.User{ID: "u1", Email: email, PasswordHash: "hash", Status: user.UserStatusActive, EmailVerifiedAt: &verifiedAt}, nil
+		},
+	}
+
+	var saved []RefreshToken
+
+	refreshRepo := &mockRefreshRepo{
+		saveFn: func(ctx context.Context, token RefreshToken) error {
+			saved = append(saved, token)
+			return nil
+		},
+	}
+	uc := newUsecaseForTest(repo, refreshRepo, &mockResetRepo{}, &mockHasher{compareFn: func(string, string) error { return nil }}, &mockTokenProvider{})
+
+	_, err := uc.Login(context.Background(), "john@example.com", "Password@123")
+	require.NoError(t, err)
+
+	_, err = uc.Login(context.Background(), "john@example.com", "Password@123")
+	require.NoError(t, err)
+
+	require.Len(t, saved, 2)
+	assert.NotEmpty(t, saved[0].FamilyID)
+	assert.NotEqual(t, saved[0].FamilyID, saved[1].FamilyID)
+}
+
+func TestLogout_RevokesFamily(t *testing.T) {
+	var revokedFamily string
+
+	refreshRepo := &mockRefreshRepo{
+		getByHashFn: func(ctx context.Context, tokenHash string) (RefreshToken, error) {
+			return RefreshToken{UserID: "u1", FamilyID: "fam-1", TokenHash: tokenHash}, nil
+		},
+		revokeFamilyFn: func(ctx context.Context, familyID string, revokedAt time.Time) error {
+			revokedFamily = familyID
+			return nil
+		},
+	}
+	uc := newUsecaseForTest(&mockUserRepo{}, refreshRepo, &mockResetRepo{}, &mockHasher{}, refreshingTokenProvider())
+
+	require.NoError(t, uc.Logout(context.Background(), "refresh-token", AccessTokenClaims{}))
+	assert.Equal(t, "fam-1", revokedFamily)
+}
+
+func TestOAuthProviders_AreSorted(t *testing.T) {
+	uc := NewUsecase(&mockUserRepo{}, &mockOAuthRepo{}, &mockRefreshRepo{}, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, OAuthProviders{
+		"okta":   &mockOAuthLoginProvider{},
+		"google": &mockOAuthLoginProvider{},
+	}, nil, "", "")
+
+	assert.Equal(t, []OAuthProvider{"google", "okta"}, uc.OAuthProviders())
+}
+
+func TestOAuthAuthURL_ReturnsFreshFlow(t *testing.T) {
+	uc := NewUsecase(&mockUserRepo{}, &mockOAuthRepo{}, &mockRefreshRepo{}, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, OAuthProviders{
+		"corp": &mockOAuthLoginProvider{},
+	}, nil, "", "")
+
+	authURL, flow, err := uc.OAuthAuthURL(context.Background(), "corp")
+	require.NoError(t, err)
+	assert.Contains(t, authURL, flow.State)
+	assert.NotEmpty(t, flow.Nonce)
+	assert.GreaterOrEqual(t, len(flow.CodeVerifier), 43)
+
+	_, next, err := uc.OAuthAuthURL(context.Background(), "corp")
+	require.NoError(t, err)
+	assert.NotEqual(t, flow, next)
+}
+
+func TestLoginWithOAuth_UnknownProvider(t *testing.T) {
+	uc := newUsecaseForTest(&mockUserRepo{}, &mockRefreshRepo{}, &mockResetRepo{}, &mockHasher{}, &mockTokenProvider{})
+
+	_, err := uc.LoginWithOAuth(context.Background(), "corp", "code", OAuthFlow{State: "state"})
+	assert.ErrorIs(t, err, ErrOAuthNotConfigured)
+
+	uc = NewUsecase(&mockUserRepo{}, &mockOAuthRepo{}, &mockRefreshRepo{}, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, OAuthProviders{
+		"google": &mockOAuthLoginProvider{},
+	}, nil, "", "")
+
+	_, err = uc.LoginWithOAuth(context.Background(), "corp", "code", OAuthFlow{State: "state"})
+	assert.ErrorIs(t, err, ErrUnknownOAuthProvider)
+}
+
+func TestLoginWithOAuth_LinksExistingUserUnderProviderName(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	repo := &mockUserRepo{
+		getByEmailFn: func(ctx context.Context, email string) (user.User, error) {
+			return user.User{ID: "u1", Email: email, Status: user.UserStatusActive, EmailVerifiedAt: &now}, nil
+		},
+	}
+	oauthRepo := &mockOAuthRepo{}
+	provider := &mockOAuthLoginProvider{user: OAuthUser{
+		ProviderUserID: "subject-1",
+		Email:          "john@example.com",
+		EmailVerified:  true,
+	}}
+	uc := NewUsecase(repo, oauthRepo, &mockRefreshRepo{}, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, OAuthProviders{
+		"corp": provider,
+	}, nil, "", "").
+		WithTrustedEmailProviders("corp")
+	flow := OAuthFlow{State: "state", Nonce: "nonce", CodeVerifier: "verifier"}
+
+	result, err := uc.LoginWithOAuth(context.Background(), "corp", "code", flow)
+
+	require.NoError(t, err)
+	assert.Equal(t, "access-token", result.Tokens.AccessToken)
+	assert.Equal(t, flow, provider.flow, "the flow is handed to the provider")
+	assert.Equal(t, []OAuthProvider{"corp"}, oauthRepo.linked)
+}
+
+func TestLoginWithOAuth_RejectsInactiveAccount(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	repo := &mockUserRepo{
+		getByIDFn: func(ctx context.Context, id string) (user.User, error) {
+			return user.User{ID: id, Email: "john@example.com", Status: user.UserStatusInactive, EmailVerifiedAt: &now}, nil
+		},
+	}
+	oauthRepo := &mockOAuthRepo{accounts: []OAuthAccount{{UserID: "u1", Provider: "corp", ProviderUserID: "subject-1"}}}
+	provider := &mockOAuthLoginProvider{user: OAuthUser{ProviderUserID: "subject-1", Email: "john@example.com", EmailVerified: true}}
+	uc := NewUsecase(repo, oauthRepo, &mockRefreshRepo{}, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, OAuthProviders{
+		"corp": provider,
+	}, nil, "", "")
+
+	_, err := uc.LoginWithOAuth(context.Background(), "corp", "code", OAuthFlow{State: "state"})
+
+	assert.ErrorIs(t, err, ErrAccountInactive)
+}
+
+func TestLoginWithOAuth_UntrustedProviderDoesNotLinkByEmail(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	repo := &mockUserRepo{
+		getByEmailFn: func(ctx context.Context, email string) (user.User, error) {
+			return user.User{ID: "u1", Email: email, Status: user.UserStatusActive, EmailVerifiedAt: &now}, nil
+		},
+	}
+	oauthRepo := &mockOAuthRepo{}
+	provider := &mockOAuthLoginProvider{user: OAuthUser{
+		ProviderUserID: "subject-1",
+		Email:          "john@example.com",
+		EmailVerified:  true,
+	}}
+	uc := NewUsecase(repo, oauthRepo, &mockRefreshRepo{}, &mockResetRepo{}, &mockVerifyRepo{}, &mockHasher{}, &mockTokenProvider{}, OAuthProviders{
+		"google": provider,
+		"corp":   provider,
+	}, nil, "", "")
+
+	_, err := uc.LoginWithOAuth(context.Background(), "corp", "code", OAuthFlow{State: "state"})
+
+	require.ErrorIs(t, err, ErrOAuthLinkRequired)
+	assert.Empty(t, oauthRepo.linked)
+
+	_, err = uc.LoginWithOAuth(context.Background(), "google", "code", OAuthFlow{State: "state"})
+
+	require.NoError(t, err, "google is trusted by default")
+	assert.Equal(t, []OAuthProvider{"google"}, oauthRepo.linked)
 }
@@ -15,8 +15,10 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 	"time"
 
+	"hexagon/audit"
 	"hexagon/auth"
 	"hexagon/hotel"
 	"hexagon/httpserver"
@@ -24,9 +26,15 @@ import (
 	"hexagon/pkg/hashing"
 	"hexagon/pkg/jwt"
 	resendmailer "hexagon/pkg/mailer/resend"
-	oauthgoogle "hexagon/pkg/oauth/google"
+	"hexagon/pkg/oauth/oidc"
+	"hexagon/pkg/pwned"
+	"hexagon/pkg/scanner/clamav"
 	"hexagon/pkg/sentry"
+	"hexagon/pkg/sms/console"
+	"hexagon/pkg/sms/httpsms"
+	localstorage "hexagon/pkg/storage/local"
 	s3storage "hexagon/pkg/storage/s3"
+	"hexagon/pkg/totp"
 	"hexagon/postgres"
 	"hexagon/room"
 	"hexagon/search"
@@ -39,6 +47,10 @@ import (
 	_ "github.com/lib/pq"
 )
 
+// tokenRevocationCacheTTL bounds how long a revocation made by another
+// instance takes to apply here.
+const tokenRevocationCacheTTL = 30 * time.Second
+
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
@@ -79,24 +91,23 @@ func main() {
 	roomRepo := postgres.NewRoomRepository(db)
 	searchRepo := postgres.NewSearchRepository(db)
 	refreshTokenRepo := postgres.NewRefreshTokenRepository(db)
+	tokenRevocations := auth.NewTokenRevocationCache(postgres.NewTokenRevocationRepository(db), tokenRevocationCacheTTL)
+	passwordPolicy := createPasswordPolicy(cfg, postgres.NewPasswordHistoryRepository(db))
+	auditLog := audit.NewUsecase(postgres.NewAuditEventRepository(db))
 	userService := user.NewUsecaseWithSession(
 		userRepo,
 		hashing.NewBcryptHasher(),
 		refreshTokenRepo,
-	)
-	hotelService := hotel.NewUsecase(hotelRepo)
-	roomService := room.NewUsecase(roomRepo)
+	).
+		WithTokenRevoker(tokenRevocations).
+		WithPasswordPolicy(passwordPolicy).
+		WithAuditLog(auditLog)
+	uploadService := createUploadService(cfg, createImageUploader(cfg), postgres.NewUploadRepository(db))
+	hotelService := hotel.NewUsecaseWithUploads(hotelRepo, uploadService)
+	roomService := room.NewUsecaseWithUploads(roomRepo, uploadService)
 	searchService := search.NewUsecase(searchRepo)
 
-	googleProvider, err := oauthgoogle.NewProvider(
-		cfg.Auth.GoogleClientID,
-		cfg.Auth.GoogleClientSecret,
-		cfg.Auth.GoogleRedirectURL,
-	)
-	if err != nil {
-		slog.Error("Cannot init google oauth provider", "error", err)
-		os.Exit(1)
-	}
+	tokenProvider := createJWTProvider(cfg)
 
 	authService := auth.NewUsecase(
 		userRepo,
@@ -105,26 +116,54 @@ func main() {
 		postgres.NewPasswordResetTokenRepository(db),
 		postgres.NewEmailVerificationTokenRepository(db),
 		hashing.NewBcryptHasher(),
-		jwt.NewJWTProvider(
-			cfg.Auth.JWTSecret,
-			time.Duration(cfg.Auth.TokenTTL)*time.Second,
-			time.Duration(cfg.Auth.RefreshTTL)*time.Second,
-		),
-		googleProvider,
+		tokenProvider,
+		createOAuthProviders(cfg),
 		createMailer(cfg),
 		cfg.Auth.ResetPasswordURL,
 		cfg.Auth.VerifyEmailURL,
-	)
+	).
+		WithTrustedEmailProviders(trustedEmailProviders(cfg)...).
+		WithSecurityEvents(postgres.NewSecurityEventRepository(db)).
+		WithTokenRevocation(tokenRevocations).
+		WithMagicLink(postgres.NewMagicLinkTokenRepository(db), cfg.Auth.MagicLinkURL).
+		WithNewSignInAlerts(postgres.NewSignInAlertRepository(db), cfg.Auth.SignInDenyURL).
+		WithTwoFactor(postgres.NewTwoFactorRepository(db), totp.NewGenerator("Hexagon")).
+		WithPasswordPolicy(passwordPolicy).
+		WithAuditLog(auditLog).
+		WithAccountDeletion(
+			postgres.NewAccountDeletionRepository(db),
+			secondsOrDefault(cfg.Auth.AccountDeletionGracePeriod, auth.DefaultAccountDeletionGracePeriod),
+		).
+		WithDataExport(postgres.NewDataExportRepository(db))
+	if smsSender := createSMSSender(cfg); smsSender != nil {
+		authService.WithPhoneVerification(postgres.NewPhoneVerificationRepository(db), smsSender)
+	}
 	server := httpserver.Default(cfg)
 	server.JWTSecret = cfg.Auth.JWTSecret
+	server.TokenKeys = tokenProvider
+	server.TokenRevocations = authService
 	server.UserService = userService
 	server.AuthService = authService
 	server.HotelService = hotelService
 	server.RoomService = roomService
 	server.SearchService = searchService
-	server.UploadService = createUploadService(cfg)
+	server.UploadService = uploadService
+	server.AuditService = auditLog
 	server.Addr = fmt.Sprintf(":%d", cfg.Port)
 
+	go runUploadSweeper(
+		context.Background(),
+		uploadService,
+		secondsOrDefault(cfg.Storage.UploadSweepInterval, time.Hour),
+		secondsOrDefault(cfg.Storage.UploadOrphanMaxAge, upload.DefaultOrphanMaxAge),
+	)
+
+	go runAccountDeletions(
+		context.Background(),
+		authService,
+		secondsOrDefault(cfg.Auth.AccountDeletionSweepInterval, time.Hour),
+	)
+
 	slog.Info("server started!")
 
 	if err := server.Start(); err != nil {
@@ -133,11 +172,169 @@ func main() {
 	}
 }
 
-func createUploadService(cfg *config.Config) upload.Service {
-	return upload.NewUsecase(createImageUploader(cfg))
+func createJWTProvider(cfg *config.Config) *jwt.JWTProvider {
+	accessTTL := time.Duration(cfg.Auth.TokenTTL) * time.Second
+	refreshTTL := time.Duration(cfg.Auth.RefreshTTL) * time.Second
+
+	if cfg.Auth.JWTPrivateKeyFile == "" {
+		return jwt.NewJWTProvider(cfg.Auth.JWTSecret, accessTTL, refreshTTL)
+	}
+
+	signingKey, err := jwt.LoadSigningKey(cfg.Auth.JWTPrivateKeyFile)
+	if err != nil {
+		slog.Error("cannot load AUTH_JWT_PRIVATE_KEY_FILE", "error", err)
+		os.Exit(1)
+	}
+
+	verificationKeys := make([]*jwt.Key, 0, len(cfg.Auth.JWTPublicKeyFiles))
+
+	for _, path := range cfg.Auth.JWTPublicKeyFiles {
+		key, err := jwt.LoadVerificationKey(strings.TrimSpace(path))
+		if err != nil {
+			slog.Error("cannot load AUTH_JWT_PUBLIC_KEY_FILES", "error", err)
+			os.Exit(1)
+		}
+
+		verificationKeys = append(verificationKeys, key)
+	}
+
+	provider := jwt.NewJWTProviderWithKeys(signingKey, verificationKeys, accessTTL, refreshTTL)
+	// Tokens signed with the secret before the switch are only accepted
+	// while AUTH_JWT_ACCEPT_LEGACY_SECRET is set.
+	if cfg.Auth.JWTAcceptLegacySecret {
+		provider.Secret = cfg.Auth.JWTSecret
+	}
+
+	slog.Info("signing tokens with asymmetric key", "kid", signingKey.ID, "alg", signingKey.Algorithm)
+
+	return provider
+}
+
+func createUploadService(cfg *config.Config, uploader upload.Uploader, repo upload.Repository) *upload.Usecase {
+	renditions, err := upload.ParseRenditions(cfg.Storage.ImageRenditions)
+	if err != nil {
+		slog.Error("Cannot parse IMAGE_RENDITIONS", "error", err)
+		os.Exit(1)
+	}
+
+	return upload.NewUsecaseWithScanner(uploader, repo, createScanner(cfg), renditions)
+}
+
+func createScanner(cfg *config.Config) upload.Scanner {
+	if cfg.Storage.ClamAVAddr == "" {
+		slog.Warn("malware scanning is disabled because CLAMAV_ADDR is empty")
+		return nil
+	}
+
+	scanner, err := clamav.NewScanner(clamav.Config{
+		Addr:    cfg.Storage.ClamAVAddr,
+		Timeout: secondsOrDefault(cfg.Storage.ClamAVTimeout, 0),
+	})
+	if err != nil {
+		slog.Error("cannot initialize clamav scanner", "error", err)
+		os.Exit(1)
+	}
+
+	return scanner
+}
+
+func createPasswordPolicy(cfg *config.Config, history user.PasswordHistoryRepository) *user.PasswordPolicy {
+	policy := user.NewPasswordPolicy(hashing.NewBcryptHasher()).WithHistory(history, cfg.Auth.PasswordHistory)
+
+	if cfg.Auth.BreachedPasswordsFile == "" {
+		slog.Warn("breached password check is disabled because AUTH_BREACHED_PASSWORDS_FILE is empty")
+		return policy
+	}
+
+	breached, err := pwned.OpenFile(cfg.Auth.BreachedPasswordsFile)
+	if err != nil {
+		slog.Error("cannot open breached password list", "error", err)
+		os.Exit(1)
+	}
+
+	return policy.WithBreachedPasswords(breached)
+}
+
+func createSMSSender(cfg *config.Config) auth.SMSSender {
+	switch cfg.SMS.Provider {
+	case "":
+		slog.Warn("phone verification is disabled because SMS_PROVIDER is empty")
+		return nil
+	case "console":
+		return console.NewSender(slog.Default())
+	case "http":
+		sender, err := httpsms.NewSender(httpsms.Config{
+			URL:     cfg.SMS.HTTPURL,
+			APIKey:  cfg.SMS.HTTPAPIKey,
+			From:    cfg.SMS.HTTPFrom,
+			Timeout: secondsOrDefault(cfg.SMS.HTTPTimeout, 0),
+		})
+		if err != nil {
+			slog.Error("cannot initialize sms sender", "error", err)
+			os.Exit(1)
+		}
+
+		return sender
+	default:
+		slog.Error("unknown SMS_PROVIDER", "provider", cfg.SMS.Provider)
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+// runUploadSweeper deletes unattached uploads every interval until ctx ends.
+func runUploadSweeper(ctx context.Context, uploads *upload.Usecase, interval, maxAge time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := uploads.SweepOrphans(ctx, maxAge)
+			if err != nil {
+				slog.Error("upload sweep failed", "error", err, "removed", removed)
+				continue
+			}
+
+			if removed > 0 {
+				slog.Info("swept orphaned uploads", "removed", removed)
+			}
+		}
+	}
+}
+
+// runAccountDeletions anonymises accounts whose deletion grace period is
+// over every interval until ctx ends.
+func runAccountDeletions(ctx context.Context, accounts *auth.Usecase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := accounts.DeleteDueAccounts(ctx)
+			if err != nil {
+				slog.Error("account deletion failed", "error", err, "deleted", deleted)
+				continue
+			}
+
+			if deleted > 0 {
+				slog.Info("deleted accounts", "deleted", deleted)
+			}
+		}
+	}
 }
 
 func createImageUploader(cfg *config.Config) upload.Uploader {
+	if cfg != nil && cfg.UsesLocalStorage() {
+		return createLocalUploader(cfg)
+	}
+
 	if cfg == nil || cfg.Storage.S3Bucket == "" {
 		slog.Warn("s3 uploader is disabled because S3_BUCKET is empty")
 		return nil
@@ -161,6 +358,68 @@ func createImageUploader(cfg *config.Config) upload.Uploader {
 	return uploader
 }
 
+func createLocalUploader(cfg *config.Config) upload.Uploader {
+	baseURL := cfg.Storage.LocalBaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://localhost:%d%s", cfg.Port, httpserver.LocalStorageRoute)
+	}
+
+	uploader, err := localstorage.NewUploader(localstorage.Config{
+		Dir:     cfg.Storage.LocalDir,
+		BaseURL: baseURL,
+	})
+	if err != nil {
+		slog.Error("cannot initialize local uploader", "error", err)
+		return nil
+	}
+
+	slog.Info("storing uploads on local disk", "dir", cfg.Storage.LocalDir, "base_url", baseURL)
+
+	return uploader
+}
+
+func secondsOrDefault(seconds int, fallback time.Duration) time.Duration {
+	if seconds <= 0 {
+		return fallback
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+func createOAuthProviders(cfg *config.Config) auth.OAuthProviders {
+	providers := make(auth.OAuthProviders, len(cfg.Auth.OIDCProviders))
+
+	for _, p := range cfg.Auth.OIDCProviders {
+		provider, err := oidc.NewProvider(oidc.Config{
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		})
+		if err != nil {
+			slog.Error("Cannot init oauth provider", "provider", p.Name, "error", err)
+			os.Exit(1)
+		}
+
+		providers[auth.OAuthProvider(p.Name)] = provider
+	}
+
+	return providers
+}
+
+func trustedEmailProviders(cfg *config.Config) []auth.OAuthProvider {
+	var names []auth.OAuthProvider
+
+	for _, p := range cfg.Auth.OIDCProviders {
+		if p.TrustEmail {
+			names = append(names, auth.OAuthProvider(p.Name))
+		}
+	}
+
+	return names
+}
+
 func createMailer(cfg *config.Config) auth.Mailer {
 	if cfg == nil {
 		return nil
@@ -42,10 +42,12 @@ type Hotel struct {
 }
 
 type HotelImage struct {
-	ID      string
-	HotelID string
-	URL     string
-	IsCover bool
+	ID        string
+	HotelID   string
+	URL       string
+	AltText   string
+	IsCover   bool
+	SortOrder int
 }
 
 type HotelPaymentOption struct {
@@ -0,0 +1,82 @@
+package hotel
+
+import (
+	"strings"
+
+	"hexagon/errs"
+)
+
+var (
+	ErrImageIDRequired     = errs.Errorf(errs.EINVALID, "hotel: image id is required")
+	ErrImageURLRequired    = errs.Errorf(errs.EINVALID, "hotel: image url is required")
+	ErrImageNotFound       = errs.Errorf(errs.ENOTFOUND, "hotel: image not found")
+	ErrMultipleCoverImages = errs.Errorf(errs.EINVALID, "hotel: only one image can be the cover")
+	ErrImageOrderInvalid   = errs.Errorf(errs.EINVALID, "hotel: image order must list every hotel image exactly once")
+	ErrImageAltTextTooLong = errs.Errorf(errs.EINVALID, "hotel: image alt text must be at most 500 characters")
+	ErrLastImage           = errs.Errorf(errs.EINVALID, "hotel: the last image of a hotel cannot be removed")
+	ErrImageInUse          = errs.Errorf(errs.ECONFLICT, "hotel: image is already used by another image")
+)
+
+const maxImageAltTextLength = 500
+
+func (img HotelImage) Validate() error {
+	if strings.TrimSpace(img.URL) == "" {
+		return ErrImageURLRequired
+	}
+
+	if len([]rune(img.AltText)) > maxImageAltTextLength {
+		return ErrImageAltTextTooLong
+	}
+
+	return nil
+}
+
+// normalizeImages numbers images in the given order and makes the first one
+// the cover when none is marked. More than one cover is rejected.
+func normalizeImages(images []HotelImage) ([]HotelImage, error) {
+	coverIdx := -1
+
+	for i := range images {
+		if err := images[i].Validate(); err != nil {
+			return nil, err
+		}
+
+		if images[i].IsCover {
+			if coverIdx >= 0 {
+				return nil, ErrMultipleCoverImages
+			}
+
+			coverIdx = i
+		}
+
+		images[i].SortOrder = i
+	}
+
+	if coverIdx < 0 && len(images) > 0 {
+		images[0].IsCover = true
+	}
+
+	return images, nil
+}
+
+// ValidateImageOrder checks that ids is a permutation of the current images.
+func ValidateImageOrder(current []HotelImage, ids []string) error {
+	if len(ids) != len(current) {
+		return ErrImageOrderInvalid
+	}
+
+	remaining := make(map[string]struct{}, len(current))
+	for i := range current {
+		remaining[current[i].ID] = struct{}{}
+	}
+
+	for i := range ids {
+		if _, ok := remaining[ids[i]]; !ok {
+			return ErrImageOrderInvalid
+		}
+
+		delete(remaining, ids[i])
+	}
+
+	return nil
+}
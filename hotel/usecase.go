@@ -1,27 +1,55 @@
 package hotel
 
-import "context"
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"hexagon/upload"
+)
 
 type Service interface {
 	ListHotels(ctx context.Context) ([]Hotel, error)
 	GetHotelByID(ctx context.Context, id string) (Hotel, error)
 	AddHotel(ctx context.Context, h Hotel) (Hotel, error)
+	AddImage(ctx context.Context, img HotelImage) (HotelImage, error)
+	RemoveImage(ctx context.Context, hotelID, imageID string) error
+	ReorderImages(ctx context.Context, hotelID string, imageIDs []string) ([]HotelImage, error)
+	SetCoverImage(ctx context.Context, hotelID, imageID string) ([]HotelImage, error)
 }
 
 type Repository interface {
 	List(ctx context.Context) ([]Hotel, error)
 	GetByID(ctx context.Context, id string) (Hotel, error)
-	Create(ctx context.Context, h Hotel) (Hotel, error)
+	// CreateTx saves h and runs fn in the same transaction. If fn returns an
+	// error nothing is saved.
+	CreateTx(ctx context.Context, h Hotel, fn func(ctx context.Context) error) (Hotel, error)
+	ListImages(ctx context.Context, hotelID string) ([]HotelImage, error)
+	// AddImageTx saves img and runs fn in the same transaction, like CreateTx.
+	AddImageTx(ctx context.Context, img HotelImage, fn func(ctx context.Context) error) (HotelImage, error)
+	// DeleteImage removes the image and moves the cover to the next image.
+	// It returns ErrLastImage instead of removing the only image of the
+	// hotel.
+	DeleteImage(ctx context.Context, hotelID, imageID string) (HotelImage, error)
+	ReorderImages(ctx context.Context, hotelID string, imageIDs []string) ([]HotelImage, error)
+	SetCoverImage(ctx context.Context, hotelID, imageID string) ([]HotelImage, error)
 }
 
 type Usecase struct {
-	repo Repository
+	repo    Repository
+	uploads upload.Tracker
 }
 
 func NewUsecase(repo Repository) *Usecase {
 	return &Usecase{repo: repo}
 }
 
+// NewUsecaseWithUploads claims uploaded images when they are added and
+// deletes their stored objects when they are removed.
+func NewUsecaseWithUploads(repo Repository, uploads upload.Tracker) *Usecase {
+	return &Usecase{repo: repo, uploads: uploads}
+}
+
 func (uc *Usecase) ListHotels(ctx context.Context) ([]Hotel, error) {
 	return uc.repo.List(ctx)
 }
@@ -44,5 +72,108 @@ func (uc *Usecase) AddHotel(ctx context.Context, h Hotel) (Hotel, error) {
 		return Hotel{}, err
 	}
 
-	return uc.repo.Create(ctx, h)
+	images, err := normalizeImages(h.Images)
+	if err != nil {
+		return Hotel{}, err
+	}
+
+	h.Images = images
+
+	return uc.repo.CreateTx(ctx, h, func(ctx context.Context) error {
+		return uc.attachImages(ctx, h.Images...)
+	})
+}
+
+// AddImage appends an image to the hotel gallery. The first image of a hotel
+// always becomes the cover; otherwise IsCover moves the cover to it.
+func (uc *Usecase) AddImage(ctx context.Context, img HotelImage) (HotelImage, error) {
+	if err := ValidateID(img.HotelID); err != nil {
+		return HotelImage{}, err
+	}
+
+	if err := img.Validate(); err != nil {
+		return HotelImage{}, err
+	}
+
+	return uc.repo.AddImageTx(ctx, img, func(ctx context.Context) error {
+		return uc.attachImages(ctx, img)
+	})
+}
+
+// RemoveImage deletes the image row, promotes the next image to cover if
+// needed, then removes the stored object. A hotel keeps at least one image.
+// An error releasing the object is returned, but does not bring the row
+// back.
+func (uc *Usecase) RemoveImage(ctx context.Context, hotelID, imageID string) error {
+	if err := ValidateID(hotelID); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(imageID) == "" {
+		return ErrImageIDRequired
+	}
+
+	removed, err := uc.repo.DeleteImage(ctx, hotelID, imageID)
+	if err != nil {
+		return err
+	}
+
+	if uc.uploads != nil {
+		return uc.uploads.Release(ctx, removed.URL)
+	}
+
+	return nil
+}
+
+func (uc *Usecase) ReorderImages(ctx context.Context, hotelID string, imageIDs []string) ([]HotelImage, error) {
+	if err := ValidateID(hotelID); err != nil {
+		return nil, err
+	}
+
+	current, err := uc.repo.ListImages(ctx, hotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateImageOrder(current, imageIDs); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.ReorderImages(ctx, hotelID, imageIDs)
+}
+
+func (uc *Usecase) SetCoverImage(ctx context.Context, hotelID, imageID string) ([]HotelImage, error) {
+	if err := ValidateID(hotelID); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(imageID) == "" {
+		return nil, ErrImageIDRequired
+	}
+
+	return uc.repo.SetCoverImage(ctx, hotelID, imageID)
+}
+
+// attachImages marks the uploads behind images as used. It runs in the
+// transaction saving the images, so uploads are only claimed by images that
+// exist and the orphan sweeper can never delete an image that is referenced.
+func (uc *Usecase) attachImages(ctx context.Context, images ...HotelImage) error {
+	if uc.uploads == nil || len(images) == 0 {
+		return nil
+	}
+
+	urls := make([]string, len(images))
+	for i := range images {
+		urls[i] = images[i].URL
+	}
+
+	if err := uc.uploads.Attach(ctx, urls); err != nil {
+		if errors.Is(err, upload.ErrUploadInUse) {
+			return ErrImageInUse
+		}
+
+		return err
+	}
+
+	return nil
 }
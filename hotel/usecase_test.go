@@ -2,20 +2,25 @@ package hotel
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"hexagon/errs"
+	"hexagon/upload"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 type hotelRepoStub struct {
-	list  func(ctx context.Context) ([]Hotel, error)
-	get   func(ctx context.Context, id string) (Hotel, error)
-	create func(ctx context.Context, h Hotel) (Hotel, error)
-	getCalled   bool
-	createCalled bool
+	list          func(ctx context.Context) ([]Hotel, error)
+	get           func(ctx context.Context, id string) (Hotel, error)
+	create        func(ctx context.Context, h Hotel) (Hotel, error)
+	getCalled     bool
+	createCalled  bool
+	listImages    func(ctx context.Context, hotelID string) ([]HotelImage, error)
+	deleteImage   func(ctx context.Context, hotelID, imageID string) (HotelImage, error)
+	reorderCalled bool
 }
 
 func (r *hotelRepoStub) List(ctx context.Context) ([]Hotel, error) {
@@ -27,11 +32,58 @@ func (r *hotelRepoStub) GetByID(ctx context.Context, id string) (Hotel, error) {
 	return r.get(ctx, id)
 }
 
-func (r *hotelRepoStub) Create(ctx context.Context, h Hotel) (Hotel, error) {
+func (r *hotelRepoStub) CreateTx(ctx context.Context, h Hotel, fn func(ctx context.Context) error) (Hotel, error) {
+	if err := fn(ctx); err != nil {
+		return Hotel{}, err
+	}
+
 	r.createCalled = true
+
 	return r.create(ctx, h)
 }
 
+func (r *hotelRepoStub) ListImages(ctx context.Context, hotelID string) ([]HotelImage, error) {
+	return r.listImages(ctx, hotelID)
+}
+
+func (r *hotelRepoStub) AddImageTx(ctx context.Context, img HotelImage, fn func(ctx context.Context) error) (HotelImage, error) {
+	if err := fn(ctx); err != nil {
+		return HotelImage{}, err
+	}
+
+	return img, nil
+}
+
+func (r *hotelRepoStub) DeleteImage(ctx context.Context, hotelID, imageID string) (HotelImage, error) {
+	return r.deleteImage(ctx, hotelID, imageID)
+}
+
+func (r *hotelRepoStub) ReorderImages(ctx context.Context, hotelID string, imageIDs []string) ([]HotelImage, error) {
+	r.reorderCalled = true
+	return nil, nil
+}
+
+func (r *hotelRepoStub) SetCoverImage(ctx context.Context, hotelID, imageID string) ([]HotelImage, error) {
+	return nil, nil
+}
+
+type trackerStub struct {
+	attached   []string
+	released   []string
+	attachErr  error
+	releaseErr error
+}
+
+func (u *trackerStub) Attach(ctx context.Context, urls []string) error {
+	u.attached = append(u.attached, urls...)
+	return u.attachErr
+}
+
+func (u *trackerStub) Release(ctx context.Context, url string) error {
+	u.released = append(u.released, url)
+	return u.releaseErr
+}
+
 func TestUsecase_GetHotelByID_Validation(t *testing.T) {
 	repo := &hotelRepoStub{
 		get: func(ctx context.Context, id string) (Hotel, error) {
@@ -56,9 +108,9 @@ func TestUsecase_AddHotel_DefaultsAndCreate(t *testing.T) {
 	uc := NewUsecase(repo)
 
 	input := Hotel{
-		Name:    "Hotel",
-		Address: "Address",
-		City:    "City",
+		Name:           "Hotel",
+		Address:        "Address",
+		City:           "City",
 		PaymentOptions: []HotelPaymentOption{{PaymentOption: PaymentOptionImmediate}},
 	}
 
@@ -69,3 +121,113 @@ func TestUsecase_AddHotel_DefaultsAndCreate(t *testing.T) {
 	assert.Equal(t, 11, captured.DefaultChildMaxAge)
 	assert.Equal(t, input.Name, created.Name)
 }
+
+func TestUsecase_AddHotel_NormalizesImages(t *testing.T) {
+	captured := Hotel{}
+	repo := &hotelRepoStub{
+		create: func(ctx context.Context, h Hotel) (Hotel, error) {
+			captured = h
+			return h, nil
+		},
+	}
+	uc := NewUsecase(repo)
+
+	input := Hotel{
+		Name:    "Hotel",
+		Address: "Address",
+		City:    "City",
+		Images:  []HotelImage{{URL: "https://a"}, {URL: "https://b"}},
+	}
+
+	_, err := uc.AddHotel(context.Background(), input)
+	require.NoError(t, err)
+	require.Len(t, captured.Images, 2)
+	assert.True(t, captured.Images[0].IsCover)
+	assert.False(t, captured.Images[1].IsCover)
+	assert.Equal(t, 1, captured.Images[1].SortOrder)
+
+	input.Images = []HotelImage{{URL: "https://a", IsCover: true}, {URL: "https://b", IsCover: true}}
+	_, err = uc.AddHotel(context.Background(), input)
+	assert.Equal(t, ErrMultipleCoverImages, err)
+}
+
+func TestUsecase_RemoveImage_DeletesStoredObject(t *testing.T) {
+	repo := &hotelRepoStub{
+		deleteImage: func(ctx context.Context, hotelID, imageID string) (HotelImage, error) {
+			return HotelImage{ID: imageID, HotelID: hotelID, URL: "https://cdn/a.jpg"}, nil
+		},
+	}
+	uploads := &trackerStub{}
+	uc := NewUsecaseWithUploads(repo, uploads)
+
+	require.NoError(t, uc.RemoveImage(context.Background(), "h-1", "img-1"))
+	assert.Equal(t, []string{"https://cdn/a.jpg"}, uploads.released)
+
+	uploads.releaseErr = errors.New("db down")
+	assert.Error(t, uc.RemoveImage(context.Background(), "h-1", "img-1"))
+}
+
+func TestUsecase_RemoveImage_KeepsLastImage(t *testing.T) {
+	repo := &hotelRepoStub{
+		deleteImage: func(ctx context.Context, hotelID, imageID string) (HotelImage, error) {
+			return HotelImage{}, ErrLastImage
+		},
+	}
+	uploads := &trackerStub{}
+	uc := NewUsecaseWithUploads(repo, uploads)
+
+	err := uc.RemoveImage(context.Background(), "h-1", "img-1")
+	assert.Equal(t, ErrLastImage, err)
+	assert.Empty(t, uploads.released)
+}
+
+func TestUsecase_AddImage_SharedUpload(t *testing.T) {
+	uploads := &trackerStub{attachErr: upload.ErrUploadInUse}
+	uc := NewUsecaseWithUploads(&hotelRepoStub{}, uploads)
+
+	_, err := uc.AddImage(context.Background(), HotelImage{HotelID: "h-1", URL: "https://cdn/a.jpg"})
+	assert.Equal(t, ErrImageInUse, err)
+}
+
+func TestUsecase_AddHotel_AttachesUploads(t *testing.T) {
+	repo := &hotelRepoStub{
+		create: func(ctx context.Context, h Hotel) (Hotel, error) {
+			return h, nil
+		},
+	}
+	uploads := &trackerStub{}
+	uc := NewUsecaseWithUploads(repo, uploads)
+
+	input := Hotel{Name: "Hotel", Address: "Address", City: "City", Images: []HotelImage{{URL: "https://cdn/a.jpg"}}}
+
+	_, err := uc.AddHotel(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://cdn/a.jpg"}, uploads.attached)
+
+	uploads.attachErr = errors.New("db down")
+	repo.createCalled = false
+
+	_, err = uc.AddHotel(context.Background(), input)
+	require.Error(t, err)
+	assert.False(t, repo.createCalled, "hotel is not saved with unclaimed images")
+}
+
+func TestUsecase_ReorderImages_RejectsPartialOrder(t *testing.T) {
+	repo := &hotelRepoStub{
+		listImages: func(ctx context.Context, hotelID string) ([]HotelImage, error) {
+			return []HotelImage{{ID: "a"}, {ID: "b"}}, nil
+		},
+	}
+	uc := NewUsecase(repo)
+
+	_, err := uc.ReorderImages(context.Background(), "h-1", []string{"a"})
+	assert.Equal(t, ErrImageOrderInvalid, err)
+
+	_, err = uc.ReorderImages(context.Background(), "h-1", []string{"a", "a"})
+	assert.Equal(t, ErrImageOrderInvalid, err)
+	assert.False(t, repo.reorderCalled)
+
+	_, err = uc.ReorderImages(context.Background(), "h-1", []string{"b", "a"})
+	require.NoError(t, err)
+	assert.True(t, repo.reorderCalled)
+}
@@ -0,0 +1,105 @@
+package httpserver
+
+import (
+	"errors"
+
+	"hexagon/auth"
+	"hexagon/user"
+
+	"github.com/labstack/echo/v4"
+)
+
+// dataExportFilename is offered to browsers saving the export.
+const dataExportFilename = "hexagon-data-export.json"
+
+// handleExportData godoc
+// @Summary Export My Data
+// @Description Download the data stored about the current user: profile, linked providers, active sessions, audit and security events and uploads, as a JSON file.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} APISuccessResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/auth/account/export [get]
+func (s *Server) handleExportData(c echo.Context) error {
+	userID, _, ok := sessionFromContext(c)
+	if !ok {
+		return s.respondUnauthorized(c, "invalid access token", "missing user id claim")
+	}
+
+	export, err := s.AuthService.ExportData(c.Request().Context(), userID)
+	if err != nil {
+		return s.respondAccountError(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+dataExportFilename+`"`)
+
+	return s.respondOK(c, APIDataResult{Data: toDataExportResponse(export)})
+}
+
+// handleRequestAccountDeletion godoc
+// @Summary Delete My Account
+// @Description Schedule the deletion of the current user's account. It can be cancelled until deleteAt; then every session is logged out and the account is anonymised. Asking again returns the same date.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} APISuccessResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Failure 501 {object} APIErrorResponse
+// @Router /api/auth/account/deletion [post]
+func (s *Server) handleRequestAccountDeletion(c echo.Context) error {
+	userID, _, ok := sessionFromContext(c)
+	if !ok {
+		return s.respondUnauthorized(c, "invalid access token", "missing user id claim")
+	}
+
+	deleteAt, err := s.AuthService.RequestAccountDeletion(c.Request().Context(), userID)
+	if err != nil {
+		return s.respondAccountError(c, err)
+	}
+
+	return s.respondOK(c, AccountDeletionResponse{DeleteAt: deleteAt})
+}
+
+// handleCancelAccountDeletion godoc
+// @Summary Cancel Account Deletion
+// @Description Keep the current user's account whose deletion was requested.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} APISuccessResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 409 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Failure 501 {object} APIErrorResponse
+// @Router /api/auth/account/deletion [delete]
+func (s *Server) handleCancelAccountDeletion(c echo.Context) error {
+	userID, _, ok := sessionFromContext(c)
+	if !ok {
+		return s.respondUnauthorized(c, "invalid access token", "missing user id claim")
+	}
+
+	if err := s.AuthService.CancelAccountDeletion(c.Request().Context(), userID); err != nil {
+		return s.respondAccountError(c, err)
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+func (s *Server) respondAccountError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, user.ErrUserNotFound):
+		return s.respondNotFound(c, "user not found", err.Error())
+	case errors.Is(err, auth.ErrAccountDeletionNotRequested):
+		return s.respondConflict(c, "account deletion not requested", err.Error())
+	case errors.Is(err, auth.ErrAccountDeletionNotConfigured):
+		return s.respondNotImplemented(c, "account deletion not configured", err.Error())
+	default:
+		return s.respondInternalServerError(c, "internal error", err.Error())
+	}
+}
@@ -0,0 +1,166 @@
+package httpserver
+
+import (
+	"strings"
+
+	"hexagon/user"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (s *Server) registerAdminUserRoutes(g *echo.Group) {
+	g.GET("/users", s.handleSearchUsers)
+	g.PATCH("/users/:id/role", s.handleChangeUserRole)
+	g.POST("/users/:id/reactivate", s.handleReactivateUser)
+	g.POST("/users/:id/unlock", s.handleUnlockUser)
+	g.POST("/users/:id/logout", s.handleForceLogout)
+}
+
+// handleSearchUsers godoc
+// @Summary Search Users
+// @Description Search users by email or name, newest first. Admins only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Part of the email or name"
+// @Param status query string false "active, inactive or locked"
+// @Param role query string false "user or admin"
+// @Param page query int false "Page, starting at 1"
+// @Param pageSize query int false "Page size, at most 100"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 403 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/admin/users [get]
+func (s *Server) handleSearchUsers(c echo.Context) error {
+	page, err := positiveQueryInt(c, "page", 1)
+	if err != nil {
+		return s.respondBadRequest(c, "invalid page", err.Error())
+	}
+
+	pageSize, err := positiveQueryInt(c, "pageSize", defaultAdminPageSize)
+	if err != nil {
+		return s.respondBadRequest(c, "invalid page size", err.Error())
+	}
+
+	pageSize = min(pageSize, maxAdminPageSize)
+	offset := (page - 1) * pageSize
+
+	result, err := s.UserService.SearchUsers(c.Request().Context(), user.SearchFilter{
+		Query:  c.QueryParam("q"),
+		Status: user.UserStatus(strings.TrimSpace(c.QueryParam("status"))),
+		Role:   user.UserRole(strings.TrimSpace(c.QueryParam("role"))),
+		Limit:  pageSize,
+		Offset: offset,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.respondOK(c, APIDataResult{Data: toUsersResponse(result, page, pageSize, offset)})
+}
+
+// handleChangeUserRole godoc
+// @Summary Change User Role
+// @Description Change the role of a user and log them out everywhere. Admins only, and not for their own account.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param payload body ChangeUserRoleRequest true "Role payload"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 403 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/admin/users/{id}/role [patch]
+func (s *Server) handleChangeUserRole(c echo.Context) error {
+	id := c.Param("id")
+
+	var req ChangeUserRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	// An admin demoting themselves could leave nobody to undo it.
+	if actorID, _, ok := sessionFromContext(c); ok && actorID == id {
+		return s.respondBadRequest(c, "invalid user", "admins cannot change their own role")
+	}
+
+	if err := s.UserService.ChangeRole(c.Request().Context(), id, user.UserRole(req.Role)); err != nil {
+		return err
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+// handleReactivateUser godoc
+// @Summary Reactivate User
+// @Description Reactivate a deactivated user account. Admins only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} APISuccessResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 403 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 409 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/admin/users/{id}/reactivate [post]
+func (s *Server) handleReactivateUser(c echo.Context) error {
+	if err := s.UserService.ReactivateUser(c.Request().Context(), c.Param("id")); err != nil {
+		return err
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+// handleUnlockUser godoc
+// @Summary Unlock User
+// @Description Lift a lock from failed logins and clear the failed attempts. Admins only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} APISuccessResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 403 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/admin/users/{id}/unlock [post]
+func (s *Server) handleUnlockUser(c echo.Context) error {
+	if err := s.UserService.UnlockUser(c.Request().Context(), c.Param("id")); err != nil {
+		return err
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+// handleForceLogout godoc
+// @Summary Force Logout
+// @Description End every session of a user and revoke their access tokens. Admins only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} APISuccessResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 403 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/admin/users/{id}/logout [post]
+func (s *Server) handleForceLogout(c echo.Context) error {
+	if err := s.UserService.ForceLogout(c.Request().Context(), c.Param("id")); err != nil {
+		return err
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
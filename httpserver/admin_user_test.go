@@ -0,0 +1,121 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hexagon/httpserver"
+	"hexagon/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func serveAdminRequest(t *testing.T, server *httpserver.Server, role, method, target string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	token, err := signTestTokenWithRole(role)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestSearchUsers_PassesFilters(t *testing.T) {
+	svc := new(MockUserService)
+	server := httpserver.Default(testConfig())
+	server.UserService = svc
+
+	svc.On("SearchUsers", mock.Anything, user.SearchFilter{
+		Query:  "john",
+		Status: user.UserStatusLocked,
+		Limit:  10,
+		Offset: 10,
+	}).Return(user.SearchResult{
+		Users: []user.User{{ID: "u-2", Email: "john@mail.com", PasswordHash: "hashed", Status: user.UserStatusLocked}},
+		Total: 11,
+	}, nil).Once()
+
+	rec := serveAdminRequest(t, server, "admin", http.MethodGet, "/api/admin/users?q=john&status=locked&page=2&pageSize=10", nil)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "hashed")
+
+	var body struct {
+		Result struct {
+			Data httpserver.UsersResponse `json:"data"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Len(t, body.Result.Data.Users, 1)
+	assert.Equal(t, 2, body.Result.Data.Pagination.TotalPages)
+	svc.AssertExpectations(t)
+}
+
+func TestAdminUserRoutes_RequireAdminRole(t *testing.T) {
+	svc := new(MockUserService)
+	server := httpserver.Default(testConfig())
+	server.UserService = svc
+
+	rec := serveAdminRequest(t, server, "user", http.MethodPost, "/api/admin/users/u-2/unlock", nil)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	svc.AssertNotCalled(t, "UnlockUser", mock.Anything, mock.Anything)
+}
+
+func TestChangeUserRole(t *testing.T) {
+	t.Run("other user", func(t *testing.T) {
+		svc := new(MockUserService)
+		server := httpserver.Default(testConfig())
+		server.UserService = svc
+
+		svc.On("ChangeRole", mock.Anything, "u-2", user.UserRoleAdmin).Return(nil).Once()
+
+		rec := serveAdminRequest(t, server, "admin", http.MethodPatch, "/api/admin/users/u-2/role", []byte(`{"role":"admin"}`))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("own account", func(t *testing.T) {
+		svc := new(MockUserService)
+		server := httpserver.Default(testConfig())
+		server.UserService = svc
+
+		rec := serveAdminRequest(t, server, "admin", http.MethodPatch, "/api/admin/users/u-1/role", []byte(`{"role":"user"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		svc.AssertNotCalled(t, "ChangeRole", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		svc := new(MockUserService)
+		server := httpserver.Default(testConfig())
+		server.UserService = svc
+
+		rec := serveAdminRequest(t, server, "admin", http.MethodPatch, "/api/admin/users/u-2/role", []byte(`{"role":"root"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestReactivateUser_NotInactive(t *testing.T) {
+	svc := new(MockUserService)
+	server := httpserver.Default(testConfig())
+	server.UserService = svc
+
+	svc.On("ReactivateUser", mock.Anything, "u-2").Return(user.ErrUserNotInactive).Once()
+
+	rec := serveAdminRequest(t, server, "admin", http.MethodPost, "/api/admin/users/u-2/reactivate", nil)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
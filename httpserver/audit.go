@@ -0,0 +1,110 @@
+package httpserver
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"hexagon/audit"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultAdminPageSize = 20
+	maxAdminPageSize     = 100
+)
+
+func (s *Server) RegisterAdminRoutes(g *echo.Group) {
+	g.GET("/audit-events", s.handleListAuditEvents)
+	s.registerAdminUserRoutes(g)
+}
+
+// handleListAuditEvents godoc
+// @Summary List Audit Events
+// @Description List audit events, newest first. Admins only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param actorId query string false "User who performed the action"
+// @Param userId query string false "User the event is about"
+// @Param type query string false "Event type, e.g. login_failed"
+// @Param ip query string false "Client IP address"
+// @Param from query string false "Earliest time (RFC 3339)"
+// @Param to query string false "Latest time (RFC 3339)"
+// @Param page query int false "Page, starting at 1"
+// @Param pageSize query int false "Page size, at most 100"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 403 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/admin/audit-events [get]
+func (s *Server) handleListAuditEvents(c echo.Context) error {
+	page, err := positiveQueryInt(c, "page", 1)
+	if err != nil {
+		return s.respondBadRequest(c, "invalid page", err.Error())
+	}
+
+	pageSize, err := positiveQueryInt(c, "pageSize", defaultAdminPageSize)
+	if err != nil {
+		return s.respondBadRequest(c, "invalid page size", err.Error())
+	}
+
+	pageSize = min(pageSize, maxAdminPageSize)
+
+	from, err := optionalQueryTime(c, "from")
+	if err != nil {
+		return s.respondBadRequest(c, "invalid from", err.Error())
+	}
+
+	to, err := optionalQueryTime(c, "to")
+	if err != nil {
+		return s.respondBadRequest(c, "invalid to", err.Error())
+	}
+
+	offset := (page - 1) * pageSize
+
+	result, err := s.AuditService.ListEvents(c.Request().Context(), audit.Filter{
+		ActorID:   strings.TrimSpace(c.QueryParam("actorId")),
+		UserID:    strings.TrimSpace(c.QueryParam("userId")),
+		Type:      audit.EventType(strings.TrimSpace(c.QueryParam("type"))),
+		IPAddress: strings.TrimSpace(c.QueryParam("ip")),
+		From:      from,
+		To:        to,
+		Limit:     pageSize,
+		Offset:    offset,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.respondOK(c, APIDataResult{Data: toAuditEventsResponse(result, page, pageSize, offset)})
+}
+
+func positiveQueryInt(c echo.Context, name string, fallback int) (int, error) {
+	raw := strings.TrimSpace(c.QueryParam(name))
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, err
+	}
+
+	if value < 1 {
+		return 0, strconv.ErrRange
+	}
+
+	return value, nil
+}
+
+func optionalQueryTime(c echo.Context, name string) (time.Time, error) {
+	raw := strings.TrimSpace(c.QueryParam(name))
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, raw)
+}
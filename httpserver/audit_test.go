@@ -0,0 +1,123 @@
+package httpserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hexagon/audit"
+	"hexagon/httpserver"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubAuditService struct {
+	filters []audit.Filter
+	page    audit.Page
+}
+
+func (s *stubAuditService) ListEvents(ctx context.Context, filter audit.Filter) (audit.Page, error) {
+	s.filters = append(s.filters, filter)
+
+	return s.page, nil
+}
+
+func signTestTokenWithRole(role string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":            "u-1",
+		"type":           "access",
+		"email":          "john@mail.com",
+		"email_verified": true,
+		"role":           role,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+}
+
+func TestAdminRoutes_RequireAdminRole(t *testing.T) {
+	svc := &stubAuditService{}
+	server := httpserver.Default(testConfig())
+	server.AuditService = svc
+
+	for role, status := range map[string]int{"admin": http.StatusOK, "user": http.StatusForbidden, "": http.StatusForbidden} {
+		token, err := signTestTokenWithRole(role)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/audit-events", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		server.Router.ServeHTTP(rec, req)
+
+		assert.Equal(t, status, rec.Code, role)
+	}
+
+	assert.Len(t, svc.filters, 1)
+}
+
+func TestListAuditEvents_PassesFilters(t *testing.T) {
+	createdAt := time.Date(2026, 4, 11, 12, 0, 0, 0, time.UTC)
+	svc := &stubAuditService{page: audit.Page{
+		Events: []audit.Event{{ID: "e-1", Type: audit.EventLoginFailed, UserID: "u-2", CreatedAt: createdAt}},
+		Total:  21,
+	}}
+	server := httpserver.Default(testConfig())
+	server.AuditService = svc
+
+	token, err := signTestTokenWithRole("admin")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(
+		http.MethodGet,
+		"/api/admin/audit-events?userId=u-2&type=login_failed&ip=203.0.113.7&from=2026-04-01T00:00:00Z&page=2&pageSize=10",
+		nil,
+	)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, svc.filters, 1)
+	assert.Equal(t, audit.Filter{
+		UserID:    "u-2",
+		Type:      audit.EventLoginFailed,
+		IPAddress: "203.0.113.7",
+		From:      time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		Limit:     10,
+		Offset:    10,
+	}, svc.filters[0])
+
+	var body struct {
+		Result struct {
+			Data httpserver.AuditEventsResponse `json:"data"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Len(t, body.Result.Data.Events, 1)
+	assert.Equal(t, 3, body.Result.Data.Pagination.TotalPages)
+	assert.Equal(t, 2, body.Result.Data.Pagination.Page)
+}
+
+func TestListAuditEvents_RejectsInvalidTime(t *testing.T) {
+	svc := &stubAuditService{}
+	server := httpserver.Default(testConfig())
+	server.AuditService = svc
+
+	token, err := signTestTokenWithRole("admin")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/audit-events?from=yesterday", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, svc.filters)
+}
@@ -1,8 +1,6 @@
 package httpserver
 
 import (
-	"crypto/rand"
-	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/http"
@@ -22,22 +20,42 @@ import (
 func (s *Server) RegisterAuthRoutes() {
 	authGroup := s.Router.Group("/api/auth")
 	authGroup.POST("/register", s.handleRegister)
-	authGroup.GET("/google/login", s.handleGoogleLogin)
-	authGroup.GET("/google/callback", s.handleGoogleCallback)
+	authGroup.GET("/providers", s.handleListOAuthProviders)
+	authGroup.GET("/:provider/login", s.handleOAuthLogin)
+	authGroup.GET("/:provider/callback", s.handleOAuthCallback)
 
 	sensitiveAuth := s.Router.Group("/api/auth")
 	sensitiveAuth.Use(s.authSensitiveRateLimiter())
 	sensitiveAuth.POST("/login", s.handleLogin)
+	sensitiveAuth.POST("/login/2fa", s.handleVerifyTwoFactorLogin)
+	sensitiveAuth.POST("/magic-link", s.handleRequestMagicLink)
+	sensitiveAuth.POST("/magic-link/login", s.handleMagicLinkLogin)
 	sensitiveAuth.POST("/verify-email/send", s.handleSendVerifyEmail)
 	sensitiveAuth.POST("/verify-email", s.handleVerifyEmail)
 	sensitiveAuth.POST("/forgot-password", s.handleForgotPassword)
 	sensitiveAuth.POST("/reset-password", s.handleResetPassword)
+	sensitiveAuth.POST("/sign-in/deny", s.handleDenySignIn)
 	sensitiveAuth.POST("/refresh", s.handleRefresh)
 }
 
 func (s *Server) RegisterAuthPrivateRoutes(g *echo.Group) {
 	g.POST("/auth/logout", s.handleLogout)
 	g.GET("/auth/me", s.handleMe)
+	g.GET("/auth/sessions", s.handleListSessions)
+	g.DELETE("/auth/sessions", s.handleRevokeOtherSessions)
+	g.DELETE("/auth/sessions/:id", s.handleRevokeSession)
+	g.POST("/auth/2fa/totp/setup", s.handleSetupTOTP)
+	g.POST("/auth/2fa/totp/enable", s.handleEnableTOTP)
+	g.POST("/auth/2fa/totp/disable", s.handleDisableTOTP, s.authSensitiveRateLimiter())
+	g.GET("/auth/oauth/accounts", s.handleListOAuthAccounts)
+	g.POST("/auth/oauth/accounts/:provider/link", s.handleLinkOAuthAccount)
+	g.DELETE("/auth/oauth/accounts/:provider", s.handleUnlinkOAuthAccount)
+	g.POST("/auth/email/change", s.handleRequestEmailChange, s.authSensitiveRateLimiter())
+	g.POST("/auth/phone/verify/send", s.handleSendPhoneVerification)
+	g.POST("/auth/phone/verify", s.handleVerifyPhone)
+	g.GET("/auth/account/export", s.handleExportData)
+	g.POST("/auth/account/deletion", s.handleRequestAccountDeletion)
+	g.DELETE("/auth/account/deletion", s.handleCancelAccountDeletion)
 }
 
 // handleRegister godoc
@@ -96,7 +114,7 @@ func (s *Server) handleRegister(c echo.Context) error {
 
 // handleLogin godoc
 // @Summary User Login
-// @Description Authenticate user and return access + refresh tokens
+// @Description Authenticate user and return access + refresh tokens. Users with two-factor authentication get `twoFactorRequired` and a `challengeToken` for /api/auth/login/2fa instead.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -104,6 +122,7 @@ func (s *Server) handleRegister(c echo.Context) error {
 // @Success 200 {object} APISuccessResponse
 // @Failure 400 {object} APIErrorResponse
 // @Failure 401 {object} APIErrorResponse
+// @Failure 403 {object} APIErrorResponse
 // @Failure 429 {object} APIErrorResponse
 // @Failure 500 {object} APIErrorResponse
 // @Router /api/auth/login [post]
@@ -118,7 +137,7 @@ func (s *Server) handleLogin(c echo.Context) error {
 		return s.respondBadRequest(c, "invalid request body", err.Error())
 	}
 
-	tokens, err := s.AuthService.Login(
+	result, err := s.AuthService.Login(
 		auth.WithClientInfo(c.Request().Context(), auth.ClientInfo{
 			UserAgent: c.Request().UserAgent(),
 			IPAddress: c.RealIP(),
@@ -143,18 +162,136 @@ func (s *Server) handleLogin(c echo.Context) error {
 			return s.respondUnauthorized(c, "email is not verified", err.Error())
 		}
 
+		if errors.Is(err, auth.ErrAccountInactive) {
+			return s.respondUnauthorized(c, "account is inactive", err.Error())
+		}
+
+		if errors.Is(err, auth.ErrPasswordResetRequired) {
+			return s.respondForbidden(c, "password reset required", err.Error())
+		}
+
 		return s.respondInternalServerError(c, "internal error", err.Error())
 	}
 
+	return s.respondLoginResult(c, result)
+}
+
+// respondLoginResult answers a first-factor login with tokens, or with a
+// challenge token when the user has two-factor authentication.
+func (s *Server) respondLoginResult(c echo.Context, result auth.LoginResult) error {
+	if result.TwoFactorRequired() {
+		return s.respondOK(c, map[string]any{
+			"twoFactorRequired": true,
+			"challengeToken":    result.ChallengeToken,
+		})
+	}
+
 	return s.respondOK(c, map[string]string{
-		"accessToken":  tokens.AccessToken,
-		"refreshToken": tokens.RefreshToken,
+		"accessToken":  result.Tokens.AccessToken,
+		"refreshToken": result.Tokens.RefreshToken,
 	})
 }
 
+// handleRequestMagicLink godoc
+// @Summary Request Magic Link
+// @Description Email a single-use login link. Responds the same whether or not the email is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body MagicLinkRequest true "Magic link payload"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 429 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Failure 501 {object} APIErrorResponse
+// @Router /api/auth/magic-link [post]
+func (s *Server) handleRequestMagicLink(c echo.Context) error {
+	var req MagicLinkRequest
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := s.AuthService.RequestMagicLink(auth.WithClientInfo(c.Request().Context(), auth.ClientInfo{
+		UserAgent: c.Request().UserAgent(),
+		IPAddress: c.RealIP(),
+	}), req.Email); err != nil {
+		if errors.Is(err, auth.ErrMissingEmail) {
+			return s.respondBadRequest(c, "invalid email", err.Error())
+		}
+
+		if errors.Is(err, auth.ErrMagicLinkNotConfigured) {
+			return s.respondNotImplemented(c, "magic link login not configured", err.Error())
+		}
+
+		return s.respondInternalServerError(c, "internal error", err.Error())
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+// handleMagicLinkLogin godoc
+// @Summary Magic Link Login
+// @Description Exchange a magic link token for access + refresh tokens, or a two-factor challenge like /api/auth/login. The token works once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body MagicLinkLoginRequest true "Magic link login payload"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 403 {object} APIErrorResponse
+// @Failure 429 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Failure 501 {object} APIErrorResponse
+// @Router /api/auth/magic-link/login [post]
+func (s *Server) handleMagicLinkLogin(c echo.Context) error {
+	var req MagicLinkLoginRequest
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	result, err := s.AuthService.LoginWithMagicLink(auth.WithClientInfo(c.Request().Context(), auth.ClientInfo{
+		UserAgent: c.Request().UserAgent(),
+		IPAddress: c.RealIP(),
+	}), req.Token)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidMagicLinkToken) {
+			return s.respondUnauthorized(c, "invalid magic link token", err.Error())
+		}
+
+		if errors.Is(err, auth.ErrAccountInactive) {
+			return s.respondUnauthorized(c, "account is inactive", err.Error())
+		}
+
+		if errors.Is(err, auth.ErrAccountLocked) {
+			return s.respondTooManyRequests(c, "account temporarily locked", err.Error())
+		}
+
+		if errors.Is(err, auth.ErrPasswordResetRequired) {
+			return s.respondForbidden(c, "password reset required", err.Error())
+		}
+
+		if errors.Is(err, auth.ErrMagicLinkNotConfigured) {
+			return s.respondNotImplemented(c, "magic link login not configured", err.Error())
+		}
+
+		return s.respondInternalServerError(c, "internal error", err.Error())
+	}
+
+	return s.respondLoginResult(c, result)
+}
+
 // handleLogout godoc
 // @Summary User Logout
-// @Description Revoke current refresh token
+// @Description Revoke the session of the refresh token and the current access token
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -175,10 +312,17 @@ func (s *Server) handleLogout(c echo.Context) error {
 		return s.respondBadRequest(c, "invalid request body", err.Error())
 	}
 
+	var current auth.AccessTokenClaims
+	if token, ok := c.Get("user").(*jwt.Token); ok && token != nil {
+		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			current = accessTokenClaims(claims)
+		}
+	}
+
 	if err := s.AuthService.Logout(auth.WithClientInfo(c.Request().Context(), auth.ClientInfo{
 		UserAgent: c.Request().UserAgent(),
 		IPAddress: c.RealIP(),
-	}), req.RefreshToken); err != nil {
+	}), req.RefreshToken, current); err != nil {
 		if errors.Is(err, auth.ErrInvalidRefreshToken) {
 			return s.respondUnauthorized(c, "invalid refresh token", err.Error())
 		}
@@ -266,7 +410,7 @@ func (s *Server) handleSendVerifyEmail(c echo.Context) error {
 
 // handleVerifyEmail godoc
 // @Summary Verify email
-// @Description Verify user email with verification token
+// @Description Verify user email with verification token. A token sent by /api/auth/email/change also moves the account to the new address.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -274,6 +418,7 @@ func (s *Server) handleSendVerifyEmail(c echo.Context) error {
 // @Success 200 {object} APISuccessResponse
 // @Failure 400 {object} APIErrorResponse
 // @Failure 401 {object} APIErrorResponse
+// @Failure 409 {object} APIErrorResponse
 // @Failure 500 {object} APIErrorResponse
 // @Router /api/auth/verify-email [post]
 func (s *Server) handleVerifyEmail(c echo.Context) error {
@@ -294,6 +439,10 @@ func (s *Server) handleVerifyEmail(c echo.Context) error {
 			return s.respondUnauthorized(c, "invalid verify token", err.Error())
 		}
 
+		if errors.Is(err, user.ErrEmailAlreadyExists) {
+			return s.respondConflict(c, "email already exists", err.Error())
+		}
+
 		return s.respondInternalServerError(c, "internal error", err.Error())
 	}
 
@@ -334,6 +483,51 @@ func (s *Server) handleResetPassword(c echo.Context) error {
 			return s.respondUnauthorized(c, "invalid reset token", err.Error())
 		}
 
+		if errs.ErrorCode(err) == errs.EINVALID {
+			return s.respondBadRequest(c, errs.ErrorMessage(err), err.Error())
+		}
+
+		return s.respondInternalServerError(c, "internal error", err.Error())
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+// handleDenySignIn godoc
+// @Summary Deny Sign-In
+// @Description Report a sign-in from a new-device alert email as not yours. Logs out every session and requires a password reset.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body DenySignInRequest true "Deny sign-in payload"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Failure 501 {object} APIErrorResponse
+// @Router /api/auth/sign-in/deny [post]
+func (s *Server) handleDenySignIn(c echo.Context) error {
+	var req DenySignInRequest
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := s.AuthService.DenySignIn(auth.WithClientInfo(c.Request().Context(), auth.ClientInfo{
+		UserAgent: c.Request().UserAgent(),
+		IPAddress: c.RealIP(),
+	}), req.Token); err != nil {
+		if errors.Is(err, auth.ErrInvalidSignInAlertToken) {
+			return s.respondUnauthorized(c, "invalid sign-in alert token", err.Error())
+		}
+
+		if errors.Is(err, auth.ErrSignInAlertsNotConfigured) {
+			return s.respondNotImplemented(c, "new sign-in alerts not configured", err.Error())
+		}
+
 		return s.respondInternalServerError(c, "internal error", err.Error())
 	}
 
@@ -374,6 +568,128 @@ func (s *Server) handleMe(c echo.Context) error {
 	return s.respondOK(c, toUserResponse(u))
 }
 
+// handleListSessions godoc
+// @Summary List Sessions
+// @Description List the current user's active sessions. `current` marks the session of the access token used for the request.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} APISuccessResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/auth/sessions [get]
+func (s *Server) handleListSessions(c echo.Context) error {
+	userID, sessionID, ok := sessionFromContext(c)
+	if !ok {
+		return s.respondUnauthorized(c, "invalid access token", "missing user id claim")
+	}
+
+	sessions, err := s.AuthService.ListSessions(c.Request().Context(), userID, sessionID)
+	if err != nil {
+		return s.respondInternalServerError(c, "internal error", err.Error())
+	}
+
+	return s.respondOK(c, APIDataResult{Data: toSessionResponses(sessions)})
+}
+
+// handleRevokeSession godoc
+// @Summary Revoke Session
+// @Description Log one of the current user's sessions out. Access tokens already issued to it are revoked as well.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} APISuccessResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/auth/sessions/{id} [delete]
+func (s *Server) handleRevokeSession(c echo.Context) error {
+	userID, _, ok := sessionFromContext(c)
+	if !ok {
+		return s.respondUnauthorized(c, "invalid access token", "missing user id claim")
+	}
+
+	if err := s.AuthService.RevokeSession(c.Request().Context(), userID, c.Param("id")); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			return s.respondNotFound(c, "session not found", err.Error())
+		}
+
+		return s.respondInternalServerError(c, "internal error", err.Error())
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+// handleRevokeOtherSessions godoc
+// @Summary Log Out Other Sessions
+// @Description Log the current user out everywhere except the session of this access token.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} APISuccessResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/auth/sessions [delete]
+func (s *Server) handleRevokeOtherSessions(c echo.Context) error {
+	userID, sessionID, ok := sessionFromContext(c)
+	if !ok {
+		return s.respondUnauthorized(c, "invalid access token", "missing user id claim")
+	}
+
+	if err := s.AuthService.RevokeOtherSessions(c.Request().Context(), userID, sessionID); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			return s.respondUnauthorized(c, "access token has no session, log in again", err.Error())
+		}
+
+		return s.respondInternalServerError(c, "internal error", err.Error())
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+// sessionFromContext reads the user id and session id ("sid") of the access
+// token. Tokens issued before sessions existed have no sid.
+func sessionFromContext(c echo.Context) (string, string, bool) {
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		return "", "", false
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", "", false
+	}
+
+	userID, ok := userIDFromClaims(claims)
+	if !ok {
+		return "", "", false
+	}
+
+	sessionID, _ := claims["sid"].(string)
+
+	return userID, strings.TrimSpace(sessionID), true
+}
+
+// accessTokenClaims reads the claims revocation works on. Missing claims
+// are left zero.
+func accessTokenClaims(claims jwt.MapClaims) auth.AccessTokenClaims {
+	token := auth.AccessTokenClaims{}
+	token.ID, _ = claims["jti"].(string)
+	token.UserID, _ = userIDFromClaims(claims)
+	token.SessionID, _ = claims["sid"].(string)
+
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		token.IssuedAt = iat.Time
+	}
+
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		token.ExpiresAt = exp.Time
+	}
+
+	return token
+}
+
 func userIDFromClaims(claims jwt.MapClaims) (string, bool) {
 	if sub, ok := claims["sub"].(string); ok && strings.TrimSpace(sub) != "" {
 		return strings.TrimSpace(sub), true
@@ -390,56 +706,72 @@ func userIDFromClaims(claims jwt.MapClaims) (string, bool) {
 	return "", false
 }
 
-// handleGoogleLogin godoc
-// @Summary Google OAuth Login
-// @Description Get Google OAuth2 authorization URL
+// oauthFlowCookie keeps the OAuth state, nonce and PKCE verifier between
+// the redirect to the provider and the callback, along with whether the
+// flow logs in or links an account.
+const oauthFlowCookie = "oauth_flow"
+
+const (
+	oauthIntentLogin = "login"
+	oauthIntentLink  = "link"
+)
+
+// handleListOAuthProviders godoc
+// @Summary List OAuth Providers
+// @Description Names of the configured login providers, for /api/auth/{provider}/login
+// @Tags auth
+// @Produce json
+// @Success 200 {object} APISuccessResponse
+// @Router /api/auth/providers [get]
+func (s *Server) handleListOAuthProviders(c echo.Context) error {
+	return s.respondOK(c, APIDataResult{Data: s.AuthService.OAuthProviders()})
+}
+
+// handleOAuthLogin godoc
+// @Summary OAuth Login
+// @Description Get the authorization URL of an OpenID Connect provider, e.g. google
 // @Tags auth
 // @Produce json
+// @Param provider path string true "Provider name"
 // @Success 200 {object} APISuccessResponse
+// @Failure 404 {object} APIErrorResponse
 // @Failure 500 {object} APIErrorResponse
-// @Router /api/auth/google/login [get]
-func (s *Server) handleGoogleLogin(c echo.Context) error {
-	state, err := generateOAuthState(32)
-	if err != nil {
-		return s.respondInternalServerError(c, "internal error", err.Error())
-	}
+// @Failure 501 {object} APIErrorResponse
+// @Router /api/auth/{provider}/login [get]
+func (s *Server) handleOAuthLogin(c echo.Context) error {
+	provider := auth.OAuthProvider(c.Param("provider"))
 
-	authURL, err := s.AuthService.GoogleAuthURL(state)
+	authURL, flow, err := s.AuthService.OAuthAuthURL(c.Request().Context(), provider)
 	if err != nil {
-		if errors.Is(err, auth.ErrOAuthNotConfigured) {
-			return s.respondNotImplemented(c, "oauth not configured", err.Error())
-		}
-
-		return s.respondInternalServerError(c, "internal error", err.Error())
+		return s.respondOAuthError(c, err)
 	}
 
-	c.SetCookie(&http.Cookie{
-		Name:     "oauth_state",
-		Value:    state,
-		HttpOnly: true,
-		Path:     "/",
-		SameSite: http.SameSiteLaxMode,
-		MaxAge:   int((5 * time.Minute).Seconds()),
-	})
+	setOAuthFlowCookie(c, provider, oauthIntentLogin, flow)
 
 	return s.respondOK(c, map[string]string{
 		"authUrl": authURL,
 	})
 }
 
-// handleGoogleCallback godoc
-// @Summary Google OAuth Callback
-// @Description Exchange Google OAuth2 code for tokens. Requires oauth_state cookie set by /api/auth/google/login.
+// handleOAuthCallback godoc
+// @Summary OAuth Callback
+// @Description Exchange an OAuth code for tokens, or a two-factor challenge like /api/auth/login, or link the account when the flow was started by /api/auth/oauth/accounts/{provider}/link. Only providers trusted for email sign in to an existing account with the same email on first use; others return 409 and must be linked. Requires the oauth_flow cookie set by either.
 // @Tags auth
 // @Produce json
+// @Param provider path string true "Provider name"
 // @Param code query string true "OAuth code"
 // @Param state query string true "OAuth state"
 // @Success 200 {object} APISuccessResponse
 // @Failure 400 {object} APIErrorResponse
 // @Failure 401 {object} APIErrorResponse
+// @Failure 403 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 409 {object} APIErrorResponse
+// @Failure 429 {object} APIErrorResponse
 // @Failure 500 {object} APIErrorResponse
-// @Router /api/auth/google/callback [get]
-func (s *Server) handleGoogleCallback(c echo.Context) error {
+// @Router /api/auth/{provider}/callback [get]
+func (s *Server) handleOAuthCallback(c echo.Context) error {
+	provider := auth.OAuthProvider(c.Param("provider"))
 	code := c.QueryParam("code")
 	state := c.QueryParam("state")
 
@@ -447,56 +779,199 @@ func (s *Server) handleGoogleCallback(c echo.Context) error {
 		return s.respondBadRequest(c, "missing code or state", "missing query parameter code or state")
 	}
 
-	stateCookie, err := c.Cookie("oauth_state")
-	if err != nil || stateCookie == nil || stateCookie.Value != state {
+	intent, flow, ok := oauthFlowFromCookie(c, provider)
+	if !ok || flow.State != state {
 		return s.respondUnauthorized(c, "invalid oauth state", "oauth state mismatch")
 	}
 
-	tokens, err := s.AuthService.LoginWithGoogle(auth.WithClientInfo(c.Request().Context(), auth.ClientInfo{
+	ctx := auth.WithClientInfo(c.Request().Context(), auth.ClientInfo{
 		UserAgent: c.Request().UserAgent(),
 		IPAddress: c.RealIP(),
-	}), code)
-	if err != nil {
-		if errors.Is(err, auth.ErrOAuthNotConfigured) {
-			return s.respondNotImplemented(c, "oauth not configured", err.Error())
-		}
+	})
 
-		if errors.Is(err, auth.ErrMissingCode) || errors.Is(err, auth.ErrMissingState) {
-			return s.respondBadRequest(c, "missing oauth parameters", err.Error())
+	if intent == oauthIntentLink {
+		account, err := s.AuthService.LinkOAuthAccount(ctx, provider, code, flow)
+		if err != nil {
+			return s.respondOAuthError(c, err)
 		}
 
-		if errors.Is(err, auth.ErrMissingEmail) || errors.Is(err, auth.ErrUnverifiedEmail) || errors.Is(err, auth.ErrInvalidOAuthUser) {
-			return s.respondUnauthorized(c, "invalid oauth user", err.Error())
-		}
+		clearOAuthFlowCookie(c)
+
+		return s.respondOK(c, toOAuthAccountResponse(account))
+	}
+
+	result, err := s.AuthService.LoginWithOAuth(ctx, provider, code, flow)
+	if err != nil {
+		return s.respondOAuthError(c, err)
+	}
+
+	clearOAuthFlowCookie(c)
+
+	return s.respondLoginResult(c, result)
+}
 
+// handleListOAuthAccounts godoc
+// @Summary List Linked OAuth Accounts
+// @Description List the providers linked to the current user
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} APISuccessResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/auth/oauth/accounts [get]
+func (s *Server) handleListOAuthAccounts(c echo.Context) error {
+	userID, _, ok := sessionFromContext(c)
+	if !ok {
+		return s.respondUnauthorized(c, "invalid access token", "missing user id claim")
+	}
+
+	accounts, err := s.AuthService.ListOAuthAccounts(c.Request().Context(), userID)
+	if err != nil {
 		return s.respondInternalServerError(c, "internal error", err.Error())
 	}
 
+	return s.respondOK(c, APIDataResult{Data: toOAuthAccountResponses(accounts)})
+}
+
+// handleLinkOAuthAccount godoc
+// @Summary Link OAuth Account
+// @Description Get the authorization URL that links a provider to the current user. The provider redirects to /api/auth/{provider}/callback, which completes the link.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider name"
+// @Success 200 {object} APISuccessResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Failure 501 {object} APIErrorResponse
+// @Router /api/auth/oauth/accounts/{provider}/link [post]
+func (s *Server) handleLinkOAuthAccount(c echo.Context) error {
+	userID, _, ok := sessionFromContext(c)
+	if !ok {
+		return s.respondUnauthorized(c, "invalid access token", "missing user id claim")
+	}
+
+	provider := auth.OAuthProvider(c.Param("provider"))
+
+	authURL, flow, err := s.AuthService.OAuthLinkURL(c.Request().Context(), userID, provider)
+	if err != nil {
+		return s.respondOAuthError(c, err)
+	}
+
+	setOAuthFlowCookie(c, provider, oauthIntentLink, flow)
+
+	return s.respondOK(c, map[string]string{
+		"authUrl": authURL,
+	})
+}
+
+// handleUnlinkOAuthAccount godoc
+// @Summary Unlink OAuth Account
+// @Description Remove a linked provider. An account without a password cannot remove its last provider.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider name"
+// @Success 200 {object} APISuccessResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 409 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/auth/oauth/accounts/{provider} [delete]
+func (s *Server) handleUnlinkOAuthAccount(c echo.Context) error {
+	userID, _, ok := sessionFromContext(c)
+	if !ok {
+		return s.respondUnauthorized(c, "invalid access token", "missing user id claim")
+	}
+
+	err := s.AuthService.UnlinkOAuthAccount(auth.WithClientInfo(c.Request().Context(), auth.ClientInfo{
+		UserAgent: c.Request().UserAgent(),
+		IPAddress: c.RealIP(),
+	}), userID, auth.OAuthProvider(c.Param("provider")))
+	if err != nil {
+		if errors.Is(err, auth.ErrPasswordAuthNotAvailable) {
+			return s.respondConflict(c, "cannot unlink the last sign-in method, set a password first", err.Error())
+		}
+
+		return s.respondOAuthError(c, err)
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+// setOAuthFlowCookie stores flow as "provider.intent.nonce.verifier.state".
+// The state goes last as a link state is a signed token containing dots.
+func setOAuthFlowCookie(c echo.Context, provider auth.OAuthProvider, intent string, flow auth.OAuthFlow) {
 	c.SetCookie(&http.Cookie{
-		Name:     "oauth_state",
-		Value:    "",
+		Name:     oauthFlowCookie,
+		Value:    strings.Join([]string{string(provider), intent, flow.Nonce, flow.CodeVerifier, flow.State}, "."),
 		HttpOnly: true,
 		Path:     "/",
-		MaxAge:   -1,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int((5 * time.Minute).Seconds()),
 	})
+}
 
-	return s.respondOK(c, map[string]string{
-		"accessToken":  tokens.AccessToken,
-		"refreshToken": tokens.RefreshToken,
+func clearOAuthFlowCookie(c echo.Context) {
+	c.SetCookie(&http.Cookie{
+		Name:     oauthFlowCookie,
+		Value:    "",
+		HttpOnly: true,
+		Path:     "/",
+		MaxAge:   -1,
 	})
 }
 
-func generateOAuthState(length int) (string, error) {
-	if length <= 0 {
-		return "", errors.New("invalid state length")
+// oauthFlowFromCookie reads the intent and flow started for provider. A
+// flow started for another provider is rejected. The intent only picks
+// the handler: a link flow is bound to its user by the signed state.
+func oauthFlowFromCookie(c echo.Context, provider auth.OAuthProvider) (string, auth.OAuthFlow, bool) {
+	cookie, err := c.Cookie(oauthFlowCookie)
+	if err != nil || cookie == nil {
+		return "", auth.OAuthFlow{}, false
+	}
+
+	parts := strings.SplitN(cookie.Value, ".", 5)
+	if len(parts) != 5 || parts[0] != string(provider) {
+		return "", auth.OAuthFlow{}, false
 	}
 
-	buf := make([]byte, length)
-	if _, err := rand.Read(buf); err != nil {
-		return "", err
+	if parts[1] != oauthIntentLogin && parts[1] != oauthIntentLink {
+		return "", auth.OAuthFlow{}, false
 	}
 
-	return base64.RawURLEncoding.EncodeToString(buf), nil
+	return parts[1], auth.OAuthFlow{State: parts[4], Nonce: parts[2], CodeVerifier: parts[3]}, true
+}
+
+func (s *Server) respondOAuthError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, auth.ErrOAuthNotConfigured):
+		return s.respondNotImplemented(c, "oauth not configured", err.Error())
+	case errors.Is(err, auth.ErrUnknownOAuthProvider):
+		return s.respondNotFound(c, "unknown oauth provider", err.Error())
+	case errors.Is(err, auth.ErrMissingCode) || errors.Is(err, auth.ErrMissingState):
+		return s.respondBadRequest(c, "missing oauth parameters", err.Error())
+	case errors.Is(err, auth.ErrMissingEmail) || errors.Is(err, auth.ErrUnverifiedEmail) || errors.Is(err, auth.ErrInvalidOAuthUser):
+		return s.respondUnauthorized(c, "invalid oauth user", err.Error())
+	case errors.Is(err, auth.ErrInvalidOAuthLinkState):
+		return s.respondUnauthorized(c, "invalid oauth link", err.Error())
+	case errors.Is(err, auth.ErrAccountInactive):
+		return s.respondUnauthorized(c, "account is inactive", err.Error())
+	case errors.Is(err, auth.ErrAccountLocked):
+		return s.respondTooManyRequests(c, "account temporarily locked", err.Error())
+	case errors.Is(err, auth.ErrPasswordResetRequired):
+		return s.respondForbidden(c, "password reset required", err.Error())
+	case errors.Is(err, auth.ErrOAuthAccountNotLinked):
+		return s.respondNotFound(c, "oauth account not linked", err.Error())
+	case errors.Is(err, auth.ErrOAuthLinkRequired):
+		return s.respondConflict(c, "oauth account must be linked", err.Error())
+	case errors.Is(err, auth.ErrOAuthAccountLinkedElsewhere) || errors.Is(err, auth.ErrOAuthProviderAlreadyLinked):
+		return s.respondConflict(c, "oauth account cannot be linked", err.Error())
+	default:
+		return s.respondInternalServerError(c, "internal error", err.Error())
+	}
 }
 
 // handleRefresh godoc
@@ -0,0 +1,76 @@
+package httpserver
+
+import (
+	"errors"
+
+	"hexagon/auth"
+	"hexagon/user"
+
+	"github.com/labstack/echo/v4"
+)
+
+// handleRequestEmailChange godoc
+// @Summary Change Email
+// @Description Send a confirmation link to the new address and a notice to the current one. The email changes when the link's token is posted to /api/auth/verify-email; every other session is then logged out. Accounts with a password must send it; wrong passwords count towards the account lock.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body ChangeEmailRequest true "Change email payload"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 409 {object} APIErrorResponse
+// @Failure 429 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Failure 501 {object} APIErrorResponse
+// @Router /api/auth/email/change [post]
+func (s *Server) handleRequestEmailChange(c echo.Context) error {
+	userID, sessionID, ok := sessionFromContext(c)
+	if !ok {
+		return s.respondUnauthorized(c, "invalid access token", "missing user id claim")
+	}
+
+	var req ChangeEmailRequest
+
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	err := s.AuthService.RequestEmailChange(
+		auth.WithClientInfo(c.Request().Context(), auth.ClientInfo{
+			UserAgent: c.Request().UserAgent(),
+			IPAddress: c.RealIP(),
+		}),
+		userID,
+		sessionID,
+		req.NewEmail,
+		req.Password,
+	)
+	if err != nil {
+		return s.respondEmailChangeError(c, err)
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+func (s *Server) respondEmailChangeError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		return s.respondUnauthorized(c, "invalid password", err.Error())
+	case errors.Is(err, auth.ErrAccountLocked):
+		return s.respondTooManyRequests(c, "account temporarily locked", err.Error())
+	case errors.Is(err, auth.ErrEmailUnchanged) || errors.Is(err, user.ErrEmailInvalidFormat):
+		return s.respondBadRequest(c, "invalid new email", err.Error())
+	case errors.Is(err, user.ErrEmailAlreadyExists):
+		return s.respondConflict(c, "email already exists", err.Error())
+	case errors.Is(err, auth.ErrMailerNotConfigured):
+		return s.respondNotImplemented(c, "mailer not configured", err.Error())
+	default:
+		return s.respondInternalServerError(c, "internal error", err.Error())
+	}
+}
@@ -11,6 +11,12 @@ func (s *Server) RegisterHotelRoutes() {
 	s.Router.GET("/api/hotels/:hotel_id", s.handleGetHotelByID)
 	s.Router.POST("/api/hotels", s.handleAddHotel)
 	s.Router.POST("/api/hotels/upload-images", s.handleUploadHotelImages)
+	s.Router.POST("/api/hotels/upload-images/presign", s.handlePresignHotelImageUpload)
+	s.Router.POST("/api/hotels/upload-images/complete", s.handleCompleteHotelImageUpload)
+	s.Router.POST("/api/hotels/:hotel_id/images", s.handleAddHotelImage)
+	s.Router.PUT("/api/hotels/:hotel_id/images/order", s.handleReorderHotelImages)
+	s.Router.PUT("/api/hotels/:hotel_id/images/:image_id/cover", s.handleSetHotelCoverImage)
+	s.Router.DELETE("/api/hotels/:hotel_id/images/:image_id", s.handleRemoveHotelImage)
 }
 
 // handleListHotels godoc
@@ -57,6 +63,7 @@ func (s *Server) handleGetHotelByID(c echo.Context) error {
 // @Param payload body AddHotelRequest true "Hotel payload"
 // @Success 201 {object} APISuccessResponse
 // @Failure 400 {object} APIErrorResponse
+// @Failure 409 {object} APIErrorResponse
 // @Router /api/hotels [post]
 func (s *Server) handleAddHotel(c echo.Context) error {
 	var req AddHotelRequest
@@ -90,6 +97,7 @@ func (s *Server) handleAddHotel(c echo.Context) error {
 // @Param images formData []file true "Image file. Repeat this field to upload multiple files (e.g. -F \"images=@a.jpg\" -F \"images=@b.jpg\")"
 // @Success 201 {object} APISuccessResponse
 // @Failure 400 {object} APIErrorResponse
+// @Failure 422 {object} APIErrorResponse
 // @Failure 501 {object} APIErrorResponse
 // @Failure 500 {object} APIErrorResponse
 // @Router /api/hotels/upload-images [post]
@@ -97,6 +105,138 @@ func (s *Server) handleUploadHotelImages(c echo.Context) error {
 	return s.handleUploadImages(c, "hotel-images")
 }
 
+// handlePresignHotelImageUpload godoc
+// @Summary Presign Hotel Image Upload
+// @Description Get a presigned PUT URL to upload one image straight to storage. Send the returned `headers` with the PUT; storage rejects a different content type or size. The URL expires after 15 minutes.
+// @Tags hotels
+// @Accept json
+// @Produce json
+// @Param payload body PresignImageUploadRequest true "File to upload"
+// @Success 201 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 501 {object} APIErrorResponse
+// @Router /api/hotels/upload-images/presign [post]
+func (s *Server) handlePresignHotelImageUpload(c echo.Context) error {
+	return s.handlePresignImageUpload(c, "hotel-images")
+}
+
+// handleCompleteHotelImageUpload godoc
+// @Summary Complete Hotel Image Upload
+// @Description Finish a presigned upload: the stored object is checked, its real type sniffed, and renditions are generated as for `upload-images`.
+// @Tags hotels
+// @Accept json
+// @Produce json
+// @Param payload body CompleteImageUploadRequest true "Key returned by presign"
+// @Success 201 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 422 {object} APIErrorResponse
+// @Failure 501 {object} APIErrorResponse
+// @Router /api/hotels/upload-images/complete [post]
+func (s *Server) handleCompleteHotelImageUpload(c echo.Context) error {
+	return s.handleCompleteImageUpload(c, "hotel-images")
+}
+
+// handleAddHotelImage godoc
+// @Summary Add Hotel Image
+// @Description Append an image to the hotel gallery. The first image becomes the cover; `isCover` moves the cover to the new image.
+// @Tags hotels
+// @Accept json
+// @Produce json
+// @Param hotel_id path string true "Hotel ID"
+// @Param payload body HotelImageRequest true "Image payload"
+// @Success 201 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 409 {object} APIErrorResponse
+// @Router /api/hotels/{hotel_id}/images [post]
+func (s *Server) handleAddHotelImage(c echo.Context) error {
+	var req HotelImageRequest
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	created, err := s.HotelService.AddImage(c.Request().Context(), req.ToHotelImage(c.Param("hotel_id")))
+	if err != nil {
+		return err
+	}
+
+	return s.respondCreated(c, toHotelImageResponse(created))
+}
+
+// handleRemoveHotelImage godoc
+// @Summary Remove Hotel Image
+// @Description Remove an image from the hotel gallery and delete the stored file. Removing the cover promotes the next image; the last image cannot be removed.
+// @Tags hotels
+// @Produce json
+// @Param hotel_id path string true "Hotel ID"
+// @Param image_id path string true "Image ID"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Router /api/hotels/{hotel_id}/images/{image_id} [delete]
+func (s *Server) handleRemoveHotelImage(c echo.Context) error {
+	if err := s.HotelService.RemoveImage(c.Request().Context(), c.Param("hotel_id"), c.Param("image_id")); err != nil {
+		return err
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+// handleReorderHotelImages godoc
+// @Summary Reorder Hotel Images
+// @Description Set the gallery order. `imageIds` must list every image of the hotel exactly once.
+// @Tags hotels
+// @Accept json
+// @Produce json
+// @Param hotel_id path string true "Hotel ID"
+// @Param payload body ReorderImagesRequest true "Image order"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Router /api/hotels/{hotel_id}/images/order [put]
+func (s *Server) handleReorderHotelImages(c echo.Context) error {
+	var req ReorderImagesRequest
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	images, err := s.HotelService.ReorderImages(c.Request().Context(), c.Param("hotel_id"), req.ImageIDs)
+	if err != nil {
+		return err
+	}
+
+	return s.respondOK(c, APIDataResult{Data: toHotelImageResponses(images)})
+}
+
+// handleSetHotelCoverImage godoc
+// @Summary Set Hotel Cover Image
+// @Description Make the image the only cover of the hotel.
+// @Tags hotels
+// @Produce json
+// @Param hotel_id path string true "Hotel ID"
+// @Param image_id path string true "Image ID"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Router /api/hotels/{hotel_id}/images/{image_id}/cover [put]
+func (s *Server) handleSetHotelCoverImage(c echo.Context) error {
+	images, err := s.HotelService.SetCoverImage(c.Request().Context(), c.Param("hotel_id"), c.Param("image_id"))
+	if err != nil {
+		return err
+	}
+
+	return s.respondOK(c, APIDataResult{Data: toHotelImageResponses(images)})
+}
+
 func parseHotelTimes(checkIn, checkOut string) (time.Time, time.Time, error) {
 	checkInTime, err := parseClockTime(checkIn)
 	if err != nil {
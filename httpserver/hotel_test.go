@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"hexagon/errs"
 	"hexagon/hotel"
 	"hexagon/httpserver"
 	"hexagon/upload"
@@ -37,12 +38,32 @@ func (m *MockHotelService) AddHotel(ctx context.Context, h hotel.Hotel) (hotel.H
 	return args.Get(0).(hotel.Hotel), args.Error(1)
 }
 
+func (m *MockHotelService) AddImage(ctx context.Context, img hotel.HotelImage) (hotel.HotelImage, error) {
+	args := m.Called(ctx, img)
+	return args.Get(0).(hotel.HotelImage), args.Error(1)
+}
+
+func (m *MockHotelService) RemoveImage(ctx context.Context, hotelID, imageID string) error {
+	args := m.Called(ctx, hotelID, imageID)
+	return args.Error(0)
+}
+
+func (m *MockHotelService) ReorderImages(ctx context.Context, hotelID string, imageIDs []string) ([]hotel.HotelImage, error) {
+	args := m.Called(ctx, hotelID, imageIDs)
+	return args.Get(0).([]hotel.HotelImage), args.Error(1)
+}
+
+func (m *MockHotelService) SetCoverImage(ctx context.Context, hotelID, imageID string) ([]hotel.HotelImage, error) {
+	args := m.Called(ctx, hotelID, imageID)
+	return args.Get(0).([]hotel.HotelImage), args.Error(1)
+}
+
 type MockUploadService struct {
 	mock.Mock
 }
 
-func (m *MockUploadService) UploadImages(ctx context.Context, folder string, files []upload.File) ([]upload.UploadedFile, error) {
-	args := m.Called(ctx, folder, mock.Anything)
+func (m *MockUploadService) UploadImages(ctx context.Context, ownerID, folder string, files []upload.File) ([]upload.UploadedFile, error) {
+	args := m.Called(ctx, ownerID, folder, mock.Anything)
 	if f, ok := args.Get(0).([]upload.UploadedFile); ok {
 		return f, args.Error(1)
 	}
@@ -50,6 +71,26 @@ func (m *MockUploadService) UploadImages(ctx context.Context, folder string, fil
 	return nil, args.Error(1)
 }
 
+func (m *MockUploadService) PresignImageUpload(ctx context.Context, folder string, req upload.PresignRequest) (upload.PresignedUpload, error) {
+	args := m.Called(ctx, folder, req)
+	return args.Get(0).(upload.PresignedUpload), args.Error(1)
+}
+
+func (m *MockUploadService) CompleteImageUpload(ctx context.Context, ownerID, folder, key string) (upload.UploadedFile, error) {
+	args := m.Called(ctx, ownerID, folder, key)
+	return args.Get(0).(upload.UploadedFile), args.Error(1)
+}
+
+func (m *MockUploadService) Attach(ctx context.Context, urls []string) error {
+	args := m.Called(ctx, urls)
+	return args.Error(0)
+}
+
+func (m *MockUploadService) Release(ctx context.Context, url string) error {
+	args := m.Called(ctx, url)
+	return args.Error(0)
+}
+
 func TestHotelRoutes_ListHotels(t *testing.T) {
 	svc := new(MockHotelService)
 	server := httpserver.Default(testConfig())
@@ -162,7 +203,10 @@ func TestHotelRoutes_UploadImages_Success(t *testing.T) {
 		Size:        3,
 		ContentType: "image/jpeg",
 	}}
-	svc.On("UploadImages", mock.Anything, "hotel-images", mock.Anything).Return(expected, nil).Once()
+	svc.On("UploadImages", mock.Anything, "u-1", "hotel-images", mock.Anything).Return(expected, nil).Once()
+
+	token, err := signTestToken()
+	require.NoError(t, err)
 
 	buf := new(bytes.Buffer)
 	writer := multipart.NewWriter(buf)
@@ -175,6 +219,7 @@ func TestHotelRoutes_UploadImages_Success(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPost, "/api/hotels/upload-images", buf)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	rec := httptest.NewRecorder()
 	server.Router.ServeHTTP(rec, req)
@@ -183,3 +228,168 @@ func TestHotelRoutes_UploadImages_Success(t *testing.T) {
 	assert.Contains(t, rec.Body.String(), "\"fileName\":\"a.jpg\"")
 	svc.AssertExpectations(t)
 }
+
+func TestHotelRoutes_UploadImages_Infected(t *testing.T) {
+	svc := new(MockUploadService)
+	server := httpserver.Default(testConfig())
+	server.UploadService = svc
+
+	infected := errs.Errorf(errs.EINFECTED, "upload: a.jpg was rejected by the malware scanner (Eicar-Signature)")
+	svc.On("UploadImages", mock.Anything, "", "hotel-images", mock.Anything).Return([]upload.UploadedFile(nil), infected).Once()
+
+	buf := new(bytes.Buffer)
+	writer := multipart.NewWriter(buf)
+	part, err := writer.CreateFormFile("images", "a.jpg")
+	require.NoError(t, err)
+
+	_, _ = part.Write([]byte("abc"))
+
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/hotels/upload-images", buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), "\"code\":\"100422\"")
+	assert.Contains(t, rec.Body.String(), "malware scanner")
+	svc.AssertExpectations(t)
+}
+
+func TestHotelRoutes_AddImage(t *testing.T) {
+	svc := new(MockHotelService)
+	server := httpserver.Default(testConfig())
+	server.HotelService = svc
+
+	body := []byte(`{"url":"https://cdn.example.com/a.jpg","altText":"Lobby","isCover":true}`)
+	expected := hotel.HotelImage{HotelID: "h-1", URL: "https://cdn.example.com/a.jpg", AltText: "Lobby", IsCover: true}
+	created := expected
+	created.ID = "img-1"
+	created.SortOrder = 2
+	svc.On("AddImage", mock.Anything, expected).Return(created, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/hotels/h-1/images", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), "\"altText\":\"Lobby\"")
+	assert.Contains(t, rec.Body.String(), "\"sortOrder\":2")
+	svc.AssertExpectations(t)
+}
+
+func TestHotelRoutes_ReorderImages(t *testing.T) {
+	svc := new(MockHotelService)
+	server := httpserver.Default(testConfig())
+	server.HotelService = svc
+
+	images := []hotel.HotelImage{{ID: "b", HotelID: "h-1", SortOrder: 0}, {ID: "a", HotelID: "h-1", SortOrder: 1}}
+	svc.On("ReorderImages", mock.Anything, "h-1", []string{"b", "a"}).Return(images, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/hotels/h-1/images/order", bytes.NewReader([]byte(`{"imageIds":["b","a"]}`)))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestHotelRoutes_ReorderImages_EmptyList(t *testing.T) {
+	svc := new(MockHotelService)
+	server := httpserver.Default(testConfig())
+	server.HotelService = svc
+
+	req := httptest.NewRequest(http.MethodPut, "/api/hotels/h-1/images/order", bytes.NewReader([]byte(`{"imageIds":[]}`)))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	svc.AssertNotCalled(t, "ReorderImages", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHotelRoutes_SetCoverAndRemoveImage(t *testing.T) {
+	svc := new(MockHotelService)
+	server := httpserver.Default(testConfig())
+	server.HotelService = svc
+
+	svc.On("SetCoverImage", mock.Anything, "h-1", "img-2").Return([]hotel.HotelImage{{ID: "img-2", IsCover: true}}, nil).Once()
+	svc.On("RemoveImage", mock.Anything, "h-1", "img-1").Return(nil).Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/hotels/h-1/images/img-2/cover", nil)
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/hotels/h-1/images/img-1", nil)
+	rec = httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	svc.AssertExpectations(t)
+}
+
+func TestHotelRoutes_PresignImageUpload(t *testing.T) {
+	svc := new(MockUploadService)
+	server := httpserver.Default(testConfig())
+	server.UploadService = svc
+
+	presigned := upload.PresignedUpload{Key: "hotel-images/k/original.jpg", URL: "https://s3/put", Method: http.MethodPut}
+	svc.On("PresignImageUpload", mock.Anything, "hotel-images", upload.PresignRequest{ContentType: "image/jpeg", Size: 1024}).Return(presigned, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/hotels/upload-images/presign", bytes.NewReader([]byte(`{"contentType":"image/jpeg","size":1024}`)))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), "\"key\":\"hotel-images/k/original.jpg\"")
+	svc.AssertExpectations(t)
+}
+
+func TestHotelRoutes_PresignImageUpload_RejectsType(t *testing.T) {
+	svc := new(MockUploadService)
+	server := httpserver.Default(testConfig())
+	server.UploadService = svc
+
+	req := httptest.NewRequest(http.MethodPost, "/api/hotels/upload-images/presign", bytes.NewReader([]byte(`{"contentType":"text/html","size":1024}`)))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	svc.AssertNotCalled(t, "PresignImageUpload", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHotelRoutes_CompleteImageUpload(t *testing.T) {
+	svc := new(MockUploadService)
+	server := httpserver.Default(testConfig())
+	server.UploadService = svc
+
+	svc.On("CompleteImageUpload", mock.Anything, "", "hotel-images", "missing").Return(upload.UploadedFile{}, upload.ErrUploadNotFound).Once()
+	svc.On("CompleteImageUpload", mock.Anything, "", "hotel-images", "ok").Return(upload.UploadedFile{URL: "https://cdn/full.jpg"}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/hotels/upload-images/complete", bytes.NewReader([]byte(`{"key":"missing"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/hotels/upload-images/complete", bytes.NewReader([]byte(`{"key":"ok"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), "https://cdn/full.jpg")
+
+	svc.AssertExpectations(t)
+}
@@ -0,0 +1,69 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+
+	jwtkeys "hexagon/pkg/jwt"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// TokenKeys is implemented by *jwt.JWTProvider from hexagon/pkg/jwt.
+type TokenKeys interface {
+	VerificationKey(kid, alg string) (any, error)
+	JWKS() jwtkeys.JWKSet
+}
+
+// jwksMaxAge is short enough for services caching the key set to pick up a
+// key published ahead of a rotation well before it signs anything.
+const jwksMaxAge = "public, max-age=300"
+
+func (s *Server) RegisterJWKSRoutes() {
+	s.Router.GET("/.well-known/jwks.json", s.handleJWKS)
+}
+
+// handleJWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys access tokens are signed with, for services that verify them. Empty when tokens are HS256 signed.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} jwtkeys.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (s *Server) handleJWKS(c echo.Context) error {
+	set := jwtkeys.JWKSet{Keys: []jwtkeys.JWK{}}
+	if s.TokenKeys != nil {
+		set = s.TokenKeys.JWKS()
+	}
+
+	c.Response().Header().Set("Cache-Control", jwksMaxAge)
+
+	return c.JSON(http.StatusOK, set)
+}
+
+// accessTokenKey is the jwt.Keyfunc for access tokens. It reads the server
+// fields on each call, so keys set after Default are used. Refresh,
+// two-factor and link tokens are signed with the same keys and rejected
+// here.
+func (s *Server) accessTokenKey(t *jwt.Token) (any, error) {
+	claims, ok := t.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+
+	if tokenType, _ := claims["type"].(string); tokenType != "access" {
+		return nil, errors.New("not an access token")
+	}
+
+	if s.TokenKeys != nil {
+		kid, _ := t.Header["kid"].(string)
+		return s.TokenKeys.VerificationKey(kid, t.Method.Alg())
+	}
+
+	if t.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	return []byte(s.JWTSecret), nil
+}
@@ -0,0 +1,127 @@
+package httpserver_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hexagon/httpserver"
+	jwtkeys "hexagon/pkg/jwt"
+	"hexagon/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTokenProvider(t *testing.T) *jwtkeys.JWTProvider {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+
+	key, err := jwtkeys.ParseSigningKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+
+	return jwtkeys.NewJWTProviderWithKeys(key, nil, time.Minute, time.Hour)
+}
+
+func TestJWKS_WithoutKeysIsEmpty(t *testing.T) {
+	server := httpserver.Default(testConfig())
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"keys":[]}`, rec.Body.String())
+}
+
+func TestJWKS_PublishesPublicKeys(t *testing.T) {
+	provider := newTestTokenProvider(t)
+	server := httpserver.Default(testConfig())
+	server.TokenKeys = provider
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Cache-Control"), "max-age")
+
+	var set jwtkeys.JWKSet
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 1)
+	assert.Equal(t, provider.SigningKey.ID, set.Keys[0].KeyID)
+	assert.Equal(t, "EdDSA", set.Keys[0].Algorithm)
+	assert.NotContains(t, rec.Body.String(), `"d"`, "no private key material")
+}
+
+func TestPrivateRoutes_VerifyAsymmetricTokens(t *testing.T) {
+	provider := newTestTokenProvider(t)
+	svc := new(MockUserService)
+	server := httpserver.Default(testConfig())
+	server.UserService = svc
+	server.TokenKeys = provider
+
+	u := user.User{ID: "u-1", Name: "John", Email: "john@mail.com"}
+	svc.On("GetUserByID", mock.Anything, "u-1").Return(u, nil).Once()
+
+	verifiedAt := time.Now()
+	token, err := provider.GenerateAccessToken(user.User{ID: "u-1", Email: "john@mail.com", EmailVerifiedAt: &verifiedAt}, "s-1")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/u-1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// The shared secret no longer verifies once keys are configured and
+	// the provider has no legacy secret.
+	legacy, err := signTestToken()
+	require.NoError(t, err)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/users/u-1", nil)
+	req.Header.Set("Authorization", "Bearer "+legacy)
+	rec = httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestPrivateRoutes_RejectNonAccessTokens(t *testing.T) {
+	provider := newTestTokenProvider(t)
+	server := httpserver.Default(testConfig())
+	server.UserService = new(MockUserService)
+	server.TokenKeys = provider
+
+	verifiedAt := time.Now()
+	u := user.User{ID: "u-1", Email: "john@mail.com", EmailVerifiedAt: &verifiedAt}
+
+	refresh, err := provider.GenerateRefreshToken(u)
+	require.NoError(t, err)
+
+	challenge, err := provider.GenerateTwoFactorToken(u)
+	require.NoError(t, err)
+
+	for name, token := range map[string]string{"refresh": refresh, "2fa": challenge} {
+		req := httptest.NewRequest(http.MethodGet, "/api/users/u-1", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		server.Router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, name)
+	}
+}
@@ -1,7 +1,12 @@
 package httpserver
 
 import (
+	"context"
+	"strings"
+
+	"hexagon/audit"
 	"hexagon/auth"
+	"hexagon/user"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
@@ -34,6 +39,86 @@ func (s *Server) requireVerifiedEmail() echo.MiddlewareFunc {
 	}
 }
 
+// TokenRevocations is implemented by auth.Usecase.
+type TokenRevocations interface {
+	IsAccessTokenRevoked(ctx context.Context, token auth.AccessTokenClaims) (bool, error)
+}
+
+func (s *Server) rejectRevokedTokens() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if s.TokenRevocations == nil {
+				return next(c)
+			}
+
+			token, ok := c.Get("user").(*jwt.Token)
+			if !ok || token == nil {
+				return s.respondUnauthorized(c, "invalid access token", "missing jwt context")
+			}
+
+			claims, ok := token.Claims.(jwt.MapClaims)
+			if !ok {
+				return s.respondUnauthorized(c, "invalid access token", "invalid jwt claims")
+			}
+
+			revoked, err := s.TokenRevocations.IsAccessTokenRevoked(c.Request().Context(), accessTokenClaims(claims))
+			if err != nil {
+				return s.respondInternalServerError(c, "internal error", err.Error())
+			}
+
+			if revoked {
+				return s.respondUnauthorized(c, "access token revoked", "access token has been revoked")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// requireRole only lets through access tokens issued to users with role.
+// The role is read from the token, so a changed role applies from the next
+// token on.
+func (s *Server) requireRole(role user.UserRole) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := c.Get("user").(*jwt.Token)
+			if !ok || token == nil {
+				return s.respondUnauthorized(c, "invalid access token", "missing jwt context")
+			}
+
+			claims, ok := token.Claims.(jwt.MapClaims)
+			if !ok {
+				return s.respondUnauthorized(c, "invalid access token", "invalid jwt claims")
+			}
+
+			if claimRole, _ := claims["role"].(string); user.UserRole(claimRole) != role {
+				return s.respondForbidden(c, "forbidden", "requires role "+string(role))
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// auditRequest passes the client of the request, and on private routes the
+// logged in user, to the audit log.
+func (s *Server) auditRequest() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			actorID, _, _ := sessionFromContext(c)
+
+			ctx := audit.WithRequest(c.Request().Context(), audit.Request{
+				ActorID:   actorID,
+				IPAddress: c.RealIP(),
+				UserAgent: c.Request().UserAgent(),
+			})
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
+
 func claimBool(value any) (bool, bool) {
 	switch v := value.(type) {
 	case bool:
@@ -57,3 +142,34 @@ func claimBool(value any) (bool, bool) {
 		return false, false
 	}
 }
+
+// optionalUserID returns the user id of a valid bearer token, or "" for
+// anonymous requests and invalid tokens. Public routes use it to attribute
+// actions without requiring a login.
+func (s *Server) optionalUserID(c echo.Context) string {
+	raw, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	if !ok || (s.TokenKeys == nil && s.JWTSecret == "") {
+		return ""
+	}
+
+	token, err := jwt.Parse(strings.TrimSpace(raw), s.accessTokenKey)
+	if err != nil || !token.Valid {
+		return ""
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+
+	if s.TokenRevocations != nil {
+		revoked, err := s.TokenRevocations.IsAccessTokenRevoked(c.Request().Context(), accessTokenClaims(claims))
+		if err != nil || revoked {
+			return ""
+		}
+	}
+
+	userID, _ := userIDFromClaims(claims)
+
+	return userID
+}
@@ -0,0 +1,71 @@
+package httpserver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hexagon/auth"
+	"hexagon/httpserver"
+	"hexagon/user"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type stubTokenRevocations struct {
+	revokedJTI string
+	checked    []auth.AccessTokenClaims
+}
+
+func (s *stubTokenRevocations) IsAccessTokenRevoked(ctx context.Context, token auth.AccessTokenClaims) (bool, error) {
+	s.checked = append(s.checked, token)
+	return token.ID == s.revokedJTI, nil
+}
+
+func signTestTokenWithID(jti string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":            "u-1",
+		"type":           "access",
+		"jti":            jti,
+		"email":          "john@mail.com",
+		"email_verified": true,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+}
+
+func TestPrivateRoutes_RejectRevokedTokens(t *testing.T) {
+	svc := new(MockUserService)
+	revocations := &stubTokenRevocations{revokedJTI: "revoked"}
+	server := httpserver.Default(testConfig())
+	server.UserService = svc
+	server.TokenRevocations = revocations
+
+	svc.On("GetUserByID", mock.Anything, "u-1").Return(user.User{ID: "u-1"}, nil).Once()
+
+	for jti, status := range map[string]int{"valid": http.StatusOK, "revoked": http.StatusUnauthorized} {
+		token, err := signTestTokenWithID(jti)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/users/u-1", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		server.Router.ServeHTTP(rec, req)
+
+		assert.Equal(t, status, rec.Code, jti)
+	}
+
+	require.Len(t, revocations.checked, 2)
+	assert.Equal(t, "u-1", revocations.checked[0].UserID)
+	assert.False(t, revocations.checked[0].IssuedAt.IsZero())
+	assert.False(t, revocations.checked[0].ExpiresAt.IsZero())
+	svc.AssertExpectations(t)
+}
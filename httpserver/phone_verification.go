@@ -0,0 +1,91 @@
+package httpserver
+
+import (
+	"errors"
+
+	"hexagon/auth"
+
+	"github.com/labstack/echo/v4"
+)
+
+// handleSendPhoneVerification godoc
+// @Summary Send Phone Verification Code
+// @Description Text a 6-digit code to the phone number of the current user. A new code replaces the pending one; codes can be resent once a minute.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 409 {object} APIErrorResponse
+// @Failure 429 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Failure 501 {object} APIErrorResponse
+// @Router /api/auth/phone/verify/send [post]
+func (s *Server) handleSendPhoneVerification(c echo.Context) error {
+	userID, _, ok := sessionFromContext(c)
+	if !ok {
+		return s.respondUnauthorized(c, "invalid access token", "missing user id claim")
+	}
+
+	if err := s.AuthService.SendPhoneVerification(c.Request().Context(), userID); err != nil {
+		return s.respondPhoneVerificationError(c, err)
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+// handleVerifyPhone godoc
+// @Summary Verify Phone
+// @Description Mark the phone number of the current user verified with the code sent by /api/auth/phone/verify/send. The code expires after 10 minutes or 5 wrong attempts.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body VerifyPhoneRequest true "Verify phone payload"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 429 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Failure 501 {object} APIErrorResponse
+// @Router /api/auth/phone/verify [post]
+func (s *Server) handleVerifyPhone(c echo.Context) error {
+	userID, _, ok := sessionFromContext(c)
+	if !ok {
+		return s.respondUnauthorized(c, "invalid access token", "missing user id claim")
+	}
+
+	var req VerifyPhoneRequest
+
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := s.AuthService.VerifyPhone(c.Request().Context(), userID, req.Code); err != nil {
+		return s.respondPhoneVerificationError(c, err)
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+func (s *Server) respondPhoneVerificationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, auth.ErrPhoneMissing):
+		return s.respondBadRequest(c, "phone number is missing", err.Error())
+	case errors.Is(err, auth.ErrInvalidPhoneCode):
+		return s.respondBadRequest(c, "invalid verification code", err.Error())
+	case errors.Is(err, auth.ErrPhoneAlreadyVerified):
+		return s.respondConflict(c, "phone number already verified", err.Error())
+	case errors.Is(err, auth.ErrPhoneCodeRecentlySent) || errors.Is(err, auth.ErrTooManyPhoneCodeAttempts):
+		return s.respondTooManyRequests(c, "too many requests", err.Error())
+	case errors.Is(err, auth.ErrPhoneVerificationNotConfigured):
+		return s.respondNotImplemented(c, "phone verification not configured", err.Error())
+	default:
+		return s.respondInternalServerError(c, "internal error", err.Error())
+	}
+}
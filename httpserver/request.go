@@ -1,12 +1,12 @@
 package httpserver
 
 import (
-	"encoding/json"
 	"time"
 
 	"hexagon/hotel"
 	"hexagon/room"
 	"hexagon/search"
+	"hexagon/upload"
 	"hexagon/user"
 )
 
@@ -38,6 +38,20 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required,notblank,max=72"`
 }
 
+type VerifyTwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challengeToken" validate:"required,notblank"`
+	// Code is a TOTP code or a recovery code.
+	Code string `json:"code" validate:"required,notblank,max=32"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" validate:"required,notblank,max=32"`
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code" validate:"required,notblank,max=32"`
+}
+
 type UpdateProfileRequest struct {
 	Name  string `json:"name" validate:"required,notblank,min=2,max=100"`
 	Phone string `json:"phone" validate:"omitempty,numeric,len=10"`
@@ -48,10 +62,22 @@ type ChangePasswordRequest struct {
 	NewPassword     string `json:"newPassword" validate:"required,notblank,password,nefield=CurrentPassword"`
 }
 
+type ChangeUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user admin" example:"admin"`
+}
+
 type RefreshRequest struct {
 	RefreshToken string `json:"refreshToken" validate:"required,notblank"`
 }
 
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+type MagicLinkLoginRequest struct {
+	Token string `json:"token" validate:"required,notblank"`
+}
+
 type ForgotPasswordRequest struct {
 	Email string `json:"email" validate:"required,email,max=255"`
 }
@@ -64,6 +90,16 @@ type VerifyEmailRequest struct {
 	Token string `json:"token" validate:"required,notblank"`
 }
 
+type DenySignInRequest struct {
+	Token string `json:"token" validate:"required,notblank"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"newEmail" validate:"required,email,max=255"`
+	// Password is required for accounts that have one.
+	Password string `json:"password" validate:"max=72"`
+}
+
 type ResetPasswordRequest struct {
 	Token       string `json:"token" validate:"required,notblank"`
 	NewPassword string `json:"newPassword" validate:"required,notblank,password"`
@@ -75,9 +111,32 @@ type LogoutRequest struct {
 
 type HotelImageRequest struct {
 	URL     string `json:"url" validate:"required,notblank,max=1000"`
+	AltText string `json:"altText" validate:"omitempty,max=500"`
 	IsCover bool   `json:"isCover"`
 }
 
+func (r HotelImageRequest) ToHotelImage(hotelID string) hotel.HotelImage {
+	return hotel.HotelImage{
+		HotelID: hotelID,
+		URL:     r.URL,
+		AltText: r.AltText,
+		IsCover: r.IsCover,
+	}
+}
+
+type PresignImageUploadRequest struct {
+	ContentType string `json:"contentType" validate:"required,oneof=image/jpeg image/png image/gif image/webp" example:"image/jpeg"`
+	Size        int64  `json:"size" validate:"required,gt=0" example:"524288"`
+}
+
+func (r PresignImageUploadRequest) ToPresignRequest() upload.PresignRequest {
+	return upload.PresignRequest{ContentType: r.ContentType, Size: r.Size}
+}
+
+type CompleteImageUploadRequest struct {
+	Key string `json:"key" validate:"required,notblank"`
+}
+
 type HotelPaymentOptionRequest struct {
 	PaymentOption string `json:"paymentOption" validate:"required,notblank,oneof=immediate pay_at_hotel deferred"`
 	Enabled       bool   `json:"enabled"`
@@ -112,10 +171,7 @@ func (r AddHotelRequest) ToHotel(checkIn, checkOut time.Time) hotel.Hotel {
 func toHotelImages(req []HotelImageRequest) []hotel.HotelImage {
 	result := make([]hotel.HotelImage, len(req))
 	for i := range req {
-		result[i] = hotel.HotelImage{
-			URL:     req[i].URL,
-			IsCover: req[i].IsCover,
-		}
+		result[i] = req[i].ToHotelImage("")
 	}
 
 	return result
@@ -135,9 +191,23 @@ func toHotelPaymentOptions(req []HotelPaymentOptionRequest) []hotel.HotelPayment
 
 type RoomImageRequest struct {
 	URL     string `json:"url" validate:"required,notblank,max=1000" example:"https://cdn.example.com/rooms/deluxe-1.jpg"`
+	AltText string `json:"altText" validate:"omitempty,max=500" example:"Deluxe room with city view"`
 	IsCover bool   `json:"isCover" example:"true"`
 }
 
+func (r RoomImageRequest) ToRoomImage(roomID string) room.RoomImage {
+	return room.RoomImage{
+		RoomID:  roomID,
+		URL:     r.URL,
+		AltText: r.AltText,
+		IsCover: r.IsCover,
+	}
+}
+
+type ReorderImagesRequest struct {
+	ImageIDs []string `json:"imageIds" validate:"required,min=1,dive,required,notblank"`
+}
+
 type AddRoomRequest struct {
 	HotelID      string             `json:"hotelId" validate:"required,notblank" example:"6d3f8c67-f3f4-4e8f-8c89-c5ff3f2c1244"`
 	Name         string             `json:"name" validate:"required,notblank,max=255" example:"Deluxe Twin Room"`
@@ -146,7 +216,7 @@ type AddRoomRequest struct {
 	MaxAdult     int                `json:"maxAdult" validate:"required,gt=0" example:"2"`
 	MaxChild     int                `json:"maxChild" validate:"gte=0" example:"1"`
 	MaxOccupancy int                `json:"maxOccupancy" validate:"required,gt=0" example:"3"`
-	BedOptions   json.RawMessage    `json:"bedOptions" swaggertype:"string" example:"{\"beds\":[{\"type\":\"queen\",\"count\":2}]}"`
+	BedOptions   []BedOptionRequest `json:"bedOptions" validate:"omitempty,dive"`
 	SizeSqm      int                `json:"sizeSqm" validate:"gte=0" example:"35"`
 	Status       room.RoomStatus    `json:"status" validate:"omitempty,oneof=active inactive" example:"active"`
 	Images       []RoomImageRequest `json:"images" validate:"required,min=1,dive"`
@@ -162,7 +232,7 @@ func (r AddRoomRequest) ToRoom() room.Room {
 		MaxAdult:     r.MaxAdult,
 		MaxChild:     r.MaxChild,
 		MaxOccupancy: r.MaxOccupancy,
-		BedOptions:   r.BedOptions,
+		BedOptions:   toBedOptions(r.BedOptions),
 		SizeSqm:      r.SizeSqm,
 		Status:       r.Status,
 		Images:       toRoomImages(r.Images),
@@ -170,23 +240,48 @@ func (r AddRoomRequest) ToRoom() room.Room {
 	}
 }
 
+type BedOptionRequest struct {
+	Type         room.BedType `json:"type" validate:"required,oneof=single twin double queen king bunk sofa_bed" example:"twin"`
+	Count        int          `json:"count" validate:"required,gt=0" example:"2"`
+	MaxExtraBeds int          `json:"maxExtraBeds" validate:"gte=0" example:"1"`
+	ExtraBedFee  float64      `json:"extraBedFee" validate:"gte=0" example:"300000"`
+	CotAvailable bool         `json:"cotAvailable" example:"true"`
+	CotFee       float64      `json:"cotFee" validate:"gte=0" example:"0"`
+}
+
+func toBedOptions(req []BedOptionRequest) []room.BedOption {
+	result := make([]room.BedOption, len(req))
+	for i := range req {
+		result[i] = room.BedOption{
+			Type:         req[i].Type,
+			Count:        req[i].Count,
+			MaxExtraBeds: req[i].MaxExtraBeds,
+			ExtraBedFee:  req[i].ExtraBedFee,
+			CotAvailable: req[i].CotAvailable,
+			CotFee:       req[i].CotFee,
+		}
+	}
+
+	return result
+}
+
 func toRoomImages(req []RoomImageRequest) []room.RoomImage {
 	result := make([]room.RoomImage, len(req))
 	for i := range req {
-		result[i] = room.RoomImage{
-			URL:     req[i].URL,
-			IsCover: req[i].IsCover,
-		}
+		result[i] = req[i].ToRoomImage("")
 	}
 
 	return result
 }
 
 type AddRoomAmenityRequest struct {
-	Code        string `json:"code" validate:"required,notblank,max=100"`
-	Name        string `json:"name" validate:"required,notblank,max=255"`
-	Description string `json:"description" validate:"omitempty,max=2000"`
-	Icon        string `json:"icon" validate:"omitempty,max=255"`
+	Code        string               `json:"code" validate:"required,notblank,max=100"`
+	Name        string               `json:"name" validate:"required,notblank,max=255"`
+	Description string               `json:"description" validate:"omitempty,max=2000"`
+	Icon        string               `json:"icon" validate:"omitempty,max=255"`
+	Category    room.AmenityCategory `json:"category" validate:"omitempty,oneof=general bathroom tech view" example:"bathroom"`
+	SortOrder   int                  `json:"sortOrder" validate:"gte=0" example:"10"`
+	Names       map[string]string    `json:"names" validate:"omitempty,dive,keys,notblank,max=10,endkeys,required,notblank,max=255"`
 }
 
 func (r AddRoomAmenityRequest) ToRoomAmenity() room.RoomAmenity {
@@ -195,9 +290,16 @@ func (r AddRoomAmenityRequest) ToRoomAmenity() room.RoomAmenity {
 		Name:        r.Name,
 		Description: r.Description,
 		Icon:        r.Icon,
+		Category:    r.Category,
+		SortOrder:   r.SortOrder,
+		Names:       r.Names,
 	}
 }
 
+type ReplaceRoomAmenitiesRequest struct {
+	AmenityIDs []string `json:"amenityIds" validate:"omitempty,dive,required,notblank" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
+
 type AddRoomInventoryRequest struct {
 	Date            string `json:"date" validate:"required,notblank"`
 	TotalInventory  int    `json:"totalInventory" validate:"gte=0"`
@@ -205,6 +307,16 @@ type AddRoomInventoryRequest struct {
 	BookedInventory int    `json:"bookedInventory" validate:"gte=0"`
 }
 
+type SetStayRestrictionsRequest struct {
+	From              string `json:"from" validate:"required,notblank" example:"2026-05-01"`
+	To                string `json:"to" validate:"required,notblank" example:"2026-05-31"`
+	MinStay           int    `json:"minStay" validate:"gte=0"`
+	MaxStay           int    `json:"maxStay" validate:"gte=0"`
+	ClosedToArrival   bool   `json:"closedToArrival"`
+	ClosedToDeparture bool   `json:"closedToDeparture"`
+	StopSell          bool   `json:"stopSell"`
+}
+
 type SearchHotelsRequest struct {
 	Query          string   `json:"query" validate:"required,notblank" example:"ha noi"`
 	CheckInAt      string   `json:"checkInAt" validate:"required,notblank,datetime=2006-01-02,date_not_past,date_within_booking_window" example:"2026-04-01"`
@@ -215,6 +327,8 @@ type SearchHotelsRequest struct {
 	RatingMin      float64  `json:"ratingMin" validate:"gte=0,lte=5" example:"4"`
 	AmenityIDs     []string `json:"amenityIds" validate:"omitempty,dive,required,notblank"`
 	PaymentOptions []string `json:"paymentOptions" validate:"omitempty,dive,oneof=immediate pay_at_hotel deferred" example:"immediate,pay_at_hotel"`
+	BedType        string   `json:"bedType" validate:"omitempty,oneof=single twin double queen king bunk sofa_bed" example:"twin"`
+	ExtraBeds      int      `json:"extraBeds" validate:"gte=0" example:"0"`
 	Page           int      `json:"page" validate:"omitempty,gte=1" example:"1"`
 	PageSize       int      `json:"pageSize" validate:"omitempty,gte=1,lte=100" example:"10"`
 	Offset         int      `json:"offset" validate:"omitempty,gte=0" example:"0"`
@@ -231,6 +345,8 @@ func (r SearchHotelsRequest) ToCriteria(checkInAt, checkOutAt time.Time) search.
 		RatingMin:      r.RatingMin,
 		AmenityIDs:     r.AmenityIDs,
 		PaymentOptions: r.PaymentOptions,
+		BedType:        r.BedType,
+		ExtraBeds:      r.ExtraBeds,
 	}
 }
 
@@ -277,6 +393,8 @@ type SearchHotelRoomsRequest struct {
 	AdultCount   int      `json:"adultCount" validate:"required,gt=0" example:"3"`
 	ChildrenAges []int    `json:"childrenAges" validate:"omitempty,dive,gte=0,lte=17" example:"5"`
 	AmenityIDs   []string `json:"amenityIds" validate:"omitempty,dive,required,notblank"`
+	BedType      string   `json:"bedType" validate:"omitempty,oneof=single twin double queen king bunk sofa_bed" example:"twin"`
+	ExtraBeds    int      `json:"extraBeds" validate:"gte=0" example:"0"`
 }
 
 func (r SearchHotelRoomsRequest) ToCriteria(checkInAt, checkOutAt time.Time) search.Criteria {
@@ -287,6 +405,8 @@ func (r SearchHotelRoomsRequest) ToCriteria(checkInAt, checkOutAt time.Time) sea
 		ChildrenAges: r.ChildrenAges,
 		RoomCount:    r.RoomCount,
 		AmenityIDs:   r.AmenityIDs,
+		BedType:      r.BedType,
+		ExtraBeds:    r.ExtraBeds,
 	}
 }
 
@@ -297,6 +417,8 @@ type SearchHotelRoomCombinationsRequest struct {
 	AdultCount      int      `json:"adultCount" validate:"required,gt=0" example:"5"`
 	ChildrenAges    []int    `json:"childrenAges" validate:"omitempty,dive,gte=0,lte=17" example:"5"`
 	AmenityIDs      []string `json:"amenityIds" validate:"omitempty,dive,required,notblank"`
+	BedType         string   `json:"bedType" validate:"omitempty,oneof=single twin double queen king bunk sofa_bed" example:"twin"`
+	ExtraBeds       int      `json:"extraBeds" validate:"gte=0" example:"0"`
 	MaxCombinations int      `json:"maxCombinations" validate:"gte=0" example:"5"`
 }
 
@@ -308,5 +430,7 @@ func (r SearchHotelRoomCombinationsRequest) ToCriteria(checkInAt, checkOutAt tim
 		ChildrenAges: r.ChildrenAges,
 		RoomCount:    r.RoomCount,
 		AmenityIDs:   r.AmenityIDs,
+		BedType:      r.BedType,
+		ExtraBeds:    r.ExtraBeds,
 	}
 }
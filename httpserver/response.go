@@ -2,28 +2,51 @@
 package httpserver
 
 import (
-	"encoding/json"
 	"net/http"
 	"strconv"
 	"time"
 
+	"hexagon/audit"
+	"hexagon/auth"
 	"hexagon/hotel"
 	"hexagon/room"
 	"hexagon/search"
+	"hexagon/upload"
 	"hexagon/user"
 
 	"github.com/labstack/echo/v4"
 )
 
 type UserResponse struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Phone     string    `json:"phone"`
-	Role      string    `json:"role"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	// PhoneVerifiedAt is null until the current phone number is verified.
+	PhoneVerifiedAt *time.Time `json:"phoneVerifiedAt"`
+	Role            string     `json:"role"`
+	Status          string     `json:"status"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+	// DeletionScheduledAt is set while a requested account deletion can
+	// still be cancelled.
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty"`
+}
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"userAgent"`
+	IPAddress  string    `json:"ipAddress"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	Current    bool      `json:"current"`
+}
+
+type OAuthAccountResponse struct {
+	Provider string    `json:"provider"`
+	Email    string    `json:"email"`
+	LinkedAt time.Time `json:"linkedAt"`
 }
 
 type APIErrorResponse struct {
@@ -50,8 +73,10 @@ var defaultHTTPStatusCodeMapper = HTTPStatusCodeMapper{
 	codes: map[int]string{
 		http.StatusBadRequest:          "100400",
 		http.StatusUnauthorized:        "100401",
+		http.StatusForbidden:           "100403",
 		http.StatusNotFound:            "100404",
 		http.StatusConflict:            "100409",
+		http.StatusUnprocessableEntity: "100422",
 		http.StatusNotImplemented:      "100501",
 		http.StatusInternalServerError: "100500",
 	},
@@ -77,6 +102,10 @@ func (s *Server) respondUnauthorized(c echo.Context, message, info string) error
 	return s.respondError(c, http.StatusUnauthorized, message, info)
 }
 
+func (s *Server) respondForbidden(c echo.Context, message, info string) error {
+	return s.respondError(c, http.StatusForbidden, message, info)
+}
+
 func (s *Server) respondNotFound(c echo.Context, message, info string) error {
 	return s.respondError(c, http.StatusNotFound, message, info)
 }
@@ -85,6 +114,10 @@ func (s *Server) respondConflict(c echo.Context, message, info string) error {
 	return s.respondError(c, http.StatusConflict, message, info)
 }
 
+func (s *Server) respondUnprocessableEntity(c echo.Context, message, info string) error {
+	return s.respondError(c, http.StatusUnprocessableEntity, message, info)
+}
+
 func (s *Server) respondInternalServerError(c echo.Context, message, info string) error {
 	return s.respondError(c, http.StatusInternalServerError, message, info)
 }
@@ -123,17 +156,53 @@ func (m HTTPStatusCodeMapper) Code(httpStatus int) string {
 
 func toUserResponse(u user.User) UserResponse {
 	return UserResponse{
-		ID:        u.ID,
-		Name:      u.Name,
-		Email:     u.Email,
-		Phone:     u.Phone,
-		Role:      string(u.Role),
-		Status:    string(u.Status),
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:                  u.ID,
+		Name:                u.Name,
+		Email:               u.Email,
+		Phone:               u.Phone,
+		PhoneVerifiedAt:     u.PhoneVerifiedAt,
+		Role:                string(u.Role),
+		Status:              string(u.Status),
+		CreatedAt:           u.CreatedAt,
+		UpdatedAt:           u.UpdatedAt,
+		DeletionScheduledAt: u.DeletionScheduledAt,
+	}
+}
+
+func toSessionResponses(sessions []auth.Session) []SessionResponse {
+	resp := make([]SessionResponse, len(sessions))
+	for i, s := range sessions {
+		resp[i] = SessionResponse{
+			ID:         s.ID,
+			Device:     s.Device,
+			UserAgent:  s.UserAgent,
+			IPAddress:  s.IPAddress,
+			CreatedAt:  s.CreatedAt,
+			LastUsedAt: s.LastUsedAt,
+			Current:    s.Current,
+		}
+	}
+
+	return resp
+}
+
+func toOAuthAccountResponse(account auth.OAuthAccount) OAuthAccountResponse {
+	return OAuthAccountResponse{
+		Provider: string(account.Provider),
+		Email:    account.ProviderEmail,
+		LinkedAt: account.LinkedAt,
 	}
 }
 
+func toOAuthAccountResponses(accounts []auth.OAuthAccount) []OAuthAccountResponse {
+	resp := make([]OAuthAccountResponse, len(accounts))
+	for i, account := range accounts {
+		resp[i] = toOAuthAccountResponse(account)
+	}
+
+	return resp
+}
+
 func toUserResponses(users []user.User) []UserResponse {
 	resp := make([]UserResponse, len(users))
 	for i, u := range users {
@@ -160,10 +229,12 @@ type HotelResponse struct {
 }
 
 type HotelImageResponse struct {
-	ID      string `json:"id"`
-	HotelID string `json:"hotelId"`
-	URL     string `json:"url"`
-	IsCover bool   `json:"isCover"`
+	ID        string `json:"id"`
+	HotelID   string `json:"hotelId"`
+	URL       string `json:"url"`
+	AltText   string `json:"altText"`
+	IsCover   bool   `json:"isCover"`
+	SortOrder int    `json:"sortOrder"`
 }
 
 type HotelPaymentOptionResponse struct {
@@ -178,23 +249,79 @@ type UploadImagesResponse struct {
 }
 
 type UploadedImageResponse struct {
-	FileName    string `json:"fileName"`
+	FileName    string                 `json:"fileName"`
+	URL         string                 `json:"url"`
+	Size        int64                  `json:"size"`
+	ContentType string                 `json:"contentType"`
+	Width       int                    `json:"width"`
+	Height      int                    `json:"height"`
+	Variants    []ImageVariantResponse `json:"variants"`
+}
+
+type PresignedUploadResponse struct {
+	Key       string            `json:"key"`
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+type ImageVariantResponse struct {
+	Name        string `json:"name"`
 	URL         string `json:"url"`
 	Size        int64  `json:"size"`
 	ContentType string `json:"contentType"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
+
+func toUploadedImageResponse(f upload.UploadedFile) UploadedImageResponse {
+	variants := make([]ImageVariantResponse, len(f.Variants))
+	for i := range f.Variants {
+		variants[i] = ImageVariantResponse{
+			Name:        f.Variants[i].Name,
+			URL:         f.Variants[i].URL,
+			Size:        f.Variants[i].Size,
+			ContentType: f.Variants[i].ContentType,
+			Width:       f.Variants[i].Width,
+			Height:      f.Variants[i].Height,
+		}
+	}
+
+	return UploadedImageResponse{
+		FileName:    f.FileName,
+		URL:         f.URL,
+		Size:        f.Size,
+		ContentType: f.ContentType,
+		Width:       f.Width,
+		Height:      f.Height,
+		Variants:    variants,
+	}
 }
 
-func toHotelResponse(h hotel.Hotel) HotelResponse {
-	images := make([]HotelImageResponse, len(h.Images))
-	for i := range h.Images {
-		images[i] = HotelImageResponse{
-			ID:      h.Images[i].ID,
-			HotelID: h.Images[i].HotelID,
-			URL:     h.Images[i].URL,
-			IsCover: h.Images[i].IsCover,
-		}
+func toHotelImageResponse(img hotel.HotelImage) HotelImageResponse {
+	return HotelImageResponse{
+		ID:        img.ID,
+		HotelID:   img.HotelID,
+		URL:       img.URL,
+		AltText:   img.AltText,
+		IsCover:   img.IsCover,
+		SortOrder: img.SortOrder,
+	}
+}
+
+func toHotelImageResponses(images []hotel.HotelImage) []HotelImageResponse {
+	result := make([]HotelImageResponse, len(images))
+	for i := range images {
+		result[i] = toHotelImageResponse(images[i])
 	}
 
+	return result
+}
+
+func toHotelResponse(h hotel.Hotel) HotelResponse {
+	images := toHotelImageResponses(h.Images)
+
 	paymentOptions := make([]HotelPaymentOptionResponse, len(h.PaymentOptions))
 	for i := range h.PaymentOptions {
 		paymentOptions[i] = HotelPaymentOptionResponse{
@@ -240,7 +367,7 @@ type RoomResponse struct {
 	MaxAdult     int                   `json:"maxAdult"`
 	MaxChild     int                   `json:"maxChild"`
 	MaxOccupancy int                   `json:"maxOccupancy"`
-	BedOptions   any                   `json:"bedOptions"`
+	BedOptions   []BedOptionResponse   `json:"bedOptions"`
 	SizeSqm      int                   `json:"sizeSqm"`
 	Status       string                `json:"status"`
 	Images       []RoomImageResponse   `json:"images"`
@@ -249,21 +376,35 @@ type RoomResponse struct {
 	UpdatedAt    time.Time             `json:"updatedAt"`
 }
 
+type BedOptionResponse struct {
+	Type         string  `json:"type"`
+	Count        int     `json:"count"`
+	MaxExtraBeds int     `json:"maxExtraBeds"`
+	ExtraBedFee  float64 `json:"extraBedFee"`
+	CotAvailable bool    `json:"cotAvailable"`
+	CotFee       float64 `json:"cotFee"`
+}
+
 type RoomImageResponse struct {
-	ID      string `json:"id"`
-	RoomID  string `json:"roomId"`
-	URL     string `json:"url"`
-	IsCover bool   `json:"isCover"`
+	ID        string `json:"id"`
+	RoomID    string `json:"roomId"`
+	URL       string `json:"url"`
+	AltText   string `json:"altText"`
+	IsCover   bool   `json:"isCover"`
+	SortOrder int    `json:"sortOrder"`
 }
 
 type RoomAmenityResponse struct {
-	ID          string    `json:"id"`
-	Code        string    `json:"code"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Icon        string    `json:"icon"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          string            `json:"id"`
+	Code        string            `json:"code"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Icon        string            `json:"icon"`
+	Category    string            `json:"category"`
+	SortOrder   int               `json:"sortOrder"`
+	Names       map[string]string `json:"names"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
 }
 
 type RoomInventoryResponse struct {
@@ -275,17 +416,58 @@ type RoomInventoryResponse struct {
 	BookedInventory int       `json:"bookedInventory"`
 }
 
-func toRoomResponse(r room.Room) RoomResponse {
-	images := make([]RoomImageResponse, len(r.Images))
-	for i := range r.Images {
-		images[i] = RoomImageResponse{
-			ID:      r.Images[i].ID,
-			RoomID:  r.Images[i].RoomID,
-			URL:     r.Images[i].URL,
-			IsCover: r.Images[i].IsCover,
-		}
+type RoomInventoryCalendarResponse struct {
+	RoomID       string                     `json:"roomId"`
+	RoomName     string                     `json:"roomName"`
+	From         string                     `json:"from"`
+	To           string                     `json:"to"`
+	Days         []RoomInventoryDayResponse `json:"days"`
+	MissingDates []string                   `json:"missingDates"`
+}
+
+type RoomInventoryDayResponse struct {
+	Date               string `json:"date"`
+	TotalInventory     int    `json:"totalInventory"`
+	HeldInventory      int    `json:"heldInventory"`
+	BookedInventory    int    `json:"bookedInventory"`
+	AvailableInventory int    `json:"availableInventory"`
+	Missing            bool   `json:"missing"`
+}
+
+type StayRestrictionResponse struct {
+	ID                string `json:"id"`
+	RoomID            string `json:"roomId"`
+	Date              string `json:"date"`
+	MinStay           int    `json:"minStay"`
+	MaxStay           int    `json:"maxStay"`
+	ClosedToArrival   bool   `json:"closedToArrival"`
+	ClosedToDeparture bool   `json:"closedToDeparture"`
+	StopSell          bool   `json:"stopSell"`
+}
+
+func toRoomImageResponse(img room.RoomImage) RoomImageResponse {
+	return RoomImageResponse{
+		ID:        img.ID,
+		RoomID:    img.RoomID,
+		URL:       img.URL,
+		AltText:   img.AltText,
+		IsCover:   img.IsCover,
+		SortOrder: img.SortOrder,
+	}
+}
+
+func toRoomImageResponses(images []room.RoomImage) []RoomImageResponse {
+	result := make([]RoomImageResponse, len(images))
+	for i := range images {
+		result[i] = toRoomImageResponse(images[i])
 	}
 
+	return result
+}
+
+func toRoomResponse(r room.Room) RoomResponse {
+	images := toRoomImageResponses(r.Images)
+
 	amenities := make([]RoomAmenityResponse, len(r.Amenities))
 	for i := range r.Amenities {
 		amenities[i] = toRoomAmenityResponse(r.Amenities[i])
@@ -300,7 +482,7 @@ func toRoomResponse(r room.Room) RoomResponse {
 		MaxAdult:     r.MaxAdult,
 		MaxChild:     r.MaxChild,
 		MaxOccupancy: r.MaxOccupancy,
-		BedOptions:   jsonValueOrEmptyArray(r.BedOptions),
+		BedOptions:   toBedOptionResponses(r.BedOptions),
 		SizeSqm:      r.SizeSqm,
 		Status:       string(r.Status),
 		Images:       images,
@@ -317,6 +499,9 @@ func toRoomAmenityResponse(a room.RoomAmenity) RoomAmenityResponse {
 		Name:        a.Name,
 		Description: a.Description,
 		Icon:        a.Icon,
+		Category:    string(a.Category),
+		SortOrder:   a.SortOrder,
+		Names:       a.Names,
 		CreatedAt:   a.CreatedAt,
 		UpdatedAt:   a.UpdatedAt,
 	}
@@ -333,17 +518,66 @@ func toRoomInventoryResponse(i room.RoomInventory) RoomInventoryResponse {
 	}
 }
 
-func jsonValueOrEmptyArray(raw json.RawMessage) any {
-	if len(raw) == 0 {
-		return []any{}
+func toRoomInventoryCalendarResponse(cal room.InventoryCalendar) RoomInventoryCalendarResponse {
+	days := make([]RoomInventoryDayResponse, len(cal.Days))
+	for i := range cal.Days {
+		days[i] = RoomInventoryDayResponse{
+			Date:               cal.Days[i].Date.Format(isoDateLayout),
+			TotalInventory:     cal.Days[i].TotalInventory,
+			HeldInventory:      cal.Days[i].HeldInventory,
+			BookedInventory:    cal.Days[i].BookedInventory,
+			AvailableInventory: cal.Days[i].AvailableInventory,
+			Missing:            cal.Days[i].Missing,
+		}
+	}
+
+	missing := make([]string, len(cal.MissingDates))
+	for i := range cal.MissingDates {
+		missing[i] = cal.MissingDates[i].Format(isoDateLayout)
+	}
+
+	return RoomInventoryCalendarResponse{
+		RoomID:       cal.RoomID,
+		RoomName:     cal.RoomName,
+		From:         cal.From.Format(isoDateLayout),
+		To:           cal.To.Format(isoDateLayout),
+		Days:         days,
+		MissingDates: missing,
+	}
+}
+
+func toStayRestrictionResponses(restrictions []room.StayRestriction) []StayRestrictionResponse {
+	items := make([]StayRestrictionResponse, len(restrictions))
+	for i := range restrictions {
+		items[i] = StayRestrictionResponse{
+			ID:                restrictions[i].ID,
+			RoomID:            restrictions[i].RoomID,
+			Date:              restrictions[i].Date.Format(isoDateLayout),
+			MinStay:           restrictions[i].MinStay,
+			MaxStay:           restrictions[i].MaxStay,
+			ClosedToArrival:   restrictions[i].ClosedToArrival,
+			ClosedToDeparture: restrictions[i].ClosedToDeparture,
+			StopSell:          restrictions[i].StopSell,
+		}
 	}
 
-	var decoded any
-	if err := json.Unmarshal(raw, &decoded); err != nil {
-		return []any{}
+	return items
+}
+
+func toBedOptionResponses(options []room.BedOption) []BedOptionResponse {
+	items := make([]BedOptionResponse, len(options))
+	for i := range options {
+		items[i] = BedOptionResponse{
+			Type:         string(options[i].Type),
+			Count:        options[i].Count,
+			MaxExtraBeds: options[i].MaxExtraBeds,
+			ExtraBedFee:  options[i].ExtraBedFee,
+			CotAvailable: options[i].CotAvailable,
+			CotFee:       options[i].CotFee,
+		}
 	}
 
-	return decoded
+	return items
 }
 
 type SearchHotelsResponse struct {
@@ -509,3 +743,149 @@ func toSearchHotelRoomCombinationsResponse(in search.HotelRoomCombinationsResult
 		Combinations:       combinations,
 	}
 }
+
+type AuditEventResponse struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	ActorID   string            `json:"actorId,omitempty"`
+	UserID    string            `json:"userId,omitempty"`
+	IPAddress string            `json:"ipAddress"`
+	UserAgent string            `json:"userAgent"`
+	Metadata  map[string]string `json:"metadata"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+type AuditEventsResponse struct {
+	Events     []AuditEventResponse     `json:"events"`
+	Pagination SearchPaginationResponse `json:"pagination"`
+}
+
+func toAuditEventResponses(in []audit.Event) []AuditEventResponse {
+	events := make([]AuditEventResponse, len(in))
+	for i, event := range in {
+		metadata := event.Metadata
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+
+		events[i] = AuditEventResponse{
+			ID:        event.ID,
+			Type:      string(event.Type),
+			ActorID:   event.ActorID,
+			UserID:    event.UserID,
+			IPAddress: event.IPAddress,
+			UserAgent: event.UserAgent,
+			Metadata:  metadata,
+			CreatedAt: event.CreatedAt,
+		}
+	}
+
+	return events
+}
+
+func toAuditEventsResponse(in audit.Page, page, pageSize, offset int) AuditEventsResponse {
+	totalPages := 0
+	if pageSize > 0 {
+		totalPages = (in.Total + pageSize - 1) / pageSize
+	}
+
+	return AuditEventsResponse{
+		Events: toAuditEventResponses(in.Events),
+		Pagination: SearchPaginationResponse{
+			Page:       page,
+			PageSize:   pageSize,
+			Offset:     offset,
+			Total:      in.Total,
+			TotalPages: totalPages,
+		},
+	}
+}
+
+type UsersResponse struct {
+	Users      []UserResponse           `json:"users"`
+	Pagination SearchPaginationResponse `json:"pagination"`
+}
+
+func toUsersResponse(in user.SearchResult, page, pageSize, offset int) UsersResponse {
+	totalPages := 0
+	if pageSize > 0 {
+		totalPages = (in.Total + pageSize - 1) / pageSize
+	}
+
+	return UsersResponse{
+		Users: toUserResponses(in.Users),
+		Pagination: SearchPaginationResponse{
+			Page:       page,
+			PageSize:   pageSize,
+			Offset:     offset,
+			Total:      in.Total,
+			TotalPages: totalPages,
+		},
+	}
+}
+
+type SecurityEventResponse struct {
+	Type      string    `json:"type"`
+	UserAgent string    `json:"userAgent"`
+	IPAddress string    `json:"ipAddress"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type UploadRecordResponse struct {
+	URL        string     `json:"url"`
+	URLs       []string   `json:"urls"`
+	Folder     string     `json:"folder"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	AttachedAt *time.Time `json:"attachedAt,omitempty"`
+}
+
+type DataExportResponse struct {
+	User             UserResponse            `json:"user"`
+	TwoFactorEnabled bool                    `json:"twoFactorEnabled"`
+	OAuthAccounts    []OAuthAccountResponse  `json:"oauthAccounts"`
+	Sessions         []SessionResponse       `json:"sessions"`
+	AuditEvents      []AuditEventResponse    `json:"auditEvents"`
+	SecurityEvents   []SecurityEventResponse `json:"securityEvents"`
+	Uploads          []UploadRecordResponse  `json:"uploads"`
+	ExportedAt       time.Time               `json:"exportedAt"`
+}
+
+func toDataExportResponse(export auth.DataExport) DataExportResponse {
+	securityEvents := make([]SecurityEventResponse, len(export.SecurityEvents))
+	for i, event := range export.SecurityEvents {
+		securityEvents[i] = SecurityEventResponse{
+			Type:      string(event.Type),
+			UserAgent: event.UserAgent,
+			IPAddress: event.IPAddress,
+			CreatedAt: event.CreatedAt,
+		}
+	}
+
+	uploads := make([]UploadRecordResponse, len(export.Uploads))
+	for i, record := range export.Uploads {
+		uploads[i] = UploadRecordResponse{
+			URL:        record.URL,
+			URLs:       record.URLs,
+			Folder:     record.Folder,
+			Status:     string(record.Status),
+			CreatedAt:  record.CreatedAt,
+			AttachedAt: record.AttachedAt,
+		}
+	}
+
+	return DataExportResponse{
+		User:             toUserResponse(export.User),
+		TwoFactorEnabled: export.TwoFactorEnabled,
+		OAuthAccounts:    toOAuthAccountResponses(export.OAuthAccounts),
+		Sessions:         toSessionResponses(export.Sessions),
+		AuditEvents:      toAuditEventResponses(export.AuditEvents),
+		SecurityEvents:   securityEvents,
+		Uploads:          uploads,
+		ExportedAt:       export.ExportedAt,
+	}
+}
+
+type AccountDeletionResponse struct {
+	DeleteAt time.Time `json:"deleteAt"`
+}
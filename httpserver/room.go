@@ -1,6 +1,7 @@
 package httpserver
 
 import (
+	"errors"
 	"time"
 
 	"hexagon/room"
@@ -8,10 +9,27 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// amenityCatalogCacheControl lets clients and CDNs reuse the amenity list;
+// the catalog changes rarely and edits show up after at most five minutes.
+const amenityCatalogCacheControl = "public, max-age=300"
+
 func (s *Server) RegisterRoomRoutes() {
 	s.Router.POST("/api/rooms", s.handleAddRoom)
 	s.Router.POST("/api/room-amenities", s.handleAddRoomAmenity)
+	s.Router.GET("/api/room-amenities", s.handleListRoomAmenities)
+	s.Router.PUT("/api/room-amenities/:amenity_id", s.handleUpdateRoomAmenity)
+	s.Router.DELETE("/api/room-amenities/:amenity_id", s.handleDeleteRoomAmenity)
+	s.Router.PUT("/api/rooms/:room_id/amenities", s.handleReplaceRoomAmenities)
+	s.Router.POST("/api/rooms/:room_id/images", s.handleAddRoomImage)
+	s.Router.PUT("/api/rooms/:room_id/images/order", s.handleReorderRoomImages)
+	s.Router.PUT("/api/rooms/:room_id/images/:image_id/cover", s.handleSetRoomCoverImage)
+	s.Router.DELETE("/api/rooms/:room_id/images/:image_id", s.handleRemoveRoomImage)
 	s.Router.POST("/api/rooms/:room_id/inventories", s.handleAddRoomInventory)
+	s.Router.GET("/api/rooms/:room_id/inventories", s.handleGetRoomInventoryCalendar)
+	s.Router.GET("/api/hotels/:hotel_id/inventories", s.handleGetHotelInventoryCalendars)
+	s.Router.PUT("/api/rooms/:room_id/restrictions", s.handleSetStayRestrictions)
+	s.Router.GET("/api/rooms/:room_id/restrictions", s.handleListStayRestrictions)
+	s.Router.DELETE("/api/rooms/:room_id/restrictions", s.handleClearStayRestrictions)
 }
 
 // handleAddRoom godoc
@@ -75,6 +93,243 @@ func (s *Server) handleAddRoomAmenity(c echo.Context) error {
 	return s.respondCreated(c, toRoomAmenityResponse(created))
 }
 
+// handleListRoomAmenities godoc
+// @Summary List Room Amenities
+// @Description List the amenity catalog ordered by category and sort order. When lang is given, name is the translated name if one exists. Responses are cacheable.
+// @Tags rooms
+// @Produce json
+// @Param category query string false "Category filter (general, bathroom, tech, view)"
+// @Param lang query string false "Language for translated names, e.g. vi"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/room-amenities [get]
+func (s *Server) handleListRoomAmenities(c echo.Context) error {
+	amenities, err := s.RoomService.ListAmenities(c.Request().Context(), room.AmenityCategory(c.QueryParam("category")))
+	if err != nil {
+		return err
+	}
+
+	lang := c.QueryParam("lang")
+	items := make([]RoomAmenityResponse, len(amenities))
+	for i := range amenities {
+		amenities[i].Name = amenities[i].LocalizedName(lang)
+		items[i] = toRoomAmenityResponse(amenities[i])
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, amenityCatalogCacheControl)
+
+	return s.respondOK(c, APIDataResult{Data: items})
+}
+
+// handleUpdateRoomAmenity godoc
+// @Summary Update Room Amenity
+// @Description Update an amenity in the master list, including category, sort order and translated names.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Param amenity_id path string true "Amenity ID"
+// @Param payload body AddRoomAmenityRequest true "Room amenity payload"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 409 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/room-amenities/{amenity_id} [put]
+func (s *Server) handleUpdateRoomAmenity(c echo.Context) error {
+	amenityID := c.Param("amenity_id")
+	if amenityID == "" {
+		return s.respondBadRequest(c, "invalid amenity id", "amenity_id is required")
+	}
+
+	var req AddRoomAmenityRequest
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	amenity := req.ToRoomAmenity()
+	amenity.ID = amenityID
+
+	updated, err := s.RoomService.UpdateAmenity(c.Request().Context(), amenity)
+	if err != nil {
+		return err
+	}
+
+	return s.respondOK(c, toRoomAmenityResponse(updated))
+}
+
+// handleDeleteRoomAmenity godoc
+// @Summary Delete Room Amenity
+// @Description Delete an amenity. Amenities still mapped to rooms are rejected with 409 unless force=true, which also removes the mappings.
+// @Tags rooms
+// @Produce json
+// @Param amenity_id path string true "Amenity ID"
+// @Param force query bool false "Also remove room mappings"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 409 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/room-amenities/{amenity_id} [delete]
+func (s *Server) handleDeleteRoomAmenity(c echo.Context) error {
+	amenityID := c.Param("amenity_id")
+	if amenityID == "" {
+		return s.respondBadRequest(c, "invalid amenity id", "amenity_id is required")
+	}
+
+	force := c.QueryParam("force") == "true"
+	if err := s.RoomService.DeleteAmenity(c.Request().Context(), amenityID, force); err != nil {
+		return err
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+// handleAddRoomImage godoc
+// @Summary Add Room Image
+// @Description Append an image to the room gallery. `isCover` moves the cover to the new image.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param payload body RoomImageRequest true "Image payload"
+// @Success 201 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 409 {object} APIErrorResponse
+// @Router /api/rooms/{room_id}/images [post]
+func (s *Server) handleAddRoomImage(c echo.Context) error {
+	var req RoomImageRequest
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	created, err := s.RoomService.AddImage(c.Request().Context(), req.ToRoomImage(c.Param("room_id")))
+	if err != nil {
+		return err
+	}
+
+	return s.respondCreated(c, toRoomImageResponse(created))
+}
+
+// handleRemoveRoomImage godoc
+// @Summary Remove Room Image
+// @Description Remove an image from the room gallery and delete the stored file. Removing the cover promotes the next image; the last image cannot be removed.
+// @Tags rooms
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param image_id path string true "Image ID"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Router /api/rooms/{room_id}/images/{image_id} [delete]
+func (s *Server) handleRemoveRoomImage(c echo.Context) error {
+	if err := s.RoomService.RemoveImage(c.Request().Context(), c.Param("room_id"), c.Param("image_id")); err != nil {
+		return err
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+// handleReorderRoomImages godoc
+// @Summary Reorder Room Images
+// @Description Set the gallery order. `imageIds` must list every image of the room exactly once.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param payload body ReorderImagesRequest true "Image order"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Router /api/rooms/{room_id}/images/order [put]
+func (s *Server) handleReorderRoomImages(c echo.Context) error {
+	var req ReorderImagesRequest
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	images, err := s.RoomService.ReorderImages(c.Request().Context(), c.Param("room_id"), req.ImageIDs)
+	if err != nil {
+		return err
+	}
+
+	return s.respondOK(c, APIDataResult{Data: toRoomImageResponses(images)})
+}
+
+// handleSetRoomCoverImage godoc
+// @Summary Set Room Cover Image
+// @Description Make the image the only cover of the room.
+// @Tags rooms
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param image_id path string true "Image ID"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Router /api/rooms/{room_id}/images/{image_id}/cover [put]
+func (s *Server) handleSetRoomCoverImage(c echo.Context) error {
+	images, err := s.RoomService.SetCoverImage(c.Request().Context(), c.Param("room_id"), c.Param("image_id"))
+	if err != nil {
+		return err
+	}
+
+	return s.respondOK(c, APIDataResult{Data: toRoomImageResponses(images)})
+}
+
+// handleReplaceRoomAmenities godoc
+// @Summary Replace Room Amenities
+// @Description Replace the full amenity set of a room in one transaction. An empty list clears it.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param payload body ReplaceRoomAmenitiesRequest true "Amenity ids"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/rooms/{room_id}/amenities [put]
+func (s *Server) handleReplaceRoomAmenities(c echo.Context) error {
+	roomID := c.Param("room_id")
+	if roomID == "" {
+		return s.respondBadRequest(c, "invalid room id", "room_id is required")
+	}
+
+	var req ReplaceRoomAmenitiesRequest
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	amenities, err := s.RoomService.ReplaceRoomAmenities(c.Request().Context(), roomID, req.AmenityIDs)
+	if err != nil {
+		return err
+	}
+
+	items := make([]RoomAmenityResponse, len(amenities))
+	for i := range amenities {
+		items[i] = toRoomAmenityResponse(amenities[i])
+	}
+
+	return s.respondOK(c, APIDataResult{Data: items})
+}
+
 // handleAddRoomInventory godoc
 // @Summary Create Room Inventory
 // @Description Create inventory for a room on a specific date.
@@ -117,6 +372,196 @@ func (s *Server) handleAddRoomInventory(c echo.Context) error {
 	return s.respondCreated(c, toRoomInventoryResponse(created))
 }
 
+// handleGetRoomInventoryCalendar godoc
+// @Summary Get Room Inventory Calendar
+// @Description Get total, held, booked and available inventory per day for a room. Days without an inventory row are flagged as missing.
+// @Tags rooms
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param from query string true "Start date (YYYY-MM-DD), inclusive"
+// @Param to query string true "End date (YYYY-MM-DD), inclusive"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/rooms/{room_id}/inventories [get]
+func (s *Server) handleGetRoomInventoryCalendar(c echo.Context) error {
+	roomID := c.Param("room_id")
+	if roomID == "" {
+		return s.respondBadRequest(c, "invalid room id", "room_id is required")
+	}
+
+	from, to, err := inventoryRangeQuery(c)
+	if err != nil {
+		return s.respondBadRequest(c, "invalid query", err.Error())
+	}
+
+	calendar, err := s.RoomService.GetInventoryCalendar(c.Request().Context(), roomID, from, to)
+	if err != nil {
+		return err
+	}
+
+	return s.respondOK(c, toRoomInventoryCalendarResponse(calendar))
+}
+
+// handleGetHotelInventoryCalendars godoc
+// @Summary Get Hotel Inventory Calendars
+// @Description Get the per-day inventory calendar of every room in a hotel. Days without an inventory row are flagged as missing.
+// @Tags rooms
+// @Produce json
+// @Param hotel_id path string true "Hotel ID"
+// @Param from query string true "Start date (YYYY-MM-DD), inclusive"
+// @Param to query string true "End date (YYYY-MM-DD), inclusive"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/hotels/{hotel_id}/inventories [get]
+func (s *Server) handleGetHotelInventoryCalendars(c echo.Context) error {
+	hotelID := c.Param("hotel_id")
+	if hotelID == "" {
+		return s.respondBadRequest(c, "invalid hotel id", "hotel_id is required")
+	}
+
+	from, to, err := inventoryRangeQuery(c)
+	if err != nil {
+		return s.respondBadRequest(c, "invalid query", err.Error())
+	}
+
+	calendars, err := s.RoomService.GetHotelInventoryCalendars(c.Request().Context(), hotelID, from, to)
+	if err != nil {
+		return err
+	}
+
+	items := make([]RoomInventoryCalendarResponse, len(calendars))
+	for i := range calendars {
+		items[i] = toRoomInventoryCalendarResponse(calendars[i])
+	}
+
+	return s.respondOK(c, APIDataResult{Data: items})
+}
+
+// handleSetStayRestrictions godoc
+// @Summary Set Room Stay Restrictions
+// @Description Apply min/max length of stay, closed-to-arrival, closed-to-departure and stop-sell to every date in [from, to], replacing existing restrictions for those dates.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param payload body SetStayRestrictionsRequest true "Stay restriction payload"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/rooms/{room_id}/restrictions [put]
+func (s *Server) handleSetStayRestrictions(c echo.Context) error {
+	roomID := c.Param("room_id")
+	if roomID == "" {
+		return s.respondBadRequest(c, "invalid room id", "room_id is required")
+	}
+
+	var req SetStayRestrictionsRequest
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	from, err := isoDate(req.From)
+	if err != nil {
+		return s.respondBadRequest(c, "invalid request body", "from must be in YYYY-MM-DD format")
+	}
+
+	to, err := isoDate(req.To)
+	if err != nil {
+		return s.respondBadRequest(c, "invalid request body", "to must be in YYYY-MM-DD format")
+	}
+
+	saved, err := s.RoomService.SetStayRestrictions(c.Request().Context(), req.ToStayRestriction(roomID), from, to)
+	if err != nil {
+		return err
+	}
+
+	return s.respondOK(c, APIDataResult{Data: toStayRestrictionResponses(saved)})
+}
+
+// handleListStayRestrictions godoc
+// @Summary List Room Stay Restrictions
+// @Description List stored stay restrictions of a room for dates in [from, to]. Dates without a row have no restriction.
+// @Tags rooms
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param from query string true "Start date (YYYY-MM-DD), inclusive"
+// @Param to query string true "End date (YYYY-MM-DD), inclusive"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/rooms/{room_id}/restrictions [get]
+func (s *Server) handleListStayRestrictions(c echo.Context) error {
+	roomID := c.Param("room_id")
+	if roomID == "" {
+		return s.respondBadRequest(c, "invalid room id", "room_id is required")
+	}
+
+	from, to, err := inventoryRangeQuery(c)
+	if err != nil {
+		return s.respondBadRequest(c, "invalid query", err.Error())
+	}
+
+	restrictions, err := s.RoomService.ListStayRestrictions(c.Request().Context(), roomID, from, to)
+	if err != nil {
+		return err
+	}
+
+	return s.respondOK(c, APIDataResult{Data: toStayRestrictionResponses(restrictions)})
+}
+
+// handleClearStayRestrictions godoc
+// @Summary Clear Room Stay Restrictions
+// @Description Remove stay restrictions of a room for dates in [from, to].
+// @Tags rooms
+// @Produce json
+// @Param room_id path string true "Room ID"
+// @Param from query string true "Start date (YYYY-MM-DD), inclusive"
+// @Param to query string true "End date (YYYY-MM-DD), inclusive"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/rooms/{room_id}/restrictions [delete]
+func (s *Server) handleClearStayRestrictions(c echo.Context) error {
+	roomID := c.Param("room_id")
+	if roomID == "" {
+		return s.respondBadRequest(c, "invalid room id", "room_id is required")
+	}
+
+	from, to, err := inventoryRangeQuery(c)
+	if err != nil {
+		return s.respondBadRequest(c, "invalid query", err.Error())
+	}
+
+	if err := s.RoomService.ClearStayRestrictions(c.Request().Context(), roomID, from, to); err != nil {
+		return err
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+func inventoryRangeQuery(c echo.Context) (time.Time, time.Time, error) {
+	from, err := isoDate(c.QueryParam("from"))
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("from must be in YYYY-MM-DD format")
+	}
+
+	to, err := isoDate(c.QueryParam("to"))
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("to must be in YYYY-MM-DD format")
+	}
+
+	return from, to, nil
+}
+
 func (r AddRoomInventoryRequest) ToRoomInventory(roomID string, date time.Time) room.RoomInventory {
 	return room.RoomInventory{
 		RoomID:          roomID,
@@ -126,3 +571,14 @@ func (r AddRoomInventoryRequest) ToRoomInventory(roomID string, date time.Time)
 		BookedInventory: r.BookedInventory,
 	}
 }
+
+func (r SetStayRestrictionsRequest) ToStayRestriction(roomID string) room.StayRestriction {
+	return room.StayRestriction{
+		RoomID:            roomID,
+		MinStay:           r.MinStay,
+		MaxStay:           r.MaxStay,
+		ClosedToArrival:   r.ClosedToArrival,
+		ClosedToDeparture: r.ClosedToDeparture,
+		StopSell:          r.StopSell,
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"hexagon/httpserver"
 	"hexagon/room"
@@ -35,6 +36,71 @@ func (m *MockRoomService) AddInventory(ctx context.Context, inv room.RoomInvento
 	return args.Get(0).(room.RoomInventory), args.Error(1)
 }
 
+func (m *MockRoomService) GetInventoryCalendar(ctx context.Context, roomID string, from, to time.Time) (room.InventoryCalendar, error) {
+	args := m.Called(ctx, roomID, from, to)
+	return args.Get(0).(room.InventoryCalendar), args.Error(1)
+}
+
+func (m *MockRoomService) GetHotelInventoryCalendars(ctx context.Context, hotelID string, from, to time.Time) ([]room.InventoryCalendar, error) {
+	args := m.Called(ctx, hotelID, from, to)
+	return args.Get(0).([]room.InventoryCalendar), args.Error(1)
+}
+
+func (m *MockRoomService) SetStayRestrictions(ctx context.Context, rule room.StayRestriction, from, to time.Time) ([]room.StayRestriction, error) {
+	args := m.Called(ctx, rule, from, to)
+	return args.Get(0).([]room.StayRestriction), args.Error(1)
+}
+
+func (m *MockRoomService) ListStayRestrictions(ctx context.Context, roomID string, from, to time.Time) ([]room.StayRestriction, error) {
+	args := m.Called(ctx, roomID, from, to)
+	return args.Get(0).([]room.StayRestriction), args.Error(1)
+}
+
+func (m *MockRoomService) ClearStayRestrictions(ctx context.Context, roomID string, from, to time.Time) error {
+	args := m.Called(ctx, roomID, from, to)
+	return args.Error(0)
+}
+
+func (m *MockRoomService) ListAmenities(ctx context.Context, category room.AmenityCategory) ([]room.RoomAmenity, error) {
+	args := m.Called(ctx, category)
+	return args.Get(0).([]room.RoomAmenity), args.Error(1)
+}
+
+func (m *MockRoomService) UpdateAmenity(ctx context.Context, amenity room.RoomAmenity) (room.RoomAmenity, error) {
+	args := m.Called(ctx, amenity)
+	return args.Get(0).(room.RoomAmenity), args.Error(1)
+}
+
+func (m *MockRoomService) DeleteAmenity(ctx context.Context, id string, force bool) error {
+	args := m.Called(ctx, id, force)
+	return args.Error(0)
+}
+
+func (m *MockRoomService) ReplaceRoomAmenities(ctx context.Context, roomID string, amenityIDs []string) ([]room.RoomAmenity, error) {
+	args := m.Called(ctx, roomID, amenityIDs)
+	return args.Get(0).([]room.RoomAmenity), args.Error(1)
+}
+
+func (m *MockRoomService) AddImage(ctx context.Context, img room.RoomImage) (room.RoomImage, error) {
+	args := m.Called(ctx, img)
+	return args.Get(0).(room.RoomImage), args.Error(1)
+}
+
+func (m *MockRoomService) RemoveImage(ctx context.Context, roomID, imageID string) error {
+	args := m.Called(ctx, roomID, imageID)
+	return args.Error(0)
+}
+
+func (m *MockRoomService) ReorderImages(ctx context.Context, roomID string, imageIDs []string) ([]room.RoomImage, error) {
+	args := m.Called(ctx, roomID, imageIDs)
+	return args.Get(0).([]room.RoomImage), args.Error(1)
+}
+
+func (m *MockRoomService) SetCoverImage(ctx context.Context, roomID, imageID string) ([]room.RoomImage, error) {
+	args := m.Called(ctx, roomID, imageID)
+	return args.Get(0).([]room.RoomImage), args.Error(1)
+}
+
 func TestRoomRoutes_AddRoom(t *testing.T) {
 	svc := new(MockRoomService)
 	server := httpserver.Default(testConfig())
@@ -140,3 +206,179 @@ func TestRoomRoutes_AddInventory(t *testing.T) {
 	assert.Contains(t, rec.Body.String(), "\"id\":\"inv-1\"")
 	svc.AssertExpectations(t)
 }
+
+func TestRoomRoutes_GetInventoryCalendar(t *testing.T) {
+	svc := new(MockRoomService)
+	server := httpserver.Default(testConfig())
+	server.RoomService = svc
+
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 0, 1)
+	calendar := room.InventoryCalendar{
+		RoomID: "r-1",
+		From:   from,
+		To:     to,
+		Days: []room.InventoryDay{
+			{Date: from, TotalInventory: 5, AvailableInventory: 5},
+			{Date: to, Missing: true},
+		},
+		MissingDates: []time.Time{to},
+	}
+	svc.On("GetInventoryCalendar", mock.Anything, "r-1", from, to).Return(calendar, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/rooms/r-1/inventories?from=2026-05-01&to=2026-05-02", nil)
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"availableInventory":5`)
+	assert.Contains(t, rec.Body.String(), `"missingDates":["2026-05-02"]`)
+	svc.AssertExpectations(t)
+}
+
+func TestRoomRoutes_GetInventoryCalendar_InvalidDate(t *testing.T) {
+	svc := new(MockRoomService)
+	server := httpserver.Default(testConfig())
+	server.RoomService = svc
+
+	req := httptest.NewRequest(http.MethodGet, "/api/rooms/r-1/inventories?from=05-01-2026&to=2026-05-02", nil)
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	svc.AssertNotCalled(t, "GetInventoryCalendar", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRoomRoutes_GetHotelInventoryCalendars(t *testing.T) {
+	svc := new(MockRoomService)
+	server := httpserver.Default(testConfig())
+	server.RoomService = svc
+
+	calendars := []room.InventoryCalendar{{RoomID: "r-1"}, {RoomID: "r-2"}}
+	svc.On("GetHotelInventoryCalendars", mock.Anything, "h-1", mock.Anything, mock.Anything).Return(calendars, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/hotels/h-1/inventories?from=2026-05-01&to=2026-05-31", nil)
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"roomId":"r-2"`)
+	svc.AssertExpectations(t)
+}
+
+func TestRoomRoutes_SetStayRestrictions(t *testing.T) {
+	svc := new(MockRoomService)
+	server := httpserver.Default(testConfig())
+	server.RoomService = svc
+
+	payload := map[string]any{
+		"from":     "2026-05-01",
+		"to":       "2026-05-02",
+		"minStay":  2,
+		"stopSell": true,
+	}
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.Local)
+	rule := room.StayRestriction{RoomID: "r-1", MinStay: 2, StopSell: true}
+	saved := []room.StayRestriction{{RoomID: "r-1", Date: from, MinStay: 2, StopSell: true}}
+	svc.On("SetStayRestrictions", mock.Anything, rule, from, from.AddDate(0, 0, 1)).Return(saved, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/rooms/r-1/restrictions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"date":"2026-05-01"`)
+	assert.Contains(t, rec.Body.String(), `"stopSell":true`)
+	svc.AssertExpectations(t)
+}
+
+func TestRoomRoutes_ClearStayRestrictions_MissingRange(t *testing.T) {
+	svc := new(MockRoomService)
+	server := httpserver.Default(testConfig())
+	server.RoomService = svc
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/rooms/r-1/restrictions", nil)
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	svc.AssertNotCalled(t, "ClearStayRestrictions", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRoomRoutes_ListAmenities_TranslatesAndCaches(t *testing.T) {
+	svc := new(MockRoomService)
+	server := httpserver.Default(testConfig())
+	server.RoomService = svc
+
+	amenities := []room.RoomAmenity{
+		{ID: "a-1", Code: "hair_dryer", Name: "Hair dryer", Category: room.AmenityCategoryBathroom, Names: map[string]string{"vi": "Máy sấy tóc"}},
+	}
+	svc.On("ListAmenities", mock.Anything, room.AmenityCategoryBathroom).Return(amenities, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/room-amenities?category=bathroom&lang=vi", nil)
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"name":"Máy sấy tóc"`)
+	assert.Contains(t, rec.Body.String(), `"category":"bathroom"`)
+	assert.Contains(t, rec.Header().Get("Cache-Control"), "max-age")
+	svc.AssertExpectations(t)
+}
+
+func TestRoomRoutes_DeleteAmenity_InUse(t *testing.T) {
+	svc := new(MockRoomService)
+	server := httpserver.Default(testConfig())
+	server.RoomService = svc
+
+	svc.On("DeleteAmenity", mock.Anything, "a-1", false).Return(room.ErrAmenityInUse).Once()
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/room-amenities/a-1", nil)
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestRoomRoutes_ReplaceRoomAmenities(t *testing.T) {
+	svc := new(MockRoomService)
+	server := httpserver.Default(testConfig())
+	server.RoomService = svc
+
+	body, err := json.Marshal(map[string]any{"amenityIds": []string{"a-1", "a-2"}})
+	require.NoError(t, err)
+
+	svc.On("ReplaceRoomAmenities", mock.Anything, "r-1", []string{"a-1", "a-2"}).
+		Return([]room.RoomAmenity{{ID: "a-1"}, {ID: "a-2"}}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/rooms/r-1/amenities", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"id":"a-2"`)
+	svc.AssertExpectations(t)
+}
+
+func TestRoomRoutes_RemoveImage_LastImage(t *testing.T) {
+	svc := new(MockRoomService)
+	server := httpserver.Default(testConfig())
+	server.RoomService = svc
+
+	svc.On("RemoveImage", mock.Anything, "r-1", "img-1").Return(room.ErrLastImage).Once()
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/rooms/r-1/images/img-1", nil)
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	svc.AssertExpectations(t)
+}
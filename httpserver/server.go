@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"net/http"
 
+	"hexagon/audit"
 	"hexagon/auth"
 	"hexagon/errs"
 	"hexagon/hotel"
@@ -42,8 +43,19 @@ type Server struct {
 
 	UploadService upload.Service
 
+	AuditService audit.Service
+
 	JWTSecret string
 
+	// TokenKeys verifies access tokens signed with asymmetric keys and
+	// publishes their public keys. When nil, tokens are HS256 signed with
+	// JWTSecret.
+	TokenKeys TokenKeys
+
+	// TokenRevocations rejects revoked access tokens on private routes.
+	// When nil, access tokens are valid until they expire.
+	TokenRevocations TokenRevocations
+
 	Config *config.Config
 }
 
@@ -68,11 +80,16 @@ func Default(cfg *config.Config) *Server {
 	// PRIVATE
 	private := api.Group("")
 	private.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey:    []byte(cfg.Auth.JWTSecret),
-		SigningMethod: "HS256",
+		KeyFunc: s.accessTokenKey,
 	}))
+	private.Use(s.rejectRevokedTokens())
 	private.Use(s.requireVerifiedEmail())
+	private.Use(s.auditRequest())
 	s.RegisterPrivateRoutes(private)
+
+	// ADMIN
+	admin := private.Group("/admin", s.requireRole(user.UserRoleAdmin))
+	s.RegisterAdminRoutes(admin)
 	s.RegisterHealthRoutes()
 	s.RegisterSwaggerRoutes()
 	s.RegisterUserRoutes(private)
@@ -80,10 +97,26 @@ func Default(cfg *config.Config) *Server {
 	s.RegisterHotelRoutes()
 	s.RegisterRoomRoutes()
 	s.RegisterSearchRoutes()
+	s.RegisterStorageRoutes()
+	s.RegisterJWKSRoutes()
 
 	return &s
 }
 
+// LocalStorageRoute is where files written by the local storage driver are
+// served from.
+const LocalStorageRoute = "/uploads"
+
+// RegisterStorageRoutes serves the local storage directory when the local
+// driver is selected; S3 serves its own objects.
+func (s *Server) RegisterStorageRoutes() {
+	if s.Config == nil || !s.Config.UsesLocalStorage() || s.Config.Storage.LocalDir == "" {
+		return
+	}
+
+	s.Router.Static(LocalStorageRoute, s.Config.Storage.LocalDir)
+}
+
 func (s *Server) RegisterGlobalMiddlewares() {
 	s.Router.Use(middleware.RequestID())
 
@@ -129,6 +162,7 @@ func (s *Server) RegisterGlobalMiddlewares() {
 	s.Router.Use(middleware.Gzip())
 	s.Router.Use(sentryecho.New(sentryecho.Options{Repanic: true}))
 	s.Router.Use(middleware.RateLimiter(middleware.NewRateLimiterMemoryStore(20)))
+	s.Router.Use(s.auditRequest())
 
 	// CORS
 	if len(s.AllowOrigins) > 0 {
@@ -174,6 +208,9 @@ func (s *Server) customHTTPErrorHandler(err error, c echo.Context) {
 		case errs.ENOTIMPLEMENTED:
 			code = http.StatusNotImplemented
 			message = errs.ErrorMessage(err)
+		case errs.EINFECTED:
+			code = http.StatusUnprocessableEntity
+			message = errs.ErrorMessage(err)
 		case errs.EINTERNAL:
 			code = http.StatusInternalServerError
 			message = "Internal server error"
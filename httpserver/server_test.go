@@ -9,6 +9,8 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -152,6 +154,12 @@ func TestCustomErrorHandler(t *testing.T) {
 			expectedStatusCode: http.StatusNotImplemented,
 			expectedMessage:    "feature not implemented",
 		},
+		{
+			name:               "infected error returns 422",
+			error:              errs.Errorf(errs.EINFECTED, "file is infected"),
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedMessage:    "file is infected",
+		},
 		{
 			name:               "internal error returns 500 with generic message",
 			error:              errs.Errorf(errs.EINTERNAL, "database connection failed"),
@@ -349,3 +357,25 @@ func assertPanicIsRecovered(t *testing.T, response *httptest.ResponseRecorder) {
 	t.Helper()
 	assert.Equal(t, http.StatusInternalServerError, response.Code, "Should return 500 on panic")
 }
+
+func TestServer_LocalStorageRoute(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0o600))
+
+	cfg := testConfig()
+	cfg.Storage.Driver = "local"
+	cfg.Storage.LocalDir = dir
+	server := httpserver.Default(cfg)
+
+	req := httptest.NewRequest(http.MethodGet, httpserver.LocalStorageRoute+"/a.txt", nil)
+	rec := httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "hello", rec.Body.String())
+
+	server = httpserver.Default(testConfig())
+	rec = httptest.NewRecorder()
+	server.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, httpserver.LocalStorageRoute+"/a.txt", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
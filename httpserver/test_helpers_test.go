@@ -21,6 +21,7 @@ func testConfig() *config.Config {
 func signTestToken() (string, error) {
 	claims := jwt.MapClaims{
 		"sub":            "u-1",
+		"type":           "access",
 		"user_id":        "u-1",
 		"email":          "john@mail.com",
 		"email_verified": true,
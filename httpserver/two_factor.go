@@ -0,0 +1,206 @@
+package httpserver
+
+import (
+	"errors"
+
+	"hexagon/auth"
+
+	"github.com/labstack/echo/v4"
+)
+
+// handleVerifyTwoFactorLogin godoc
+// @Summary Verify Two-Factor Login
+// @Description Exchange the challenge token from /api/auth/login and a TOTP or recovery code for access + refresh tokens. Wrong codes count towards the account lockout.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body VerifyTwoFactorLoginRequest true "Two-factor login payload"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 403 {object} APIErrorResponse
+// @Failure 429 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/auth/login/2fa [post]
+func (s *Server) handleVerifyTwoFactorLogin(c echo.Context) error {
+	var req VerifyTwoFactorLoginRequest
+
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	tokens, err := s.AuthService.VerifyTwoFactorLogin(
+		auth.WithClientInfo(c.Request().Context(), auth.ClientInfo{
+			UserAgent: c.Request().UserAgent(),
+			IPAddress: c.RealIP(),
+		}),
+		req.ChallengeToken,
+		req.Code,
+	)
+	if err != nil {
+		if errors.Is(err, auth.ErrAccountLocked) {
+			return s.respondTooManyRequests(c, "account temporarily locked", err.Error())
+		}
+
+		if errors.Is(err, auth.ErrInvalidChallengeToken) {
+			return s.respondUnauthorized(c, "invalid challenge token", err.Error())
+		}
+
+		if errors.Is(err, auth.ErrInvalidTwoFactorCode) {
+			return s.respondUnauthorized(c, "invalid two-factor code", err.Error())
+		}
+
+		if errors.Is(err, auth.ErrAccountInactive) {
+			return s.respondUnauthorized(c, "account is inactive", err.Error())
+		}
+
+		if errors.Is(err, auth.ErrPasswordResetRequired) {
+			return s.respondForbidden(c, "password reset required", err.Error())
+		}
+
+		if errors.Is(err, auth.ErrTwoFactorNotConfigured) {
+			return s.respondNotImplemented(c, "two-factor authentication not configured", err.Error())
+		}
+
+		return s.respondInternalServerError(c, "internal error", err.Error())
+	}
+
+	return s.respondOK(c, map[string]string{
+		"accessToken":  tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+	})
+}
+
+// handleSetupTOTP godoc
+// @Summary Set Up TOTP
+// @Description Start two-factor enrolment. Returns a secret and an otpauth URI to show as a QR code; two-factor authentication is only on after /api/auth/2fa/totp/enable.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} APISuccessResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 409 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/auth/2fa/totp/setup [post]
+func (s *Server) handleSetupTOTP(c echo.Context) error {
+	userID, _, ok := sessionFromContext(c)
+	if !ok {
+		return s.respondUnauthorized(c, "invalid access token", "missing user id claim")
+	}
+
+	setup, err := s.AuthService.SetupTOTP(c.Request().Context(), userID)
+	if err != nil {
+		return s.respondTwoFactorError(c, err)
+	}
+
+	return s.respondOK(c, map[string]string{
+		"secret": setup.Secret,
+		"uri":    setup.URI,
+	})
+}
+
+// handleEnableTOTP godoc
+// @Summary Enable TOTP
+// @Description Turn two-factor authentication on with a code from the authenticator app. Returns recovery codes, which are not shown again.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body TOTPCodeRequest true "TOTP code"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 409 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/auth/2fa/totp/enable [post]
+func (s *Server) handleEnableTOTP(c echo.Context) error {
+	userID, _, ok := sessionFromContext(c)
+	if !ok {
+		return s.respondUnauthorized(c, "invalid access token", "missing user id claim")
+	}
+
+	var req TOTPCodeRequest
+
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	codes, err := s.AuthService.EnableTOTP(auth.WithClientInfo(c.Request().Context(), auth.ClientInfo{
+		UserAgent: c.Request().UserAgent(),
+		IPAddress: c.RealIP(),
+	}), userID, req.Code)
+	if err != nil {
+		return s.respondTwoFactorError(c, err)
+	}
+
+	return s.respondOK(c, map[string]any{
+		"recoveryCodes": codes,
+	})
+}
+
+// handleDisableTOTP godoc
+// @Summary Disable TOTP
+// @Description Turn two-factor authentication off. Requires a current TOTP code or a recovery code. Wrong codes count towards the account lock.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body TOTPCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} APISuccessResponse
+// @Failure 400 {object} APIErrorResponse
+// @Failure 401 {object} APIErrorResponse
+// @Failure 404 {object} APIErrorResponse
+// @Failure 429 {object} APIErrorResponse
+// @Failure 500 {object} APIErrorResponse
+// @Router /api/auth/2fa/totp/disable [post]
+func (s *Server) handleDisableTOTP(c echo.Context) error {
+	userID, _, ok := sessionFromContext(c)
+	if !ok {
+		return s.respondUnauthorized(c, "invalid access token", "missing user id claim")
+	}
+
+	var req TOTPCodeRequest
+
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := s.AuthService.DisableTOTP(auth.WithClientInfo(c.Request().Context(), auth.ClientInfo{
+		UserAgent: c.Request().UserAgent(),
+		IPAddress: c.RealIP(),
+	}), userID, req.Code); err != nil {
+		return s.respondTwoFactorError(c, err)
+	}
+
+	return s.respondOK(c, map[string]any{})
+}
+
+func (s *Server) respondTwoFactorError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, auth.ErrInvalidTwoFactorCode):
+		return s.respondBadRequest(c, "invalid two-factor code", err.Error())
+	case errors.Is(err, auth.ErrTwoFactorNotEnrolled):
+		return s.respondNotFound(c, "two-factor authentication is not set up", err.Error())
+	case errors.Is(err, auth.ErrTwoFactorAlreadyEnabled):
+		return s.respondConflict(c, "two-factor authentication is already enabled", err.Error())
+	case errors.Is(err, auth.ErrTwoFactorNotConfigured):
+		return s.respondNotImplemented(c, "two-factor authentication not configured", err.Error())
+	case errors.Is(err, auth.ErrAccountLocked):
+		return s.respondTooManyRequests(c, "account temporarily locked", err.Error())
+	default:
+		return s.respondInternalServerError(c, "internal error", err.Error())
+	}
+}
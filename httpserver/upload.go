@@ -5,6 +5,7 @@ import (
 	"io"
 	"mime/multipart"
 
+	"hexagon/errs"
 	"hexagon/upload"
 
 	"github.com/labstack/echo/v4"
@@ -22,33 +23,93 @@ func (s *Server) handleUploadImages(c echo.Context, folder string) error {
 
 	files := toUploadFiles(collectImageFiles(form))
 
-	uploaded, err := s.UploadService.UploadImages(c.Request().Context(), folder, files)
+	uploaded, err := s.UploadService.UploadImages(c.Request().Context(), s.optionalUserID(c), folder, files)
 	if err != nil {
-		switch {
-		case errors.Is(err, upload.ErrNoImageFile):
-			return s.respondBadRequest(c, upload.ErrNoImageFile.Error(), "")
-		case errors.Is(err, upload.ErrImageTooLarge), errors.Is(err, upload.ErrUnsupportedImageType):
-			return s.respondBadRequest(c, "invalid image file", err.Error())
-		case errors.Is(err, upload.ErrUploaderUnavailable):
-			return s.respondNotImplemented(c, "upload service is not configured", "")
-		default:
-			return s.respondInternalServerError(c, "failed to upload image", err.Error())
-		}
+		return s.respondUploadError(c, err)
 	}
 
 	result := make([]UploadedImageResponse, len(uploaded))
 	for i := range uploaded {
-		result[i] = UploadedImageResponse{
-			FileName:    uploaded[i].FileName,
-			URL:         uploaded[i].URL,
-			Size:        uploaded[i].Size,
-			ContentType: uploaded[i].ContentType,
-		}
+		result[i] = toUploadedImageResponse(uploaded[i])
 	}
 
 	return s.respondCreated(c, APIDataResult{Data: UploadImagesResponse{Files: result}})
 }
 
+func (s *Server) handlePresignImageUpload(c echo.Context, folder string) error {
+	if s.UploadService == nil {
+		return s.respondNotImplemented(c, "upload service is not configured", "")
+	}
+
+	var req PresignImageUploadRequest
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	presigned, err := s.UploadService.PresignImageUpload(c.Request().Context(), folder, req.ToPresignRequest())
+	if err != nil {
+		return s.respondUploadError(c, err)
+	}
+
+	return s.respondCreated(c, PresignedUploadResponse{
+		Key:       presigned.Key,
+		URL:       presigned.URL,
+		Method:    presigned.Method,
+		Headers:   presigned.Headers,
+		ExpiresAt: presigned.ExpiresAt,
+	})
+}
+
+func (s *Server) handleCompleteImageUpload(c echo.Context, folder string) error {
+	if s.UploadService == nil {
+		return s.respondNotImplemented(c, "upload service is not configured", "")
+	}
+
+	var req CompleteImageUploadRequest
+	if err := c.Bind(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return s.respondBadRequest(c, "invalid request body", err.Error())
+	}
+
+	uploaded, err := s.UploadService.CompleteImageUpload(c.Request().Context(), s.optionalUserID(c), folder, req.Key)
+	if err != nil {
+		return s.respondUploadError(c, err)
+	}
+
+	return s.respondCreated(c, toUploadedImageResponse(uploaded))
+}
+
+func (s *Server) respondUploadError(c echo.Context, err error) error {
+	switch {
+	case errs.ErrorCode(err) == errs.EINFECTED:
+		return s.respondUnprocessableEntity(c, errs.ErrorMessage(err), "")
+	case errors.Is(err, upload.ErrNoImageFile):
+		return s.respondBadRequest(c, upload.ErrNoImageFile.Error(), "")
+	case errors.Is(err, upload.ErrImageTooLarge),
+		errors.Is(err, upload.ErrImageDimensionsTooLarge),
+		errors.Is(err, upload.ErrUnsupportedImageType),
+		errors.Is(err, upload.ErrContentTypeMismatch):
+		return s.respondBadRequest(c, "invalid image file", err.Error())
+	case errors.Is(err, upload.ErrInvalidUploadKey):
+		return s.respondBadRequest(c, upload.ErrInvalidUploadKey.Error(), "")
+	case errors.Is(err, upload.ErrUploadNotFound):
+		return s.respondNotFound(c, upload.ErrUploadNotFound.Error(), "")
+	case errors.Is(err, upload.ErrUploaderUnavailable):
+		return s.respondNotImplemented(c, "upload service is not configured", "")
+	case errors.Is(err, upload.ErrDirectUploadUnavailable):
+		return s.respondNotImplemented(c, upload.ErrDirectUploadUnavailable.Error(), "")
+	default:
+		return s.respondInternalServerError(c, "failed to upload image", err.Error())
+	}
+}
+
 func collectImageFiles(form *multipart.Form) []*multipart.FileHeader {
 	if form == nil {
 		return nil
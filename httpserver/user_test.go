@@ -55,6 +55,31 @@ func (m *MockUserService) DeactivateUser(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
+func (m *MockUserService) SearchUsers(ctx context.Context, filter user.SearchFilter) (user.SearchResult, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(user.SearchResult), args.Error(1)
+}
+
+func (m *MockUserService) ChangeRole(ctx context.Context, id string, role user.UserRole) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ReactivateUser(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserService) UnlockUser(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ForceLogout(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func TestUserRoutes_ListUsers_HidesPasswordHash(t *testing.T) {
 	svc := new(MockUserService)
 	server := httpserver.Default(testConfig())
@@ -0,0 +1,19 @@
+-- +migrate Up
+CREATE TABLE room_stay_restrictions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    min_stay INT NOT NULL DEFAULT 0,
+    max_stay INT NOT NULL DEFAULT 0,
+    closed_to_arrival BOOLEAN NOT NULL DEFAULT FALSE,
+    closed_to_departure BOOLEAN NOT NULL DEFAULT FALSE,
+    stop_sell BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(room_id, date)
+);
+
+CREATE INDEX idx_room_stay_restrictions_room_id ON room_stay_restrictions(room_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS room_stay_restrictions;
@@ -0,0 +1,5 @@
+-- +migrate Up
+UPDATE rooms SET bed_options = '[]'::jsonb WHERE jsonb_typeof(bed_options) <> 'array';
+
+-- +migrate Down
+-- Free-form values dropped by the Up step cannot be restored.
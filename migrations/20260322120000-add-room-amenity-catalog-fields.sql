@@ -0,0 +1,15 @@
+-- +migrate Up
+ALTER TABLE room_amenities
+    ADD COLUMN category VARCHAR(32) NOT NULL DEFAULT 'general',
+    ADD COLUMN sort_order INT NOT NULL DEFAULT 0,
+    ADD COLUMN names JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX idx_room_amenities_category_sort_order ON room_amenities(category, sort_order);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_room_amenities_category_sort_order;
+
+ALTER TABLE room_amenities
+    DROP COLUMN IF EXISTS names,
+    DROP COLUMN IF EXISTS sort_order,
+    DROP COLUMN IF EXISTS category;
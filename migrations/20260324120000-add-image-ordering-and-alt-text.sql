@@ -0,0 +1,48 @@
+-- +migrate Up
+ALTER TABLE hotel_images
+    ADD COLUMN sort_order INT NOT NULL DEFAULT 0,
+    ADD COLUMN alt_text VARCHAR(500) NOT NULL DEFAULT '';
+
+ALTER TABLE room_images
+    ADD COLUMN sort_order INT NOT NULL DEFAULT 0,
+    ADD COLUMN alt_text VARCHAR(500) NOT NULL DEFAULT '';
+
+-- Keep the existing cover first and make sure every gallery has exactly one.
+WITH ranked AS (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY hotel_id ORDER BY is_cover DESC, id) - 1 AS pos
+    FROM hotel_images
+)
+UPDATE hotel_images
+SET sort_order = ranked.pos,
+    is_cover = (ranked.pos = 0)
+FROM ranked
+WHERE hotel_images.id = ranked.id;
+
+WITH ranked AS (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY room_id ORDER BY is_cover DESC, id) - 1 AS pos
+    FROM room_images
+)
+UPDATE room_images
+SET sort_order = ranked.pos,
+    is_cover = (ranked.pos = 0)
+FROM ranked
+WHERE room_images.id = ranked.id;
+
+CREATE UNIQUE INDEX uq_hotel_images_cover ON hotel_images(hotel_id) WHERE is_cover;
+CREATE UNIQUE INDEX uq_room_images_cover ON room_images(room_id) WHERE is_cover;
+CREATE INDEX idx_hotel_images_hotel_sort_order ON hotel_images(hotel_id, sort_order);
+CREATE INDEX idx_room_images_room_sort_order ON room_images(room_id, sort_order);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_room_images_room_sort_order;
+DROP INDEX IF EXISTS idx_hotel_images_hotel_sort_order;
+DROP INDEX IF EXISTS uq_room_images_cover;
+DROP INDEX IF EXISTS uq_hotel_images_cover;
+
+ALTER TABLE room_images
+    DROP COLUMN IF EXISTS alt_text,
+    DROP COLUMN IF EXISTS sort_order;
+
+ALTER TABLE hotel_images
+    DROP COLUMN IF EXISTS alt_text,
+    DROP COLUMN IF EXISTS sort_order;
@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE uploads (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id    UUID NULL,
+    folder      VARCHAR(100) NOT NULL,
+    url         TEXT NOT NULL UNIQUE,
+    urls        TEXT[] NOT NULL,
+    status      VARCHAR(16) NOT NULL DEFAULT 'pending',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    attached_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX idx_uploads_urls ON uploads USING GIN (urls);
+CREATE INDEX idx_uploads_status_created_at ON uploads (status, created_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS uploads;
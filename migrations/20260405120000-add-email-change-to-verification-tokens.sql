@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE email_verification_tokens
+    ADD COLUMN new_email VARCHAR(255),
+    ADD COLUMN session_id UUID;
+
+-- +migrate Down
+ALTER TABLE email_verification_tokens
+    DROP COLUMN IF EXISTS session_id,
+    DROP COLUMN IF EXISTS new_email;
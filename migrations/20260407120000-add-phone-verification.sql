@@ -0,0 +1,16 @@
+-- +migrate Up
+ALTER TABLE users ADD COLUMN phone_verified_at TIMESTAMPTZ;
+
+CREATE TABLE phone_verification_codes (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    -- Number the code was sent to; it only verifies that number.
+    phone VARCHAR(50) NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS phone_verification_codes;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_verified_at;
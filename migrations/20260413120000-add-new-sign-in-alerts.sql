@@ -0,0 +1,24 @@
+-- +migrate Up
+-- Set when the owner reports a sign-in as not theirs; password login is
+-- refused until the password is reset.
+ALTER TABLE users ADD COLUMN must_reset_password BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Refresh tokens double as the sign-in history of a user.
+CREATE INDEX idx_refresh_tokens_user_id_client ON refresh_tokens (user_id, user_agent, ip_address);
+
+CREATE TABLE sign_in_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- Redeemed by the "this wasn't me" link of the alert email.
+    token_hash VARCHAR(255) NOT NULL UNIQUE,
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS sign_in_alerts;
+DROP INDEX IF EXISTS idx_refresh_tokens_user_id_client;
+ALTER TABLE users DROP COLUMN IF EXISTS must_reset_password;
@@ -0,0 +1,16 @@
+-- +migrate Up
+-- A requested deletion waits until deletion_scheduled_at so it can be
+-- cancelled. Deleted accounts keep their row, anonymised, because other
+-- tables reference it; deleted_at tells them apart from deactivated ones.
+ALTER TABLE users
+    ADD COLUMN deletion_scheduled_at TIMESTAMPTZ,
+    ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_users_deletion_scheduled_at ON users (deletion_scheduled_at)
+    WHERE deletion_scheduled_at IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS deletion_scheduled_at;
@@ -0,0 +1,11 @@
+-- +migrate Up
+CREATE TABLE revoked_sessions (
+    session_id VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_revoked_sessions_expires_at ON revoked_sessions (expires_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS revoked_sessions;
@@ -0,0 +1,32 @@
+-- +migrate Up
+-- Deleting an account has to erase the client details and emails its
+-- audit events hold. Updates may only do that: blank ip_address and
+-- user_agent and drop metadata keys. Everything else stays append-only.
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.id = OLD.id
+        AND NEW.event_type = OLD.event_type
+        AND NEW.actor_id IS NOT DISTINCT FROM OLD.actor_id
+        AND NEW.user_id IS NOT DISTINCT FROM OLD.user_id
+        AND NEW.created_at = OLD.created_at
+        AND NEW.ip_address IN ('', OLD.ip_address)
+        AND NEW.user_agent IN ('', OLD.user_agent)
+        AND OLD.metadata @> NEW.metadata THEN
+        RETURN NEW;
+    END IF;
+
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
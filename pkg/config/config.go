@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
@@ -32,12 +34,48 @@ type Config struct {
 		GoogleRedirectURL  string `envconfig:"AUTH_GOOGLE_REDIRECT_URL"`
 		ResetPasswordURL   string `envconfig:"AUTH_RESET_PASSWORD_URL"`
 		VerifyEmailURL     string `envconfig:"AUTH_VERIFY_EMAIL_URL"`
+		MagicLinkURL       string `envconfig:"AUTH_MAGIC_LINK_URL"`
+		SignInDenyURL      string `envconfig:"AUTH_SIGN_IN_DENY_URL"`
 		ResendAPIKey       string `envconfig:"AUTH_RESEND_API_KEY"`
 		ResendFromEmail    string `envconfig:"AUTH_RESEND_FROM_EMAIL"`
 		ResendFromName     string `envconfig:"AUTH_RESEND_FROM_NAME"`
+		// PasswordHistory is how many of a user's last passwords cannot be
+		// set again (default 5).
+		PasswordHistory int `envconfig:"AUTH_PASSWORD_HISTORY"`
+		// BreachedPasswordsFile is a Have I Been Pwned style list of SHA-1
+		// hashes sorted by hash; passwords in it are rejected. Empty skips
+		// the check.
+		BreachedPasswordsFile string `envconfig:"AUTH_BREACHED_PASSWORDS_FILE"`
+		// AccountDeletionGracePeriod is how many seconds a requested account
+		// deletion can still be cancelled (default 30 days).
+		// AccountDeletionSweepInterval is the seconds between runs of the job
+		// that carries due deletions out (default 3600).
+		AccountDeletionGracePeriod   int `envconfig:"AUTH_ACCOUNT_DELETION_GRACE_PERIOD"`
+		AccountDeletionSweepInterval int `envconfig:"AUTH_ACCOUNT_DELETION_SWEEP_INTERVAL"`
+		// JWTPrivateKeyFile is a PEM RSA or Ed25519 key that signs tokens
+		// instead of JWTSecret. JWTPublicKeyFiles lists other keys still
+		// accepted and published, e.g. the previous key after a rotation.
+		// JWTAcceptLegacySecret keeps accepting tokens signed with
+		// JWTSecret after the switch, until they have expired.
+		JWTPrivateKeyFile     string   `envconfig:"AUTH_JWT_PRIVATE_KEY_FILE"`
+		JWTPublicKeyFiles     []string `envconfig:"AUTH_JWT_PUBLIC_KEY_FILES"`
+		JWTAcceptLegacySecret bool     `envconfig:"AUTH_JWT_ACCEPT_LEGACY_SECRET"`
+		// OIDCProviderNames lists OpenID Connect login providers besides
+		// Google, e.g. "microsoft,corp". Each is configured by
+		// AUTH_OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL
+		// and optionally _SCOPES and _TRUST_EMAIL.
+		OIDCProviderNames []string `envconfig:"AUTH_OIDC_PROVIDERS"`
+		// OIDCProviders holds every login provider, including Google when
+		// the AUTH_GOOGLE_* variables are set.
+		OIDCProviders []OIDCProvider `ignored:"true"`
 	}
 
 	Storage struct {
+		// Driver selects the image storage: "s3" (default) or "local".
+		Driver       string `envconfig:"STORAGE_DRIVER"`
+		LocalDir     string `envconfig:"LOCAL_STORAGE_DIR"`
+		LocalBaseURL string `envconfig:"LOCAL_STORAGE_BASE_URL"`
+
 		S3Region          string `envconfig:"S3_REGION"`
 		S3Bucket          string `envconfig:"S3_BUCKET"`
 		S3BaseURL         string `envconfig:"S3_BASE_URL"`
@@ -46,9 +84,53 @@ type Config struct {
 		S3AccessKeyID     string `envconfig:"S3_ACCESS_KEY_ID"`
 		S3SecretAccessKey string `envconfig:"S3_SECRET_ACCESS_KEY"`
 		S3SessionToken    string `envconfig:"S3_SESSION_TOKEN"`
+		// ImageRenditions lists generated image sizes as
+		// "name:WxH,name:WxH", smallest first. Empty uses the defaults.
+		ImageRenditions string `envconfig:"IMAGE_RENDITIONS"`
+		// UploadOrphanMaxAge and UploadSweepInterval are in seconds; uploads
+		// never attached to a hotel or room are deleted after the max age.
+		UploadOrphanMaxAge  int `envconfig:"UPLOAD_ORPHAN_MAX_AGE"`
+		UploadSweepInterval int `envconfig:"UPLOAD_SWEEP_INTERVAL"`
+		// ClamAVAddr is a clamd TCP address; empty disables malware scanning.
+		// ClamAVTimeout is in seconds.
+		ClamAVAddr    string `envconfig:"CLAMAV_ADDR"`
+		ClamAVTimeout int    `envconfig:"CLAMAV_TIMEOUT"`
+	}
+
+	SMS struct {
+		// Provider selects how phone verification codes are sent: "console"
+		// logs them, "http" posts them to HTTPURL. Empty disables phone
+		// verification.
+		Provider   string `envconfig:"SMS_PROVIDER"`
+		HTTPURL    string `envconfig:"SMS_HTTP_URL"`
+		HTTPAPIKey string `envconfig:"SMS_HTTP_API_KEY"`
+		HTTPFrom   string `envconfig:"SMS_HTTP_FROM"`
+		// HTTPTimeout is in seconds.
+		HTTPTimeout int `envconfig:"SMS_HTTP_TIMEOUT"`
 	}
 }
 
+// googleIssuer lets the AUTH_GOOGLE_* variables configure Google as an
+// OpenID Connect provider.
+const googleIssuer = "https://accounts.google.com"
+
+// providerNamePattern keeps names usable in URLs and in the 32 character
+// provider column of oauth_provider_accounts.
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+type OIDCProvider struct {
+	Name         string `ignored:"true"`
+	Issuer       string `required:"true"`
+	ClientID     string `split_words:"true" required:"true"`
+	ClientSecret string `split_words:"true"`
+	RedirectURL  string `split_words:"true" required:"true"`
+	Scopes       []string
+	// TrustEmail lets a first sign-in with the provider into the existing
+	// account with the same email. Only set it for issuers that verify
+	// email ownership; it is always set for Google.
+	TrustEmail bool `split_words:"true"`
+}
+
 func LoadConfig() (*Config, error) {
 	// load default .env file, ignore the error
 	_ = godotenv.Load()
@@ -60,10 +142,58 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("load config error: %v", err)
 	}
 
+	if err := cfg.loadOIDCProviders(); err != nil {
+		return nil, fmt.Errorf("load config error: %v", err)
+	}
+
 	return cfg, nil
 }
 
+func (c *Config) loadOIDCProviders() error {
+	seen := make(map[string]bool)
+
+	if c.Auth.GoogleClientID != "" {
+		c.Auth.OIDCProviders = append(c.Auth.OIDCProviders, OIDCProvider{
+			Name:         "google",
+			Issuer:       googleIssuer,
+			ClientID:     c.Auth.GoogleClientID,
+			ClientSecret: c.Auth.GoogleClientSecret,
+			RedirectURL:  c.Auth.GoogleRedirectURL,
+			TrustEmail:   true,
+		})
+		seen["google"] = true
+	}
+
+	for _, name := range c.Auth.OIDCProviderNames {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !providerNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid oidc provider name %q", name)
+		}
+
+		if seen[name] {
+			return fmt.Errorf("oidc provider %q is configured twice", name)
+		}
+
+		seen[name] = true
+
+		provider := OIDCProvider{Name: name}
+
+		prefix := "AUTH_OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		if err := envconfig.Process(prefix, &provider); err != nil {
+			return err
+		}
+
+		c.Auth.OIDCProviders = append(c.Auth.OIDCProviders, provider)
+	}
+
+	return nil
+}
+
 func (c *Config) IsProduction() bool {
 	env := c.AppEnv
 	return env == "production" || env == "prod"
 }
+
+func (c *Config) UsesLocalStorage() bool {
+	return c.Storage.Driver == "local"
+}
@@ -79,4 +79,45 @@ func TestLoadConfig(t *testing.T) {
 		assert.Nil(t, cfg)
 		assert.Contains(t, err.Error(), "load config error")
 	})
+
+	t.Run("loads oidc providers", func(t *testing.T) {
+		t.Setenv("AUTH_GOOGLE_CLIENT_ID", "google-id")
+		t.Setenv("AUTH_GOOGLE_REDIRECT_URL", "http://localhost/api/auth/google/callback")
+		t.Setenv("AUTH_OIDC_PROVIDERS", "corp-sso")
+		t.Setenv("AUTH_OIDC_CORP_SSO_ISSUER", "https://sso.example.com")
+		t.Setenv("AUTH_OIDC_CORP_SSO_CLIENT_ID", "corp-id")
+		t.Setenv("AUTH_OIDC_CORP_SSO_CLIENT_SECRET", "corp-secret")
+		t.Setenv("AUTH_OIDC_CORP_SSO_REDIRECT_URL", "http://localhost/api/auth/corp-sso/callback")
+		t.Setenv("AUTH_OIDC_CORP_SSO_SCOPES", "openid,email")
+		t.Setenv("AUTH_OIDC_CORP_SSO_TRUST_EMAIL", "true")
+
+		cfg, err := config.LoadConfig()
+
+		require.NoError(t, err)
+		require.Len(t, cfg.Auth.OIDCProviders, 2)
+		assert.Equal(t, "google", cfg.Auth.OIDCProviders[0].Name)
+		assert.Equal(t, "https://accounts.google.com", cfg.Auth.OIDCProviders[0].Issuer)
+		assert.True(t, cfg.Auth.OIDCProviders[0].TrustEmail)
+		assert.Equal(t, config.OIDCProvider{
+			Name:         "corp-sso",
+			Issuer:       "https://sso.example.com",
+			ClientID:     "corp-id",
+			ClientSecret: "corp-secret",
+			RedirectURL:  "http://localhost/api/auth/corp-sso/callback",
+			Scopes:       []string{"openid", "email"},
+			TrustEmail:   true,
+		}, cfg.Auth.OIDCProviders[1])
+	})
+
+	t.Run("rejects invalid oidc providers", func(t *testing.T) {
+		t.Setenv("AUTH_OIDC_PROVIDERS", "corp")
+
+		_, err := config.LoadConfig()
+		assert.ErrorContains(t, err, "ISSUER", "issuer is required")
+
+		t.Setenv("AUTH_OIDC_PROVIDERS", "corp/../x")
+
+		_, err = config.LoadConfig()
+		assert.ErrorContains(t, err, "invalid oidc provider name")
+	})
 }
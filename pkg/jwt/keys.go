@@ -0,0 +1,213 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+var (
+	ErrUnsupportedKey = errors.New("unsupported key: use an RSA (2048 bits or more) or Ed25519 key")
+	ErrUnknownKey     = errors.New("unknown signing key")
+)
+
+const minRSABits = 2048
+
+// Key is an asymmetric key tokens are signed or verified with. Its ID is the
+// RFC 7638 thumbprint of the public key, so the same PEM file always gets
+// the same kid without any extra configuration.
+type Key struct {
+	ID        string
+	Algorithm string
+
+	public  crypto.PublicKey
+	private crypto.Signer
+}
+
+// LoadSigningKey reads a PKCS#8 or PKCS#1 private key in PEM format.
+func LoadSigningKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ParseSigningKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return key, nil
+}
+
+// LoadVerificationKey reads a PEM public key. A private key file is accepted
+// too; only its public half is kept.
+func LoadVerificationKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ParseVerificationKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return key, nil
+}
+
+func ParseSigningKey(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var (
+		parsed any
+		err    error
+	)
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	key, err := newKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	key.private = signer
+
+	return key, nil
+}
+
+func ParseVerificationKey(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		return newKey(parsed)
+	case "RSA PUBLIC KEY":
+		parsed, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		return newKey(parsed)
+	default:
+		key, err := ParseSigningKey(data)
+		if err != nil {
+			return nil, err
+		}
+
+		key.private = nil
+
+		return key, nil
+	}
+}
+
+func newKey(public crypto.PublicKey) (*Key, error) {
+	key := &Key{public: public}
+
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, ErrUnsupportedKey
+		}
+
+		key.Algorithm = "RS256"
+	case ed25519.PublicKey:
+		key.Algorithm = "EdDSA"
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	key.ID = thumbprint(key.JWK())
+
+	return key, nil
+}
+
+// JWK is the public part of a Key as served at /.well-known/jwks.json.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+func (k *Key) JWK() JWK {
+	jwk := JWK{Use: "sig", Algorithm: k.Algorithm, KeyID: k.ID}
+
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+
+	return jwk
+}
+
+// thumbprint computes the RFC 7638 thumbprint: the hash of the required
+// members only, in lexicographic order. encoding/json sorts map keys.
+func thumbprint(jwk JWK) string {
+	members := map[string]string{"kty": jwk.KeyType}
+
+	switch jwk.KeyType {
+	case "RSA":
+		members["n"] = jwk.N
+		members["e"] = jwk.E
+	case "OKP":
+		members["crv"] = jwk.Curve
+		members["x"] = jwk.X
+	}
+
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,109 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rsaKeyPEM(t *testing.T, bits int) []byte {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func ed25519KeyPEM(t *testing.T) []byte {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func publicKeyPEM(t *testing.T, privatePEM []byte) []byte {
+	t.Helper()
+
+	key, err := ParseSigningKey(privatePEM)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(key.public)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func TestParseSigningKey(t *testing.T) {
+	rsaKey, err := ParseSigningKey(rsaKeyPEM(t, 2048))
+	require.NoError(t, err)
+	assert.Equal(t, "RS256", rsaKey.Algorithm)
+	assert.NotEmpty(t, rsaKey.ID)
+
+	edKey, err := ParseSigningKey(ed25519KeyPEM(t))
+	require.NoError(t, err)
+	assert.Equal(t, "EdDSA", edKey.Algorithm)
+	assert.Equal(t, "OKP", edKey.JWK().KeyType)
+	assert.Equal(t, "Ed25519", edKey.JWK().Curve)
+
+	_, err = ParseSigningKey(rsaKeyPEM(t, 1024))
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+
+	_, err = ParseSigningKey([]byte("not a pem"))
+	assert.Error(t, err)
+}
+
+func TestParseVerificationKey_MatchesSigningKey(t *testing.T) {
+	privatePEM := rsaKeyPEM(t, 2048)
+
+	signing, err := ParseSigningKey(privatePEM)
+	require.NoError(t, err)
+
+	fromPublic, err := ParseVerificationKey(publicKeyPEM(t, privatePEM))
+	require.NoError(t, err)
+	assert.Equal(t, signing.ID, fromPublic.ID, "the kid only depends on the public key")
+	assert.Nil(t, fromPublic.private)
+
+	fromPrivate, err := ParseVerificationKey(privatePEM)
+	require.NoError(t, err)
+	assert.Equal(t, signing.ID, fromPrivate.ID)
+	assert.Nil(t, fromPrivate.private, "a private key file only contributes its public key")
+}
+
+func TestThumbprint_RFC7638Example(t *testing.T) {
+	// RFC 7638 section 3.1.
+	jwk := JWK{
+		KeyType: "RSA",
+		N: "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMs" +
+			"tn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5h" +
+			"ajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E: "AQAB",
+	}
+
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint(jwk))
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	require.NoError(t, os.WriteFile(path, ed25519KeyPEM(t), 0o600))
+
+	key, err := LoadSigningKey(path)
+	require.NoError(t, err)
+	assert.Equal(t, "EdDSA", key.Algorithm)
+
+	_, err = LoadSigningKey(filepath.Join(t.TempDir(), "missing.pem"))
+	assert.Error(t, err)
+}
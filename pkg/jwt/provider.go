@@ -11,29 +11,64 @@ import (
 	"github.com/golang-jwt/jwt"
 )
 
+// defaultTwoFactorTTL bounds how long a password-verified login may wait for
+// its second factor.
+const defaultTwoFactorTTL = 5 * time.Minute
+
+// defaultOAuthLinkTTL matches the lifetime of the OAuth flow cookie.
+const defaultOAuthLinkTTL = 5 * time.Minute
+
 type JWTProvider struct {
-	Secret     string
-	AccessTTL  time.Duration
-	RefreshTTL time.Duration
-	Issuer     string
-	Audience   string
+	// Secret signs tokens with HS256 when SigningKey is nil. With a
+	// SigningKey it only verifies tokens issued before the switch, so
+	// existing sessions survive it; leave it empty to reject them.
+	Secret string
+	// SigningKey signs new tokens, with its ID as the kid header.
+	SigningKey *Key
+	// VerificationKeys are accepted besides SigningKey, e.g. the previous
+	// key while its tokens are still valid, or the next one published
+	// ahead of a rotation.
+	VerificationKeys []*Key
+
+	AccessTTL    time.Duration
+	RefreshTTL   time.Duration
+	TwoFactorTTL time.Duration
+	OAuthLinkTTL time.Duration
+	Issuer       string
+	Audience     string
 }
 
 func NewJWTProvider(secret string, accessTTL, refreshTTL time.Duration) *JWTProvider {
 	return &JWTProvider{
-		Secret:     secret,
-		AccessTTL:  accessTTL,
-		RefreshTTL: refreshTTL,
-		Issuer:     "hexagon-api",
-		Audience:   "hexagon-clients",
+		Secret:       secret,
+		AccessTTL:    accessTTL,
+		RefreshTTL:   refreshTTL,
+		TwoFactorTTL: defaultTwoFactorTTL,
+		OAuthLinkTTL: defaultOAuthLinkTTL,
+		Issuer:       "hexagon-api",
+		Audience:     "hexagon-clients",
 	}
 }
 
+// NewJWTProviderWithKeys returns a provider that signs with signingKey and
+// also accepts tokens signed with any of verificationKeys.
+func NewJWTProviderWithKeys(signingKey *Key, verificationKeys []*Key, accessTTL, refreshTTL time.Duration) *JWTProvider {
+	p := NewJWTProvider("", accessTTL, refreshTTL)
+	p.SigningKey = signingKey
+	p.VerificationKeys = verificationKeys
+
+	return p
+}
+
+func (p *JWTProvider) GetAccessTTL() time.Duration {
+	return p.AccessTTL
+}
+
 func (p *JWTProvider) GetRefreshTTL() time.Duration {
 	return p.RefreshTTL
 }
 
-func (p *JWTProvider) GenerateAccessToken(u user.User) (string, error) {
+func (p *JWTProvider) GenerateAccessToken(u user.User, sessionID string) (string, error) {
 	now := time.Now().UTC()
 
 	jti, err := generateJTI(24)
@@ -50,15 +85,14 @@ func (p *JWTProvider) GenerateAccessToken(u user.User) (string, error) {
 		"nbf":            now.Unix(),
 		"exp":            now.Add(p.AccessTTL).Unix(),
 		"type":           "access",
+		"sid":            sessionID,
 		"user_id":        u.ID,
 		"email":          u.Email,
 		"email_verified": u.EmailVerifiedAt != nil,
 		"role":           string(u.Role),
 	}
 
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	return t.SignedString([]byte(p.Secret))
+	return p.sign(claims)
 }
 
 func (p *JWTProvider) GenerateRefreshToken(u user.User) (string, error) {
@@ -84,9 +118,85 @@ func (p *JWTProvider) GenerateRefreshToken(u user.User) (string, error) {
 		"role":           string(u.Role),
 	}
 
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return p.sign(claims)
+}
+
+// GenerateTwoFactorToken issues the challenge token returned by a password
+// login that still needs a second factor. It cannot be used as an access or
+// refresh token.
+func (p *JWTProvider) GenerateTwoFactorToken(u user.User) (string, error) {
+	now := time.Now().UTC()
+
+	jti, err := generateJTI(24)
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{
+		"iss":     p.Issuer,
+		"aud":     p.Audience,
+		"sub":     u.ID,
+		"jti":     jti,
+		"iat":     now.Unix(),
+		"nbf":     now.Unix(),
+		"exp":     now.Add(p.TwoFactorTTL).Unix(),
+		"type":    "2fa",
+		"user_id": u.ID,
+		"email":   u.Email,
+		"role":    string(u.Role),
+	}
+
+	return p.sign(claims)
+}
+
+func (p *JWTProvider) ParseTwoFactorToken(token string) (user.User, error) {
+	return p.parseTokenByType(token, "2fa")
+}
+
+// GenerateOAuthLinkToken issues the state of an OAuth flow that links
+// provider to u. It cannot be used as an access or refresh token.
+func (p *JWTProvider) GenerateOAuthLinkToken(u user.User, provider string) (string, error) {
+	now := time.Now().UTC()
+
+	jti, err := generateJTI(24)
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{
+		"iss":      p.Issuer,
+		"aud":      p.Audience,
+		"sub":      u.ID,
+		"jti":      jti,
+		"iat":      now.Unix(),
+		"nbf":      now.Unix(),
+		"exp":      now.Add(p.OAuthLinkTTL).Unix(),
+		"type":     "oauth_link",
+		"user_id":  u.ID,
+		"email":    u.Email,
+		"provider": provider,
+	}
 
-	return t.SignedString([]byte(p.Secret))
+	return p.sign(claims)
+}
+
+func (p *JWTProvider) ParseOAuthLinkToken(token string) (user.User, string, error) {
+	claims, err := p.parseClaimsByType(token, "oauth_link")
+	if err != nil {
+		return user.User{}, "", err
+	}
+
+	u, err := userFromClaims(claims)
+	if err != nil {
+		return user.User{}, "", err
+	}
+
+	provider, ok := claims["provider"].(string)
+	if !ok || provider == "" {
+		return user.User{}, "", errors.New("invalid provider")
+	}
+
+	return u, provider, nil
 }
 
 func (p *JWTProvider) ParseRefreshToken(refreshToken string) (user.User, error) {
@@ -98,15 +208,28 @@ func (p *JWTProvider) ParseAccessToken(accessToken string) (user.User, error) {
 }
 
 func (p *JWTProvider) parseTokenByType(token string, expectedType string) (user.User, error) {
-	claims, err := p.parseTokenClaims(token)
+	claims, err := p.parseClaimsByType(token, expectedType)
 	if err != nil {
 		return user.User{}, err
 	}
 
+	return userFromClaims(claims)
+}
+
+func (p *JWTProvider) parseClaimsByType(token string, expectedType string) (jwt.MapClaims, error) {
+	claims, err := p.parseTokenClaims(token)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := p.validateTokenClaims(claims, expectedType); err != nil {
-		return user.User{}, err
+		return nil, err
 	}
 
+	return claims, nil
+}
+
+func userFromClaims(claims jwt.MapClaims) (user.User, error) {
 	userID, err := userIDFromClaims(claims)
 	if err != nil {
 		return user.User{}, err
@@ -124,13 +247,74 @@ func (p *JWTProvider) parseTokenByType(token string, expectedType string) (user.
 	}, nil
 }
 
-func (p *JWTProvider) parseTokenClaims(refreshToken string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(refreshToken, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
+func (p *JWTProvider) sign(claims jwt.MapClaims) (string, error) {
+	if p.SigningKey == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(p.Secret))
+	}
+
+	method := jwt.GetSigningMethod(p.SigningKey.Algorithm)
+	if method == nil || p.SigningKey.private == nil {
+		return "", ErrUnknownKey
+	}
+
+	t := jwt.NewWithClaims(method, claims)
+	t.Header["kid"] = p.SigningKey.ID
+
+	return t.SignedString(p.SigningKey.private)
+}
+
+// VerificationKey returns the key for a token with the given kid and alg
+// headers. The alg must be the one of the key, so a public key can never
+// be used as an HMAC secret, and HS256 needs a non-empty Secret.
+func (p *JWTProvider) VerificationKey(kid, alg string) (any, error) {
+	if alg == jwt.SigningMethodHS256.Alg() {
+		if p.Secret == "" {
+			return nil, ErrUnknownKey
 		}
 
 		return []byte(p.Secret), nil
+	}
+
+	for _, key := range p.keys() {
+		if key.ID == kid && key.Algorithm == alg {
+			return key.public, nil
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// JWKS returns the public keys tokens may be signed with. It is empty when
+// tokens are signed with Secret.
+func (p *JWTProvider) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range p.keys() {
+		set.Keys = append(set.Keys, key.JWK())
+	}
+
+	return set
+}
+
+func (p *JWTProvider) keys() []*Key {
+	keys := make([]*Key, 0, len(p.VerificationKeys)+1)
+	seen := make(map[string]bool)
+
+	for _, key := range append([]*Key{p.SigningKey}, p.VerificationKeys...) {
+		if key == nil || seen[key.ID] {
+			continue
+		}
+
+		seen[key.ID] = true
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func (p *JWTProvider) parseTokenClaims(refreshToken string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(refreshToken, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.VerificationKey(kid, t.Method.Alg())
 	})
 	if err != nil || !token.Valid {
 		return nil, errors.New("invalid token")
@@ -15,7 +15,7 @@ func TestJWTProvider_GenerateAndParseAccessToken(t *testing.T) {
 	provider := NewJWTProvider("secret", time.Minute, time.Hour)
 	u := user.User{ID: "u-1", Email: "u1@example.com", Role: user.UserRoleAdmin}
 
-	token, err := provider.GenerateAccessToken(u)
+	token, err := provider.GenerateAccessToken(u, "s-1")
 	require.NoError(t, err)
 
 	parsed, err := provider.ParseAccessToken(token)
@@ -23,6 +23,10 @@ func TestJWTProvider_GenerateAndParseAccessToken(t *testing.T) {
 	assert.Equal(t, u.ID, parsed.ID)
 	assert.Equal(t, u.Email, parsed.Email)
 	assert.Equal(t, u.Role, parsed.Role)
+
+	claims, err := provider.parseTokenClaims(token)
+	require.NoError(t, err)
+	assert.Equal(t, "s-1", claims["sid"])
 }
 
 func TestJWTProvider_GenerateAndParseRefreshToken(t *testing.T) {
@@ -132,3 +136,165 @@ func TestGenerateJTI(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, jti)
 }
+
+func TestJWTProvider_TwoFactorTokenIsNotAccessToken(t *testing.T) {
+	provider := NewJWTProvider("secret", time.Minute, time.Hour)
+	u := user.User{ID: "u-1", Email: "u1@example.com", Role: user.UserRoleUser}
+
+	token, err := provider.GenerateTwoFactorToken(u)
+	require.NoError(t, err)
+
+	parsed, err := provider.ParseTwoFactorToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, parsed.ID)
+
+	_, err = provider.ParseAccessToken(token)
+	assert.Error(t, err)
+}
+
+func TestJWTProvider_OAuthLinkToken(t *testing.T) {
+	provider := NewJWTProvider("secret", time.Minute, time.Hour)
+	u := user.User{ID: "u-1", Email: "u1@example.com", Role: user.UserRoleUser}
+
+	token, err := provider.GenerateOAuthLinkToken(u, "google")
+	require.NoError(t, err)
+
+	parsed, linkProvider, err := provider.ParseOAuthLinkToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, parsed.ID)
+	assert.Equal(t, "google", linkProvider)
+
+	_, err = provider.ParseAccessToken(token)
+	assert.Error(t, err)
+
+	twoFactorToken, err := provider.GenerateTwoFactorToken(u)
+	require.NoError(t, err)
+
+	_, _, err = provider.ParseOAuthLinkToken(twoFactorToken)
+	assert.Error(t, err)
+}
+
+func TestJWTProvider_AsymmetricSigning(t *testing.T) {
+	for name, keyPEM := range map[string][]byte{
+		"RS256": rsaKeyPEM(t, 2048),
+		"EdDSA": ed25519KeyPEM(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			key, err := ParseSigningKey(keyPEM)
+			require.NoError(t, err)
+
+			provider := NewJWTProviderWithKeys(key, nil, time.Minute, time.Hour)
+			u := user.User{ID: "u-1", Email: "u1@example.com", Role: user.UserRoleUser}
+
+			token, err := provider.GenerateAccessToken(u, "s-1")
+			require.NoError(t, err)
+
+			parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, name, parsed.Method.Alg())
+			assert.Equal(t, key.ID, parsed.Header["kid"])
+
+			got, err := provider.ParseAccessToken(token)
+			require.NoError(t, err)
+			assert.Equal(t, u.ID, got.ID)
+
+			jwks := provider.JWKS()
+			require.Len(t, jwks.Keys, 1)
+			assert.Equal(t, key.ID, jwks.Keys[0].KeyID)
+			assert.Equal(t, name, jwks.Keys[0].Algorithm)
+		})
+	}
+}
+
+func TestJWTProvider_KeyRotation(t *testing.T) {
+	oldKey, err := ParseSigningKey(rsaKeyPEM(t, 2048))
+	require.NoError(t, err)
+
+	newKey, err := ParseSigningKey(ed25519KeyPEM(t))
+	require.NoError(t, err)
+
+	u := user.User{ID: "u-1", Email: "u1@example.com"}
+
+	before := NewJWTProviderWithKeys(oldKey, nil, time.Minute, time.Hour)
+	oldToken, err := before.GenerateRefreshToken(u)
+	require.NoError(t, err)
+
+	oldPublic, err := ParseVerificationKey(publicKeyPEM(t, rsaKeyPEM(t, 2048)))
+	require.NoError(t, err)
+
+	after := NewJWTProviderWithKeys(newKey, []*Key{oldPublic}, time.Minute, time.Hour)
+	_, err = after.ParseRefreshToken(oldToken)
+	assert.Error(t, err, "an unrelated old key does not verify")
+
+	after.VerificationKeys = []*Key{oldKey}
+	_, err = after.ParseRefreshToken(oldToken)
+	require.NoError(t, err, "tokens of the previous key stay valid")
+
+	newToken, err := after.GenerateRefreshToken(u)
+	require.NoError(t, err)
+
+	_, err = before.ParseRefreshToken(newToken)
+	assert.Error(t, err, "the old provider does not know the new key")
+
+	assert.Len(t, after.JWKS().Keys, 2)
+}
+
+func TestJWTProvider_LegacySecretAfterSwitch(t *testing.T) {
+	key, err := ParseSigningKey(ed25519KeyPEM(t))
+	require.NoError(t, err)
+
+	u := user.User{ID: "u-1", Email: "u1@example.com"}
+
+	legacy, err := NewJWTProvider("secret", time.Minute, time.Hour).GenerateAccessToken(u, "s-1")
+	require.NoError(t, err)
+
+	provider := NewJWTProviderWithKeys(key, nil, time.Minute, time.Hour)
+	_, err = provider.ParseAccessToken(legacy)
+	assert.Error(t, err, "HS256 is rejected without a secret")
+
+	provider.Secret = "secret"
+	_, err = provider.ParseAccessToken(legacy)
+	require.NoError(t, err)
+}
+
+func TestJWTProvider_RejectsEmptySecret(t *testing.T) {
+	u := user.User{ID: "u-1", Email: "u1@example.com"}
+
+	forged, err := NewJWTProvider("", time.Minute, time.Hour).GenerateAccessToken(u, "s-1")
+	require.NoError(t, err)
+
+	_, err = NewJWTProvider("", time.Minute, time.Hour).ParseAccessToken(forged)
+	assert.Error(t, err)
+
+	_, err = NewJWTProvider("", time.Minute, time.Hour).VerificationKey("", jwt.SigningMethodHS256.Alg())
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestJWTProvider_RejectsPublicKeyAsHMACSecret(t *testing.T) {
+	privatePEM := rsaKeyPEM(t, 2048)
+
+	key, err := ParseSigningKey(privatePEM)
+	require.NoError(t, err)
+
+	provider := NewJWTProviderWithKeys(key, nil, time.Minute, time.Hour)
+
+	claims := jwt.MapClaims{
+		"iss":     provider.Issuer,
+		"aud":     provider.Audience,
+		"sub":     "u-1",
+		"jti":     "jti",
+		"exp":     time.Now().UTC().Add(time.Minute).Unix(),
+		"type":    "access",
+		"user_id": "u-1",
+		"email":   "u1@example.com",
+	}
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	forged.Header["kid"] = key.ID
+
+	signed, err := forged.SignedString(publicKeyPEM(t, privatePEM))
+	require.NoError(t, err)
+
+	_, err = provider.ParseAccessToken(signed)
+	assert.Error(t, err)
+}
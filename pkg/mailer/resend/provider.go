@@ -3,6 +3,7 @@ package resend
 import (
 	"context"
 	"fmt"
+	"html/template"
 	"strings"
 
 	resendlib "github.com/resend/resend-go/v3"
@@ -96,6 +97,115 @@ func (p *Provider) SendVerifyEmail(ctx context.Context, toEmail, toName, verifyU
 	return err
 }
 
+func (p *Provider) SendMagicLinkEmail(ctx context.Context, toEmail, toName, loginURL string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	toEmail = strings.TrimSpace(toEmail)
+	toName = strings.TrimSpace(toName)
+	loginURL = strings.TrimSpace(loginURL)
+
+	if toEmail == "" || loginURL == "" {
+		return fmt.Errorf("invalid magic link mail payload")
+	}
+
+	html := fmt.Sprintf(
+		"<p>Hello %s,</p><p>Click <a href=\"%s\">here</a> to log in. The link works once and expires soon.</p><p>If you did not request this, ignore this email.</p>",
+		displayName(toName),
+		loginURL,
+	)
+
+	params := &resendlib.SendEmailRequest{
+		From:    fromHeader(p.fromName, p.fromEmail),
+		To:      []string{toEmail},
+		Subject: "Your login link",
+		Html:    html,
+	}
+
+	_, err := p.client.Emails.Send(params)
+
+	return err
+}
+
+func (p *Provider) SendEmailChangeNotice(ctx context.Context, toEmail, toName, newEmail string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	toEmail = strings.TrimSpace(toEmail)
+	toName = strings.TrimSpace(toName)
+	newEmail = strings.TrimSpace(newEmail)
+
+	if toEmail == "" || newEmail == "" {
+		return fmt.Errorf("invalid email change mail payload")
+	}
+
+	html := fmt.Sprintf(
+		"<p>Hello %s,</p><p>Someone asked to change the email of your account to %s. It changes once the new address is confirmed.</p><p>If this was not you, change your password and log out your other sessions now.</p>",
+		displayName(toName),
+		template.HTMLEscapeString(newEmail),
+	)
+
+	params := &resendlib.SendEmailRequest{
+		From:    fromHeader(p.fromName, p.fromEmail),
+		To:      []string{toEmail},
+		Subject: "Your email is being changed",
+		Html:    html,
+	}
+
+	_, err := p.client.Emails.Send(params)
+
+	return err
+}
+
+func (p *Provider) SendNewSignInAlert(ctx context.Context, toEmail, toName, userAgent, ipAddress, denyURL string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	toEmail = strings.TrimSpace(toEmail)
+	toName = strings.TrimSpace(toName)
+	denyURL = strings.TrimSpace(denyURL)
+
+	if toEmail == "" || denyURL == "" {
+		return fmt.Errorf("invalid new sign-in mail payload")
+	}
+
+	html := fmt.Sprintf(
+		"<p>Hello %s,</p><p>Your account was just signed in to from a new device.</p><p>Device: %s<br>IP address: %s</p><p>If this was you, there is nothing to do. If it was not, <a href=\"%s\">secure your account</a>: every session is logged out and you will be asked to reset your password.</p>",
+		displayName(toName),
+		template.HTMLEscapeString(orUnknown(userAgent)),
+		template.HTMLEscapeString(orUnknown(ipAddress)),
+		template.HTMLEscapeString(denyURL),
+	)
+
+	params := &resendlib.SendEmailRequest{
+		From:    fromHeader(p.fromName, p.fromEmail),
+		To:      []string{toEmail},
+		Subject: "New sign-in to your account",
+		Html:    html,
+	}
+
+	_, err := p.client.Emails.Send(params)
+
+	return err
+}
+
+func orUnknown(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "unknown"
+	}
+
+	return strings.TrimSpace(value)
+}
+
 func fromHeader(name, email string) string {
 	if strings.TrimSpace(name) == "" {
 		return email
@@ -114,6 +114,42 @@ func TestProvider_SendVerifyEmail(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestProvider_SendMagicLinkEmail(t *testing.T) {
+	provider := newTestProvider(t, func(payload *capturedEmail) {
+		assert.Equal(t, "Sender <from@example.com>", payload.From)
+		assert.Equal(t, []string{"to@example.com"}, payload.To)
+		assert.Equal(t, "Your login link", payload.Subject)
+		assert.True(t, strings.Contains(payload.Html, "https://login?token=abc"))
+	})
+
+	err := provider.SendMagicLinkEmail(context.Background(), "to@example.com", "John", "https://login?token=abc")
+	assert.NoError(t, err)
+}
+
+func TestProvider_SendEmailChangeNotice(t *testing.T) {
+	provider := newTestProvider(t, func(payload *capturedEmail) {
+		assert.Equal(t, []string{"old@example.com"}, payload.To)
+		assert.Equal(t, "Your email is being changed", payload.Subject)
+		assert.True(t, strings.Contains(payload.Html, "new@example.com"))
+	})
+
+	err := provider.SendEmailChangeNotice(context.Background(), "old@example.com", "John", "new@example.com")
+	assert.NoError(t, err)
+}
+
+func TestProvider_SendNewSignInAlert(t *testing.T) {
+	provider := newTestProvider(t, func(payload *capturedEmail) {
+		assert.Equal(t, []string{"to@example.com"}, payload.To)
+		assert.Equal(t, "New sign-in to your account", payload.Subject)
+		assert.True(t, strings.Contains(payload.Html, "https://deny?token=abc"))
+		assert.True(t, strings.Contains(payload.Html, "203.0.113.7"))
+		assert.True(t, strings.Contains(payload.Html, "Mozilla/5.0 &lt;test&gt;"))
+	})
+
+	err := provider.SendNewSignInAlert(context.Background(), "to@example.com", "John", "Mozilla/5.0 <test>", "203.0.113.7", "https://deny?token=abc")
+	assert.NoError(t, err)
+}
+
 func TestProvider_SendEmail_InvalidPayload(t *testing.T) {
 	provider := newTestProvider(t, func(payload *capturedEmail) {})
 
@@ -0,0 +1,57 @@
+package oidc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// flexibleBool accepts booleans sent as strings or numbers, which some
+// providers do for email_verified.
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "" || s == "null" {
+		*b = false
+		return nil
+	}
+
+	if s == "true" || s == "false" {
+		var v bool
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+
+		*b = flexibleBool(v)
+
+		return nil
+	}
+
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return err
+		}
+
+		v, err := strconv.ParseBool(strings.TrimSpace(unquoted))
+		if err != nil {
+			return err
+		}
+
+		*b = flexibleBool(v)
+
+		return nil
+	}
+
+	var num json.Number
+	if err := json.Unmarshal(data, &num); err == nil {
+		if i, err := num.Int64(); err == nil {
+			*b = i != 0
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid bool value: %s", s)
+}
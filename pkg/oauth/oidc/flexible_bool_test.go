@@ -0,0 +1,40 @@
+package oidc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlexibleBool_UnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want bool
+	}{
+		{"true", "true", true},
+		{"false", "false", false},
+		{"string-true", "\"true\"", true},
+		{"string-false", "\"false\"", false},
+		{"number-1", "1", true},
+		{"number-0", "0", false},
+		{"null", "null", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b flexibleBool
+			err := json.Unmarshal([]byte(tc.in), &b)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, bool(b))
+		})
+	}
+}
+
+func TestFlexibleBool_UnmarshalJSON_Invalid(t *testing.T) {
+	var b flexibleBool
+	err := json.Unmarshal([]byte("\"notabool\""), &b)
+	assert.Error(t, err)
+}
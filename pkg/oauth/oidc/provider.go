@@ -0,0 +1,273 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"hexagon/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrMissingConfig = errors.New("oidc: issuer, client id and redirect url are required")
+	// ErrInvalidIDToken wraps auth.ErrInvalidOAuthUser so callers answer
+	// forged or stale tokens like any other rejected identity.
+	ErrInvalidIDToken = fmt.Errorf("oidc: %w: invalid id token", auth.ErrInvalidOAuthUser)
+)
+
+var defaultScopes = []string{"openid", "email", "profile"}
+
+// signingMethods are the ID token algorithms accepted. HMAC is left out on
+// purpose: it would make the client secret a verification key.
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+const (
+	requestTimeout = 10 * time.Second
+	// clockSkew tolerates small clock differences with the identity provider.
+	clockSkew = time.Minute
+)
+
+type Config struct {
+	// Issuer is the provider's issuer URL; the discovery document is read
+	// from Issuer + "/.well-known/openid-configuration".
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// Scopes defaults to openid, email and profile.
+	Scopes []string
+	// HTTPClient is used for discovery, JWKS and token requests.
+	HTTPClient *http.Client
+}
+
+// Provider logs users in with any OpenID Connect provider. The discovery
+// document and signing keys are fetched on first use, so an unreachable
+// provider does not keep the server from starting.
+type Provider struct {
+	cfg    Config
+	client *http.Client
+	keys   *keySet
+	now    func() time.Time
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"` // nolint: tagliatelle
+	TokenEndpoint         string `json:"token_endpoint"`         // nolint: tagliatelle
+	JWKSURI               string `json:"jwks_uri"`               // nolint: tagliatelle
+}
+
+func NewProvider(cfg Config) (*Provider, error) {
+	cfg.Issuer = strings.TrimSpace(cfg.Issuer)
+	cfg.ClientID = strings.TrimSpace(cfg.ClientID)
+	cfg.ClientSecret = strings.TrimSpace(cfg.ClientSecret)
+	cfg.RedirectURL = strings.TrimSpace(cfg.RedirectURL)
+
+	if cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, ErrMissingConfig
+	}
+
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = defaultScopes
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+
+	return &Provider{
+		cfg:    cfg,
+		client: client,
+		keys:   newKeySet(client),
+		now:    time.Now,
+	}, nil
+}
+
+func (p *Provider) AuthCodeURL(ctx context.Context, flow auth.OAuthFlow) (string, error) {
+	config, err := p.oauthConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return config.AuthCodeURL(
+		flow.State,
+		oauth2.SetAuthURLParam("nonce", flow.Nonce),
+		oauth2.S256ChallengeOption(flow.CodeVerifier),
+	), nil
+}
+
+// Exchange redeems code with the PKCE verifier of flow and returns the
+// identity from the ID token, after checking its signature, issuer,
+// audience, expiry and nonce.
+func (p *Provider) Exchange(ctx context.Context, code string, flow auth.OAuthFlow) (auth.OAuthUser, error) {
+	config, err := p.oauthConfig(ctx)
+	if err != nil {
+		return auth.OAuthUser{}, err
+	}
+
+	token, err := config.Exchange(
+		context.WithValue(ctx, oauth2.HTTPClient, p.client),
+		code,
+		oauth2.VerifierOption(flow.CodeVerifier),
+	)
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.Response != nil && retrieveErr.Response.StatusCode < http.StatusInternalServerError {
+			// The provider refused the code, e.g. because it was used or the
+			// PKCE verifier does not match.
+			return auth.OAuthUser{}, fmt.Errorf("oidc: %w: code rejected: %w", auth.ErrInvalidOAuthUser, err)
+		}
+
+		return auth.OAuthUser{}, err
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return auth.OAuthUser{}, fmt.Errorf("%w: token response has no id_token", ErrInvalidIDToken)
+	}
+
+	claims, err := p.verifyIDToken(ctx, rawIDToken, flow.Nonce)
+	if err != nil {
+		return auth.OAuthUser{}, err
+	}
+
+	return auth.OAuthUser{
+		ProviderUserID: claims.Subject,
+		Email:          claims.Email,
+		Name:           claims.Name,
+		EmailVerified:  bool(claims.EmailVerified),
+	}, nil
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	AuthorizedParty string       `json:"azp"`
+	Nonce           string       `json:"nonce"`
+	Email           string       `json:"email"`
+	EmailVerified   flexibleBool `json:"email_verified"` // nolint: tagliatelle
+	Name            string       `json:"name"`
+}
+
+func (p *Provider) verifyIDToken(ctx context.Context, raw, nonce string) (idTokenClaims, error) {
+	doc, err := p.loadDiscovery(ctx)
+	if err != nil {
+		return idTokenClaims{}, err
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithTimeFunc(p.now),
+	)
+
+	var claims idTokenClaims
+
+	_, err = parser.ParseWithClaims(raw, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.keys.key(ctx, doc.JWKSURI, kid)
+	})
+	if err != nil {
+		return idTokenClaims{}, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+
+	if claims.Subject == "" {
+		return idTokenClaims{}, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	// OpenID Connect Core 3.1.3.7: with several audiences, azp names the
+	// client the token was issued to.
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.cfg.ClientID {
+		return idTokenClaims{}, fmt.Errorf("%w: issued to another client", ErrInvalidIDToken)
+	}
+
+	if nonce == "" || claims.Nonce != nonce {
+		return idTokenClaims{}, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	return claims, nil
+}
+
+func (p *Provider) oauthConfig(ctx context.Context) (*oauth2.Config, error) {
+	doc, err := p.loadDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  doc.AuthorizationEndpoint,
+			TokenURL: doc.TokenEndpoint,
+		},
+		Scopes: p.cfg.Scopes,
+	}, nil
+}
+
+// loadDiscovery fetches the discovery document once. Failures are not
+// cached, so a provider that was down at the first login is retried.
+func (p *Provider) loadDiscovery(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+
+	var doc discoveryDocument
+	if err := getJSON(ctx, p.client, wellKnown, &doc); err != nil {
+		return nil, fmt.Errorf("oidc: discovery: %w", err)
+	}
+
+	// OpenID Connect Discovery 4.3: the document must be for the issuer it
+	// was fetched from.
+	if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(p.cfg.Issuer, "/") {
+		return nil, fmt.Errorf("oidc: discovery issuer %q does not match %q", doc.Issuer, p.cfg.Issuer)
+	}
+
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery document is missing endpoints")
+	}
+
+	p.discovery = &doc
+
+	return p.discovery, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,353 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"hexagon/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockIdP is a minimal OpenID Connect provider: discovery, JWKS and a token
+// endpoint that checks PKCE and returns a signed ID token.
+type mockIdP struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu          sync.Mutex
+	key         *rsa.PrivateKey
+	kid         string
+	jwksFetches int
+	// codes maps an authorization code to the values of its request.
+	codes map[string]url.Values
+	// claims overrides ID token claims.
+	claims jwt.MapClaims
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+
+	idp := &mockIdP{t: t, codes: map[string]url.Values{}, claims: jwt.MapClaims{}}
+	idp.rotateKey("key-1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", idp.handleJWKS)
+	mux.HandleFunc("/token", idp.handleToken)
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+func (idp *mockIdP) rotateKey(kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(idp.t, err)
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	idp.key = key
+	idp.kid = kid
+}
+
+// authorize simulates the user approving the request behind authURL.
+func (idp *mockIdP) authorize(authURL string) string {
+	u, err := url.Parse(authURL)
+	require.NoError(idp.t, err)
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	code := "code-" + u.Query().Get("state")
+	idp.codes[code] = u.Query()
+
+	return code
+}
+
+func (idp *mockIdP) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	idp.jwksFetches++
+
+	writeJSON(w, map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"use": "sig",
+		"kid": idp.kid,
+		"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+	}}})
+}
+
+func (idp *mockIdP) handleToken(w http.ResponseWriter, r *http.Request) {
+	require.NoError(idp.t, r.ParseForm())
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	request, ok := idp.codes[r.PostForm.Get("code")]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, map[string]string{"error": "invalid_grant"})
+
+		return
+	}
+
+	delete(idp.codes, r.PostForm.Get("code"))
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != request.Get("code_challenge") {
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, map[string]string{"error": "invalid_grant", "error_description": "pkce mismatch"})
+
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"aud":            request.Get("client_id"),
+		"sub":            "subject-1",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          request.Get("nonce"),
+		"email":          "user@example.com",
+		"email_verified": "true",
+		"name":           "User",
+	}
+	for k, v := range idp.claims {
+		claims[k] = v
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = idp.kid
+
+	signed, err := token.SignedString(idp.key)
+	require.NoError(idp.t, err)
+
+	writeJSON(w, map[string]any{
+		"access_token": "access-1",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newTestProvider(t *testing.T, idp *mockIdP) *Provider {
+	t.Helper()
+
+	p, err := NewProvider(Config{
+		Issuer:       idp.server.URL,
+		ClientID:     "client-1",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/api/auth/corp/callback",
+	})
+	require.NoError(t, err)
+
+	return p
+}
+
+func testFlow(state string) auth.OAuthFlow {
+	return auth.OAuthFlow{
+		State:        state,
+		Nonce:        "nonce-" + state,
+		CodeVerifier: "verifier-" + state + "-0123456789012345678901234567890123",
+	}
+}
+
+func TestNewProvider_Validation(t *testing.T) {
+	_, err := NewProvider(Config{ClientID: "id", RedirectURL: "http://localhost/callback"})
+	assert.ErrorIs(t, err, ErrMissingConfig)
+
+	_, err = NewProvider(Config{Issuer: "https://idp.example.com", RedirectURL: "http://localhost/callback"})
+	assert.ErrorIs(t, err, ErrMissingConfig)
+
+	_, err = NewProvider(Config{Issuer: "https://idp.example.com", ClientID: "id"})
+	assert.ErrorIs(t, err, ErrMissingConfig)
+}
+
+func TestProvider_AuthCodeURL(t *testing.T) {
+	idp := newMockIdP(t)
+	p := newTestProvider(t, idp)
+	flow := testFlow("s1")
+
+	authURL, err := p.AuthCodeURL(context.Background(), flow)
+	require.NoError(t, err)
+
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, idp.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "s1", u.Query().Get("state"))
+	assert.Equal(t, flow.Nonce, u.Query().Get("nonce"))
+	assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+	assert.NotEmpty(t, u.Query().Get("code_challenge"))
+	assert.Equal(t, "openid email profile", u.Query().Get("scope"))
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	idp := newMockIdP(t)
+	p := newTestProvider(t, idp)
+	flow := testFlow("s1")
+
+	authURL, err := p.AuthCodeURL(context.Background(), flow)
+	require.NoError(t, err)
+
+	user, err := p.Exchange(context.Background(), idp.authorize(authURL), flow)
+	require.NoError(t, err)
+	assert.Equal(t, "subject-1", user.ProviderUserID)
+	assert.Equal(t, "user@example.com", user.Email)
+	assert.Equal(t, "User", user.Name)
+	assert.True(t, user.EmailVerified)
+}
+
+func TestProvider_Exchange_RejectsWrongVerifier(t *testing.T) {
+	idp := newMockIdP(t)
+	p := newTestProvider(t, idp)
+	flow := testFlow("s1")
+
+	authURL, err := p.AuthCodeURL(context.Background(), flow)
+	require.NoError(t, err)
+
+	stolen := flow
+	stolen.CodeVerifier = "another-verifier-0123456789012345678901234567890123"
+
+	_, err = p.Exchange(context.Background(), idp.authorize(authURL), stolen)
+	assert.ErrorIs(t, err, auth.ErrInvalidOAuthUser)
+}
+
+func TestProvider_Exchange_RejectsInvalidIDTokens(t *testing.T) {
+	cases := map[string]struct {
+		claims jwt.MapClaims
+		flow   func(auth.OAuthFlow) auth.OAuthFlow
+	}{
+		"nonce mismatch": {
+			flow: func(f auth.OAuthFlow) auth.OAuthFlow { f.Nonce = "other"; return f },
+		},
+		"other audience": {
+			claims: jwt.MapClaims{"aud": "client-2"},
+		},
+		"other issuer": {
+			claims: jwt.MapClaims{"iss": "https://evil.example.com"},
+		},
+		"expired": {
+			claims: jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()},
+		},
+		"azp of another client": {
+			claims: jwt.MapClaims{"aud": []string{"client-1", "client-2"}, "azp": "client-2"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			idp := newMockIdP(t)
+			idp.claims = tc.claims
+			p := newTestProvider(t, idp)
+			flow := testFlow("s1")
+
+			authURL, err := p.AuthCodeURL(context.Background(), flow)
+			require.NoError(t, err)
+
+			code := idp.authorize(authURL)
+			if tc.flow != nil {
+				flow = tc.flow(flow)
+			}
+
+			_, err = p.Exchange(context.Background(), code, flow)
+			assert.ErrorIs(t, err, ErrInvalidIDToken)
+			assert.ErrorIs(t, err, auth.ErrInvalidOAuthUser)
+		})
+	}
+}
+
+func TestProvider_Exchange_RefetchesKeysAfterRotation(t *testing.T) {
+	idp := newMockIdP(t)
+	p := newTestProvider(t, idp)
+	clock := time.Now()
+	p.keys.now = func() time.Time { return clock }
+
+	login := func(state string) error {
+		flow := testFlow(state)
+
+		authURL, err := p.AuthCodeURL(context.Background(), flow)
+		require.NoError(t, err)
+
+		_, err = p.Exchange(context.Background(), idp.authorize(authURL), flow)
+
+		return err
+	}
+
+	require.NoError(t, login("s1"))
+	require.NoError(t, login("s2"))
+	assert.Equal(t, 1, idp.jwksFetches, "keys are cached")
+
+	idp.rotateKey("key-2")
+
+	assert.ErrorIs(t, login("s3"), ErrInvalidIDToken, "refetching right away is throttled")
+
+	clock = clock.Add(minRefreshInterval)
+
+	require.NoError(t, login("s4"))
+	assert.Equal(t, 2, idp.jwksFetches)
+}
+
+func TestProvider_DiscoveryIssuerMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+
+	p, err := NewProvider(Config{
+		Issuer:      "https://other.example.com",
+		ClientID:    "client-1",
+		RedirectURL: "http://localhost/callback",
+		HTTPClient:  &http.Client{Transport: rewriteHost{target: idp.server.URL, base: http.DefaultTransport}},
+	})
+	require.NoError(t, err)
+
+	_, err = p.AuthCodeURL(context.Background(), testFlow("s1"))
+	assert.ErrorContains(t, err, "does not match")
+}
+
+// rewriteHost sends every request to target, so a provider configured with
+// another issuer can be served by the mock.
+type rewriteHost struct {
+	target string
+	base   http.RoundTripper
+}
+
+func (r rewriteHost) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, err := url.Parse(r.target)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.URL.Scheme = target.Scheme
+	req.URL.Host = target.Host
+
+	return r.base.RoundTrip(req)
+}
@@ -0,0 +1,146 @@
+package pwned
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1" //nolint:gosec // Have I Been Pwned lists passwords by their SHA-1 hash.
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	hashLength = sha1.Size * 2
+	// readChunk covers a whole "HASH:COUNT" line in one read.
+	readChunk = 64
+)
+
+var ErrInvalidFile = errors.New("pwned: file is not a sorted list of SHA-1 hashes")
+
+// File looks passwords up in a file of SHA-1 hashes, one per line and
+// optionally followed by ":count", sorted by hash. That is the format of the
+// "ordered by hash" Have I Been Pwned download. The file is binary searched
+// on disk, so it does not have to fit in memory. A File is safe for
+// concurrent use.
+type File struct {
+	f    *os.File
+	size int64
+}
+
+func OpenFile(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	list := &File{f: f, size: info.Size()}
+
+	if list.size > 0 {
+		first, err := list.hashAt(0)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		if _, err := hex.DecodeString(first); err != nil || len(first) != hashLength {
+			f.Close()
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFile, path)
+		}
+	}
+
+	return list, nil
+}
+
+func (l *File) Close() error {
+	return l.f.Close()
+}
+
+// Contains reports whether the SHA-1 hash of password is in the file.
+func (l *File) Contains(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	target := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	// Search the lines starting in [lo, hi).
+	lo, hi := int64(0), l.size
+	for lo < hi {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		mid := lo + (hi-lo)/2
+
+		start, err := l.lineStart(mid)
+		if err != nil {
+			return false, err
+		}
+
+		if start >= hi {
+			hi = mid
+			continue
+		}
+
+		hash, err := l.hashAt(start)
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.Compare(hash, target) {
+		case 0:
+			return true, nil
+		case -1:
+			lo = start + 1
+		default:
+			hi = mid
+		}
+	}
+
+	return false, nil
+}
+
+// lineStart returns the offset of the first line starting at or after off.
+func (l *File) lineStart(off int64) (int64, error) {
+	if off == 0 {
+		return 0, nil
+	}
+
+	buf := make([]byte, readChunk)
+
+	for pos := off - 1; pos < l.size; pos += readChunk {
+		n, err := l.f.ReadAt(buf, pos)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+
+		if i := bytes.IndexByte(buf[:n], '\n'); i >= 0 {
+			return pos + int64(i) + 1, nil
+		}
+	}
+
+	return l.size, nil
+}
+
+// hashAt returns the upper-cased hash of the line starting at off.
+func (l *File) hashAt(off int64) (string, error) {
+	buf := make([]byte, readChunk)
+
+	n, err := l.f.ReadAt(buf, off)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	line := buf[:n]
+	if i := bytes.IndexAny(line, ":\r\n"); i >= 0 {
+		line = line[:i]
+	}
+
+	return strings.ToUpper(string(bytes.TrimSpace(line))), nil
+}
@@ -0,0 +1,75 @@
+package pwned
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec // test data for SHA-1 keyed lists.
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func writeList(t *testing.T, lines []string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o600))
+
+	return path
+}
+
+func TestFile_Contains(t *testing.T) {
+	breached := []string{"password", "123456", "Password123!", "qwerty", "letmein", "P@ssw0rd2024"}
+
+	lines := make([]string, 0, len(breached))
+	for i, password := range breached {
+		lines = append(lines, sha1Hex(password)+":"+strings.Repeat("9", i+1))
+	}
+
+	slices.Sort(lines)
+
+	list, err := OpenFile(writeList(t, lines))
+	require.NoError(t, err)
+	t.Cleanup(func() { list.Close() })
+
+	for _, password := range breached {
+		found, err := list.Contains(context.Background(), password)
+		require.NoError(t, err)
+		assert.True(t, found, password)
+	}
+
+	for _, password := range []string{"Correct-Horse-9", "", "password "} {
+		found, err := list.Contains(context.Background(), password)
+		require.NoError(t, err)
+		assert.False(t, found, password)
+	}
+}
+
+func TestFile_ContainsWithoutCounts(t *testing.T) {
+	lines := []string{strings.ToLower(sha1Hex("hunter2")), strings.ToLower(sha1Hex("dragon"))}
+	slices.Sort(lines)
+
+	list, err := OpenFile(writeList(t, lines))
+	require.NoError(t, err)
+	t.Cleanup(func() { list.Close() })
+
+	found, err := list.Contains(context.Background(), "hunter2")
+	require.NoError(t, err)
+	assert.True(t, found, "lower-case hashes match too")
+}
+
+func TestOpenFile_RejectsOtherFormats(t *testing.T) {
+	_, err := OpenFile(writeList(t, []string{"password", "qwerty"}))
+
+	assert.ErrorIs(t, err, ErrInvalidFile)
+}
@@ -0,0 +1,155 @@
+package clamav
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"hexagon/upload"
+)
+
+const (
+	defaultTimeout   = 30 * time.Second
+	defaultChunkSize = 64 << 10
+)
+
+var (
+	ErrMissingAddr = errors.New("clamav: address is required")
+	// ErrScanFailed is returned when clamd answers with an error, e.g. when
+	// the stream exceeds its StreamMaxLength.
+	ErrScanFailed = errors.New("clamav: scan failed")
+)
+
+type Config struct {
+	// Addr is the clamd TCP address, e.g. localhost:3310.
+	Addr string
+	// Timeout bounds one whole scan including the connection.
+	Timeout time.Duration
+}
+
+// Scanner sends files to clamd with the INSTREAM command. A new connection is
+// used per scan, so one Scanner is safe for concurrent use.
+type Scanner struct {
+	addr      string
+	timeout   time.Duration
+	chunkSize int
+	dialer    net.Dialer
+}
+
+func NewScanner(cfg Config) (*Scanner, error) {
+	addr := strings.TrimSpace(cfg.Addr)
+	if addr == "" {
+		return nil, ErrMissingAddr
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &Scanner{
+		addr:      addr,
+		timeout:   timeout,
+		chunkSize: defaultChunkSize,
+	}, nil
+}
+
+// Scan streams body to clamd and parses its verdict.
+func (s *Scanner) Scan(ctx context.Context, body io.Reader, size int64) (upload.ScanResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	conn, err := s.dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return upload.ScanResult{}, fmt.Errorf("clamav: connect: %w", err)
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return upload.ScanResult{}, fmt.Errorf("clamav: set deadline: %w", err)
+	}
+
+	if err := s.stream(conn, body); err != nil {
+		return upload.ScanResult{}, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return upload.ScanResult{}, fmt.Errorf("clamav: read reply: %w", err)
+	}
+
+	return parseReply(reply)
+}
+
+// stream writes the INSTREAM command followed by length-prefixed chunks and
+// the zero-length chunk that ends the stream.
+func (s *Scanner) stream(w io.Writer, body io.Reader) error {
+	bw := bufio.NewWriter(w)
+
+	if _, err := bw.WriteString("zINSTREAM\x00"); err != nil {
+		return fmt.Errorf("clamav: send command: %w", err)
+	}
+
+	buf := make([]byte, s.chunkSize)
+	var length [4]byte
+
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(length[:], uint32(n))
+
+			if _, werr := bw.Write(length[:]); werr != nil {
+				return fmt.Errorf("clamav: send chunk: %w", werr)
+			}
+
+			if _, werr := bw.Write(buf[:n]); werr != nil {
+				return fmt.Errorf("clamav: send chunk: %w", werr)
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("clamav: read body: %w", err)
+		}
+	}
+
+	binary.BigEndian.PutUint32(length[:], 0)
+	if _, err := bw.Write(length[:]); err != nil {
+		return fmt.Errorf("clamav: end stream: %w", err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("clamav: send stream: %w", err)
+	}
+
+	return nil
+}
+
+// parseReply reads replies such as "stream: OK" and
+// "stream: Eicar-Signature FOUND".
+func parseReply(reply string) (upload.ScanResult, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	verdict := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+
+	switch {
+	case verdict == "OK":
+		return upload.ScanResult{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return upload.ScanResult{
+			Infected:  true,
+			Signature: strings.TrimSpace(strings.TrimSuffix(verdict, " FOUND")),
+		}, nil
+	default:
+		return upload.ScanResult{}, fmt.Errorf("%w: %q", ErrScanFailed, reply)
+	}
+}
@@ -0,0 +1,129 @@
+package clamav
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd accepts one INSTREAM session per connection, reassembles the
+// chunks and answers like clamd does.
+func fakeClamd(t *testing.T, reply func(body []byte) string) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveClamd(conn, reply)
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func serveClamd(conn net.Conn, reply func(body []byte) string) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+
+	command, err := r.ReadString(0)
+	if err != nil || command != "zINSTREAM\x00" {
+		_, _ = conn.Write([]byte("UNKNOWN COMMAND\x00"))
+		return
+	}
+
+	var body bytes.Buffer
+
+	for {
+		var length uint32
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return
+		}
+
+		if length == 0 {
+			break
+		}
+
+		if _, err := io.CopyN(&body, r, int64(length)); err != nil {
+			return
+		}
+	}
+
+	_, _ = conn.Write([]byte(reply(body.Bytes()) + "\x00"))
+}
+
+func clamdVerdict(body []byte) string {
+	if bytes.Contains(body, []byte("EICAR-STANDARD-ANTIVIRUS-TEST-FILE")) {
+		return "stream: Eicar-Signature FOUND"
+	}
+
+	return "stream: OK"
+}
+
+func TestNewScanner_MissingAddr(t *testing.T) {
+	_, err := NewScanner(Config{})
+	assert.ErrorIs(t, err, ErrMissingAddr)
+}
+
+func TestScanner_Clean(t *testing.T) {
+	scanner, err := NewScanner(Config{Addr: fakeClamd(t, clamdVerdict)})
+	require.NoError(t, err)
+	scanner.chunkSize = 7
+
+	body := strings.Repeat("clean image bytes ", 10)
+
+	result, err := scanner.Scan(context.Background(), strings.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	assert.False(t, result.Infected)
+}
+
+func TestScanner_Infected(t *testing.T) {
+	scanner, err := NewScanner(Config{Addr: fakeClamd(t, clamdVerdict)})
+	require.NoError(t, err)
+	scanner.chunkSize = 16
+
+	result, err := scanner.Scan(context.Background(), strings.NewReader(eicar), int64(len(eicar)))
+	require.NoError(t, err)
+	assert.True(t, result.Infected)
+	assert.Equal(t, "Eicar-Signature", result.Signature)
+}
+
+func TestScanner_ErrorReply(t *testing.T) {
+	addr := fakeClamd(t, func([]byte) string { return "INSTREAM size limit exceeded. ERROR" })
+	scanner, err := NewScanner(Config{Addr: addr})
+	require.NoError(t, err)
+
+	_, err = scanner.Scan(context.Background(), strings.NewReader("data"), 4)
+	assert.ErrorIs(t, err, ErrScanFailed)
+}
+
+func TestScanner_Unreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	scanner, err := NewScanner(Config{Addr: addr})
+	require.NoError(t, err)
+
+	_, err = scanner.Scan(context.Background(), strings.NewReader("data"), 4)
+	assert.Error(t, err)
+}
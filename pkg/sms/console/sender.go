@@ -0,0 +1,34 @@
+package console
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// Sender logs text messages instead of sending them, for local development.
+// The message is logged as is, so codes in it end up in the logs.
+type Sender struct {
+	logger *slog.Logger
+}
+
+// NewSender logs to logger, or to slog.Default when logger is nil.
+func NewSender(logger *slog.Logger) *Sender {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Sender{logger: logger}
+}
+
+func (s *Sender) SendSMS(ctx context.Context, toPhone, message string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.logger.InfoContext(ctx, "sms", "to", strings.TrimSpace(toPhone), "message", message)
+
+	return nil
+}
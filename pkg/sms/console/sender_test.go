@@ -0,0 +1,22 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSender_SendSMS(t *testing.T) {
+	var buf bytes.Buffer
+	sender := NewSender(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	err := sender.SendSMS(context.Background(), " 0912345678 ", "code 123456")
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "to=0912345678")
+	assert.Contains(t, buf.String(), `message="code 123456"`)
+}
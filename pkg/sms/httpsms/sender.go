@@ -0,0 +1,102 @@
+package httpsms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 10 * time.Second
+
+var (
+	ErrMissingURL = errors.New("httpsms: url is required")
+	// ErrSendFailed is returned when the provider answers with a non-2xx
+	// status.
+	ErrSendFailed = errors.New("httpsms: send failed")
+)
+
+type Config struct {
+	// URL receives a POST with a JSON body {"to", "message", "from"}.
+	URL string
+	// APIKey is sent as a bearer token when set.
+	APIKey string
+	// From is the sender ID, if the provider supports one.
+	From string
+	// Timeout bounds one request.
+	Timeout time.Duration
+}
+
+// Sender sends text messages through a provider with a JSON HTTP API.
+type Sender struct {
+	url    string
+	apiKey string
+	from   string
+	client *http.Client
+}
+
+func NewSender(cfg Config) (*Sender, error) {
+	url := strings.TrimSpace(cfg.URL)
+	if url == "" {
+		return nil, ErrMissingURL
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &Sender{
+		url:    url,
+		apiKey: strings.TrimSpace(cfg.APIKey),
+		from:   strings.TrimSpace(cfg.From),
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+type sendRequest struct {
+	To      string `json:"to"`
+	Message string `json:"message"`
+	From    string `json:"from,omitempty"`
+}
+
+func (s *Sender) SendSMS(ctx context.Context, toPhone, message string) error {
+	toPhone = strings.TrimSpace(toPhone)
+	if toPhone == "" || message == "" {
+		return fmt.Errorf("invalid sms payload")
+	}
+
+	body, err := json.Marshal(sendRequest{To: toPhone, Message: message, From: s.from})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%w: status %d: %s", ErrSendFailed, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+
+	return nil
+}
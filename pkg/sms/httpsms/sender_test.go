@@ -0,0 +1,58 @@
+package httpsms
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSender_RequiresURL(t *testing.T) {
+	_, err := NewSender(Config{URL: " "})
+
+	assert.ErrorIs(t, err, ErrMissingURL)
+}
+
+func TestSender_SendSMS(t *testing.T) {
+	var (
+		got  sendRequest
+		auth string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sender, err := NewSender(Config{URL: server.URL, APIKey: "key", From: "Hexagon"})
+	require.NoError(t, err)
+
+	err = sender.SendSMS(context.Background(), " 0912345678 ", "code 123456")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer key", auth)
+	assert.Equal(t, sendRequest{To: "0912345678", Message: "code 123456", From: "Hexagon"}, got)
+}
+
+func TestSender_SendSMS_ReturnsErrorOnFailureStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	sender, err := NewSender(Config{URL: server.URL})
+	require.NoError(t, err)
+
+	err = sender.SendSMS(context.Background(), "0912345678", "code")
+
+	assert.ErrorIs(t, err, ErrSendFailed)
+	assert.Contains(t, err.Error(), "quota exceeded")
+}
@@ -0,0 +1,128 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrMissingDir  = errors.New("local: directory is required")
+	ErrInvalidKey  = errors.New("local: invalid object key")
+	ErrSizeChanged = errors.New("local: written size does not match declared size")
+)
+
+type Config struct {
+	// Dir is where objects are written; it is created if missing.
+	Dir string
+	// BaseURL is the public URL Dir is served under, e.g.
+	// http://localhost:8088/uploads.
+	BaseURL string
+}
+
+// Uploader stores objects as files under a directory. It is meant for local
+// development and tests; files are served by the HTTP server's static route.
+type Uploader struct {
+	dir     string
+	baseURL string
+}
+
+func NewUploader(cfg Config) (*Uploader, error) {
+	dir := strings.TrimSpace(cfg.Dir)
+	if dir == "" {
+		return nil, ErrMissingDir
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("local: resolve directory: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("local: create directory: %w", err)
+	}
+
+	return &Uploader{
+		dir:     dir,
+		baseURL: strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/"),
+	}, nil
+}
+
+// Upload writes body to a temporary file and renames it into place, so a
+// failed upload never leaves a partial object behind.
+func (u *Uploader) Upload(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
+	key, target, err := u.resolve(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("local: create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("local: create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("local: write file: %w", err)
+	}
+
+	if size > 0 && written != size {
+		return "", ErrSizeChanged
+	}
+
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", fmt.Errorf("local: store file: %w", err)
+	}
+
+	return u.baseURL + "/" + key, nil
+}
+
+// Delete removes the file behind url. URLs outside baseURL and files that are
+// already gone are ignored.
+func (u *Uploader) Delete(ctx context.Context, url string) error {
+	key, ok := strings.CutPrefix(strings.TrimSpace(url), u.baseURL+"/")
+	if !ok || key == "" {
+		return nil
+	}
+
+	_, target, err := u.resolve(key)
+	if err != nil {
+		return nil
+	}
+
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("local: delete file: %w", err)
+	}
+
+	return nil
+}
+
+// resolve cleans key and maps it to a path that is guaranteed to stay
+// inside dir.
+func (u *Uploader) resolve(key string) (string, string, error) {
+	key = strings.Trim(strings.TrimSpace(key), "/")
+	if key == "" {
+		return "", "", ErrInvalidKey
+	}
+
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned != key || strings.HasPrefix(path.Base(cleaned), ".") {
+		return "", "", ErrInvalidKey
+	}
+
+	return cleaned, filepath.Join(u.dir, filepath.FromSlash(cleaned)), nil
+}
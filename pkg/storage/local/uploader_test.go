@@ -0,0 +1,61 @@
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUploader_MissingDir(t *testing.T) {
+	_, err := NewUploader(Config{})
+	assert.ErrorIs(t, err, ErrMissingDir)
+}
+
+func TestUploader_UploadAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	uploader, err := NewUploader(Config{Dir: dir, BaseURL: "http://localhost:8088/uploads/"})
+	require.NoError(t, err)
+
+	url, err := uploader.Upload(context.Background(), "hotel-images/20260101/abc/full.jpg", strings.NewReader("hello"), 5, "image/jpeg")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8088/uploads/hotel-images/20260101/abc/full.jpg", url)
+
+	data, err := os.ReadFile(filepath.Join(dir, "hotel-images", "20260101", "abc", "full.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	require.NoError(t, uploader.Delete(context.Background(), url))
+	_, err = os.Stat(filepath.Join(dir, "hotel-images", "20260101", "abc", "full.jpg"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, uploader.Delete(context.Background(), url), "deleting twice is a no-op")
+	require.NoError(t, uploader.Delete(context.Background(), "https://cdn.example.com/a.jpg"))
+}
+
+func TestUploader_Upload_RejectsEscapingKeys(t *testing.T) {
+	uploader, err := NewUploader(Config{Dir: t.TempDir(), BaseURL: "/uploads"})
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../x.jpg", "a/../../x.jpg", "a/.hidden"} {
+		_, err = uploader.Upload(context.Background(), key, strings.NewReader("x"), 1, "image/jpeg")
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
+
+func TestUploader_Upload_SizeMismatch(t *testing.T) {
+	dir := t.TempDir()
+	uploader, err := NewUploader(Config{Dir: dir, BaseURL: "/uploads"})
+	require.NoError(t, err)
+
+	_, err = uploader.Upload(context.Background(), "a.jpg", strings.NewReader("hello"), 10, "image/jpeg")
+	assert.ErrorIs(t, err, ErrSizeChanged)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "no partial file is left behind")
+}
@@ -7,11 +7,15 @@ import (
 	"io"
 	"path"
 	"strings"
+	"time"
+
+	"hexagon/upload"
 
 	aws "github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 var ErrMissingBucket = errors.New("s3: bucket is required")
@@ -88,10 +92,7 @@ func NewUploader(ctx context.Context, cfg Config) (*Uploader, error) {
 }
 
 func (u *Uploader) Upload(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
-	key = strings.Trim(strings.TrimSpace(key), "/")
-	if u.prefix != "" {
-		key = path.Join(u.prefix, key)
-	}
+	key = u.objectKey(key)
 
 	input := &s3.PutObjectInput{
 		Bucket:      &u.bucket,
@@ -110,3 +111,87 @@ func (u *Uploader) Upload(ctx context.Context, key string, body io.Reader, size
 
 	return u.baseURL + "/" + key, nil
 }
+
+// Delete removes the object behind url. URLs outside baseURL, such as images
+// hosted elsewhere and linked directly, are left alone.
+func (u *Uploader) Delete(ctx context.Context, url string) error {
+	key, ok := strings.CutPrefix(strings.TrimSpace(url), u.baseURL+"/")
+	if !ok || key == "" {
+		return nil
+	}
+
+	_, err := u.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: &u.bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return fmt.Errorf("s3: delete object: %w", err)
+	}
+
+	return nil
+}
+
+// PresignPut signs a PUT for key with Content-Type and Content-Length
+// included in the signature, so S3 rejects any other type or size.
+func (u *Uploader) PresignPut(ctx context.Context, key, contentType string, size int64, ttl time.Duration) (upload.PresignedRequest, error) {
+	key = u.objectKey(key)
+
+	req, err := s3.NewPresignClient(u.client).PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:        &u.bucket,
+		Key:           &key,
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return upload.PresignedRequest{}, fmt.Errorf("s3: presign put object: %w", err)
+	}
+
+	headers := make(map[string]string, len(req.SignedHeader))
+	for name := range req.SignedHeader {
+		if strings.EqualFold(name, "Host") {
+			continue
+		}
+
+		headers[name] = req.SignedHeader.Get(name)
+	}
+
+	return upload.PresignedRequest{
+		URL:       req.URL,
+		Method:    req.Method,
+		Headers:   headers,
+		ObjectURL: u.baseURL + "/" + key,
+	}, nil
+}
+
+func (u *Uploader) Open(ctx context.Context, key string) (upload.StoredObject, error) {
+	key = u.objectKey(key)
+
+	out, err := u.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &u.bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return upload.StoredObject{}, upload.ErrObjectNotFound
+		}
+
+		return upload.StoredObject{}, fmt.Errorf("s3: get object: %w", err)
+	}
+
+	return upload.StoredObject{
+		Body:        out.Body,
+		URL:         u.baseURL + "/" + key,
+		Size:        aws.ToInt64(out.ContentLength),
+		ContentType: aws.ToString(out.ContentType),
+	}, nil
+}
+
+func (u *Uploader) objectKey(key string) string {
+	key = strings.Trim(strings.TrimSpace(key), "/")
+	if u.prefix != "" {
+		key = path.Join(u.prefix, key)
+	}
+
+	return key
+}
@@ -7,6 +7,9 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
+
+	"hexagon/upload"
 
 	aws "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/credentials"
@@ -73,6 +76,121 @@ func TestUploader_Upload_WithEndpoint(t *testing.T) {
 	assert.Equal(t, payload, bodyBytes)
 }
 
+func TestUploader_Delete(t *testing.T) {
+	var gotMethod, gotPath string
+	calls := 0
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		gotMethod = req.Method
+		gotPath = req.URL.Path
+
+		return &http.Response{
+			StatusCode: http.StatusNoContent,
+			Body:       io.NopCloser(strings.NewReader("")),
+			Header:     make(http.Header),
+			Request:    req,
+		}, nil
+	})
+
+	awsCfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider("key", "secret", "")),
+		HTTPClient:  &http.Client{Transport: transport},
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String("https://s3.local")
+		o.UsePathStyle = true
+	})
+
+	uploader := &Uploader{
+		client:  client,
+		bucket:  "test-bucket",
+		baseURL: "https://s3.local/test-bucket",
+	}
+
+	require.NoError(t, uploader.Delete(context.Background(), "https://s3.local/test-bucket/uploads/image.jpg"))
+	assert.Equal(t, http.MethodDelete, gotMethod)
+	assert.Equal(t, "/test-bucket/uploads/image.jpg", gotPath)
+
+	require.NoError(t, uploader.Delete(context.Background(), "https://cdn.example.com/image.jpg"))
+	assert.Equal(t, 1, calls)
+}
+
+func TestUploader_PresignPut(t *testing.T) {
+	uploader := &Uploader{
+		client: newTestClient(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			t.Fatalf("presign must not call S3")
+			return nil, nil
+		})),
+		bucket:  "test-bucket",
+		baseURL: "https://s3.local/test-bucket",
+		prefix:  "uploads",
+	}
+
+	req, err := uploader.PresignPut(context.Background(), "hotel-images/a/original.jpg", "image/jpeg", 1234, 15*time.Minute)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPut, req.Method)
+	assert.True(t, strings.HasPrefix(req.URL, "https://s3.local/test-bucket/uploads/hotel-images/a/original.jpg?"))
+	assert.Contains(t, req.URL, "X-Amz-Signature=")
+	assert.Contains(t, req.URL, "X-Amz-Expires=900")
+	assert.Equal(t, "image/jpeg", req.Headers["Content-Type"])
+	assert.Equal(t, "1234", req.Headers["Content-Length"])
+	assert.Equal(t, "https://s3.local/test-bucket/uploads/hotel-images/a/original.jpg", req.ObjectURL)
+	assert.NotContains(t, req.Headers, "Host")
+}
+
+func TestUploader_Open(t *testing.T) {
+	uploader := &Uploader{
+		client: newTestClient(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if strings.HasSuffix(req.URL.Path, "/missing.jpg") {
+				return &http.Response{
+					StatusCode: http.StatusNotFound,
+					Body:       io.NopCloser(strings.NewReader(`<Error><Code>NoSuchKey</Code></Error>`)),
+					Header:     http.Header{"Content-Type": []string{"application/xml"}},
+					Request:    req,
+				}, nil
+			}
+
+			return &http.Response{
+				StatusCode:    http.StatusOK,
+				Body:          io.NopCloser(strings.NewReader("hello")),
+				Header:        http.Header{"Content-Type": []string{"image/png"}, "Content-Length": []string{"5"}},
+				ContentLength: 5,
+				Request:       req,
+			}, nil
+		})),
+		bucket:  "test-bucket",
+		baseURL: "https://s3.local/test-bucket",
+	}
+
+	obj, err := uploader.Open(context.Background(), "a/original.png")
+	require.NoError(t, err)
+	defer obj.Body.Close()
+
+	assert.Equal(t, "https://s3.local/test-bucket/a/original.png", obj.URL)
+	assert.Equal(t, int64(5), obj.Size)
+	assert.Equal(t, "image/png", obj.ContentType)
+
+	_, err = uploader.Open(context.Background(), "a/missing.jpg")
+	assert.ErrorIs(t, err, upload.ErrObjectNotFound)
+}
+
+func newTestClient(transport http.RoundTripper) *s3.Client {
+	awsCfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider("key", "secret", "")),
+		HTTPClient:  &http.Client{Transport: transport},
+	}
+
+	return s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String("https://s3.local")
+		o.UsePathStyle = true
+	})
+}
+
 type roundTripFunc func(req *http.Request) (*http.Response, error)
 
 func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -0,0 +1,68 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA1 seed from RFC 6238 appendix B.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerator_RFC6238Vectors(t *testing.T) {
+	g := NewGenerator("Hexagon")
+	g.Digits = 8
+
+	vectors := map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	}
+
+	for unix, want := range vectors {
+		code, err := g.Code(rfcSecret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, want, code, "t=%d", unix)
+	}
+}
+
+func TestGenerator_ValidateAcceptsAdjacentSteps(t *testing.T) {
+	g := NewGenerator("Hexagon")
+	secret, err := g.GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Unix(1_700_000_000, 0)
+	previous, err := g.Code(secret, now.Add(-30*time.Second))
+	require.NoError(t, err)
+
+	step, ok := g.Validate(secret, previous, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/30-1, step)
+
+	stale, err := g.Code(secret, now.Add(-90*time.Second))
+	require.NoError(t, err)
+
+	_, ok = g.Validate(secret, stale, now)
+	assert.False(t, ok)
+
+	_, ok = g.Validate(secret, "12345", now)
+	assert.False(t, ok)
+}
+
+func TestGenerator_URI(t *testing.T) {
+	g := NewGenerator("Hexagon")
+
+	uri := g.URI("john@example.com", "JBSWY3DPEHPK3PXP")
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Hexagon:john@example.com?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=Hexagon")
+	assert.Contains(t, uri, "digits=6")
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"hexagon/user"
+
+	"gorm.io/gorm"
+)
+
+// deletedUserName replaces the name of deleted accounts.
+const deletedUserName = "Deleted user"
+
+type AccountDeletionRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountDeletionRepository(db *gorm.DB) *AccountDeletionRepository {
+	return &AccountDeletionRepository{db: db}
+}
+
+func (r *AccountDeletionRepository) ScheduleDeletion(ctx context.Context, userID string, deleteAt time.Time) error {
+	return r.setDeletionScheduledAt(ctx, userID, &deleteAt)
+}
+
+func (r *AccountDeletionRepository) CancelDeletion(ctx context.Context, userID string) error {
+	return r.setDeletionScheduledAt(ctx, userID, nil)
+}
+
+func (r *AccountDeletionRepository) setDeletionScheduledAt(ctx context.Context, userID string, at *time.Time) error {
+	result := r.db.WithContext(ctx).Model(&UserModel{}).
+		Where("id = ? AND deleted_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"deletion_scheduled_at": at,
+			"updated_at":            time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *AccountDeletionRepository) ListDueDeletions(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	var userIDs []string
+
+	err := r.db.WithContext(ctx).Model(&UserModel{}).
+		Where("deletion_scheduled_at <= ? AND deleted_at IS NULL", now).
+		Order("deletion_scheduled_at").
+		Limit(limit).
+		Pluck("id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return userIDs, nil
+}
+
+// Anonymize blanks the users row and forgets the client details of the
+// user's refresh tokens and security events. Rows that only make sense for
+// a live account, such as OAuth links, pending tokens and 2FA secrets, are
+// deleted. Audit events are kept, but lose their client details and the
+// emails in their metadata, including failed logins with the old email.
+func (r *AccountDeletionRepository) Anonymize(ctx context.Context, userID string, deletedAt time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var emails []string
+
+		if err := tx.Model(&UserModel{}).Where("id = ? AND deleted_at IS NULL", userID).Pluck("email", &emails).Error; err != nil {
+			return err
+		}
+
+		if len(emails) == 0 {
+			return user.ErrUserNotFound
+		}
+
+		result := tx.Model(&UserModel{}).
+			Where("id = ? AND deleted_at IS NULL", userID).
+			Updates(map[string]interface{}{
+				"name":                  deletedUserName,
+				"email":                 userID + "@deleted.invalid",
+				"phone":                 "",
+				"password_hash":         "",
+				"email_verified_at":     nil,
+				"phone_verified_at":     nil,
+				"must_reset_password":   false,
+				"role":                  string(user.UserRoleUser),
+				"status":                string(user.UserStatusInactive),
+				"failed_login_attempts": 0,
+				"lock_until":            nil,
+				"lock_escalation_level": 0,
+				"last_failed_login_at":  nil,
+				"deletion_scheduled_at": nil,
+				"deleted_at":            deletedAt,
+				"updated_at":            deletedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return user.ErrUserNotFound
+		}
+
+		clientDetails := map[string]interface{}{"user_agent": "", "ip_address": ""}
+
+		if err := tx.Model(&RefreshTokenModel{}).Where("user_id = ?", userID).Updates(clientDetails).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&SecurityEventModel{}).Where("user_id = ?", userID).Updates(clientDetails).Error; err != nil {
+			return err
+		}
+
+		// The append-only trigger of audit_events allows exactly this update.
+		if err := tx.Model(&AuditEventModel{}).
+			Where("user_id = ? OR actor_id = ? OR lower(metadata->>'email') = lower(?)", userID, userID, emails[0]).
+			Updates(map[string]interface{}{
+				"ip_address": "",
+				"user_agent": "",
+				"metadata":   gorm.Expr("metadata - 'email' - 'new_email'"),
+			}).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{
+			&OAuthProviderAccountModel{},
+			&PasswordResetTokenModel{},
+			&EmailVerificationTokenModel{},
+			&MagicLinkTokenModel{},
+			&SignInAlertModel{},
+			&PhoneVerificationCodeModel{},
+			&UserTOTPModel{},
+			&RecoveryCodeModel{},
+			&PasswordHistoryModel{},
+		} {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"hexagon/audit"
+
+	"gorm.io/gorm"
+)
+
+type AuditEventModel struct {
+	ID        string    `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	EventType string    `gorm:"not null"`
+	ActorID   *string   `gorm:"type:uuid"`
+	UserID    *string   `gorm:"type:uuid"`
+	IPAddress string    `gorm:"not null"`
+	UserAgent string    `gorm:"not null"`
+	Metadata  []byte    `gorm:"type:jsonb;not null;default:'{}'"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (AuditEventModel) TableName() string {
+	return "audit_events"
+}
+
+type AuditEventRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditEventRepository(db *gorm.DB) *AuditEventRepository {
+	return &AuditEventRepository{db: db}
+}
+
+func (r *AuditEventRepository) Append(ctx context.Context, event audit.Event) error {
+	metadata := event.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	model := AuditEventModel{
+		EventType: string(event.Type),
+		IPAddress: event.IPAddress,
+		UserAgent: event.UserAgent,
+		Metadata:  encoded,
+		CreatedAt: event.CreatedAt,
+	}
+
+	if event.ActorID != "" {
+		model.ActorID = &event.ActorID
+	}
+
+	if event.UserID != "" {
+		model.UserID = &event.UserID
+	}
+
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *AuditEventRepository) List(ctx context.Context, filter audit.Filter) (audit.Page, error) {
+	query := r.db.WithContext(ctx).Model(&AuditEventModel{})
+
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+
+	if filter.Type != "" {
+		query = query.Where("event_type = ?", string(filter.Type))
+	}
+
+	if filter.IPAddress != "" {
+		query = query.Where("ip_address = ?", filter.IPAddress)
+	}
+
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+
+	if !filter.To.IsZero() {
+		query = query.Where("created_at <= ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return audit.Page{}, err
+	}
+
+	var models []AuditEventModel
+
+	err := query.
+		Order("created_at DESC, id DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&models).Error
+	if err != nil {
+		return audit.Page{}, err
+	}
+
+	events := make([]audit.Event, len(models))
+	for i, model := range models {
+		event, err := toDomainAuditEvent(model)
+		if err != nil {
+			return audit.Page{}, err
+		}
+
+		events[i] = event
+	}
+
+	return audit.Page{Events: events, Total: int(total)}, nil
+}
+
+func toDomainAuditEvent(model AuditEventModel) (audit.Event, error) {
+	event := audit.Event{
+		ID:        model.ID,
+		Type:      audit.EventType(model.EventType),
+		IPAddress: model.IPAddress,
+		UserAgent: model.UserAgent,
+		CreatedAt: model.CreatedAt,
+	}
+
+	if model.ActorID != nil {
+		event.ActorID = *model.ActorID
+	}
+
+	if model.UserID != nil {
+		event.UserID = *model.UserID
+	}
+
+	if len(model.Metadata) > 0 {
+		if err := json.Unmarshal(model.Metadata, &event.Metadata); err != nil {
+			return audit.Event{}, err
+		}
+	}
+
+	return event, nil
+}
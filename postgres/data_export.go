@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+
+	"hexagon/audit"
+	"hexagon/auth"
+	"hexagon/upload"
+
+	"gorm.io/gorm"
+)
+
+type DataExportRepository struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepository(db *gorm.DB) *DataExportRepository {
+	return &DataExportRepository{db: db}
+}
+
+func (r *DataExportRepository) ListAuditEvents(ctx context.Context, userID string) ([]audit.Event, error) {
+	var models []AuditEventModel
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? OR actor_id = ?", userID, userID).
+		Order("created_at ASC, id ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]audit.Event, len(models))
+	for i, model := range models {
+		event, err := toDomainAuditEvent(model)
+		if err != nil {
+			return nil, err
+		}
+
+		events[i] = event
+	}
+
+	return events, nil
+}
+
+func (r *DataExportRepository) ListSecurityEvents(ctx context.Context, userID string) ([]auth.SecurityEvent, error) {
+	var models []SecurityEventModel
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC, id ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]auth.SecurityEvent, len(models))
+	for i, model := range models {
+		events[i] = auth.SecurityEvent{
+			UserID:    model.UserID,
+			Type:      auth.SecurityEventType(model.EventType),
+			UserAgent: model.UserAgent,
+			IPAddress: model.IPAddress,
+			CreatedAt: model.CreatedAt,
+		}
+
+		if model.FamilyID != nil {
+			events[i].FamilyID = *model.FamilyID
+		}
+	}
+
+	return events, nil
+}
+
+func (r *DataExportRepository) ListUploads(ctx context.Context, ownerID string) ([]upload.Record, error) {
+	var models []UploadModel
+
+	err := r.db.WithContext(ctx).
+		Where("owner_id = ?", ownerID).
+		Order("created_at ASC, id ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]upload.Record, len(models))
+	for i := range models {
+		records[i] = toDomainUpload(models[i])
+	}
+
+	return records, nil
+}
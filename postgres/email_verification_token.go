@@ -16,6 +16,8 @@ type EmailVerificationTokenModel struct {
 	TokenHash string    `gorm:"not null;unique"`
 	ExpiresAt time.Time `gorm:"not null"`
 	UsedAt    *time.Time 
+	NewEmail  *string
+	SessionID *string   `gorm:"type:uuid"`
 	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
 }
 
@@ -39,6 +41,14 @@ func (r *EmailVerificationTokenRepository) Save(ctx context.Context, token auth.
 		UsedAt:    token.UsedAt,
 	}
 
+	if token.NewEmail != "" {
+		model.NewEmail = &token.NewEmail
+	}
+
+	if token.SessionID != "" {
+		model.SessionID = &token.SessionID
+	}
+
 	return r.db.WithContext(ctx).Create(&model).Error
 }
 
@@ -56,12 +66,22 @@ func (r *EmailVerificationTokenRepository) GetActiveByHash(ctx context.Context,
 		return auth.EmailVerificationToken{}, err
 	}
 
-	return auth.EmailVerificationToken{
+	token := auth.EmailVerificationToken{
 		UserID:    model.UserID,
 		TokenHash: model.TokenHash,
 		ExpiresAt: model.ExpiresAt,
 		UsedAt:    model.UsedAt,
-	}, nil
+	}
+
+	if model.NewEmail != nil {
+		token.NewEmail = *model.NewEmail
+	}
+
+	if model.SessionID != nil {
+		token.SessionID = *model.SessionID
+	}
+
+	return token, nil
 }
 
 func (r *EmailVerificationTokenRepository) MarkUsedByHash(ctx context.Context, tokenHash string, usedAt time.Time) error {
@@ -8,6 +8,7 @@ import (
 	"hexagon/hotel"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type HotelModel struct {
@@ -32,10 +33,12 @@ func (HotelModel) TableName() string {
 }
 
 type HotelImageModel struct {
-	ID      string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	HotelID string `gorm:"type:uuid;not null;index"`
-	URL     string `gorm:"not null"`
-	IsCover bool   `gorm:"not null;default:false"`
+	ID        string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	HotelID   string `gorm:"type:uuid;not null;index"`
+	URL       string `gorm:"not null"`
+	AltText   string `gorm:"not null;default:''"`
+	IsCover   bool   `gorm:"not null;default:false"`
+	SortOrder int    `gorm:"not null;default:0"`
 }
 
 func (HotelImageModel) TableName() string {
@@ -64,7 +67,7 @@ func NewHotelRepository(db *gorm.DB) *HotelRepository {
 func (r *HotelRepository) List(ctx context.Context) ([]hotel.Hotel, error) {
 	var models []HotelModel
 	if err := r.db.WithContext(ctx).
-		Preload("Images").
+		Preload("Images", orderImagesBySortOrder).
 		Preload("PaymentOptions").
 		Order("created_at DESC").
 		Find(&models).Error; err != nil {
@@ -82,7 +85,7 @@ func (r *HotelRepository) List(ctx context.Context) ([]hotel.Hotel, error) {
 func (r *HotelRepository) GetByID(ctx context.Context, id string) (hotel.Hotel, error) {
 	var model HotelModel
 	if err := r.db.WithContext(ctx).
-		Preload("Images").
+		Preload("Images", orderImagesBySortOrder).
 		Preload("PaymentOptions").
 		Where("id = ?", id).
 		First(&model).Error; err != nil {
@@ -96,10 +99,16 @@ func (r *HotelRepository) GetByID(ctx context.Context, id string) (hotel.Hotel,
 	return toDomainHotel(model), nil
 }
 
-func (r *HotelRepository) Create(ctx context.Context, h hotel.Hotel) (hotel.Hotel, error) {
+func (r *HotelRepository) CreateTx(ctx context.Context, h hotel.Hotel, fn func(ctx context.Context) error) (hotel.Hotel, error) {
 	var created HotelModel
 
 	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if fn != nil {
+			if err := fn(withTx(ctx, tx)); err != nil {
+				return err
+			}
+		}
+
 		model := HotelModel{
 			Name:               h.Name,
 			Description:        h.Description,
@@ -118,10 +127,12 @@ func (r *HotelRepository) Create(ctx context.Context, h hotel.Hotel) (hotel.Hote
 			images := make([]HotelImageModel, len(h.Images))
 			for i := range h.Images {
 				images[i] = HotelImageModel{
-					ID:      h.Images[i].ID,
-					HotelID: model.ID,
-					URL:     h.Images[i].URL,
-					IsCover: h.Images[i].IsCover,
+					ID:        h.Images[i].ID,
+					HotelID:   model.ID,
+					URL:       h.Images[i].URL,
+					AltText:   h.Images[i].AltText,
+					IsCover:   h.Images[i].IsCover,
+					SortOrder: h.Images[i].SortOrder,
 				}
 			}
 
@@ -157,18 +168,236 @@ func (r *HotelRepository) Create(ctx context.Context, h hotel.Hotel) (hotel.Hote
 	return r.GetByID(ctx, created.ID)
 }
 
+func (r *HotelRepository) ListImages(ctx context.Context, hotelID string) ([]hotel.HotelImage, error) {
+	if err := ensureHotelExists(r.db.WithContext(ctx), hotelID); err != nil {
+		return nil, err
+	}
+
+	return listHotelImages(r.db.WithContext(ctx), hotelID)
+}
+
+// AddImage appends the image after the current last one. The first image of a
+// hotel, or one flagged IsCover, takes over the cover.
+func (r *HotelRepository) AddImageTx(ctx context.Context, img hotel.HotelImage, fn func(ctx context.Context) error) (hotel.HotelImage, error) {
+	var created HotelImageModel
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureHotelExists(tx, img.HotelID); err != nil {
+			return err
+		}
+
+		if fn != nil {
+			if err := fn(withTx(ctx, tx)); err != nil {
+				return err
+			}
+		}
+
+		var stats struct {
+			Count   int64
+			MaxSort int
+		}
+		if err := tx.Model(&HotelImageModel{}).
+			Select("COUNT(*) AS count, COALESCE(MAX(sort_order), -1) AS max_sort").
+			Where("hotel_id = ?", img.HotelID).
+			Scan(&stats).Error; err != nil {
+			return err
+		}
+
+		isCover := img.IsCover || stats.Count == 0
+		if isCover {
+			if err := tx.Model(&HotelImageModel{}).
+				Where("hotel_id = ? AND is_cover", img.HotelID).
+				Update("is_cover", false).Error; err != nil {
+				return err
+			}
+		}
+
+		created = HotelImageModel{
+			HotelID:   img.HotelID,
+			URL:       img.URL,
+			AltText:   img.AltText,
+			IsCover:   isCover,
+			SortOrder: stats.MaxSort + 1,
+		}
+
+		return tx.Create(&created).Error
+	})
+	if err != nil {
+		return hotel.HotelImage{}, err
+	}
+
+	return toDomainHotelImage(created), nil
+}
+
+// DeleteImage removes the image and hands the cover to the next image in
+// order when the removed one was the cover.
+func (r *HotelRepository) DeleteImage(ctx context.Context, hotelID, imageID string) (hotel.HotelImage, error) {
+	var removed HotelImageModel
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locking the hotel serialises concurrent deletes, so two of them
+		// cannot both see a second image and empty the gallery.
+		var parent HotelModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", hotelID).First(&parent).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return hotel.ErrImageNotFound
+			}
+
+			return err
+		}
+
+		if err := tx.Where("id = ? AND hotel_id = ?", imageID, hotelID).First(&removed).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return hotel.ErrImageNotFound
+			}
+
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&HotelImageModel{}).Where("hotel_id = ?", hotelID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count <= 1 {
+			return hotel.ErrLastImage
+		}
+
+		if err := tx.Delete(&HotelImageModel{}, "id = ?", removed.ID).Error; err != nil {
+			return err
+		}
+
+		if !removed.IsCover {
+			return nil
+		}
+
+		var next HotelImageModel
+		if err := tx.Where("hotel_id = ?", hotelID).Order("sort_order ASC, id ASC").First(&next).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		return tx.Model(&HotelImageModel{}).Where("id = ?", next.ID).Update("is_cover", true).Error
+	})
+	if err != nil {
+		return hotel.HotelImage{}, err
+	}
+
+	return toDomainHotelImage(removed), nil
+}
+
+func (r *HotelRepository) ReorderImages(ctx context.Context, hotelID string, imageIDs []string) ([]hotel.HotelImage, error) {
+	var images []hotel.HotelImage
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range imageIDs {
+			if err := tx.Model(&HotelImageModel{}).
+				Where("id = ? AND hotel_id = ?", imageIDs[i], hotelID).
+				Update("sort_order", i).Error; err != nil {
+				return err
+			}
+		}
+
+		var err error
+		images, err = listHotelImages(tx, hotelID)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+func (r *HotelRepository) SetCoverImage(ctx context.Context, hotelID, imageID string) ([]hotel.HotelImage, error) {
+	var images []hotel.HotelImage
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var target HotelImageModel
+		if err := tx.Where("id = ? AND hotel_id = ?", imageID, hotelID).First(&target).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return hotel.ErrImageNotFound
+			}
+
+			return err
+		}
+
+		// Clear first so the partial unique index never sees two covers.
+		if err := tx.Model(&HotelImageModel{}).
+			Where("hotel_id = ? AND is_cover", hotelID).
+			Update("is_cover", false).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&HotelImageModel{}).Where("id = ?", target.ID).Update("is_cover", true).Error; err != nil {
+			return err
+		}
+
+		var err error
+		images, err = listHotelImages(tx, hotelID)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+func ensureHotelExists(db *gorm.DB, hotelID string) error {
+	var count int64
+	if err := db.Model(&HotelModel{}).Where("id = ?", hotelID).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return hotel.ErrHotelNotFound
+	}
+
+	return nil
+}
+
+func listHotelImages(db *gorm.DB, hotelID string) ([]hotel.HotelImage, error) {
+	var models []HotelImageModel
+	if err := orderImagesBySortOrder(db.Where("hotel_id = ?", hotelID)).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	images := make([]hotel.HotelImage, len(models))
+	for i := range models {
+		images[i] = toDomainHotelImage(models[i])
+	}
+
+	return images, nil
+}
+
+func orderImagesBySortOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order ASC, id ASC")
+}
+
+func toDomainHotelImage(model HotelImageModel) hotel.HotelImage {
+	return hotel.HotelImage{
+		ID:        model.ID,
+		HotelID:   model.HotelID,
+		URL:       model.URL,
+		AltText:   model.AltText,
+		IsCover:   model.IsCover,
+		SortOrder: model.SortOrder,
+	}
+}
+
 func toDomainHotel(model HotelModel) hotel.Hotel {
 	checkInTime, _ := parseClock(model.CheckInTime)
 	checkOutTime, _ := parseClock(model.CheckOutTime)
 
 	images := make([]hotel.HotelImage, len(model.Images))
 	for i := range model.Images {
-		images[i] = hotel.HotelImage{
-			ID:      model.Images[i].ID,
-			HotelID: model.Images[i].HotelID,
-			URL:     model.Images[i].URL,
-			IsCover: model.Images[i].IsCover,
-		}
+		images[i] = toDomainHotelImage(model.Images[i])
 	}
 
 	paymentOptions := make([]hotel.HotelPaymentOption, len(model.PaymentOptions))
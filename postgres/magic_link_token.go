@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"hexagon/auth"
+
+	"gorm.io/gorm"
+)
+
+type MagicLinkTokenModel struct {
+	ID        string    `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    string    `gorm:"type:uuid;not null"`
+	TokenHash string    `gorm:"not null;unique"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
+}
+
+func (MagicLinkTokenModel) TableName() string {
+	return "magic_link_tokens"
+}
+
+type MagicLinkTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewMagicLinkTokenRepository(db *gorm.DB) *MagicLinkTokenRepository {
+	return &MagicLinkTokenRepository{db: db}
+}
+
+func (r *MagicLinkTokenRepository) Save(ctx context.Context, token auth.MagicLinkToken) error {
+	model := MagicLinkTokenModel{
+		UserID:    token.UserID,
+		TokenHash: token.TokenHash,
+		ExpiresAt: token.ExpiresAt,
+		UsedAt:    token.UsedAt,
+	}
+
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *MagicLinkTokenRepository) GetActiveByHash(ctx context.Context, tokenHash string) (auth.MagicLinkToken, error) {
+	var model MagicLinkTokenModel
+
+	err := r.db.WithContext(ctx).
+		Where("token_hash = ? AND used_at IS NULL", tokenHash).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.MagicLinkToken{}, errors.New("magic link token not found")
+		}
+
+		return auth.MagicLinkToken{}, err
+	}
+
+	return auth.MagicLinkToken{
+		UserID:    model.UserID,
+		TokenHash: model.TokenHash,
+		ExpiresAt: model.ExpiresAt,
+		UsedAt:    model.UsedAt,
+	}, nil
+}
+
+func (r *MagicLinkTokenRepository) MarkUsedByHash(ctx context.Context, tokenHash string, usedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&MagicLinkTokenModel{}).
+		Where("token_hash = ? AND used_at IS NULL", tokenHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("magic link token not found")
+	}
+
+	return nil
+}
@@ -46,7 +46,7 @@ func (r *OAuthProviderAccountRepository) GetUserIDByProvider(
 		First(&model).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", errors.New("oauth account not found")
+			return "", auth.ErrOAuthAccountNotLinked
 		}
 
 		return "", err
@@ -88,6 +88,46 @@ func (r *OAuthProviderAccountRepository) Upsert(
 	return nil
 }
 
+func (r *OAuthProviderAccountRepository) ListByUserID(ctx context.Context, userID string) ([]auth.OAuthAccount, error) {
+	var models []OAuthProviderAccountModel
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("provider ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]auth.OAuthAccount, 0, len(models))
+	for _, model := range models {
+		accounts = append(accounts, auth.OAuthAccount{
+			UserID:         model.UserID,
+			Provider:       auth.OAuthProvider(model.Provider),
+			ProviderUserID: model.ProviderUserID,
+			ProviderEmail:  model.ProviderEmail,
+			LinkedAt:       model.CreatedAt,
+		})
+	}
+
+	return accounts, nil
+}
+
+func (r *OAuthProviderAccountRepository) Delete(ctx context.Context, userID string, provider auth.OAuthProvider) error {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND provider = ?", userID, string(provider)).
+		Delete(&OAuthProviderAccountModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return auth.ErrOAuthAccountNotLinked
+	}
+
+	return nil
+}
+
 func isOAuthProviderConflict(err error) bool {
 	var pqErr *pq.Error
 	if errors.As(err, &pqErr) {
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasswordHistoryModel struct {
+	ID           string    `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID       string    `gorm:"type:uuid;not null"`
+	PasswordHash string    `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"not null"`
+}
+
+func (PasswordHistoryModel) TableName() string {
+	return "password_history"
+}
+
+type PasswordHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordHistoryRepository(db *gorm.DB) *PasswordHistoryRepository {
+	return &PasswordHistoryRepository{db: db}
+}
+
+func (r *PasswordHistoryRepository) RecentPasswordHashes(ctx context.Context, userID string, limit int) ([]string, error) {
+	var hashes []string
+
+	err := r.db.WithContext(ctx).Model(&PasswordHistoryModel{}).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Pluck("password_hash", &hashes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// AddPasswordHash joins the transaction of ctx, e.g. the one creating the
+// user.
+func (r *PasswordHistoryRepository) AddPasswordHash(ctx context.Context, userID, hash string, keep int) error {
+	db := r.db
+	if tx := txFromContext(ctx); tx != nil {
+		db = tx
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&PasswordHistoryModel{
+			UserID:       userID,
+			PasswordHash: hash,
+			CreatedAt:    time.Now().UTC(),
+		}).Error; err != nil {
+			return err
+		}
+
+		kept := tx.Model(&PasswordHistoryModel{}).
+			Select("id").
+			Where("user_id = ?", userID).
+			Order("created_at DESC").
+			Limit(keep)
+
+		return tx.Where("user_id = ? AND id NOT IN (?)", userID, kept).Delete(&PasswordHistoryModel{}).Error
+	})
+}
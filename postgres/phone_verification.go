@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"hexagon/auth"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PhoneVerificationCodeModel struct {
+	UserID    string    `gorm:"type:uuid;primaryKey"`
+	Phone     string    `gorm:"not null"`
+	CodeHash  string    `gorm:"not null"`
+	Attempts  int       `gorm:"not null;default:0"`
+	ExpiresAt time.Time `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (PhoneVerificationCodeModel) TableName() string {
+	return "phone_verification_codes"
+}
+
+type PhoneVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewPhoneVerificationRepository(db *gorm.DB) *PhoneVerificationRepository {
+	return &PhoneVerificationRepository{db: db}
+}
+
+func (r *PhoneVerificationRepository) Save(ctx context.Context, code auth.PhoneVerificationCode) error {
+	model := PhoneVerificationCodeModel{
+		UserID:    code.UserID,
+		Phone:     code.Phone,
+		CodeHash:  code.CodeHash,
+		Attempts:  code.Attempts,
+		ExpiresAt: code.ExpiresAt,
+		CreatedAt: code.CreatedAt,
+	}
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"phone", "code_hash", "attempts", "expires_at", "created_at"}),
+	}).Create(&model).Error
+}
+
+func (r *PhoneVerificationRepository) Get(ctx context.Context, userID string) (auth.PhoneVerificationCode, error) {
+	var model PhoneVerificationCodeModel
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.PhoneVerificationCode{}, auth.ErrInvalidPhoneCode
+		}
+
+		return auth.PhoneVerificationCode{}, err
+	}
+
+	return auth.PhoneVerificationCode{
+		UserID:    model.UserID,
+		Phone:     model.Phone,
+		CodeHash:  model.CodeHash,
+		Attempts:  model.Attempts,
+		ExpiresAt: model.ExpiresAt,
+		CreatedAt: model.CreatedAt,
+	}, nil
+}
+
+// IncrementAttempts counts the attempt in the database, so concurrent
+// guesses cannot all read the same count.
+func (r *PhoneVerificationRepository) IncrementAttempts(ctx context.Context, userID string) (int, error) {
+	var model PhoneVerificationCodeModel
+
+	result := r.db.WithContext(ctx).Model(&model).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "attempts"}}}).
+		Where("user_id = ?", userID).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return 0, auth.ErrInvalidPhoneCode
+	}
+
+	return model.Attempts, nil
+}
+
+func (r *PhoneVerificationRepository) Delete(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&PhoneVerificationCodeModel{}).Error
+}
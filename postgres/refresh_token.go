@@ -13,6 +13,7 @@ import (
 type RefreshTokenModel struct {
 	ID        string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	UserID    string `gorm:"type:uuid;not null"`
+	FamilyID  string `gorm:"type:uuid;not null"`
 	TokenHash string `gorm:"not null;unique"`
 	UserAgent string
 	IPAddress string
@@ -36,6 +37,7 @@ func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
 func (r *RefreshTokenRepository) Save(ctx context.Context, token auth.RefreshToken) error {
 	model := RefreshTokenModel{
 		UserID:    token.UserID,
+		FamilyID:  token.FamilyID,
 		TokenHash: token.TokenHash,
 		UserAgent: token.UserAgent,
 		IPAddress: token.IPAddress,
@@ -51,11 +53,11 @@ func (r *RefreshTokenRepository) Save(ctx context.Context, token auth.RefreshTok
 	return db.WithContext(ctx).Create(&model).Error
 }
 
-func (r *RefreshTokenRepository) GetActiveByHash(ctx context.Context, tokenHash string) (auth.RefreshToken, error) {
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (auth.RefreshToken, error) {
 	var model RefreshTokenModel
 
 	err := r.db.WithContext(ctx).
-		Where("token_hash = ? AND revoked_at IS NULL", tokenHash).
+		Where("token_hash = ?", tokenHash).
 		First(&model).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -67,6 +69,7 @@ func (r *RefreshTokenRepository) GetActiveByHash(ctx context.Context, tokenHash
 
 	return auth.RefreshToken{
 		UserID:    model.UserID,
+		FamilyID:  model.FamilyID,
 		TokenHash: model.TokenHash,
 		UserAgent: model.UserAgent,
 		IPAddress: model.IPAddress,
@@ -84,12 +87,76 @@ func (r *RefreshTokenRepository) RevokeByHash(ctx context.Context, tokenHash str
 	}
 
 	if result.RowsAffected == 0 {
-		return errors.New("refresh token not found")
+		return auth.ErrRefreshTokenRevoked
 	}
 
 	return nil
 }
 
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&RefreshTokenModel{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", revokedAt).Error
+}
+
+// sessionRow is one active token with the time its family started.
+type sessionRow struct {
+	FamilyID   string
+	UserAgent  string
+	IPAddress  string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+func (r *RefreshTokenRepository) ListActiveSessions(ctx context.Context, userID string, now time.Time) ([]auth.Session, error) {
+	var rows []sessionRow
+
+	err := r.db.WithContext(ctx).
+		Table("refresh_tokens AS t").
+		Select(`t.family_id, t.user_agent, t.ip_address, t.created_at AS last_used_at,
+			(SELECT MIN(f.created_at) FROM refresh_tokens f WHERE f.family_id = t.family_id) AS created_at`).
+		Where("t.user_id = ? AND t.revoked_at IS NULL AND t.expires_at > ?", userID, now).
+		Order("t.created_at DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]auth.Session, len(rows))
+	for i := range rows {
+		sessions[i] = auth.Session{
+			ID:         rows[i].FamilyID,
+			UserAgent:  rows[i].UserAgent,
+			IPAddress:  rows[i].IPAddress,
+			CreatedAt:  rows[i].CreatedAt,
+			LastUsedAt: rows[i].LastUsedAt,
+		}
+	}
+
+	return sessions, nil
+}
+
+func (r *RefreshTokenRepository) RevokeSession(ctx context.Context, userID, sessionID string, revokedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&RefreshTokenModel{}).
+		Where("user_id = ? AND family_id = ? AND revoked_at IS NULL", userID, sessionID).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return auth.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeOtherSessions(ctx context.Context, userID, keepSessionID string, revokedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&RefreshTokenModel{}).
+		Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, keepSessionID).
+		Update("revoked_at", revokedAt).Error
+}
+
 func (r *RefreshTokenRepository) RevokeAllByUserID(ctx context.Context, userID string, revokedAt time.Time) error {
 	result := r.db.WithContext(ctx).Model(&RefreshTokenModel{}).
 		Where("user_id = ? AND revoked_at IS NULL", userID).
@@ -3,12 +3,15 @@ package postgres
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"strings"
 	"time"
 
 	"hexagon/room"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type RoomModel struct {
@@ -32,10 +35,12 @@ type RoomModel struct {
 func (RoomModel) TableName() string { return "rooms" }
 
 type RoomImageModel struct {
-	ID      string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	RoomID  string `gorm:"type:uuid;not null;index"`
-	URL     string `gorm:"not null"`
-	IsCover bool   `gorm:"not null;default:false"`
+	ID        string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	RoomID    string `gorm:"type:uuid;not null;index"`
+	URL       string `gorm:"not null"`
+	AltText   string `gorm:"not null;default:''"`
+	IsCover   bool   `gorm:"not null;default:false"`
+	SortOrder int    `gorm:"not null;default:0"`
 }
 
 func (RoomImageModel) TableName() string { return "room_images" }
@@ -46,6 +51,9 @@ type RoomAmenityModel struct {
 	Name        string `gorm:"not null"`
 	Description string
 	Icon        string
+	Category    string    `gorm:"not null;default:general"`
+	SortOrder   int       `gorm:"not null;default:0"`
+	Names       []byte    `gorm:"type:jsonb;not null;default:'{}'"`
 	CreatedAt   time.Time `gorm:"not null;autoCreateTime"`
 	UpdatedAt   time.Time `gorm:"not null;autoUpdateTime"`
 }
@@ -72,13 +80,33 @@ type RoomInventoryModel struct {
 
 func (RoomInventoryModel) TableName() string { return "room_inventories" }
 
+type RoomStayRestrictionModel struct {
+	ID                string    `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	RoomID            string    `gorm:"type:uuid;not null;index"`
+	Date              time.Time `gorm:"type:date;not null"`
+	MinStay           int       `gorm:"not null;default:0"`
+	MaxStay           int       `gorm:"not null;default:0"`
+	ClosedToArrival   bool      `gorm:"not null;default:false"`
+	ClosedToDeparture bool      `gorm:"not null;default:false"`
+	StopSell          bool      `gorm:"not null;default:false"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (RoomStayRestrictionModel) TableName() string { return "room_stay_restrictions" }
+
 type RoomRepository struct {
 	db *gorm.DB
 }
 
 func NewRoomRepository(db *gorm.DB) *RoomRepository { return &RoomRepository{db: db} }
 
-func (r *RoomRepository) CreateRoom(ctx context.Context, rm room.Room) (room.Room, error) {
+func (r *RoomRepository) CreateRoomTx(ctx context.Context, rm room.Room, fn func(ctx context.Context) error) (room.Room, error) {
+	bedOptions, err := encodeBedOptions(rm.BedOptions)
+	if err != nil {
+		return room.Room{}, err
+	}
+
 	model := RoomModel{
 		HotelID:      rm.HotelID,
 		Name:         rm.Name,
@@ -87,12 +115,18 @@ func (r *RoomRepository) CreateRoom(ctx context.Context, rm room.Room) (room.Roo
 		MaxAdult:     rm.MaxAdult,
 		MaxChild:     rm.MaxChild,
 		MaxOccupancy: rm.MaxOccupancy,
-		BedOptions:   emptyJSONIfNil(rm.BedOptions),
+		BedOptions:   bedOptions,
 		SizeSqm:      rm.SizeSqm,
 		Status:       string(rm.Status),
 	}
 
-	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if fn != nil {
+			if err := fn(withTx(ctx, tx)); err != nil {
+				return err
+			}
+		}
+
 		if err := tx.Create(&model).Error; err != nil {
 			return err
 		}
@@ -100,7 +134,13 @@ func (r *RoomRepository) CreateRoom(ctx context.Context, rm room.Room) (room.Roo
 		if len(rm.Images) > 0 {
 			images := make([]RoomImageModel, len(rm.Images))
 			for i := range rm.Images {
-				images[i] = RoomImageModel{RoomID: model.ID, URL: rm.Images[i].URL, IsCover: rm.Images[i].IsCover}
+				images[i] = RoomImageModel{
+					RoomID:    model.ID,
+					URL:       rm.Images[i].URL,
+					AltText:   rm.Images[i].AltText,
+					IsCover:   rm.Images[i].IsCover,
+					SortOrder: rm.Images[i].SortOrder,
+				}
 			}
 
 			if err := tx.Create(&images).Error; err != nil {
@@ -115,7 +155,7 @@ func (r *RoomRepository) CreateRoom(ctx context.Context, rm room.Room) (room.Roo
 	}
 
 	var created RoomModel
-	if err := r.db.WithContext(ctx).Preload("Images").Where("id = ?", model.ID).First(&created).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Images", orderImagesBySortOrder).Where("id = ?", model.ID).First(&created).Error; err != nil {
 		return room.Room{}, err
 	}
 
@@ -131,25 +171,146 @@ func (r *RoomRepository) CreateRoom(ctx context.Context, rm room.Room) (room.Roo
 }
 
 func (r *RoomRepository) CreateAmenity(ctx context.Context, amenity room.RoomAmenity) (room.RoomAmenity, error) {
-	model := RoomAmenityModel{
-		Code:        amenity.Code,
-		Name:        amenity.Name,
-		Description: amenity.Description,
-		Icon:        amenity.Icon,
+	model, err := toRoomAmenityModel(amenity)
+	if err != nil {
+		return room.RoomAmenity{}, err
 	}
+
 	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		if isDuplicateAmenityCodeError(err) {
+			return room.RoomAmenity{}, room.ErrAmenityCodeExists
+		}
+
 		return room.RoomAmenity{}, err
 	}
 
-	return room.RoomAmenity{
-		ID:          model.ID,
-		Code:        model.Code,
-		Name:        model.Name,
-		Description: model.Description,
-		Icon:        model.Icon,
-		CreatedAt:   model.CreatedAt,
-		UpdatedAt:   model.UpdatedAt,
-	}, nil
+	return toDomainRoomAmenity(model), nil
+}
+
+func (r *RoomRepository) ListAmenities(ctx context.Context, category room.AmenityCategory) ([]room.RoomAmenity, error) {
+	query := r.db.WithContext(ctx).Model(&RoomAmenityModel{})
+	if category != "" {
+		query = query.Where("category = ?", string(category))
+	}
+
+	var models []RoomAmenityModel
+	if err := query.Order("category ASC, sort_order ASC, name ASC, id ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]room.RoomAmenity, len(models))
+	for i := range models {
+		result[i] = toDomainRoomAmenity(models[i])
+	}
+
+	return result, nil
+}
+
+func (r *RoomRepository) UpdateAmenity(ctx context.Context, amenity room.RoomAmenity) (room.RoomAmenity, error) {
+	model, err := toRoomAmenityModel(amenity)
+	if err != nil {
+		return room.RoomAmenity{}, err
+	}
+
+	res := r.db.WithContext(ctx).Model(&RoomAmenityModel{}).
+		Where("id = ?", amenity.ID).
+		Updates(map[string]any{
+			"code":        model.Code,
+			"name":        model.Name,
+			"description": model.Description,
+			"icon":        model.Icon,
+			"category":    model.Category,
+			"sort_order":  model.SortOrder,
+			"names":       model.Names,
+			"updated_at":  time.Now(),
+		})
+	if res.Error != nil {
+		if isDuplicateAmenityCodeError(res.Error) {
+			return room.RoomAmenity{}, room.ErrAmenityCodeExists
+		}
+
+		return room.RoomAmenity{}, res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return room.RoomAmenity{}, room.ErrAmenityNotFound
+	}
+
+	var updated RoomAmenityModel
+	if err := r.db.WithContext(ctx).Where("id = ?", amenity.ID).First(&updated).Error; err != nil {
+		return room.RoomAmenity{}, err
+	}
+
+	return toDomainRoomAmenity(updated), nil
+}
+
+func (r *RoomRepository) CountAmenityUsage(ctx context.Context, amenityID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&RoomAmenityMapModel{}).
+		Where("amenity_id = ?", amenityID).
+		Count(&count).Error
+
+	return count, err
+}
+
+// DeleteAmenity removes the amenity and its room mappings in one transaction
+// instead of relying on the FK cascade.
+func (r *RoomRepository) DeleteAmenity(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("amenity_id = ?", id).Delete(&RoomAmenityMapModel{}).Error; err != nil {
+			return err
+		}
+
+		res := tx.Where("id = ?", id).Delete(&RoomAmenityModel{})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return room.ErrAmenityNotFound
+		}
+
+		return nil
+	})
+}
+
+// ReplaceRoomAmenities swaps a room's amenity set in one transaction, so the
+// room never shows a partial set and unknown ids leave it untouched.
+func (r *RoomRepository) ReplaceRoomAmenities(ctx context.Context, roomID string, amenityIDs []string) ([]room.RoomAmenity, error) {
+	unique := uniqueTrimmedStrings(amenityIDs)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var roomCount int64
+		if err := tx.Model(&RoomModel{}).Where("id = ?", roomID).Count(&roomCount).Error; err != nil {
+			return err
+		}
+
+		if roomCount == 0 {
+			return room.ErrRoomNotFound
+		}
+
+		if len(unique) > 0 {
+			var amenityCount int64
+			if err := tx.Model(&RoomAmenityModel{}).Where("id IN ?", unique).Count(&amenityCount).Error; err != nil {
+				return err
+			}
+
+			if amenityCount != int64(len(unique)) {
+				return room.ErrAmenityNotFound
+			}
+		}
+
+		if err := tx.Where("room_id = ?", roomID).Delete(&RoomAmenityMapModel{}).Error; err != nil {
+			return err
+		}
+
+		return createRoomAmenityMaps(tx, roomID, unique)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.listRoomAmenities(ctx, roomID)
 }
 
 func (r *RoomRepository) CreateInventory(ctx context.Context, inv room.RoomInventory) (room.RoomInventory, error) {
@@ -164,14 +325,153 @@ func (r *RoomRepository) CreateInventory(ctx context.Context, inv room.RoomInven
 		return room.RoomInventory{}, err
 	}
 
-	return room.RoomInventory{
-		ID:              model.ID,
-		RoomID:          model.RoomID,
-		Date:            model.Date,
-		TotalInventory:  model.TotalInventory,
-		HeldInventory:   model.HeldInventory,
-		BookedInventory: model.BookedInventory,
-	}, nil
+	return toDomainRoomInventory(model), nil
+}
+
+func (r *RoomRepository) GetRoomByID(ctx context.Context, id string) (room.Room, error) {
+	var model RoomModel
+	if err := r.db.WithContext(ctx).Preload("Images", orderImagesBySortOrder).Where("id = ?", id).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return room.Room{}, room.ErrRoomNotFound
+		}
+
+		return room.Room{}, err
+	}
+
+	amenities, err := r.listRoomAmenities(ctx, model.ID)
+	if err != nil {
+		return room.Room{}, err
+	}
+
+	result := toDomainRoom(model)
+	result.Amenities = amenities
+
+	return result, nil
+}
+
+func (r *RoomRepository) ListRoomsByHotel(ctx context.Context, hotelID string) ([]room.Room, error) {
+	var hotelCount int64
+	if err := r.db.WithContext(ctx).Model(&HotelModel{}).Where("id = ?", hotelID).Count(&hotelCount).Error; err != nil {
+		return nil, err
+	}
+
+	if hotelCount == 0 {
+		return nil, room.ErrHotelNotFound
+	}
+
+	var models []RoomModel
+	if err := r.db.WithContext(ctx).
+		Preload("Images", orderImagesBySortOrder).
+		Where("hotel_id = ?", hotelID).
+		Order("name ASC").
+		Order("id ASC").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	rooms := make([]room.Room, len(models))
+	for i := range models {
+		rooms[i] = toDomainRoom(models[i])
+	}
+
+	return rooms, nil
+}
+
+// ListInventories returns stored inventory rows for the given rooms with
+// date in [from, to]. Missing nights are simply absent from the result.
+func (r *RoomRepository) ListInventories(ctx context.Context, roomIDs []string, from, to time.Time) ([]room.RoomInventory, error) {
+	result := make([]room.RoomInventory, 0)
+
+	for _, roomIDChunk := range chunkStrings(roomIDs, roomQueryChunkSize) {
+		var models []RoomInventoryModel
+		if err := r.db.WithContext(ctx).
+			Where("room_id IN ?", roomIDChunk).
+			Where("date >= ? AND date <= ?", from, to).
+			Order("room_id ASC").
+			Order("date ASC").
+			Find(&models).Error; err != nil {
+			return nil, err
+		}
+
+		for i := range models {
+			result = append(result, toDomainRoomInventory(models[i]))
+		}
+	}
+
+	return result, nil
+}
+
+func (r *RoomRepository) UpsertStayRestrictions(ctx context.Context, restrictions []room.StayRestriction) ([]room.StayRestriction, error) {
+	if len(restrictions) == 0 {
+		return []room.StayRestriction{}, nil
+	}
+
+	models := make([]RoomStayRestrictionModel, len(restrictions))
+	for i := range restrictions {
+		models[i] = RoomStayRestrictionModel{
+			RoomID:            restrictions[i].RoomID,
+			Date:              restrictions[i].Date,
+			MinStay:           restrictions[i].MinStay,
+			MaxStay:           restrictions[i].MaxStay,
+			ClosedToArrival:   restrictions[i].ClosedToArrival,
+			ClosedToDeparture: restrictions[i].ClosedToDeparture,
+			StopSell:          restrictions[i].StopSell,
+		}
+	}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "room_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"min_stay", "max_stay", "closed_to_arrival", "closed_to_departure", "stop_sell", "updated_at",
+		}),
+	}).Create(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]room.StayRestriction, len(models))
+	for i := range models {
+		result[i] = toDomainStayRestriction(models[i])
+	}
+
+	return result, nil
+}
+
+func (r *RoomRepository) ListStayRestrictions(ctx context.Context, roomIDs []string, from, to time.Time) ([]room.StayRestriction, error) {
+	return listStayRestrictions(r.db.WithContext(ctx), roomIDs, from, to)
+}
+
+func (r *RoomRepository) DeleteStayRestrictions(ctx context.Context, roomID string, from, to time.Time) error {
+	return r.db.WithContext(ctx).
+		Where("room_id = ?", roomID).
+		Where("date >= ? AND date <= ?", from, to).
+		Delete(&RoomStayRestrictionModel{}).Error
+}
+
+/*
+listStayRestrictions loads restrictions with date in [from, to] for the given
+rooms. It is shared with the search repository, which enforces them.
+*/
+func listStayRestrictions(db *gorm.DB, roomIDs []string, from, to time.Time) ([]room.StayRestriction, error) {
+	result := make([]room.StayRestriction, 0)
+
+	for _, roomIDChunk := range chunkStrings(roomIDs, roomQueryChunkSize) {
+		var models []RoomStayRestrictionModel
+		if err := db.
+			Where("room_id IN ?", roomIDChunk).
+			Where("date >= ? AND date <= ?", from, to).
+			Order("room_id ASC").
+			Order("date ASC").
+			Find(&models).Error; err != nil {
+			return nil, err
+		}
+
+		for i := range models {
+			result = append(result, toDomainStayRestriction(models[i]))
+		}
+	}
+
+	return result, nil
 }
 
 func (r *RoomRepository) listRoomAmenities(ctx context.Context, roomID string) ([]room.RoomAmenity, error) {
@@ -182,6 +482,7 @@ func (r *RoomRepository) listRoomAmenities(ctx context.Context, roomID string) (
 		Select("ra.*").
 		Joins("JOIN room_amenity_maps AS ram ON ram.amenity_id = ra.id").
 		Where("ram.room_id = ?", roomID).
+		Order("ra.category ASC, ra.sort_order ASC, ra.name ASC").
 		Scan(&models).Error
 	if err != nil {
 		return nil, err
@@ -189,29 +490,235 @@ func (r *RoomRepository) listRoomAmenities(ctx context.Context, roomID string) (
 
 	result := make([]room.RoomAmenity, len(models))
 	for i := range models {
-		result[i] = room.RoomAmenity{
-			ID:          models[i].ID,
-			Code:        models[i].Code,
-			Name:        models[i].Name,
-			Description: models[i].Description,
-			Icon:        models[i].Icon,
-			CreatedAt:   models[i].CreatedAt,
-			UpdatedAt:   models[i].UpdatedAt,
-		}
+		result[i] = toDomainRoomAmenity(models[i])
 	}
 
 	return result, nil
 }
 
+func (r *RoomRepository) ListImages(ctx context.Context, roomID string) ([]room.RoomImage, error) {
+	if err := ensureRoomExists(r.db.WithContext(ctx), roomID); err != nil {
+		return nil, err
+	}
+
+	return listRoomImages(r.db.WithContext(ctx), roomID)
+}
+
+// AddImage appends the image after the current last one. An image flagged
+// IsCover takes over the cover from the previous one.
+func (r *RoomRepository) AddImageTx(ctx context.Context, img room.RoomImage, fn func(ctx context.Context) error) (room.RoomImage, error) {
+	var created RoomImageModel
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureRoomExists(tx, img.RoomID); err != nil {
+			return err
+		}
+
+		if fn != nil {
+			if err := fn(withTx(ctx, tx)); err != nil {
+				return err
+			}
+		}
+
+		var stats struct {
+			Count   int64
+			MaxSort int
+		}
+		if err := tx.Model(&RoomImageModel{}).
+			Select("COUNT(*) AS count, COALESCE(MAX(sort_order), -1) AS max_sort").
+			Where("room_id = ?", img.RoomID).
+			Scan(&stats).Error; err != nil {
+			return err
+		}
+
+		isCover := img.IsCover || stats.Count == 0
+		if isCover {
+			if err := tx.Model(&RoomImageModel{}).
+				Where("room_id = ? AND is_cover", img.RoomID).
+				Update("is_cover", false).Error; err != nil {
+				return err
+			}
+		}
+
+		created = RoomImageModel{
+			RoomID:    img.RoomID,
+			URL:       img.URL,
+			AltText:   img.AltText,
+			IsCover:   isCover,
+			SortOrder: stats.MaxSort + 1,
+		}
+
+		return tx.Create(&created).Error
+	})
+	if err != nil {
+		return room.RoomImage{}, err
+	}
+
+	return toDomainRoomImage(created), nil
+}
+
+// DeleteImage removes the image and hands the cover to the next image in
+// order when the removed one was the cover.
+func (r *RoomRepository) DeleteImage(ctx context.Context, roomID, imageID string) (room.RoomImage, error) {
+	var removed RoomImageModel
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locking the room serialises concurrent deletes, so two of them
+		// cannot both see a second image and empty the gallery.
+		var parent RoomModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", roomID).First(&parent).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return room.ErrImageNotFound
+			}
+
+			return err
+		}
+
+		if err := tx.Where("id = ? AND room_id = ?", imageID, roomID).First(&removed).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return room.ErrImageNotFound
+			}
+
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&RoomImageModel{}).Where("room_id = ?", roomID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count <= 1 {
+			return room.ErrLastImage
+		}
+
+		if err := tx.Delete(&RoomImageModel{}, "id = ?", removed.ID).Error; err != nil {
+			return err
+		}
+
+		if !removed.IsCover {
+			return nil
+		}
+
+		var next RoomImageModel
+		if err := tx.Where("room_id = ?", roomID).Order("sort_order ASC, id ASC").First(&next).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		return tx.Model(&RoomImageModel{}).Where("id = ?", next.ID).Update("is_cover", true).Error
+	})
+	if err != nil {
+		return room.RoomImage{}, err
+	}
+
+	return toDomainRoomImage(removed), nil
+}
+
+func (r *RoomRepository) ReorderImages(ctx context.Context, roomID string, imageIDs []string) ([]room.RoomImage, error) {
+	var images []room.RoomImage
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range imageIDs {
+			if err := tx.Model(&RoomImageModel{}).
+				Where("id = ? AND room_id = ?", imageIDs[i], roomID).
+				Update("sort_order", i).Error; err != nil {
+				return err
+			}
+		}
+
+		var err error
+		images, err = listRoomImages(tx, roomID)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+func (r *RoomRepository) SetCoverImage(ctx context.Context, roomID, imageID string) ([]room.RoomImage, error) {
+	var images []room.RoomImage
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var target RoomImageModel
+		if err := tx.Where("id = ? AND room_id = ?", imageID, roomID).First(&target).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return room.ErrImageNotFound
+			}
+
+			return err
+		}
+
+		// Clear first so the partial unique index never sees two covers.
+		if err := tx.Model(&RoomImageModel{}).
+			Where("room_id = ? AND is_cover", roomID).
+			Update("is_cover", false).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&RoomImageModel{}).Where("id = ?", target.ID).Update("is_cover", true).Error; err != nil {
+			return err
+		}
+
+		var err error
+		images, err = listRoomImages(tx, roomID)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+func ensureRoomExists(db *gorm.DB, roomID string) error {
+	var count int64
+	if err := db.Model(&RoomModel{}).Where("id = ?", roomID).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return room.ErrRoomNotFound
+	}
+
+	return nil
+}
+
+func listRoomImages(db *gorm.DB, roomID string) ([]room.RoomImage, error) {
+	var models []RoomImageModel
+	if err := orderImagesBySortOrder(db.Where("room_id = ?", roomID)).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	images := make([]room.RoomImage, len(models))
+	for i := range models {
+		images[i] = toDomainRoomImage(models[i])
+	}
+
+	return images, nil
+}
+
+func toDomainRoomImage(model RoomImageModel) room.RoomImage {
+	return room.RoomImage{
+		ID:        model.ID,
+		RoomID:    model.RoomID,
+		URL:       model.URL,
+		AltText:   model.AltText,
+		IsCover:   model.IsCover,
+		SortOrder: model.SortOrder,
+	}
+}
+
 func toDomainRoom(model RoomModel) room.Room {
 	images := make([]room.RoomImage, len(model.Images))
 	for i := range model.Images {
-		images[i] = room.RoomImage{ID: model.Images[i].ID, RoomID: model.Images[i].RoomID, URL: model.Images[i].URL, IsCover: model.Images[i].IsCover}
-	}
-
-	bed := json.RawMessage(model.BedOptions)
-	if len(bed) == 0 {
-		bed = json.RawMessage("[]")
+		images[i] = toDomainRoomImage(model.Images[i])
 	}
 
 	return room.Room{
@@ -223,7 +730,7 @@ func toDomainRoom(model RoomModel) room.Room {
 		MaxAdult:     model.MaxAdult,
 		MaxChild:     model.MaxChild,
 		MaxOccupancy: model.MaxOccupancy,
-		BedOptions:   bed,
+		BedOptions:   decodeBedOptions(model.BedOptions),
 		SizeSqm:      model.SizeSqm,
 		Status:       room.RoomStatus(model.Status),
 		Images:       images,
@@ -232,12 +739,132 @@ func toDomainRoom(model RoomModel) room.Room {
 	}
 }
 
-func emptyJSONIfNil(value []byte) []byte {
-	if len(value) == 0 {
-		return []byte("[]")
+func toRoomAmenityModel(amenity room.RoomAmenity) (RoomAmenityModel, error) {
+	names := amenity.Names
+	if names == nil {
+		names = map[string]string{}
+	}
+
+	encoded, err := json.Marshal(names)
+	if err != nil {
+		return RoomAmenityModel{}, err
+	}
+
+	return RoomAmenityModel{
+		Code:        amenity.Code,
+		Name:        amenity.Name,
+		Description: amenity.Description,
+		Icon:        amenity.Icon,
+		Category:    string(amenity.Category),
+		SortOrder:   amenity.SortOrder,
+		Names:       encoded,
+	}, nil
+}
+
+func toDomainRoomAmenity(model RoomAmenityModel) room.RoomAmenity {
+	names := map[string]string{}
+	if len(model.Names) > 0 {
+		_ = json.Unmarshal(model.Names, &names)
+	}
+
+	return room.RoomAmenity{
+		ID:          model.ID,
+		Code:        model.Code,
+		Name:        model.Name,
+		Description: model.Description,
+		Icon:        model.Icon,
+		Category:    room.AmenityCategory(model.Category),
+		SortOrder:   model.SortOrder,
+		Names:       names,
+		CreatedAt:   model.CreatedAt,
+		UpdatedAt:   model.UpdatedAt,
+	}
+}
+
+func isDuplicateAmenityCodeError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505" && strings.Contains(strings.ToLower(pqErr.Constraint), "code")
+	}
+
+	return false
+}
+
+func toDomainRoomInventory(model RoomInventoryModel) room.RoomInventory {
+	return room.RoomInventory{
+		ID:              model.ID,
+		RoomID:          model.RoomID,
+		Date:            model.Date,
+		TotalInventory:  model.TotalInventory,
+		HeldInventory:   model.HeldInventory,
+		BookedInventory: model.BookedInventory,
+	}
+}
+
+func toDomainStayRestriction(model RoomStayRestrictionModel) room.StayRestriction {
+	return room.StayRestriction{
+		ID:                model.ID,
+		RoomID:            model.RoomID,
+		Date:              model.Date,
+		MinStay:           model.MinStay,
+		MaxStay:           model.MaxStay,
+		ClosedToArrival:   model.ClosedToArrival,
+		ClosedToDeparture: model.ClosedToDeparture,
+		StopSell:          model.StopSell,
+		CreatedAt:         model.CreatedAt,
+		UpdatedAt:         model.UpdatedAt,
+	}
+}
+
+// bedOptionRecord is the JSONB shape of rooms.bed_options.
+type bedOptionRecord struct {
+	Type         string  `json:"type"`
+	Count        int     `json:"count"`
+	MaxExtraBeds int     `json:"maxExtraBeds"`
+	ExtraBedFee  float64 `json:"extraBedFee"`
+	CotAvailable bool    `json:"cotAvailable"`
+	CotFee       float64 `json:"cotFee"`
+}
+
+func encodeBedOptions(options []room.BedOption) ([]byte, error) {
+	records := make([]bedOptionRecord, len(options))
+	for i := range options {
+		records[i] = bedOptionRecord{
+			Type:         string(options[i].Type),
+			Count:        options[i].Count,
+			MaxExtraBeds: options[i].MaxExtraBeds,
+			ExtraBedFee:  options[i].ExtraBedFee,
+			CotAvailable: options[i].CotAvailable,
+			CotFee:       options[i].CotFee,
+		}
+	}
+
+	return json.Marshal(records)
+}
+
+/*
+decodeBedOptions reads rooms.bed_options. Values that are not an array of
+bed options (written before the column was typed) decode to no options.
+*/
+func decodeBedOptions(raw []byte) []room.BedOption {
+	var records []bedOptionRecord
+	if err := json.Unmarshal(raw, &records); err != nil {
+		return []room.BedOption{}
+	}
+
+	options := make([]room.BedOption, len(records))
+	for i := range records {
+		options[i] = room.BedOption{
+			Type:         room.BedType(records[i].Type),
+			Count:        records[i].Count,
+			MaxExtraBeds: records[i].MaxExtraBeds,
+			ExtraBedFee:  records[i].ExtraBedFee,
+			CotAvailable: records[i].CotAvailable,
+			CotFee:       records[i].CotFee,
+		}
 	}
 
-	return value
+	return options
 }
 
 func createRoomAmenityMaps(tx *gorm.DB, roomID string, amenityIDs []string) error {
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"hexagon/room"
 	"hexagon/search"
 
 	"gorm.io/gorm"
@@ -89,7 +90,7 @@ func (r *SearchRepository) SearchHotelRooms(ctx context.Context, hotelID string,
 	}
 
 	searchCandidates := toSearchRoomCandidates(candidates)
-	minimumRooms := search.MinimumRequiredRooms(searchCandidates, criteria.RoomCount, criteria.Adults, criteria.ChildrenAges, hotel.DefaultChildMaxAge)
+	minimumRooms := search.MinimumRequiredRooms(searchCandidates, criteria.RoomCount, criteria.Adults, criteria.ChildrenAges, hotel.DefaultChildMaxAge, criteria.BedRequirement())
 
 	amenityDetailsByRoomID, err := r.roomAmenityDetailsByRoomIDs(ctx, roomIDsFromCandidates(candidates))
 	if err != nil {
@@ -130,6 +131,7 @@ func (r *SearchRepository) SearchHotelRoomCombinations(
 		criteria.Adults,
 		criteria.ChildrenAges,
 		hotel.DefaultChildMaxAge,
+		criteria.BedRequirement(),
 	)
 	combinations = sortAndLimitCombinations(combinations, maxCombinations)
 
@@ -204,11 +206,17 @@ func buildHotelRoomItems(candidates []roomCandidate, amenityDetailsByRoomID map[
 	return rooms
 }
 
-func buildRoomCombinations(candidates []search.RoomCandidate, roomCount, adults int, childrenAges []int, childMaxAge int) []search.RoomCombination {
+func buildRoomCombinations(
+	candidates []search.RoomCandidate,
+	roomCount, adults int,
+	childrenAges []int,
+	childMaxAge int,
+	beds search.BedRequirement,
+) []search.RoomCombination {
 	combinations := make([]search.RoomCombination, 0, len(candidates))
 
 	for i := range candidates {
-		quantity, ok := search.RequiredSingleRoomTypeQuantity(candidates[i], roomCount, adults, childrenAges, childMaxAge)
+		quantity, ok := search.RequiredSingleRoomTypeQuantity(candidates[i], roomCount, adults, childrenAges, childMaxAge, beds)
 		if !ok {
 			continue
 		}
@@ -291,7 +299,7 @@ func evaluateHotelAvailabilityFromCandidates(
 		Find the minimum number of rooms that can satisfy the party constraints.
 		This supports flexible matches when a strict room count is not possible.
 	*/
-	requiredRoomCount := search.MinimumRequiredRooms(toSearchRoomCandidates(candidates), criteria.RoomCount, criteria.Adults, criteria.ChildrenAges, hotel.DefaultChildMaxAge)
+	requiredRoomCount := search.MinimumRequiredRooms(toSearchRoomCandidates(candidates), criteria.RoomCount, criteria.Adults, criteria.ChildrenAges, hotel.DefaultChildMaxAge, criteria.BedRequirement())
 	if requiredRoomCount == 0 {
 		return hotelAvailability{
 			MinPrice:           minPrice,
@@ -442,6 +450,19 @@ func toSearchRoomCandidates(in []roomCandidate) []search.RoomCandidate {
 			MaxChild:       in[i].Room.MaxChild,
 			MaxOccupancy:   in[i].Room.MaxOccupancy,
 			AvailableCount: in[i].AvailableCount,
+			BedOptions:     toCandidateBedOptions(decodeBedOptions(in[i].Room.BedOptions)),
+		}
+	}
+
+	return out
+}
+
+func toCandidateBedOptions(options []room.BedOption) []search.CandidateBedOption {
+	out := make([]search.CandidateBedOption, len(options))
+	for i := range options {
+		out[i] = search.CandidateBedOption{
+			BedType:      string(options[i].Type),
+			MaxExtraBeds: options[i].MaxExtraBeds,
 		}
 	}
 
@@ -493,17 +514,31 @@ func (r *SearchRepository) loadRoomCandidatesByHotels(ctx context.Context, hotel
 		return nil, err
 	}
 
+	restrictionMap, err := r.roomStayRestrictions(ctx, roomIDs, criteria.CheckInDate, criteria.CheckOutDate)
+	if err != nil {
+		return nil, err
+	}
+
 	for i := range roomModels {
 		available := availMap[roomModels[i].ID]
 		if available <= 0 {
 			continue
 		}
 
+		if room.EvaluateStay(restrictionMap[roomModels[i].ID], criteria.CheckInDate, criteria.CheckOutDate) != "" {
+			continue
+		}
+
 		amenityIDs := amenityMap[roomModels[i].ID]
 		if !hasAllAmenities(amenityIDs, criteria.AmenityIDs) {
 			continue
 		}
 
+		bedOptions := toCandidateBedOptions(decodeBedOptions(roomModels[i].BedOptions))
+		if !search.OffersBeds(bedOptions, criteria.BedRequirement()) {
+			continue
+		}
+
 		hotelID := roomModels[i].HotelID
 		result[hotelID] = append(result[hotelID], roomCandidate{
 			Room:           roomModels[i],
@@ -563,6 +598,24 @@ func (r *SearchRepository) roomAvailabilityByDateRange(ctx context.Context, room
 	return result, nil
 }
 
+/*
+roomStayRestrictions loads restrictions from check-in through check-out
+(inclusive, for closed-to-departure) and groups them by room id.
+*/
+func (r *SearchRepository) roomStayRestrictions(ctx context.Context, roomIDs []string, checkIn, checkOut time.Time) (map[string][]room.StayRestriction, error) {
+	restrictions, err := listStayRestrictions(r.db.WithContext(ctx), roomIDs, checkIn, checkOut)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]room.StayRestriction)
+	for i := range restrictions {
+		result[restrictions[i].RoomID] = append(result[restrictions[i].RoomID], restrictions[i])
+	}
+
+	return result, nil
+}
+
 /*
 roomAmenityIDs loads amenity ids per room and groups them by room id.
 */
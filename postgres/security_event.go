@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"hexagon/auth"
+
+	"gorm.io/gorm"
+)
+
+type SecurityEventModel struct {
+	ID        string  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    string  `gorm:"type:uuid;not null"`
+	EventType string  `gorm:"not null"`
+	FamilyID  *string `gorm:"type:uuid"`
+	UserAgent string
+	IPAddress string
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (SecurityEventModel) TableName() string {
+	return "security_events"
+}
+
+type SecurityEventRepository struct {
+	db *gorm.DB
+}
+
+func NewSecurityEventRepository(db *gorm.DB) *SecurityEventRepository {
+	return &SecurityEventRepository{db: db}
+}
+
+func (r *SecurityEventRepository) Save(ctx context.Context, event auth.SecurityEvent) error {
+	model := SecurityEventModel{
+		UserID:    event.UserID,
+		EventType: string(event.Type),
+		UserAgent: event.UserAgent,
+		IPAddress: event.IPAddress,
+		CreatedAt: event.CreatedAt,
+	}
+
+	if event.FamilyID != "" {
+		model.FamilyID = &event.FamilyID
+	}
+
+	return r.db.WithContext(ctx).Create(&model).Error
+}
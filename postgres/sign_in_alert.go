@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"hexagon/auth"
+
+	"gorm.io/gorm"
+)
+
+type SignInAlertModel struct {
+	ID        string    `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    string    `gorm:"type:uuid;not null"`
+	TokenHash string    `gorm:"not null;unique"`
+	UserAgent string    `gorm:"not null"`
+	IPAddress string    `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
+}
+
+func (SignInAlertModel) TableName() string {
+	return "sign_in_alerts"
+}
+
+type SignInAlertRepository struct {
+	db *gorm.DB
+}
+
+func NewSignInAlertRepository(db *gorm.DB) *SignInAlertRepository {
+	return &SignInAlertRepository{db: db}
+}
+
+// HasSignedInFrom looks the client up in the refresh tokens of the user.
+// Rotated, revoked and expired tokens are kept, so they cover every sign-in.
+func (r *SignInAlertRepository) HasSignedInFrom(ctx context.Context, userID string, client auth.ClientInfo) (bool, bool, error) {
+	var history struct {
+		FromClient     bool
+		SignedInBefore bool
+	}
+
+	err := r.db.WithContext(ctx).Model(&RefreshTokenModel{}).
+		Select(
+			"COALESCE(BOOL_OR(COALESCE(user_agent, '') = ? AND COALESCE(ip_address, '') = ?), FALSE) AS from_client, COUNT(*) > 0 AS signed_in_before",
+			client.UserAgent,
+			client.IPAddress,
+		).
+		Where("user_id = ?", userID).
+		Scan(&history).Error
+	if err != nil {
+		return false, false, err
+	}
+
+	return history.FromClient, history.SignedInBefore, nil
+}
+
+func (r *SignInAlertRepository) Save(ctx context.Context, alert auth.SignInAlert) error {
+	model := SignInAlertModel{
+		UserID:    alert.UserID,
+		TokenHash: alert.TokenHash,
+		UserAgent: alert.UserAgent,
+		IPAddress: alert.IPAddress,
+		ExpiresAt: alert.ExpiresAt,
+		UsedAt:    alert.UsedAt,
+	}
+
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *SignInAlertRepository) GetActiveByHash(ctx context.Context, tokenHash string) (auth.SignInAlert, error) {
+	var model SignInAlertModel
+
+	err := r.db.WithContext(ctx).
+		Where("token_hash = ? AND used_at IS NULL", tokenHash).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.SignInAlert{}, auth.ErrInvalidSignInAlertToken
+		}
+
+		return auth.SignInAlert{}, err
+	}
+
+	return auth.SignInAlert{
+		UserID:    model.UserID,
+		TokenHash: model.TokenHash,
+		UserAgent: model.UserAgent,
+		IPAddress: model.IPAddress,
+		ExpiresAt: model.ExpiresAt,
+		UsedAt:    model.UsedAt,
+	}, nil
+}
+
+func (r *SignInAlertRepository) MarkUsedByHash(ctx context.Context, tokenHash string, usedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&SignInAlertModel{}).
+		Where("token_hash = ? AND used_at IS NULL", tokenHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return auth.ErrInvalidSignInAlertToken
+	}
+
+	return nil
+}
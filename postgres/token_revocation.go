@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RevokedAccessTokenModel struct {
+	JTI       string    `gorm:"column:jti;primaryKey"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt time.Time `gorm:"not null"`
+}
+
+func (RevokedAccessTokenModel) TableName() string {
+	return "revoked_access_tokens"
+}
+
+type RevokedSessionModel struct {
+	SessionID string    `gorm:"primaryKey"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt time.Time `gorm:"not null"`
+}
+
+func (RevokedSessionModel) TableName() string {
+	return "revoked_sessions"
+}
+
+type UserTokenRevocationModel struct {
+	UserID        string    `gorm:"type:uuid;primaryKey"`
+	RevokedBefore time.Time `gorm:"not null"`
+}
+
+func (UserTokenRevocationModel) TableName() string {
+	return "user_token_revocations"
+}
+
+type TokenRevocationRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRevocationRepository(db *gorm.DB) *TokenRevocationRepository {
+	return &TokenRevocationRepository{db: db}
+}
+
+// RevokeToken also deletes rows of tokens that have expired anyway, which
+// keeps the table at the size of the tokens revoked within one access TTL.
+func (r *TokenRevocationRepository) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	now := time.Now().UTC()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", now).Delete(&RevokedAccessTokenModel{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&RevokedAccessTokenModel{
+			JTI:       jti,
+			ExpiresAt: expiresAt,
+			RevokedAt: now,
+		}).Error
+	})
+}
+
+// RevokeSessionTokens purges expired rows like RevokeToken. Revoking a
+// session again keeps the later expiry.
+func (r *TokenRevocationRepository) RevokeSessionTokens(ctx context.Context, sessionID string, expiresAt time.Time) error {
+	now := time.Now().UTC()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", now).Delete(&RevokedSessionModel{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "session_id"}},
+			DoUpdates: clause.Set{{
+				Column: clause.Column{Name: "expires_at"},
+				Value:  gorm.Expr("GREATEST(revoked_sessions.expires_at, EXCLUDED.expires_at)"),
+			}},
+		}).Create(&RevokedSessionModel{
+			SessionID: sessionID,
+			ExpiresAt: expiresAt,
+			RevokedAt: now,
+		}).Error
+	})
+}
+
+// RevokeUserTokens never moves an existing cutoff back. The cutoff is
+// stored to the second, the precision of iat.
+func (r *TokenRevocationRepository) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	before = before.Truncate(time.Second)
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Set{{
+			Column: clause.Column{Name: "revoked_before"},
+			Value:  gorm.Expr("GREATEST(user_token_revocations.revoked_before, EXCLUDED.revoked_before)"),
+		}},
+	}).Create(&UserTokenRevocationModel{UserID: userID, RevokedBefore: before}).Error
+}
+
+func (r *TokenRevocationRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Model(&RevokedAccessTokenModel{}).
+		Where("jti = ?", jti).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+func (r *TokenRevocationRepository) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Model(&RevokedSessionModel{}).
+		Where("session_id = ?", sessionID).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+func (r *TokenRevocationRepository) UserTokensRevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	var model UserTokenRevocationModel
+
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Take(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, nil
+	}
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return model.RevokedBefore, nil
+}
@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"hexagon/auth"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserTOTPModel struct {
+	UserID       string `gorm:"type:uuid;primaryKey"`
+	Secret       string `gorm:"not null"`
+	EnabledAt    *time.Time
+	LastUsedStep int64     `gorm:"not null;default:0"`
+	CreatedAt    time.Time `gorm:"not null;autoCreateTime"`
+}
+
+func (UserTOTPModel) TableName() string {
+	return "user_totp"
+}
+
+type RecoveryCodeModel struct {
+	ID        string `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    string `gorm:"type:uuid;not null"`
+	CodeHash  string `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
+}
+
+func (RecoveryCodeModel) TableName() string {
+	return "user_recovery_codes"
+}
+
+type TwoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+func (r *TwoFactorRepository) GetTOTP(ctx context.Context, userID string) (auth.TOTPEnrollment, error) {
+	var model UserTOTPModel
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.TOTPEnrollment{}, auth.ErrTwoFactorNotEnrolled
+		}
+
+		return auth.TOTPEnrollment{}, err
+	}
+
+	return auth.TOTPEnrollment{
+		UserID:       model.UserID,
+		Secret:       model.Secret,
+		EnabledAt:    model.EnabledAt,
+		LastUsedStep: model.LastUsedStep,
+	}, nil
+}
+
+func (r *TwoFactorRepository) SaveTOTP(ctx context.Context, enrollment auth.TOTPEnrollment) error {
+	model := UserTOTPModel{
+		UserID: enrollment.UserID,
+		Secret: enrollment.Secret,
+	}
+
+	// Only a pending enrolment may be replaced; an enabled one must be
+	// disabled first.
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]any{"secret": model.Secret, "last_used_step": 0}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "user_totp.enabled_at IS NULL"}}},
+	}).Create(&model)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return auth.ErrTwoFactorAlreadyEnabled
+	}
+
+	return nil
+}
+
+func (r *TwoFactorRepository) EnableTOTP(
+	ctx context.Context,
+	userID string,
+	step int64,
+	enabledAt time.Time,
+	recoveryCodeHashes []string,
+) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&UserTOTPModel{}).
+			Where("user_id = ? AND enabled_at IS NULL", userID).
+			Updates(map[string]any{"enabled_at": enabledAt, "last_used_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return auth.ErrTwoFactorNotEnrolled
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCodeModel{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]RecoveryCodeModel, len(recoveryCodeHashes))
+		for i, hash := range recoveryCodeHashes {
+			codes[i] = RecoveryCodeModel{UserID: userID, CodeHash: hash}
+		}
+
+		if len(codes) == 0 {
+			return nil
+		}
+
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *TwoFactorRepository) DisableTOTP(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCodeModel{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", userID).Delete(&UserTOTPModel{}).Error
+	})
+}
+
+func (r *TwoFactorRepository) MarkTOTPStepUsed(ctx context.Context, userID string, step int64) error {
+	// The condition on last_used_step makes two concurrent requests with the
+	// same code race for one row update, so only one of them succeeds.
+	result := r.db.WithContext(ctx).Model(&UserTOTPModel{}).
+		Where("user_id = ? AND enabled_at IS NOT NULL AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return auth.ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&RecoveryCodeModel{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return auth.ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"hexagon/upload"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UploadModel struct {
+	ID         string         `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	OwnerID    *string        `gorm:"type:uuid"`
+	Folder     string         `gorm:"not null"`
+	URL        string         `gorm:"not null;unique"`
+	URLs       pq.StringArray `gorm:"type:text[];not null"`
+	Status     string         `gorm:"not null"`
+	CreatedAt  time.Time      `gorm:"not null;autoCreateTime"`
+	AttachedAt *time.Time
+}
+
+func (UploadModel) TableName() string {
+	return "uploads"
+}
+
+type UploadRepository struct {
+	db *gorm.DB
+}
+
+func NewUploadRepository(db *gorm.DB) *UploadRepository {
+	return &UploadRepository{db: db}
+}
+
+func (r *UploadRepository) Create(ctx context.Context, record upload.Record) (upload.Record, error) {
+	model := UploadModel{
+		Folder: record.Folder,
+		URL:    record.URL,
+		URLs:   pq.StringArray(record.URLs),
+		Status: string(record.Status),
+	}
+
+	// Anonymous uploads have no owner; an empty string is not a valid UUID.
+	if record.OwnerID != "" {
+		model.OwnerID = &record.OwnerID
+	}
+
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return upload.Record{}, err
+	}
+
+	return toDomainUpload(model), nil
+}
+
+// Attach locks the matching records first, so two images claiming the same
+// upload at once cannot both succeed. It joins the caller's transaction, if
+// any, so the claim is rolled back with the images.
+func (r *UploadRepository) Attach(ctx context.Context, urls []string, at time.Time) error {
+	db := r.db
+	if tx := txFromContext(ctx); tx != nil {
+		db = tx
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var models []UploadModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("urls && ?", pq.StringArray(urls)).
+			Find(&models).Error; err != nil {
+			return err
+		}
+
+		ids := make([]string, 0, len(models))
+
+		for i := range models {
+			if models[i].Status == string(upload.StatusAttached) || countShared(models[i].URLs, urls) > 1 {
+				return upload.ErrUploadInUse
+			}
+
+			ids = append(ids, models[i].ID)
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		return tx.Model(&UploadModel{}).
+			Where("id IN ?", ids).
+			Updates(map[string]any{
+				"status":      string(upload.StatusAttached),
+				"attached_at": at,
+			}).Error
+	})
+}
+
+func (r *UploadRepository) FindByURL(ctx context.Context, url string) (upload.Record, error) {
+	var model UploadModel
+
+	err := r.db.WithContext(ctx).
+		Where("? = ANY(urls)", url).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return upload.Record{}, upload.ErrRecordNotFound
+		}
+
+		return upload.Record{}, err
+	}
+
+	return toDomainUpload(model), nil
+}
+
+func (r *UploadRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&UploadModel{}, "id = ?", id).Error
+}
+
+func (r *UploadRepository) Detach(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Model(&UploadModel{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":      string(upload.StatusPending),
+			"attached_at": nil,
+		}).Error
+}
+
+func (r *UploadRepository) ListPendingBefore(ctx context.Context, before time.Time, limit int) ([]upload.Record, error) {
+	var models []UploadModel
+
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND created_at < ?", string(upload.StatusPending), before).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]upload.Record, len(models))
+	for i := range models {
+		records[i] = toDomainUpload(models[i])
+	}
+
+	return records, nil
+}
+
+// countShared counts the entries of urls that are one of recordURLs.
+func countShared(recordURLs []string, urls []string) int {
+	count := 0
+
+	for i := range urls {
+		if slices.Contains(recordURLs, urls[i]) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func toDomainUpload(model UploadModel) upload.Record {
+	record := upload.Record{
+		ID:         model.ID,
+		Folder:     model.Folder,
+		URL:        model.URL,
+		URLs:       []string(model.URLs),
+		Status:     upload.Status(model.Status),
+		CreatedAt:  model.CreatedAt,
+		AttachedAt: model.AttachedAt,
+	}
+
+	if model.OwnerID != nil {
+		record.OwnerID = *model.OwnerID
+	}
+
+	return record
+}
@@ -20,12 +20,16 @@ type UserModel struct {
 	Phone               string
 	PasswordHash        string
 	EmailVerifiedAt     *time.Time
+	PhoneVerifiedAt     *time.Time
+	MustResetPassword   bool   `gorm:"not null;default:false"`
 	Role                string `gorm:"not null;default:user"`
 	Status              string `gorm:"not null;default:active"`
 	FailedLoginAttempts int    `gorm:"not null;default:0"`
 	LockUntil           *time.Time
 	LockEscalationLevel int `gorm:"not null;default:0"`
 	LastFailedLoginAt   *time.Time
+	DeletionScheduledAt *time.Time
+	DeletedAt           *time.Time
 	CreatedAt           time.Time `gorm:"not null;autoCreateTime"`
 	UpdatedAt           time.Time `gorm:"not null;autoUpdateTime"`
 }
@@ -139,9 +143,11 @@ func (r *UserRepository) AllUsers(ctx context.Context) ([]user.User, error) {
 // UpdateProfile updates mutable profile fields and returns updated user.
 func (r *UserRepository) UpdateProfile(ctx context.Context, id, name, phone string) (user.User, error) {
 	result := r.db.WithContext(ctx).Model(&UserModel{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"name":       name,
-		"phone":      phone,
-		"updated_at": time.Now().UTC(),
+		"name":  name,
+		"phone": phone,
+		// A new number has to be verified again.
+		"phone_verified_at": gorm.Expr("CASE WHEN phone = ? THEN phone_verified_at END", phone),
+		"updated_at":        time.Now().UTC(),
 	})
 	if result.Error != nil {
 		return user.User{}, result.Error
@@ -154,11 +160,13 @@ func (r *UserRepository) UpdateProfile(ctx context.Context, id, name, phone stri
 	return r.GetByID(ctx, id)
 }
 
-// UpdatePasswordHash updates user's password hash.
+// UpdatePasswordHash updates user's password hash. A new password also
+// ends a required password reset.
 func (r *UserRepository) UpdatePasswordHash(ctx context.Context, id, passwordHash string) error {
 	result := r.db.WithContext(ctx).Model(&UserModel{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"password_hash": passwordHash,
-		"updated_at":    time.Now().UTC(),
+		"password_hash":       passwordHash,
+		"must_reset_password": false,
+		"updated_at":          time.Now().UTC(),
 	})
 	if result.Error != nil {
 		return result.Error
@@ -188,6 +196,149 @@ func (r *UserRepository) UpdateEmailVerifiedAt(ctx context.Context, id string, v
 	return nil
 }
 
+// UpdateEmail moves the user to email, verified at verifiedAt.
+func (r *UserRepository) UpdateEmail(ctx context.Context, id, email string, verifiedAt *time.Time) error {
+	result := r.db.WithContext(ctx).Model(&UserModel{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"email":             email,
+		"email_verified_at": verifiedAt,
+		"updated_at":        time.Now().UTC(),
+	})
+	if result.Error != nil {
+		if isDuplicateEmailError(result.Error) {
+			return user.ErrEmailAlreadyExists
+		}
+
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// UpdatePhoneVerifiedAt marks the user's phone verified if it is still
+// phone.
+func (r *UserRepository) UpdatePhoneVerifiedAt(ctx context.Context, id, phone string, verifiedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&UserModel{}).Where("id = ? AND phone = ?", id, phone).Updates(map[string]interface{}{
+		"phone_verified_at": verifiedAt,
+		"updated_at":        time.Now().UTC(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// RequirePasswordReset blocks password login until the password is
+// updated.
+func (r *UserRepository) RequirePasswordReset(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Model(&UserModel{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"must_reset_password": true,
+		"updated_at":          time.Now().UTC(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// SearchUsers returns the users matching filter, newest first. Deleted
+// accounts are left out.
+func (r *UserRepository) SearchUsers(ctx context.Context, filter user.SearchFilter) (user.SearchResult, error) {
+	query := r.db.WithContext(ctx).Model(&UserModel{}).Where("deleted_at IS NULL")
+
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		like := "%" + escapeLike(strings.ToLower(q)) + "%"
+		query = query.Where("(LOWER(email) LIKE ? OR LOWER(name) LIKE ?)", like, like)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", string(filter.Status))
+	}
+
+	if filter.Role != "" {
+		query = query.Where("role = ?", string(filter.Role))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return user.SearchResult{}, err
+	}
+
+	var models []UserModel
+
+	err := query.
+		Order("created_at DESC, id DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&models).Error
+	if err != nil {
+		return user.SearchResult{}, err
+	}
+
+	users := make([]user.User, len(models))
+	for i, model := range models {
+		users[i] = toDomainUser(model)
+	}
+
+	return user.SearchResult{Users: users, Total: int(total)}, nil
+}
+
+// UpdateRole updates user's role.
+func (r *UserRepository) UpdateRole(ctx context.Context, id string, role user.UserRole) error {
+	result := r.db.WithContext(ctx).Model(&UserModel{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"role":       string(role),
+		"updated_at": time.Now().UTC(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ClearLoginFailures resets the failed login state. Locked users become
+// active; deactivated users stay inactive.
+func (r *UserRepository) ClearLoginFailures(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Model(&UserModel{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"lock_until":            nil,
+		"lock_escalation_level": 0,
+		"last_failed_login_at":  nil,
+		"status": gorm.Expr(
+			"CASE WHEN status = ? THEN ? ELSE status END",
+			string(user.UserStatusLocked),
+			string(user.UserStatusActive),
+		),
+		"updated_at": time.Now().UTC(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return nil
+}
+
 // UpdateStatus updates user's status.
 func (r *UserRepository) UpdateStatus(ctx context.Context, id string, status user.UserStatus) error {
 	result := r.db.WithContext(ctx).Model(&UserModel{}).Where("id = ?", id).Updates(map[string]interface{}{
@@ -242,12 +393,16 @@ func toDomainUser(model UserModel) user.User {
 		Phone:               model.Phone,
 		PasswordHash:        model.PasswordHash,
 		EmailVerifiedAt:     model.EmailVerifiedAt,
+		PhoneVerifiedAt:     model.PhoneVerifiedAt,
+		MustResetPassword:   model.MustResetPassword,
 		Role:                user.UserRole(model.Role),
 		Status:              user.UserStatus(model.Status),
 		FailedLoginAttempts: model.FailedLoginAttempts,
 		LockUntil:           model.LockUntil,
 		LockEscalationLevel: model.LockEscalationLevel,
 		LastFailedLoginAt:   model.LastFailedLoginAt,
+		DeletionScheduledAt: model.DeletionScheduledAt,
+		DeletedAt:           model.DeletedAt,
 		CreatedAt:           model.CreatedAt,
 		UpdatedAt:           model.UpdatedAt,
 	}
@@ -261,15 +416,24 @@ func toModelUser(u user.User) UserModel {
 		Phone:               u.Phone,
 		PasswordHash:        u.PasswordHash,
 		EmailVerifiedAt:     u.EmailVerifiedAt,
+		PhoneVerifiedAt:     u.PhoneVerifiedAt,
+		MustResetPassword:   u.MustResetPassword,
 		Role:                string(u.Role),
 		Status:              string(u.Status),
 		FailedLoginAttempts: u.FailedLoginAttempts,
 		LockUntil:           u.LockUntil,
 		LockEscalationLevel: u.LockEscalationLevel,
 		LastFailedLoginAt:   u.LastFailedLoginAt,
+		DeletionScheduledAt: u.DeletionScheduledAt,
+		DeletedAt:           u.DeletedAt,
 	}
 }
 
+// escapeLike makes value match literally in a LIKE pattern.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
 func isDuplicateEmailError(err error) bool {
 	var pqErr *pq.Error
 	if errors.As(err, &pqErr) {
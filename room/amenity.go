@@ -0,0 +1,89 @@
+package room
+
+import (
+	"strings"
+
+	"hexagon/errs"
+)
+
+var (
+	ErrAmenityNotFound           = errs.Errorf(errs.ENOTFOUND, "room: amenity not found")
+	ErrAmenityCodeExists         = errs.Errorf(errs.ECONFLICT, "room: amenity code already exists")
+	ErrAmenityInUse              = errs.Errorf(errs.ECONFLICT, "room: amenity is mapped to rooms")
+	ErrAmenityCategoryInvalid    = errs.Errorf(errs.EINVALID, "room: invalid amenity category")
+	ErrAmenitySortOrderInvalid   = errs.Errorf(errs.EINVALID, "room: amenity sort order must be >= 0")
+	ErrAmenityTranslationInvalid = errs.Errorf(errs.EINVALID, "room: amenity translation needs a language and a name")
+)
+
+type AmenityCategory string
+
+const (
+	AmenityCategoryGeneral  AmenityCategory = "general"
+	AmenityCategoryBathroom AmenityCategory = "bathroom"
+	AmenityCategoryTech     AmenityCategory = "tech"
+	AmenityCategoryView     AmenityCategory = "view"
+)
+
+func (c AmenityCategory) IsValid() bool {
+	switch c {
+	case AmenityCategoryGeneral, AmenityCategoryBathroom, AmenityCategoryTech, AmenityCategoryView:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a RoomAmenity) ValidateForUpdate() error {
+	if strings.TrimSpace(a.ID) == "" {
+		return ErrAmenityIDRequired
+	}
+
+	return a.ValidateForCreate()
+}
+
+// LocalizedName returns the translated name for lang, falling back to Name
+// when lang is empty or has no translation.
+func (a RoomAmenity) LocalizedName(lang string) string {
+	if name, ok := a.Names[strings.ToLower(strings.TrimSpace(lang))]; ok {
+		return name
+	}
+
+	return a.Name
+}
+
+func (a RoomAmenity) validateCatalogFields() error {
+	if a.Category != "" && !a.Category.IsValid() {
+		return ErrAmenityCategoryInvalid
+	}
+
+	if a.SortOrder < 0 {
+		return ErrAmenitySortOrderInvalid
+	}
+
+	for lang, name := range a.Names {
+		if strings.TrimSpace(lang) == "" || strings.TrimSpace(name) == "" {
+			return ErrAmenityTranslationInvalid
+		}
+	}
+
+	return nil
+}
+
+// normalizeAmenity defaults the category and lower-cases translation keys so
+// lookups by LocalizedName are case-insensitive.
+func normalizeAmenity(a RoomAmenity) RoomAmenity {
+	if a.Category == "" {
+		a.Category = AmenityCategoryGeneral
+	}
+
+	if len(a.Names) > 0 {
+		names := make(map[string]string, len(a.Names))
+		for lang, name := range a.Names {
+			names[strings.ToLower(strings.TrimSpace(lang))] = name
+		}
+
+		a.Names = names
+	}
+
+	return a
+}
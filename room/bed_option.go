@@ -0,0 +1,67 @@
+package room
+
+import "hexagon/errs"
+
+var (
+	ErrBedTypeInvalid     = errs.Errorf(errs.EINVALID, "room: invalid bed type")
+	ErrBedCountInvalid    = errs.Errorf(errs.EINVALID, "room: bed count must be greater than 0")
+	ErrExtraBedsInvalid   = errs.Errorf(errs.EINVALID, "room: max extra beds must be >= 0")
+	ErrExtraBedFeeInvalid = errs.Errorf(errs.EINVALID, "room: extra bed fee must be >= 0 and only set when extra beds are available")
+	ErrCotFeeInvalid      = errs.Errorf(errs.EINVALID, "room: cot fee must be >= 0 and only set when a cot is available")
+)
+
+type BedType string
+
+const (
+	BedTypeSingle  BedType = "single"
+	BedTypeTwin    BedType = "twin"
+	BedTypeDouble  BedType = "double"
+	BedTypeQueen   BedType = "queen"
+	BedTypeKing    BedType = "king"
+	BedTypeBunk    BedType = "bunk"
+	BedTypeSofaBed BedType = "sofa_bed"
+)
+
+// BedOption is one bed configuration a room can be sold with, e.g. one king
+// or two twins. Extra beds and cots are offered per configuration.
+type BedOption struct {
+	Type         BedType
+	Count        int
+	MaxExtraBeds int
+	ExtraBedFee  float64
+	CotAvailable bool
+	CotFee       float64
+}
+
+func (t BedType) IsValid() bool {
+	switch t {
+	case BedTypeSingle, BedTypeTwin, BedTypeDouble, BedTypeQueen, BedTypeKing, BedTypeBunk, BedTypeSofaBed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b BedOption) Validate() error {
+	if !b.Type.IsValid() {
+		return ErrBedTypeInvalid
+	}
+
+	if b.Count <= 0 {
+		return ErrBedCountInvalid
+	}
+
+	if b.MaxExtraBeds < 0 {
+		return ErrExtraBedsInvalid
+	}
+
+	if b.ExtraBedFee < 0 || (b.MaxExtraBeds == 0 && b.ExtraBedFee > 0) {
+		return ErrExtraBedFeeInvalid
+	}
+
+	if b.CotFee < 0 || (!b.CotAvailable && b.CotFee > 0) {
+		return ErrCotFeeInvalid
+	}
+
+	return nil
+}
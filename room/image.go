@@ -0,0 +1,60 @@
+package room
+
+import "hexagon/errs"
+
+var (
+	ErrImageNotFound       = errs.Errorf(errs.ENOTFOUND, "room: image not found")
+	ErrMultipleCoverImages = errs.Errorf(errs.EINVALID, "room: only one image can be the cover")
+	ErrImageOrderInvalid   = errs.Errorf(errs.EINVALID, "room: image order must list every room image exactly once")
+	ErrImageAltTextTooLong = errs.Errorf(errs.EINVALID, "room: image alt text must be at most 500 characters")
+	ErrLastImage           = errs.Errorf(errs.EINVALID, "room: the last image of a room cannot be removed")
+	ErrImageInUse          = errs.Errorf(errs.ECONFLICT, "room: image is already used by another image")
+)
+
+const maxImageAltTextLength = 500
+
+// normalizeImages numbers images in the given order and makes the first one
+// the cover when none is marked. More than one cover is rejected.
+func normalizeImages(images []RoomImage) ([]RoomImage, error) {
+	coverIdx := -1
+
+	for i := range images {
+		if images[i].IsCover {
+			if coverIdx >= 0 {
+				return nil, ErrMultipleCoverImages
+			}
+
+			coverIdx = i
+		}
+
+		images[i].SortOrder = i
+	}
+
+	if coverIdx < 0 && len(images) > 0 {
+		images[0].IsCover = true
+	}
+
+	return images, nil
+}
+
+// ValidateImageOrder checks that ids is a permutation of the current images.
+func ValidateImageOrder(current []RoomImage, ids []string) error {
+	if len(ids) != len(current) {
+		return ErrImageOrderInvalid
+	}
+
+	remaining := make(map[string]struct{}, len(current))
+	for i := range current {
+		remaining[current[i].ID] = struct{}{}
+	}
+
+	for i := range ids {
+		if _, ok := remaining[ids[i]]; !ok {
+			return ErrImageOrderInvalid
+		}
+
+		delete(remaining, ids[i])
+	}
+
+	return nil
+}
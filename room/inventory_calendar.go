@@ -0,0 +1,108 @@
+package room
+
+import (
+	"time"
+
+	"hexagon/errs"
+)
+
+const (
+	inventoryDateLayout      = "2006-01-02"
+	MaxInventoryCalendarDays = 366
+)
+
+var (
+	ErrInventoryRangeRequired = errs.Errorf(errs.EINVALID, "room: inventory date range is required")
+	ErrInventoryRangeInvalid  = errs.Errorf(errs.EINVALID, "room: inventory range end must not be before start")
+	ErrInventoryRangeTooLarge = errs.Errorf(errs.EINVALID, "room: inventory range must not exceed 366 days")
+)
+
+// InventoryCalendar is the per-day inventory view of one room over an
+// inclusive date range. Nights without a room_inventories row are kept in
+// Days with Missing set, because search treats them as unavailable.
+type InventoryCalendar struct {
+	RoomID       string
+	RoomName     string
+	From         time.Time
+	To           time.Time
+	Days         []InventoryDay
+	MissingDates []time.Time
+}
+
+type InventoryDay struct {
+	Date               time.Time
+	TotalInventory     int
+	HeldInventory      int
+	BookedInventory    int
+	AvailableInventory int
+	Missing            bool
+}
+
+func (inv RoomInventory) Available() int {
+	return inv.TotalInventory - inv.HeldInventory - inv.BookedInventory
+}
+
+func ValidateInventoryRange(from, to time.Time) error {
+	if from.IsZero() || to.IsZero() {
+		return ErrInventoryRangeRequired
+	}
+
+	if to.Before(from) {
+		return ErrInventoryRangeInvalid
+	}
+
+	if inventoryRangeDays(from, to) > MaxInventoryCalendarDays {
+		return ErrInventoryRangeTooLarge
+	}
+
+	return nil
+}
+
+// BuildInventoryCalendar lays out one entry per day in [from, to] and fills
+// it from inventories, which may be unordered and may contain other rooms.
+func BuildInventoryCalendar(r Room, from, to time.Time, inventories []RoomInventory) InventoryCalendar {
+	byDate := make(map[string]RoomInventory, len(inventories))
+	for i := range inventories {
+		if inventories[i].RoomID != r.ID {
+			continue
+		}
+
+		byDate[inventories[i].Date.Format(inventoryDateLayout)] = inventories[i]
+	}
+
+	calendar := InventoryCalendar{
+		RoomID:       r.ID,
+		RoomName:     r.Name,
+		From:         from,
+		To:           to,
+		Days:         make([]InventoryDay, 0, inventoryRangeDays(from, to)),
+		MissingDates: []time.Time{},
+	}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		inv, ok := byDate[day.Format(inventoryDateLayout)]
+		if !ok {
+			calendar.Days = append(calendar.Days, InventoryDay{Date: day, Missing: true})
+			calendar.MissingDates = append(calendar.MissingDates, day)
+
+			continue
+		}
+
+		calendar.Days = append(calendar.Days, InventoryDay{
+			Date:               day,
+			TotalInventory:     inv.TotalInventory,
+			HeldInventory:      inv.HeldInventory,
+			BookedInventory:    inv.BookedInventory,
+			AvailableInventory: inv.Available(),
+		})
+	}
+
+	return calendar
+}
+
+func inventoryRangeDays(from, to time.Time) int {
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(toDay.Sub(fromDay).Hours()/24) + 1
+}
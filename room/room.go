@@ -1,7 +1,6 @@
 package room
 
 import (
-	"encoding/json"
 	"strings"
 	"time"
 
@@ -12,6 +11,7 @@ var (
 	ErrRoomIDRequired               = errs.Errorf(errs.EINVALID, "room: id is required")
 	ErrRoomNotFound                 = errs.Errorf(errs.ENOTFOUND, "room: not found")
 	ErrHotelIDRequired              = errs.Errorf(errs.EINVALID, "room: hotel id is required")
+	ErrHotelNotFound                = errs.Errorf(errs.ENOTFOUND, "room: hotel not found")
 	ErrNameRequired                 = errs.Errorf(errs.EINVALID, "room: name is required")
 	ErrRoomImagesRequired           = errs.Errorf(errs.EINVALID, "room: at least one image is required")
 	ErrBasePriceInvalid             = errs.Errorf(errs.EINVALID, "room: base price must be greater than 0")
@@ -49,7 +49,7 @@ type Room struct {
 	MaxAdult     int
 	MaxChild     int
 	MaxOccupancy int
-	BedOptions   json.RawMessage
+	BedOptions   []BedOption
 	SizeSqm      int
 	Status       RoomStatus
 	Images       []RoomImage
@@ -60,10 +60,12 @@ type Room struct {
 }
 
 type RoomImage struct {
-	ID      string
-	RoomID  string
-	URL     string
-	IsCover bool
+	ID        string
+	RoomID    string
+	URL       string
+	AltText   string
+	IsCover   bool
+	SortOrder int
 }
 
 type RoomInventory struct {
@@ -81,6 +83,9 @@ type RoomAmenity struct {
 	Name        string
 	Description string
 	Icon        string
+	Category    AmenityCategory
+	SortOrder   int
+	Names       map[string]string
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
@@ -147,6 +152,12 @@ func (r Room) ValidateForCreate() error {
 		}
 	}
 
+	for i := range r.BedOptions {
+		if err := r.BedOptions[i].Validate(); err != nil {
+			return err
+		}
+	}
+
 	for i := range r.AmenityIDs {
 		if strings.TrimSpace(r.AmenityIDs[i]) == "" {
 			return ErrAmenityIDRequired
@@ -169,6 +180,10 @@ func (img RoomImage) ValidateForRoomCreate() error {
 		return ErrImageURLRequired
 	}
 
+	if len([]rune(img.AltText)) > maxImageAltTextLength {
+		return ErrImageAltTextTooLong
+	}
+
 	return nil
 }
 
@@ -209,7 +224,7 @@ func (a RoomAmenity) ValidateForCreate() error {
 		return ErrAmenityNameRequired
 	}
 
-	return nil
+	return a.validateCatalogFields()
 }
 
 func (m RoomAmenityMap) ValidateForCreate() error {
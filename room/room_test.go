@@ -92,6 +92,21 @@ func TestRoomImageValidate(t *testing.T) {
 	assert.Equal(t, errs.EINVALID, errs.ErrorCode(img.ValidateForCreate()))
 }
 
+func TestNormalizeImages(t *testing.T) {
+	images, err := normalizeImages([]RoomImage{{URL: "a"}, {URL: "b", IsCover: true}})
+	assert.NoError(t, err)
+	assert.False(t, images[0].IsCover)
+	assert.True(t, images[1].IsCover)
+	assert.Equal(t, 1, images[1].SortOrder)
+
+	images, err = normalizeImages([]RoomImage{{URL: "a"}, {URL: "b"}})
+	assert.NoError(t, err)
+	assert.True(t, images[0].IsCover)
+
+	_, err = normalizeImages([]RoomImage{{URL: "a", IsCover: true}, {URL: "b", IsCover: true}})
+	assert.Equal(t, ErrMultipleCoverImages, err)
+}
+
 func TestRoomInventoryValidate(t *testing.T) {
 	inv := RoomInventory{RoomID: "r-1", Date: time.Now().UTC(), TotalInventory: 10}
 	assert.NoError(t, inv.ValidateForCreate())
@@ -132,6 +147,12 @@ func TestRoomAmenityValidate(t *testing.T) {
 
 	amenity = RoomAmenity{Code: "wifi"}
 	assert.Equal(t, errs.EINVALID, errs.ErrorCode(amenity.ValidateForCreate()))
+
+	amenity = RoomAmenity{Code: "wifi", Name: "WiFi", Category: "kitchen"}
+	assert.Equal(t, ErrAmenityCategoryInvalid, amenity.ValidateForCreate())
+
+	amenity = RoomAmenity{Code: "wifi", Name: "WiFi", Names: map[string]string{"vi": " "}}
+	assert.Equal(t, ErrAmenityTranslationInvalid, amenity.ValidateForCreate())
 }
 
 func TestRoomAmenityMapValidate(t *testing.T) {
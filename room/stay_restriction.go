@@ -0,0 +1,120 @@
+package room
+
+import (
+	"strings"
+	"time"
+
+	"hexagon/errs"
+)
+
+var (
+	ErrStayRestrictionMinInvalid   = errs.Errorf(errs.EINVALID, "room: min stay must be >= 0")
+	ErrStayRestrictionMaxInvalid   = errs.Errorf(errs.EINVALID, "room: max stay must be >= 0")
+	ErrStayRestrictionMaxBelowMin  = errs.Errorf(errs.EINVALID, "room: max stay must be >= min stay")
+	ErrStayRestrictionDateRequired = errs.Errorf(errs.EINVALID, "room: restriction date is required")
+)
+
+// StayRestrictionReason explains why a stay is rejected by a restriction.
+// The zero value means the stay is allowed.
+type StayRestrictionReason string
+
+const (
+	StayRestrictionStopSell          StayRestrictionReason = "stop_sell"
+	StayRestrictionClosedToArrival   StayRestrictionReason = "closed_to_arrival"
+	StayRestrictionClosedToDeparture StayRestrictionReason = "closed_to_departure"
+	StayRestrictionMinStay           StayRestrictionReason = "min_stay"
+	StayRestrictionMaxStay           StayRestrictionReason = "max_stay"
+)
+
+// StayRestriction holds the per-room per-date selling rules.
+// MinStay and MaxStay are counted in nights and apply to stays arriving on
+// Date; zero means no limit.
+type StayRestriction struct {
+	ID                string
+	RoomID            string
+	Date              time.Time
+	MinStay           int
+	MaxStay           int
+	ClosedToArrival   bool
+	ClosedToDeparture bool
+	StopSell          bool
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (sr StayRestriction) ValidateForUpsert() error {
+	if strings.TrimSpace(sr.RoomID) == "" {
+		return ErrRoomIDRequired
+	}
+
+	if sr.Date.IsZero() {
+		return ErrStayRestrictionDateRequired
+	}
+
+	if sr.MinStay < 0 {
+		return ErrStayRestrictionMinInvalid
+	}
+
+	if sr.MaxStay < 0 {
+		return ErrStayRestrictionMaxInvalid
+	}
+
+	if sr.MaxStay > 0 && sr.MaxStay < sr.MinStay {
+		return ErrStayRestrictionMaxBelowMin
+	}
+
+	return nil
+}
+
+/*
+EvaluateStay checks one room's restrictions against a stay:
+- stop-sell on any night in [checkIn, checkOut) closes the stay.
+- closed-to-arrival is read on checkIn, closed-to-departure on checkOut.
+- min/max stay are read on checkIn.
+
+restrictions may contain rows outside the stay; they are ignored.
+*/
+func EvaluateStay(restrictions []StayRestriction, checkIn, checkOut time.Time) StayRestrictionReason {
+	checkInKey := checkIn.Format(inventoryDateLayout)
+	checkOutKey := checkOut.Format(inventoryDateLayout)
+
+	var arrival, departure StayRestriction
+
+	for i := range restrictions {
+		key := restrictions[i].Date.Format(inventoryDateLayout)
+		if key < checkInKey || key > checkOutKey {
+			continue
+		}
+
+		if key < checkOutKey && restrictions[i].StopSell {
+			return StayRestrictionStopSell
+		}
+
+		switch key {
+		case checkInKey:
+			arrival = restrictions[i]
+		case checkOutKey:
+			departure = restrictions[i]
+		}
+	}
+
+	if arrival.ClosedToArrival {
+		return StayRestrictionClosedToArrival
+	}
+
+	if departure.ClosedToDeparture {
+		return StayRestrictionClosedToDeparture
+	}
+
+	nights := inventoryRangeDays(checkIn, checkOut) - 1
+
+	if arrival.MinStay > 0 && nights < arrival.MinStay {
+		return StayRestrictionMinStay
+	}
+
+	if arrival.MaxStay > 0 && nights > arrival.MaxStay {
+		return StayRestrictionMaxStay
+	}
+
+	return ""
+}
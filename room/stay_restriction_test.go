@@ -0,0 +1,73 @@
+package room
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateStay(t *testing.T) {
+	checkIn := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	checkOut := checkIn.AddDate(0, 0, 2)
+	day := func(offset int) time.Time { return checkIn.AddDate(0, 0, offset) }
+
+	tests := []struct {
+		name         string
+		restrictions []StayRestriction
+		want         StayRestrictionReason
+	}{
+		{name: "no restrictions", want: ""},
+		{
+			name:         "stop sell on second night",
+			restrictions: []StayRestriction{{Date: day(1), StopSell: true}},
+			want:         StayRestrictionStopSell,
+		},
+		{
+			name:         "stop sell on checkout day is ignored",
+			restrictions: []StayRestriction{{Date: day(2), StopSell: true}},
+			want:         "",
+		},
+		{
+			name:         "closed to arrival",
+			restrictions: []StayRestriction{{Date: day(0), ClosedToArrival: true}},
+			want:         StayRestrictionClosedToArrival,
+		},
+		{
+			name:         "closed to arrival mid stay is ignored",
+			restrictions: []StayRestriction{{Date: day(1), ClosedToArrival: true}},
+			want:         "",
+		},
+		{
+			name:         "closed to departure",
+			restrictions: []StayRestriction{{Date: day(2), ClosedToDeparture: true}},
+			want:         StayRestrictionClosedToDeparture,
+		},
+		{
+			name:         "min stay read on arrival",
+			restrictions: []StayRestriction{{Date: day(0), MinStay: 3}, {Date: day(1), MinStay: 1}},
+			want:         StayRestrictionMinStay,
+		},
+		{
+			name:         "min stay on later night is ignored",
+			restrictions: []StayRestriction{{Date: day(1), MinStay: 3}},
+			want:         "",
+		},
+		{
+			name:         "max stay",
+			restrictions: []StayRestriction{{Date: day(0), MaxStay: 1}},
+			want:         StayRestrictionMaxStay,
+		},
+		{
+			name:         "within limits",
+			restrictions: []StayRestriction{{Date: day(0), MinStay: 2, MaxStay: 2}},
+			want:         "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, EvaluateStay(tt.restrictions, checkIn, checkOut))
+		})
+	}
+}
@@ -1,27 +1,79 @@
 package room
 
-import "context"
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"hexagon/upload"
+)
 
 type Service interface {
 	AddRoom(ctx context.Context, r Room) (Room, error)
 	AddAmenity(ctx context.Context, amenity RoomAmenity) (RoomAmenity, error)
 	AddInventory(ctx context.Context, inv RoomInventory) (RoomInventory, error)
+	GetInventoryCalendar(ctx context.Context, roomID string, from, to time.Time) (InventoryCalendar, error)
+	GetHotelInventoryCalendars(ctx context.Context, hotelID string, from, to time.Time) ([]InventoryCalendar, error)
+	SetStayRestrictions(ctx context.Context, rule StayRestriction, from, to time.Time) ([]StayRestriction, error)
+	ListStayRestrictions(ctx context.Context, roomID string, from, to time.Time) ([]StayRestriction, error)
+	ClearStayRestrictions(ctx context.Context, roomID string, from, to time.Time) error
+	ListAmenities(ctx context.Context, category AmenityCategory) ([]RoomAmenity, error)
+	UpdateAmenity(ctx context.Context, amenity RoomAmenity) (RoomAmenity, error)
+	DeleteAmenity(ctx context.Context, id string, force bool) error
+	ReplaceRoomAmenities(ctx context.Context, roomID string, amenityIDs []string) ([]RoomAmenity, error)
+	AddImage(ctx context.Context, img RoomImage) (RoomImage, error)
+	RemoveImage(ctx context.Context, roomID, imageID string) error
+	ReorderImages(ctx context.Context, roomID string, imageIDs []string) ([]RoomImage, error)
+	SetCoverImage(ctx context.Context, roomID, imageID string) ([]RoomImage, error)
 }
 
 type Repository interface {
-	CreateRoom(ctx context.Context, r Room) (Room, error)
+	// CreateRoomTx saves r and runs fn in the same transaction. If fn returns
+	// an error nothing is saved.
+	CreateRoomTx(ctx context.Context, r Room, fn func(ctx context.Context) error) (Room, error)
 	CreateAmenity(ctx context.Context, amenity RoomAmenity) (RoomAmenity, error)
 	CreateInventory(ctx context.Context, inv RoomInventory) (RoomInventory, error)
+	GetRoomByID(ctx context.Context, id string) (Room, error)
+	// ListRoomsByHotel returns ErrHotelNotFound for an unknown hotel, so it
+	// can be told apart from a hotel without rooms.
+	ListRoomsByHotel(ctx context.Context, hotelID string) ([]Room, error)
+	ListInventories(ctx context.Context, roomIDs []string, from, to time.Time) ([]RoomInventory, error)
+	UpsertStayRestrictions(ctx context.Context, restrictions []StayRestriction) ([]StayRestriction, error)
+	ListStayRestrictions(ctx context.Context, roomIDs []string, from, to time.Time) ([]StayRestriction, error)
+	DeleteStayRestrictions(ctx context.Context, roomID string, from, to time.Time) error
+	ListAmenities(ctx context.Context, category AmenityCategory) ([]RoomAmenity, error)
+	UpdateAmenity(ctx context.Context, amenity RoomAmenity) (RoomAmenity, error)
+	CountAmenityUsage(ctx context.Context, amenityID string) (int64, error)
+	DeleteAmenity(ctx context.Context, id string) error
+	ReplaceRoomAmenities(ctx context.Context, roomID string, amenityIDs []string) ([]RoomAmenity, error)
+	ListImages(ctx context.Context, roomID string) ([]RoomImage, error)
+	// AddImageTx saves img and runs fn in the same transaction, like
+	// CreateRoomTx.
+	AddImageTx(ctx context.Context, img RoomImage, fn func(ctx context.Context) error) (RoomImage, error)
+	// DeleteImage removes the image and moves the cover to the next image.
+	// It returns ErrLastImage instead of removing the only image of the
+	// room.
+	DeleteImage(ctx context.Context, roomID, imageID string) (RoomImage, error)
+	ReorderImages(ctx context.Context, roomID string, imageIDs []string) ([]RoomImage, error)
+	SetCoverImage(ctx context.Context, roomID, imageID string) ([]RoomImage, error)
 }
 
 type Usecase struct {
-	repo Repository
+	repo    Repository
+	uploads upload.Tracker
 }
 
 func NewUsecase(repo Repository) *Usecase {
 	return &Usecase{repo: repo}
 }
 
+// NewUsecaseWithUploads claims uploaded images when they are added and
+// deletes their stored objects when they are removed.
+func NewUsecaseWithUploads(repo Repository, uploads upload.Tracker) *Usecase {
+	return &Usecase{repo: repo, uploads: uploads}
+}
+
 func (uc *Usecase) AddRoom(ctx context.Context, r Room) (Room, error) {
 	if r.Status == "" {
 		r.Status = RoomStatusActive
@@ -31,10 +83,20 @@ func (uc *Usecase) AddRoom(ctx context.Context, r Room) (Room, error) {
 		return Room{}, err
 	}
 
-	return uc.repo.CreateRoom(ctx, r)
+	images, err := normalizeImages(r.Images)
+	if err != nil {
+		return Room{}, err
+	}
+
+	r.Images = images
+
+	return uc.repo.CreateRoomTx(ctx, r, func(ctx context.Context) error {
+		return uc.attachImages(ctx, r.Images...)
+	})
 }
 
 func (uc *Usecase) AddAmenity(ctx context.Context, amenity RoomAmenity) (RoomAmenity, error) {
+	amenity = normalizeAmenity(amenity)
 	if err := amenity.ValidateForCreate(); err != nil {
 		return RoomAmenity{}, err
 	}
@@ -49,3 +111,252 @@ func (uc *Usecase) AddInventory(ctx context.Context, inv RoomInventory) (RoomInv
 
 	return uc.repo.CreateInventory(ctx, inv)
 }
+
+func (uc *Usecase) GetInventoryCalendar(ctx context.Context, roomID string, from, to time.Time) (InventoryCalendar, error) {
+	if err := ValidateID(roomID); err != nil {
+		return InventoryCalendar{}, err
+	}
+
+	if err := ValidateInventoryRange(from, to); err != nil {
+		return InventoryCalendar{}, err
+	}
+
+	r, err := uc.repo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return InventoryCalendar{}, err
+	}
+
+	inventories, err := uc.repo.ListInventories(ctx, []string{r.ID}, from, to)
+	if err != nil {
+		return InventoryCalendar{}, err
+	}
+
+	return BuildInventoryCalendar(r, from, to, inventories), nil
+}
+
+func (uc *Usecase) GetHotelInventoryCalendars(ctx context.Context, hotelID string, from, to time.Time) ([]InventoryCalendar, error) {
+	if strings.TrimSpace(hotelID) == "" {
+		return nil, ErrHotelIDRequired
+	}
+
+	if err := ValidateInventoryRange(from, to); err != nil {
+		return nil, err
+	}
+
+	rooms, err := uc.repo.ListRoomsByHotel(ctx, hotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rooms) == 0 {
+		return []InventoryCalendar{}, nil
+	}
+
+	roomIDs := make([]string, len(rooms))
+	for i := range rooms {
+		roomIDs[i] = rooms[i].ID
+	}
+
+	inventories, err := uc.repo.ListInventories(ctx, roomIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	calendars := make([]InventoryCalendar, len(rooms))
+	for i := range rooms {
+		calendars[i] = BuildInventoryCalendar(rooms[i], from, to, inventories)
+	}
+
+	return calendars, nil
+}
+
+// SetStayRestrictions applies the same rule to every date in [from, to],
+// replacing whatever restriction was stored for those dates.
+func (uc *Usecase) SetStayRestrictions(ctx context.Context, rule StayRestriction, from, to time.Time) ([]StayRestriction, error) {
+	if err := ValidateInventoryRange(from, to); err != nil {
+		return nil, err
+	}
+
+	rule.Date = from
+	if err := rule.ValidateForUpsert(); err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.repo.GetRoomByID(ctx, rule.RoomID); err != nil {
+		return nil, err
+	}
+
+	restrictions := make([]StayRestriction, 0, inventoryRangeDays(from, to))
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		restriction := rule
+		restriction.Date = day
+		restrictions = append(restrictions, restriction)
+	}
+
+	return uc.repo.UpsertStayRestrictions(ctx, restrictions)
+}
+
+func (uc *Usecase) ListStayRestrictions(ctx context.Context, roomID string, from, to time.Time) ([]StayRestriction, error) {
+	if err := ValidateID(roomID); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateInventoryRange(from, to); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.ListStayRestrictions(ctx, []string{roomID}, from, to)
+}
+
+func (uc *Usecase) ClearStayRestrictions(ctx context.Context, roomID string, from, to time.Time) error {
+	if err := ValidateID(roomID); err != nil {
+		return err
+	}
+
+	if err := ValidateInventoryRange(from, to); err != nil {
+		return err
+	}
+
+	return uc.repo.DeleteStayRestrictions(ctx, roomID, from, to)
+}
+
+func (uc *Usecase) ListAmenities(ctx context.Context, category AmenityCategory) ([]RoomAmenity, error) {
+	if category != "" && !category.IsValid() {
+		return nil, ErrAmenityCategoryInvalid
+	}
+
+	return uc.repo.ListAmenities(ctx, category)
+}
+
+func (uc *Usecase) UpdateAmenity(ctx context.Context, amenity RoomAmenity) (RoomAmenity, error) {
+	amenity = normalizeAmenity(amenity)
+	if err := amenity.ValidateForUpdate(); err != nil {
+		return RoomAmenity{}, err
+	}
+
+	return uc.repo.UpdateAmenity(ctx, amenity)
+}
+
+// DeleteAmenity refuses to remove an amenity still mapped to rooms unless
+// force is set, in which case the mappings are removed with it.
+func (uc *Usecase) DeleteAmenity(ctx context.Context, id string, force bool) error {
+	if strings.TrimSpace(id) == "" {
+		return ErrAmenityIDRequired
+	}
+
+	if !force {
+		usage, err := uc.repo.CountAmenityUsage(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if usage > 0 {
+			return ErrAmenityInUse
+		}
+	}
+
+	return uc.repo.DeleteAmenity(ctx, id)
+}
+
+func (uc *Usecase) ReplaceRoomAmenities(ctx context.Context, roomID string, amenityIDs []string) ([]RoomAmenity, error) {
+	if err := ValidateID(roomID); err != nil {
+		return nil, err
+	}
+
+	for i := range amenityIDs {
+		if strings.TrimSpace(amenityIDs[i]) == "" {
+			return nil, ErrAmenityIDRequired
+		}
+	}
+
+	return uc.repo.ReplaceRoomAmenities(ctx, roomID, amenityIDs)
+}
+
+// AddImage appends an image to the room gallery; IsCover moves the cover to it.
+func (uc *Usecase) AddImage(ctx context.Context, img RoomImage) (RoomImage, error) {
+	if err := img.ValidateForCreate(); err != nil {
+		return RoomImage{}, err
+	}
+
+	return uc.repo.AddImageTx(ctx, img, func(ctx context.Context) error {
+		return uc.attachImages(ctx, img)
+	})
+}
+
+// RemoveImage deletes the image row, promotes the next image to cover if
+// needed, then removes the stored object. A room keeps at least one image.
+// An error releasing the object is returned, but does not bring the row
+// back.
+func (uc *Usecase) RemoveImage(ctx context.Context, roomID, imageID string) error {
+	if err := ValidateID(roomID); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(imageID) == "" {
+		return ErrRoomImageIDRequired
+	}
+
+	removed, err := uc.repo.DeleteImage(ctx, roomID, imageID)
+	if err != nil {
+		return err
+	}
+
+	if uc.uploads != nil {
+		return uc.uploads.Release(ctx, removed.URL)
+	}
+
+	return nil
+}
+
+func (uc *Usecase) ReorderImages(ctx context.Context, roomID string, imageIDs []string) ([]RoomImage, error) {
+	if err := ValidateID(roomID); err != nil {
+		return nil, err
+	}
+
+	current, err := uc.repo.ListImages(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateImageOrder(current, imageIDs); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.ReorderImages(ctx, roomID, imageIDs)
+}
+
+func (uc *Usecase) SetCoverImage(ctx context.Context, roomID, imageID string) ([]RoomImage, error) {
+	if err := ValidateID(roomID); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(imageID) == "" {
+		return nil, ErrRoomImageIDRequired
+	}
+
+	return uc.repo.SetCoverImage(ctx, roomID, imageID)
+}
+
+// attachImages marks the uploads behind images as used. It runs in the
+// transaction saving the images, so uploads are only claimed by images that
+// exist and the orphan sweeper can never delete an image that is referenced.
+func (uc *Usecase) attachImages(ctx context.Context, images ...RoomImage) error {
+	if uc.uploads == nil || len(images) == 0 {
+		return nil
+	}
+
+	urls := make([]string, len(images))
+	for i := range images {
+		urls[i] = images[i].URL
+	}
+
+	if err := uc.uploads.Attach(ctx, urls); err != nil {
+		if errors.Is(err, upload.ErrUploadInUse) {
+			return ErrImageInUse
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package room
 import (
 	"context"
 	"testing"
+	"time"
 
 	"hexagon/errs"
 
@@ -11,16 +12,32 @@ import (
 )
 
 type roomRepoStub struct {
-	createRoomCalled     bool
-	createAmenityCalled  bool
+	createRoomCalled      bool
+	createAmenityCalled   bool
 	createInventoryCalled bool
-	createRoom           func(ctx context.Context, r Room) (Room, error)
-	createAmenity        func(ctx context.Context, a RoomAmenity) (RoomAmenity, error)
-	createInventory      func(ctx context.Context, inv RoomInventory) (RoomInventory, error)
+	createRoom            func(ctx context.Context, r Room) (Room, error)
+	createAmenity         func(ctx context.Context, a RoomAmenity) (RoomAmenity, error)
+	createInventory       func(ctx context.Context, inv RoomInventory) (RoomInventory, error)
+	getRoomByID           func(ctx context.Context, id string) (Room, error)
+	listRoomsByHotel      func(ctx context.Context, hotelID string) ([]Room, error)
+	listInventories       func(ctx context.Context, roomIDs []string, from, to time.Time) ([]RoomInventory, error)
+	listInventoriesCalled bool
+	upsertRestrictions    func(ctx context.Context, restrictions []StayRestriction) ([]StayRestriction, error)
+	upsertCalled          bool
+	countAmenityUsage     func(ctx context.Context, amenityID string) (int64, error)
+	deleteAmenityCalled   bool
+	listImages            func(ctx context.Context, roomID string) ([]RoomImage, error)
+	deleteImageCalled     bool
+	deleteImageErr        error
 }
 
-func (r *roomRepoStub) CreateRoom(ctx context.Context, room Room) (Room, error) {
+func (r *roomRepoStub) CreateRoomTx(ctx context.Context, room Room, fn func(ctx context.Context) error) (Room, error) {
+	if err := fn(ctx); err != nil {
+		return Room{}, err
+	}
+
 	r.createRoomCalled = true
+
 	return r.createRoom(ctx, room)
 }
 
@@ -34,6 +51,82 @@ func (r *roomRepoStub) CreateInventory(ctx context.Context, inv RoomInventory) (
 	return r.createInventory(ctx, inv)
 }
 
+func (r *roomRepoStub) GetRoomByID(ctx context.Context, id string) (Room, error) {
+	return r.getRoomByID(ctx, id)
+}
+
+func (r *roomRepoStub) ListRoomsByHotel(ctx context.Context, hotelID string) ([]Room, error) {
+	return r.listRoomsByHotel(ctx, hotelID)
+}
+
+func (r *roomRepoStub) ListInventories(ctx context.Context, roomIDs []string, from, to time.Time) ([]RoomInventory, error) {
+	r.listInventoriesCalled = true
+	return r.listInventories(ctx, roomIDs, from, to)
+}
+
+func (r *roomRepoStub) UpsertStayRestrictions(ctx context.Context, restrictions []StayRestriction) ([]StayRestriction, error) {
+	r.upsertCalled = true
+	return r.upsertRestrictions(ctx, restrictions)
+}
+
+func (r *roomRepoStub) ListStayRestrictions(ctx context.Context, roomIDs []string, from, to time.Time) ([]StayRestriction, error) {
+	return nil, nil
+}
+
+func (r *roomRepoStub) DeleteStayRestrictions(ctx context.Context, roomID string, from, to time.Time) error {
+	return nil
+}
+
+func (r *roomRepoStub) ListAmenities(ctx context.Context, category AmenityCategory) ([]RoomAmenity, error) {
+	return nil, nil
+}
+
+func (r *roomRepoStub) UpdateAmenity(ctx context.Context, amenity RoomAmenity) (RoomAmenity, error) {
+	return amenity, nil
+}
+
+func (r *roomRepoStub) CountAmenityUsage(ctx context.Context, amenityID string) (int64, error) {
+	return r.countAmenityUsage(ctx, amenityID)
+}
+
+func (r *roomRepoStub) DeleteAmenity(ctx context.Context, id string) error {
+	r.deleteAmenityCalled = true
+	return nil
+}
+
+func (r *roomRepoStub) ReplaceRoomAmenities(ctx context.Context, roomID string, amenityIDs []string) ([]RoomAmenity, error) {
+	return nil, nil
+}
+
+func (r *roomRepoStub) ListImages(ctx context.Context, roomID string) ([]RoomImage, error) {
+	return r.listImages(ctx, roomID)
+}
+
+func (r *roomRepoStub) AddImageTx(ctx context.Context, img RoomImage, fn func(ctx context.Context) error) (RoomImage, error) {
+	if err := fn(ctx); err != nil {
+		return RoomImage{}, err
+	}
+
+	return img, nil
+}
+
+func (r *roomRepoStub) DeleteImage(ctx context.Context, roomID, imageID string) (RoomImage, error) {
+	r.deleteImageCalled = true
+	if r.deleteImageErr != nil {
+		return RoomImage{}, r.deleteImageErr
+	}
+
+	return RoomImage{ID: imageID, RoomID: roomID}, nil
+}
+
+func (r *roomRepoStub) ReorderImages(ctx context.Context, roomID string, imageIDs []string) ([]RoomImage, error) {
+	return nil, nil
+}
+
+func (r *roomRepoStub) SetCoverImage(ctx context.Context, roomID, imageID string) ([]RoomImage, error) {
+	return nil, nil
+}
+
 func TestUsecase_AddRoom_DefaultStatus(t *testing.T) {
 	captured := Room{}
 	repo := &roomRepoStub{
@@ -87,3 +180,126 @@ func TestUsecase_AddInventory_Validation(t *testing.T) {
 	assert.Equal(t, errs.EINVALID, errs.ErrorCode(err))
 	assert.False(t, repo.createInventoryCalled)
 }
+
+func TestUsecase_GetInventoryCalendar_FillsGaps(t *testing.T) {
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 2)
+	repo := &roomRepoStub{
+		getRoomByID: func(ctx context.Context, id string) (Room, error) {
+			return Room{ID: id, Name: "Deluxe"}, nil
+		},
+		listInventories: func(ctx context.Context, roomIDs []string, _, _ time.Time) ([]RoomInventory, error) {
+			assert.Equal(t, []string{"r-1"}, roomIDs)
+			return []RoomInventory{
+				{RoomID: "r-1", Date: from, TotalInventory: 5, HeldInventory: 1, BookedInventory: 2},
+				{RoomID: "r-1", Date: to, TotalInventory: 3},
+			}, nil
+		},
+	}
+	uc := NewUsecase(repo)
+
+	calendar, err := uc.GetInventoryCalendar(context.Background(), "r-1", from, to)
+	require.NoError(t, err)
+	require.Len(t, calendar.Days, 3)
+	assert.Equal(t, "Deluxe", calendar.RoomName)
+	assert.Equal(t, 2, calendar.Days[0].AvailableInventory)
+	assert.True(t, calendar.Days[1].Missing)
+	assert.Equal(t, 3, calendar.Days[2].AvailableInventory)
+	assert.Equal(t, []time.Time{from.AddDate(0, 0, 1)}, calendar.MissingDates)
+}
+
+func TestUsecase_GetInventoryCalendar_InvalidRange(t *testing.T) {
+	repo := &roomRepoStub{}
+	uc := NewUsecase(repo)
+	from := time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	_, err := uc.GetInventoryCalendar(context.Background(), "r-1", from, from.AddDate(0, 0, -1))
+	assert.Equal(t, errs.EINVALID, errs.ErrorCode(err))
+
+	_, err = uc.GetInventoryCalendar(context.Background(), "r-1", from, from.AddDate(0, 0, MaxInventoryCalendarDays))
+	assert.Equal(t, errs.EINVALID, errs.ErrorCode(err))
+	assert.False(t, repo.listInventoriesCalled)
+}
+
+func TestUsecase_GetHotelInventoryCalendars_NoRooms(t *testing.T) {
+	repo := &roomRepoStub{
+		listRoomsByHotel: func(ctx context.Context, hotelID string) ([]Room, error) { return nil, nil },
+	}
+	uc := NewUsecase(repo)
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	calendars, err := uc.GetHotelInventoryCalendars(context.Background(), "h-1", from, from)
+	require.NoError(t, err)
+	assert.Empty(t, calendars)
+	assert.False(t, repo.listInventoriesCalled)
+}
+
+func TestUsecase_GetHotelInventoryCalendars_UnknownHotel(t *testing.T) {
+	repo := &roomRepoStub{
+		listRoomsByHotel: func(ctx context.Context, hotelID string) ([]Room, error) { return nil, ErrHotelNotFound },
+	}
+	uc := NewUsecase(repo)
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := uc.GetHotelInventoryCalendars(context.Background(), "h-404", from, from)
+	assert.Equal(t, errs.ENOTFOUND, errs.ErrorCode(err))
+	assert.False(t, repo.listInventoriesCalled)
+}
+
+func TestUsecase_SetStayRestrictions_ExpandsRange(t *testing.T) {
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	repo := &roomRepoStub{
+		getRoomByID: func(ctx context.Context, id string) (Room, error) { return Room{ID: id}, nil },
+		upsertRestrictions: func(ctx context.Context, restrictions []StayRestriction) ([]StayRestriction, error) {
+			return restrictions, nil
+		},
+	}
+	uc := NewUsecase(repo)
+
+	saved, err := uc.SetStayRestrictions(context.Background(), StayRestriction{RoomID: "r-1", MinStay: 2}, from, from.AddDate(0, 0, 2))
+	require.NoError(t, err)
+	require.Len(t, saved, 3)
+	assert.Equal(t, from.AddDate(0, 0, 2), saved[2].Date)
+	assert.Equal(t, 2, saved[2].MinStay)
+}
+
+func TestUsecase_SetStayRestrictions_MaxBelowMin(t *testing.T) {
+	repo := &roomRepoStub{}
+	uc := NewUsecase(repo)
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := uc.SetStayRestrictions(context.Background(), StayRestriction{RoomID: "r-1", MinStay: 3, MaxStay: 2}, from, from)
+	assert.Equal(t, errs.EINVALID, errs.ErrorCode(err))
+	assert.False(t, repo.upsertCalled)
+}
+
+func TestUsecase_AddAmenity_DefaultsCategory(t *testing.T) {
+	repo := &roomRepoStub{createAmenity: func(ctx context.Context, a RoomAmenity) (RoomAmenity, error) { return a, nil }}
+	uc := NewUsecase(repo)
+
+	created, err := uc.AddAmenity(context.Background(), RoomAmenity{Code: "wifi", Name: "Wi-Fi", Names: map[string]string{"VI": "Wi-Fi miễn phí"}})
+	require.NoError(t, err)
+	assert.Equal(t, AmenityCategoryGeneral, created.Category)
+	assert.Equal(t, "Wi-Fi miễn phí", created.LocalizedName("vi"))
+	assert.Equal(t, "Wi-Fi", created.LocalizedName("fr"))
+}
+
+func TestUsecase_DeleteAmenity_InUse(t *testing.T) {
+	repo := &roomRepoStub{countAmenityUsage: func(ctx context.Context, amenityID string) (int64, error) { return 2, nil }}
+	uc := NewUsecase(repo)
+
+	err := uc.DeleteAmenity(context.Background(), "a-1", false)
+	assert.Equal(t, ErrAmenityInUse, err)
+	assert.False(t, repo.deleteAmenityCalled)
+
+	require.NoError(t, uc.DeleteAmenity(context.Background(), "a-1", true))
+	assert.True(t, repo.deleteAmenityCalled)
+}
+
+func TestUsecase_RemoveImage_KeepsLastImage(t *testing.T) {
+	repo := &roomRepoStub{deleteImageErr: ErrLastImage}
+	uc := NewUsecase(repo)
+
+	err := uc.RemoveImage(context.Background(), "r-1", "img-1")
+	assert.Equal(t, ErrLastImage, err)
+}
@@ -14,11 +14,26 @@ type RoomCandidate struct {
 	MaxChild       int
 	MaxOccupancy   int
 	AvailableCount int
+	BedOptions     []CandidateBedOption
+}
+
+// CandidateBedOption is the part of a room bed configuration search needs.
+type CandidateBedOption struct {
+	BedType      string
+	MaxExtraBeds int
+}
+
+// BedRequirement narrows rooms to a bed type (empty means any) offering at
+// least ExtraBeds extra beds per room. Each extra bed hosts one more guest.
+type BedRequirement struct {
+	BedType   string
+	ExtraBeds int
 }
 
 type guestRequest struct {
 	adults       int
 	childrenAges []int
+	beds         BedRequirement
 }
 
 // MinimumRequiredRooms uses backtracking (DFS) to find the minimum number
@@ -31,6 +46,7 @@ func MinimumRequiredRooms(
 	adults int,
 	childrenAges []int,
 	childMaxAge int,
+	beds BedRequirement,
 ) int {
 	totalAvailable := 0
 	for i := range candidates {
@@ -39,6 +55,10 @@ func MinimumRequiredRooms(
 
 	for roomCount := startRoomCount; roomCount <= totalAvailable; roomCount++ {
 		requests := splitGuestsAcrossRooms(roomCount, adults, childrenAges)
+		for i := range requests {
+			requests[i].beds = beds
+		}
+
 		if canAllocateRequestedRooms(candidates, requests, childMaxAge) {
 			return roomCount
 		}
@@ -147,13 +167,39 @@ func buildCompatibilityOrder(candidates []RoomCandidate, requests []guestRequest
 	return order, compat, true
 }
 
-// candidateCanFit evaluates whether a single RoomCandidate has enough
-// MaxAdult, MaxChild, and MaxOccupancy to accommodate the given guest request.
+// candidateCanFit evaluates whether a single RoomCandidate offers the requested
+// bed setup and has enough MaxAdult, MaxChild, and MaxOccupancy (plus requested
+// extra beds) to accommodate the given guest request.
 func candidateCanFit(c RoomCandidate, req guestRequest, childMaxAge int) bool {
+	if !OffersBeds(c.BedOptions, req.beds) {
+		return false
+	}
+
 	adults, children := normalizeGuest(req, childMaxAge)
 	total := adults + children
+	extra := req.beds.ExtraBeds
+
+	return adults <= c.MaxAdult+extra && children <= c.MaxChild && total <= c.MaxOccupancy+extra
+}
 
-	return adults <= c.MaxAdult && children <= c.MaxChild && total <= c.MaxOccupancy
+// OffersBeds reports whether one bed configuration of a room has the
+// requested type and enough extra beds. No requirement matches any room.
+func OffersBeds(options []CandidateBedOption, beds BedRequirement) bool {
+	if beds.BedType == "" && beds.ExtraBeds == 0 {
+		return true
+	}
+
+	for i := range options {
+		if beds.BedType != "" && options[i].BedType != beds.BedType {
+			continue
+		}
+
+		if options[i].MaxExtraBeds >= beds.ExtraBeds {
+			return true
+		}
+	}
+
+	return false
 }
 
 func normalizeGuest(req guestRequest, childMaxAge int) (int, int) {
@@ -191,11 +237,14 @@ func hasAnyUnaccompaniedChildren(requests []guestRequest, childMaxAge int) bool
 // were to stay exclusively in a single specific room type.
 // This is critical for quickly assessing if a room type can handle the full capacity
 // without exceeding its maximum capability limits.
-func RequiredSingleRoomTypeQuantity(candidate RoomCandidate, requestedRoomCount, adults int, childrenAges []int, childMaxAge int) (int, bool) {
-	if candidate.AvailableCount <= 0 {
+func RequiredSingleRoomTypeQuantity(candidate RoomCandidate, requestedRoomCount, adults int, childrenAges []int, childMaxAge int, beds BedRequirement) (int, bool) {
+	if candidate.AvailableCount <= 0 || !OffersBeds(candidate.BedOptions, beds) {
 		return 0, false
 	}
 
+	candidate.MaxAdult += beds.ExtraBeds
+	candidate.MaxOccupancy += beds.ExtraBeds
+
 	normalizedAdults, normalizedChildren := normalizeGuestCounts(adults, childrenAges, childMaxAge)
 	if !canHostNormalizedGuests(candidate, normalizedAdults, normalizedChildren) {
 		return 0, false
@@ -22,32 +22,54 @@ func TestMinimumRequiredRooms(t *testing.T) {
 		{RoomID: "r2", MaxAdult: 2, MaxChild: 1, MaxOccupancy: 3, AvailableCount: 1},
 	}
 
-	count := MinimumRequiredRooms(candidates, 1, 3, []int{}, 12)
+	count := MinimumRequiredRooms(candidates, 1, 3, []int{}, 12, BedRequirement{})
 	assert.Equal(t, 2, count)
 
-	count = MinimumRequiredRooms(candidates, 1, 2, []int{5}, 12)
+	count = MinimumRequiredRooms(candidates, 1, 2, []int{5}, 12, BedRequirement{})
 	assert.Equal(t, 1, count)
 }
 
 func TestMinimumRequiredRooms_UnaccompaniedChildren(t *testing.T) {
 	candidates := []RoomCandidate{{RoomID: "r1", MaxAdult: 1, MaxChild: 1, MaxOccupancy: 2, AvailableCount: 1}}
-	count := MinimumRequiredRooms(candidates, 1, 0, []int{5}, 12)
+	count := MinimumRequiredRooms(candidates, 1, 0, []int{5}, 12, BedRequirement{})
 	assert.Equal(t, 0, count)
 }
 
 func TestRequiredSingleRoomTypeQuantity(t *testing.T) {
 	candidate := RoomCandidate{RoomID: "r1", MaxAdult: 2, MaxChild: 1, MaxOccupancy: 3, AvailableCount: 3}
 
-	qty, ok := RequiredSingleRoomTypeQuantity(candidate, 1, 3, []int{}, 12)
+	qty, ok := RequiredSingleRoomTypeQuantity(candidate, 1, 3, []int{}, 12, BedRequirement{})
 	assert.True(t, ok)
 	assert.Equal(t, 2, qty)
 
 	candidate.AvailableCount = 1
-	qty, ok = RequiredSingleRoomTypeQuantity(candidate, 1, 3, []int{}, 12)
+	qty, ok = RequiredSingleRoomTypeQuantity(candidate, 1, 3, []int{}, 12, BedRequirement{})
 	assert.False(t, ok)
 	assert.Equal(t, 0, qty)
 }
 
+func TestMinimumRequiredRooms_BedRequirement(t *testing.T) {
+	candidates := []RoomCandidate{
+		{RoomID: "king", MaxAdult: 2, MaxOccupancy: 2, AvailableCount: 2, BedOptions: []CandidateBedOption{{BedType: "king", MaxExtraBeds: 1}}},
+		{RoomID: "twin", MaxAdult: 2, MaxOccupancy: 2, AvailableCount: 1, BedOptions: []CandidateBedOption{{BedType: "twin"}}},
+	}
+
+	assert.Equal(t, 1, MinimumRequiredRooms(candidates, 1, 2, []int{}, 12, BedRequirement{BedType: "twin"}))
+	assert.Equal(t, 0, MinimumRequiredRooms(candidates, 1, 3, []int{}, 12, BedRequirement{BedType: "twin", ExtraBeds: 1}))
+	assert.Equal(t, 1, MinimumRequiredRooms(candidates, 1, 3, []int{}, 12, BedRequirement{ExtraBeds: 1}))
+}
+
+func TestRequiredSingleRoomTypeQuantity_ExtraBeds(t *testing.T) {
+	candidate := RoomCandidate{RoomID: "r1", MaxAdult: 2, MaxOccupancy: 2, AvailableCount: 3, BedOptions: []CandidateBedOption{{BedType: "double", MaxExtraBeds: 1}}}
+
+	qty, ok := RequiredSingleRoomTypeQuantity(candidate, 1, 3, []int{}, 12, BedRequirement{ExtraBeds: 1})
+	assert.True(t, ok)
+	assert.Equal(t, 1, qty)
+
+	_, ok = RequiredSingleRoomTypeQuantity(candidate, 1, 3, []int{}, 12, BedRequirement{BedType: "king"})
+	assert.False(t, ok)
+}
+
 func TestNormalizeGuestAndUnaccompanied(t *testing.T) {
 	adults, children := normalizeGuest(guestRequest{adults: 1, childrenAges: []int{5, 18}}, 12)
 	assert.Equal(t, 2, adults)
@@ -27,6 +27,8 @@ type Criteria struct {
 	RatingMin      float64
 	AmenityIDs     []string
 	PaymentOptions []string
+	BedType        string
+	ExtraBeds      int
 }
 
 type HotelSearchResult struct {
@@ -130,5 +132,13 @@ func (c Criteria) Validate() error {
 		return errs.Errorf(errs.EINVALID, "search: ratingMin must be between 0 and 5")
 	}
 
+	if c.ExtraBeds < 0 {
+		return errs.Errorf(errs.EINVALID, "search: extra beds must be >= 0")
+	}
+
 	return nil
 }
+
+func (c Criteria) BedRequirement() BedRequirement {
+	return BedRequirement{BedType: strings.TrimSpace(c.BedType), ExtraBeds: c.ExtraBeds}
+}
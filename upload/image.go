@@ -0,0 +1,361 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// maxImagePixels bounds decoded size so a small, highly compressed file
+// cannot expand into gigabytes of pixels.
+const maxImagePixels = 40_000_000
+
+const jpegQuality = 85
+
+var (
+	ErrImageDimensionsTooLarge = errors.New("image exceeds 40 megapixels")
+	ErrInvalidRendition        = errors.New("invalid image rendition")
+)
+
+// Rendition is one resized copy generated for every uploaded image. The image
+// is scaled to fit inside MaxWidth x MaxHeight and never enlarged.
+type Rendition struct {
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+}
+
+// DefaultRenditions is ordered smallest first; the last rendition is the one
+// reported as the primary URL of an upload.
+var DefaultRenditions = []Rendition{
+	{Name: "thumbnail", MaxWidth: 320, MaxHeight: 320},
+	{Name: "card", MaxWidth: 800, MaxHeight: 600},
+	{Name: "full", MaxWidth: 1920, MaxHeight: 1920},
+}
+
+// ParseRenditions reads a spec such as "thumbnail:320x320,card:800x600".
+// An empty spec returns DefaultRenditions.
+func ParseRenditions(spec string) ([]Rendition, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return DefaultRenditions, nil
+	}
+
+	parts := strings.Split(spec, ",")
+	renditions := make([]Rendition, 0, len(parts))
+	seen := make(map[string]struct{}, len(parts))
+
+	for _, part := range parts {
+		name, size, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRendition, part)
+		}
+
+		width, height, ok := strings.Cut(size, "x")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRendition, part)
+		}
+
+		r := Rendition{Name: strings.TrimSpace(name)}
+		r.MaxWidth, _ = strconv.Atoi(strings.TrimSpace(width))
+		r.MaxHeight, _ = strconv.Atoi(strings.TrimSpace(height))
+
+		if err := r.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %q", err, part)
+		}
+
+		if _, dup := seen[r.Name]; dup {
+			return nil, fmt.Errorf("%w: duplicate name %q", ErrInvalidRendition, r.Name)
+		}
+
+		seen[r.Name] = struct{}{}
+		renditions = append(renditions, r)
+	}
+
+	return renditions, nil
+}
+
+func (r Rendition) Validate() error {
+	if r.Name == "" || strings.ContainsAny(r.Name, "/. ") {
+		return ErrInvalidRendition
+	}
+
+	if r.MaxWidth <= 0 || r.MaxHeight <= 0 {
+		return ErrInvalidRendition
+	}
+
+	return nil
+}
+
+// sourceImage is a decoded upload. Pixels are kept as stored; orientation is
+// the EXIF orientation (1-8) that must be applied for display.
+type sourceImage struct {
+	img         image.Image
+	contentType string
+	orientation int
+}
+
+// encodedImage is a rendition ready to upload. Re-encoding from pixels is
+// what strips EXIF and every other metadata block from the original.
+type encodedImage struct {
+	body        []byte
+	contentType string
+	width       int
+	height      int
+}
+
+func decodeImage(data []byte, contentType string) (sourceImage, error) {
+	decodeConfig, decode := imageCodec(contentType)
+	if decode == nil {
+		return sourceImage{}, fmt.Errorf("%w: %s", ErrUnsupportedImageType, contentType)
+	}
+
+	cfg, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return sourceImage{}, fmt.Errorf("%w: %v", ErrUnsupportedImageType, err)
+	}
+
+	if int64(cfg.Width)*int64(cfg.Height) > maxImagePixels {
+		return sourceImage{}, ErrImageDimensionsTooLarge
+	}
+
+	// Animated GIFs decode to their first frame.
+	img, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return sourceImage{}, fmt.Errorf("%w: %v", ErrUnsupportedImageType, err)
+	}
+
+	src := sourceImage{img: img, contentType: contentType, orientation: 1}
+	if contentType == "image/jpeg" {
+		src.orientation = jpegOrientation(data)
+	}
+
+	return src, nil
+}
+
+func imageCodec(contentType string) (func(r *bytes.Reader) (image.Config, error), func(r *bytes.Reader) (image.Image, error)) {
+	switch contentType {
+	case "image/jpeg":
+		return func(r *bytes.Reader) (image.Config, error) { return jpeg.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return jpeg.Decode(r) }
+	case "image/png":
+		return func(r *bytes.Reader) (image.Config, error) { return png.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return png.Decode(r) }
+	case "image/gif":
+		return func(r *bytes.Reader) (image.Config, error) { return gif.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return gif.Decode(r) }
+	case "image/webp":
+		return func(r *bytes.Reader) (image.Config, error) { return webp.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return webp.Decode(r) }
+	default:
+		return nil, nil
+	}
+}
+
+// render scales src into the rendition box, applies the EXIF orientation and
+// encodes the result. Orientation is applied after scaling so only the small
+// image is transposed.
+func (src sourceImage) render(r Rendition) (encodedImage, error) {
+	bounds := src.img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	swap := src.orientation >= 5
+	displayW, displayH := srcW, srcH
+	if swap {
+		displayW, displayH = srcH, srcW
+	}
+
+	width, height := fitWithin(displayW, displayH, r.MaxWidth, r.MaxHeight)
+
+	scaledW, scaledH := width, height
+	if swap {
+		scaledW, scaledH = height, width
+	}
+
+	scaled := image.NewNRGBA(image.Rect(0, 0, scaledW, scaledH))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), src.img, bounds, draw.Src, nil)
+
+	oriented := applyOrientation(scaled, src.orientation)
+
+	var buf bytes.Buffer
+
+	contentType := src.outputContentType()
+	switch contentType {
+	case "image/jpeg":
+		if err := jpeg.Encode(&buf, oriented, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return encodedImage{}, fmt.Errorf("encode %s rendition: %w", r.Name, err)
+		}
+	default:
+		if err := png.Encode(&buf, oriented); err != nil {
+			return encodedImage{}, fmt.Errorf("encode %s rendition: %w", r.Name, err)
+		}
+	}
+
+	return encodedImage{
+		body:        buf.Bytes(),
+		contentType: contentType,
+		width:       oriented.Bounds().Dx(),
+		height:      oriented.Bounds().Dy(),
+	}, nil
+}
+
+// outputContentType keeps JPEG as JPEG and everything that may carry
+// transparency as PNG. Opaque WebP becomes JPEG since there is no WebP
+// encoder in the standard library.
+func (src sourceImage) outputContentType() string {
+	switch src.contentType {
+	case "image/jpeg":
+		return "image/jpeg"
+	case "image/webp":
+		if opaque, ok := src.img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+			return "image/jpeg"
+		}
+
+		return "image/png"
+	default:
+		return "image/png"
+	}
+}
+
+func fitWithin(width, height, maxWidth, maxHeight int) (int, int) {
+	if width <= maxWidth && height <= maxHeight {
+		return width, height
+	}
+
+	// Compare width/maxWidth against height/maxHeight without floats.
+	if int64(width)*int64(maxHeight) >= int64(height)*int64(maxWidth) {
+		return maxWidth, max(1, int(int64(height)*int64(maxWidth)/int64(width)))
+	}
+
+	return max(1, int(int64(width)*int64(maxHeight)/int64(height))), maxHeight
+}
+
+// applyOrientation returns img as it should be displayed for the given EXIF
+// orientation value.
+func applyOrientation(img *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+
+			si := img.PixOffset(x, y)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], img.Pix[si:si+4])
+		}
+	}
+
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag from a JPEG. Anything it
+// cannot parse is treated as the default orientation.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			return 1
+		}
+
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 {
+			// Start of scan or end of image: no more metadata segments.
+			return 1
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if length < 2 || pos+2+length > len(data) {
+			return 1
+		}
+
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+
+		pos += 2 + length
+	}
+
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if value < 1 || value > 8 {
+				return 1
+			}
+
+			return value
+		}
+	}
+
+	return 1
+}
@@ -0,0 +1,123 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRenditions(t *testing.T) {
+	renditions, err := ParseRenditions("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRenditions, renditions)
+
+	renditions, err = ParseRenditions("small:100x80, large:1600x1200")
+	require.NoError(t, err)
+	assert.Equal(t, []Rendition{{Name: "small", MaxWidth: 100, MaxHeight: 80}, {Name: "large", MaxWidth: 1600, MaxHeight: 1200}}, renditions)
+
+	for _, spec := range []string{"small", "small:100", "small:0x10", "a/b:10x10", "a:10x10,a:20x20"} {
+		_, err = ParseRenditions(spec)
+		assert.ErrorIs(t, err, ErrInvalidRendition, spec)
+	}
+}
+
+func TestFitWithin(t *testing.T) {
+	w, h := fitWithin(4000, 3000, 800, 600)
+	assert.Equal(t, []int{800, 600}, []int{w, h})
+
+	w, h = fitWithin(3000, 4000, 800, 600)
+	assert.Equal(t, []int{450, 600}, []int{w, h})
+
+	w, h = fitWithin(200, 100, 800, 600)
+	assert.Equal(t, []int{200, 100}, []int{w, h}, "never upscale")
+}
+
+func TestRender_StripsExifAndAppliesOrientation(t *testing.T) {
+	data := jpegWithOrientation(t, 40, 20, 6)
+	assert.Contains(t, string(data), "Exif")
+
+	src, err := decodeImage(data, "image/jpeg")
+	require.NoError(t, err)
+	assert.Equal(t, 6, src.orientation)
+
+	out, err := src.render(Rendition{Name: "full", MaxWidth: 100, MaxHeight: 100})
+	require.NoError(t, err)
+
+	assert.Equal(t, "image/jpeg", out.contentType)
+	assert.Equal(t, 20, out.width)
+	assert.Equal(t, 40, out.height)
+	assert.NotContains(t, string(out.body), "Exif")
+}
+
+func TestDecodeImage_RejectsHugeDimensions(t *testing.T) {
+	// A PNG header claiming 10000x10000 pixels; DecodeConfig stops after IHDR.
+	var buf bytes.Buffer
+	buf.Write([]byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'})
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:4], 10000)
+	binary.BigEndian.PutUint32(ihdr[4:8], 10000)
+	ihdr[8], ihdr[9] = 8, 2
+	writePNGChunk(&buf, "IHDR", ihdr)
+
+	_, err := decodeImage(buf.Bytes(), "image/png")
+	assert.ErrorIs(t, err, ErrImageDimensionsTooLarge)
+}
+
+func TestApplyOrientation(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Pix[0] = 1 // left pixel marked
+
+	rotated := applyOrientation(img, 6)
+	assert.Equal(t, image.Rect(0, 0, 1, 2), rotated.Bounds())
+	assert.Equal(t, uint8(1), rotated.Pix[rotated.PixOffset(0, 0)])
+
+	flipped := applyOrientation(img, 2)
+	assert.Equal(t, uint8(1), flipped.Pix[flipped.PixOffset(1, 0)])
+}
+
+// jpegWithOrientation encodes a JPEG and splices in an APP1 EXIF segment
+// holding only the orientation tag.
+func jpegWithOrientation(t *testing.T, width, height int, orientation uint16) []byte {
+	t.Helper()
+
+	var encoded bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encoded, image.NewGray(image.Rect(0, 0, width, height)), nil))
+
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1}
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry[0:2], 0x0112)
+	binary.BigEndian.PutUint16(entry[2:4], 3)
+	binary.BigEndian.PutUint32(entry[4:8], 1)
+	binary.BigEndian.PutUint16(entry[8:10], orientation)
+	tiff = append(tiff, entry...)
+	tiff = append(tiff, 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:4], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	jpg := encoded.Bytes()
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, segment...)
+
+	return append(out, jpg[2:]...)
+}
+
+func writePNGChunk(buf *bytes.Buffer, kind string, data []byte) {
+	length := make([]byte, 4)
+	binary.BigEndian.PutUint32(length, uint32(len(data)))
+	buf.Write(length)
+	buf.WriteString(kind)
+	buf.Write(data)
+
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.ChecksumIEEE(append([]byte(kind), data...)))
+	buf.Write(crc)
+}
@@ -0,0 +1,221 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// DefaultOrphanMaxAge is how long an upload may stay unattached before the
+// sweeper deletes it.
+const DefaultOrphanMaxAge = 24 * time.Hour
+
+// sweepBatchSize bounds how many uploads one SweepOrphans call removes.
+const sweepBatchSize = 100
+
+var (
+	ErrRecordNotFound = errors.New("upload record not found")
+	// ErrUploadInUse is returned when an upload would be shared by more than
+	// one image: removing either would delete the other's objects.
+	ErrUploadInUse = errors.New("upload is already used by another image")
+)
+
+type Status string
+
+const (
+	// StatusPending uploads are stored but not used by any hotel or room yet.
+	StatusPending Status = "pending"
+	// StatusAttached uploads are referenced and never swept.
+	StatusAttached Status = "attached"
+)
+
+// Record tracks one upload and every object stored for it. URL is the
+// primary rendition; URLs holds all renditions including URL.
+type Record struct {
+	ID         string
+	OwnerID    string
+	Folder     string
+	URL        string
+	URLs       []string
+	Status     Status
+	CreatedAt  time.Time
+	AttachedAt *time.Time
+}
+
+type Repository interface {
+	Create(ctx context.Context, r Record) (Record, error)
+	// Attach marks pending records holding any of urls as attached. It
+	// attaches nothing and returns ErrUploadInUse if one of them is already
+	// attached or holds more than one of urls.
+	Attach(ctx context.Context, urls []string, at time.Time) error
+	// FindByURL returns the record holding url; ErrRecordNotFound otherwise.
+	FindByURL(ctx context.Context, url string) (Record, error)
+	Delete(ctx context.Context, id string) error
+	// Detach marks the record pending again, so the sweeper deletes the
+	// objects Release could not.
+	Detach(ctx context.Context, id string) error
+	ListPendingBefore(ctx context.Context, before time.Time, limit int) ([]Record, error)
+}
+
+// Tracker is what hotels and rooms use to claim uploaded images and to give
+// them back when an image is removed.
+type Tracker interface {
+	Attach(ctx context.Context, urls []string) error
+	Release(ctx context.Context, url string) error
+}
+
+// Attach marks the uploads behind urls as in use so the sweeper keeps them.
+// URLs that were not uploaded through this service are ignored. An upload
+// can only be attached once; see ErrUploadInUse.
+func (uc *Usecase) Attach(ctx context.Context, urls []string) error {
+	if uc.repo == nil {
+		return nil
+	}
+
+	cleaned := make([]string, 0, len(urls))
+	for i := range urls {
+		if url := strings.TrimSpace(urls[i]); url != "" {
+			cleaned = append(cleaned, url)
+		}
+	}
+
+	if len(cleaned) == 0 {
+		return nil
+	}
+
+	return uc.repo.Attach(ctx, cleaned, time.Now().UTC())
+}
+
+// Release deletes every rendition of the upload behind url and its record.
+// Untracked URLs are passed straight to the storage. Objects that cannot be
+// deleted are left pending for the sweeper to retry, so Release only fails
+// when not even that is possible.
+func (uc *Usecase) Release(ctx context.Context, url string) error {
+	if uc.uploader == nil {
+		return nil
+	}
+
+	if uc.repo == nil {
+		return uc.uploader.Delete(ctx, url)
+	}
+
+	record, err := uc.repo.FindByURL(ctx, url)
+	if errors.Is(err, ErrRecordNotFound) {
+		if err := uc.uploader.Delete(ctx, url); err != nil {
+			_, trackErr := uc.repo.Create(ctx, Record{URL: url, URLs: []string{url}, Status: StatusPending})
+			if trackErr != nil {
+				return errors.Join(err, trackErr)
+			}
+		}
+
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := uc.deleteRecord(ctx, record); err != nil {
+		if detachErr := uc.repo.Detach(ctx, record.ID); detachErr != nil {
+			return errors.Join(err, detachErr)
+		}
+	}
+
+	return nil
+}
+
+// SweepOrphans deletes pending uploads created more than maxAge ago and
+// returns how many were removed. Uploads whose objects cannot be deleted keep
+// their record and are retried on the next sweep.
+func (uc *Usecase) SweepOrphans(ctx context.Context, maxAge time.Duration) (int, error) {
+	if uc.repo == nil || uc.uploader == nil {
+		return 0, nil
+	}
+
+	if maxAge <= 0 {
+		maxAge = DefaultOrphanMaxAge
+	}
+
+	records, err := uc.repo.ListPendingBefore(ctx, time.Now().UTC().Add(-maxAge), sweepBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+
+	var errs []error
+
+	for i := range records {
+		if err := uc.deleteRecord(ctx, records[i]); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		removed++
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+func (uc *Usecase) deleteRecord(ctx context.Context, record Record) error {
+	for i := range record.URLs {
+		if err := uc.uploader.Delete(ctx, record.URLs[i]); err != nil {
+			return err
+		}
+	}
+
+	return uc.repo.Delete(ctx, record.ID)
+}
+
+// trackStaged records the original of a presigned upload as pending.
+func (uc *Usecase) trackStaged(ctx context.Context, folder, url string) error {
+	if uc.repo == nil || url == "" {
+		return nil
+	}
+
+	_, err := uc.repo.Create(ctx, Record{
+		Folder: normalizeFolder(folder),
+		URL:    url,
+		URLs:   []string{url},
+		Status: StatusPending,
+	})
+
+	return err
+}
+
+// discardStaged deletes the original of a completed presigned upload and
+// its record. If the object cannot be deleted the record stays pending, so
+// the sweeper retries.
+func (uc *Usecase) discardStaged(ctx context.Context, url string) {
+	if err := uc.uploader.Delete(ctx, url); err != nil || uc.repo == nil {
+		return
+	}
+
+	if record, err := uc.repo.FindByURL(ctx, url); err == nil {
+		_ = uc.repo.Delete(ctx, record.ID)
+	}
+}
+
+// track records a finished upload as pending. Without a repository uploads
+// are not tracked and never swept.
+func (uc *Usecase) track(ctx context.Context, ownerID, folder string, file UploadedFile) error {
+	if uc.repo == nil {
+		return nil
+	}
+
+	urls := make([]string, len(file.Variants))
+	for i := range file.Variants {
+		urls[i] = file.Variants[i].URL
+	}
+
+	_, err := uc.repo.Create(ctx, Record{
+		OwnerID: ownerID,
+		Folder:  folder,
+		URL:     file.URL,
+		URLs:    urls,
+		Status:  StatusPending,
+	})
+
+	return err
+}
@@ -0,0 +1,234 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	records     map[string]Record
+	attached    []string
+	createErr   error
+	pendingSeen time.Time
+}
+
+func newFakeRepository(records ...Record) *fakeRepository {
+	repo := &fakeRepository{records: map[string]Record{}}
+	for _, r := range records {
+		repo.records[r.ID] = r
+	}
+
+	return repo
+}
+
+func (f *fakeRepository) Create(ctx context.Context, r Record) (Record, error) {
+	if f.createErr != nil {
+		return Record{}, f.createErr
+	}
+
+	r.ID = r.URL
+	f.records[r.ID] = r
+
+	return r, nil
+}
+
+func (f *fakeRepository) Attach(ctx context.Context, urls []string, at time.Time) error {
+	f.attached = append(f.attached, urls...)
+	return nil
+}
+
+func (f *fakeRepository) FindByURL(ctx context.Context, url string) (Record, error) {
+	for _, r := range f.records {
+		for _, u := range r.URLs {
+			if u == url {
+				return r, nil
+			}
+		}
+	}
+
+	return Record{}, ErrRecordNotFound
+}
+
+func (f *fakeRepository) Delete(ctx context.Context, id string) error {
+	delete(f.records, id)
+	return nil
+}
+
+func (f *fakeRepository) Detach(ctx context.Context, id string) error {
+	r := f.records[id]
+	r.Status = StatusPending
+	r.AttachedAt = nil
+	f.records[id] = r
+
+	return nil
+}
+
+func (f *fakeRepository) ListPendingBefore(ctx context.Context, before time.Time, limit int) ([]Record, error) {
+	f.pendingSeen = before
+
+	var records []Record
+	for _, r := range f.records {
+		if r.Status == StatusPending && r.CreatedAt.Before(before) {
+			records = append(records, r)
+		}
+	}
+
+	return records, nil
+}
+
+type failingDeleteUploader struct {
+	fakeUploader
+	failURL string
+}
+
+func (f *failingDeleteUploader) Delete(ctx context.Context, url string) error {
+	f.deleted = append(f.deleted, url)
+	if url == f.failURL {
+		return errors.New("storage unavailable")
+	}
+
+	return nil
+}
+
+func TestUsecase_UploadImages_TracksUpload(t *testing.T) {
+	repo := newFakeRepository()
+	uc := NewUsecaseWithRepository(&fakeUploader{}, repo, nil)
+
+	data := encodePNG(t, 100, 100)
+	files, err := uc.UploadImages(context.Background(), "u-1", "hotels", []File{{
+		Filename: "a.png",
+		Size:     int64(len(data)),
+		Open:     func() (io.ReadSeekCloser, error) { return newReadSeekCloser(data), nil },
+	}})
+	require.NoError(t, err)
+
+	record, err := repo.FindByURL(context.Background(), files[0].URL)
+	require.NoError(t, err)
+	assert.Equal(t, "u-1", record.OwnerID)
+	assert.Equal(t, StatusPending, record.Status)
+	assert.Len(t, record.URLs, len(DefaultRenditions))
+}
+
+func TestUsecase_UploadImages_DiscardsUntrackedUpload(t *testing.T) {
+	repo := newFakeRepository()
+	repo.createErr = errors.New("db down")
+	uploader := &fakeUploader{}
+	uc := NewUsecaseWithRepository(uploader, repo, nil)
+
+	data := encodePNG(t, 100, 100)
+	_, err := uc.UploadImages(context.Background(), "", "hotels", []File{{
+		Filename: "a.png",
+		Size:     int64(len(data)),
+		Open:     func() (io.ReadSeekCloser, error) { return newReadSeekCloser(data), nil },
+	}})
+	require.Error(t, err)
+	assert.Len(t, uploader.deleted, len(DefaultRenditions))
+}
+
+func TestUsecase_Attach(t *testing.T) {
+	repo := newFakeRepository()
+	uc := NewUsecaseWithRepository(&fakeUploader{}, repo, nil)
+
+	require.NoError(t, uc.Attach(context.Background(), []string{" https://cdn/a.jpg ", ""}))
+	assert.Equal(t, []string{"https://cdn/a.jpg"}, repo.attached)
+
+	assert.NoError(t, NewUsecase(&fakeUploader{}).Attach(context.Background(), []string{"https://cdn/a.jpg"}))
+}
+
+func TestUsecase_PresignImageUpload_TracksStagedObject(t *testing.T) {
+	repo := newFakeRepository()
+	direct := &fakeDirectUploader{objects: map[string][]byte{}, contentType: "image/png"}
+	uc := NewUsecaseWithRepository(direct, repo, nil)
+
+	presigned, err := uc.PresignImageUpload(context.Background(), "hotel-images", PresignRequest{ContentType: "image/png", Size: 10})
+	require.NoError(t, err)
+
+	staged, err := repo.FindByURL(context.Background(), "https://cdn.example.com/"+presigned.Key)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, staged.Status, "never completed uploads are swept")
+
+	direct.objects[presigned.Key] = encodePNG(t, 20, 10)
+
+	file, err := uc.CompleteImageUpload(context.Background(), "u-1", "hotel-images", presigned.Key)
+	require.NoError(t, err)
+
+	_, err = repo.FindByURL(context.Background(), staged.URL)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	record, err := repo.FindByURL(context.Background(), file.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "u-1", record.OwnerID)
+}
+
+func TestUsecase_Release(t *testing.T) {
+	repo := newFakeRepository(Record{
+		ID:   "rec-1",
+		URL:  "https://cdn/full.png",
+		URLs: []string{"https://cdn/thumbnail.png", "https://cdn/full.png"},
+	})
+	uploader := &fakeUploader{}
+	uc := NewUsecaseWithRepository(uploader, repo, nil)
+
+	require.NoError(t, uc.Release(context.Background(), "https://cdn/full.png"))
+	assert.Equal(t, []string{"https://cdn/thumbnail.png", "https://cdn/full.png"}, uploader.deleted)
+	assert.Empty(t, repo.records)
+
+	require.NoError(t, uc.Release(context.Background(), "https://elsewhere/x.png"))
+	assert.Equal(t, "https://elsewhere/x.png", uploader.deleted[2])
+}
+
+func TestUsecase_Release_LeavesFailedDeletesToSweeper(t *testing.T) {
+	attachedAt := time.Now().UTC()
+	repo := newFakeRepository(Record{
+		ID:         "rec-1",
+		URL:        "https://cdn/full.png",
+		URLs:       []string{"https://cdn/full.png"},
+		Status:     StatusAttached,
+		AttachedAt: &attachedAt,
+	})
+	uploader := &failingDeleteUploader{failURL: "https://cdn/full.png"}
+	uc := NewUsecaseWithRepository(uploader, repo, nil)
+
+	require.NoError(t, uc.Release(context.Background(), "https://cdn/full.png"))
+	assert.Equal(t, StatusPending, repo.records["rec-1"].Status)
+	assert.Nil(t, repo.records["rec-1"].AttachedAt)
+
+	uploader.failURL = "https://elsewhere/x.png"
+	require.NoError(t, uc.Release(context.Background(), "https://elsewhere/x.png"))
+
+	record, err := repo.FindByURL(context.Background(), "https://elsewhere/x.png")
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, record.Status)
+
+	repo.createErr = errors.New("db down")
+	uploader.failURL = "https://elsewhere/y.png"
+	assert.Error(t, uc.Release(context.Background(), "https://elsewhere/y.png"))
+}
+
+func TestUsecase_SweepOrphans(t *testing.T) {
+	old := time.Now().UTC().Add(-48 * time.Hour)
+	repo := newFakeRepository(
+		Record{ID: "orphan", URLs: []string{"https://cdn/orphan.png"}, Status: StatusPending, CreatedAt: old},
+		Record{ID: "stuck", URLs: []string{"https://cdn/stuck.png"}, Status: StatusPending, CreatedAt: old},
+		Record{ID: "fresh", URLs: []string{"https://cdn/fresh.png"}, Status: StatusPending, CreatedAt: time.Now().UTC()},
+		Record{ID: "used", URLs: []string{"https://cdn/used.png"}, Status: StatusAttached, CreatedAt: old},
+	)
+	uploader := &failingDeleteUploader{failURL: "https://cdn/stuck.png"}
+	uc := NewUsecaseWithRepository(uploader, repo, nil)
+
+	removed, err := uc.SweepOrphans(context.Background(), 0)
+	require.Error(t, err)
+	assert.Equal(t, 1, removed)
+	assert.WithinDuration(t, time.Now().UTC().Add(-DefaultOrphanMaxAge), repo.pendingSeen, time.Minute)
+
+	assert.NotContains(t, repo.records, "orphan")
+	assert.Contains(t, repo.records, "stuck", "failed deletes are retried on the next sweep")
+	assert.Contains(t, repo.records, "fresh")
+	assert.Contains(t, repo.records, "used")
+}
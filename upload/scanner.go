@@ -0,0 +1,32 @@
+package upload
+
+import (
+	"context"
+	"io"
+
+	"hexagon/errs"
+)
+
+// ScanResult is the verdict of a Scanner. Signature names what was found and
+// is empty for clean files.
+type ScanResult struct {
+	Infected  bool
+	Signature string
+}
+
+// Scanner checks uploaded content for malware before anything is stored.
+// Errors mean the file could not be scanned and the upload is refused.
+type Scanner interface {
+	Scan(ctx context.Context, body io.Reader, size int64) (ScanResult, error)
+}
+
+// NopScanner accepts every file. It is used when no scanner is configured.
+type NopScanner struct{}
+
+func (NopScanner) Scan(ctx context.Context, body io.Reader, size int64) (ScanResult, error) {
+	return ScanResult{}, nil
+}
+
+func infectedFileError(fileName string, result ScanResult) error {
+	return errs.Errorf(errs.EINFECTED, "upload: %s was rejected by the malware scanner (%s)", fileName, result.Signature)
+}
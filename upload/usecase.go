@@ -1,6 +1,7 @@
 package upload
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
@@ -8,7 +9,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"path/filepath"
+	"path"
 	"strings"
 	"time"
 )
@@ -16,46 +17,157 @@ import (
 const DefaultMaxImageSize = 10 << 20 // 10 MB
 
 var (
-	ErrNoImageFile          = errors.New("at least one image file is required")
-	ErrImageTooLarge        = errors.New("image exceeds 10MB limit")
-	ErrUnsupportedImageType = errors.New("unsupported image type")
-	ErrUploaderUnavailable  = errors.New("upload service is not configured")
+	ErrNoImageFile             = errors.New("at least one image file is required")
+	ErrImageTooLarge           = errors.New("image exceeds 10MB limit")
+	ErrUnsupportedImageType    = errors.New("unsupported image type")
+	ErrUploaderUnavailable     = errors.New("upload service is not configured")
+	ErrDirectUploadUnavailable = errors.New("direct upload is not supported by the configured storage")
+	ErrInvalidUploadKey        = errors.New("invalid upload key")
+	ErrUploadNotFound          = errors.New("uploaded object not found")
+	ErrContentTypeMismatch     = errors.New("uploaded content does not match the declared content type")
+	// ErrObjectNotFound is returned by DirectUploader.Open for missing keys.
+	ErrObjectNotFound = errors.New("object not found")
 )
 
+// presignTTL is how long a presigned upload URL stays valid.
+const presignTTL = 15 * time.Minute
+
 type File struct {
 	Filename string
 	Size     int64
 	Open     func() (io.ReadSeekCloser, error)
 }
 
+// UploadedFile describes one processed upload. URL, Size, ContentType and the
+// dimensions refer to the primary (largest) rendition; Variants lists every
+// rendition, smallest first.
 type UploadedFile struct {
 	FileName    string
 	URL         string
 	Size        int64
 	ContentType string
+	Width       int
+	Height      int
+	Variants    []ImageVariant
+}
+
+type ImageVariant struct {
+	Name        string
+	URL         string
+	Size        int64
+	ContentType string
+	Width       int
+	Height      int
 }
 
 type Uploader interface {
 	Upload(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error)
+	// Delete removes an object previously stored by Upload, identified by the
+	// URL Upload returned. URLs this storage does not serve are ignored.
+	Delete(ctx context.Context, url string) error
+}
+
+// DirectUploader is implemented by storage that lets clients upload straight
+// to it with a presigned request instead of streaming through the API.
+type DirectUploader interface {
+	// PresignPut returns a request that stores exactly size bytes of
+	// contentType under key. Storage rejects uploads that differ.
+	PresignPut(ctx context.Context, key, contentType string, size int64, ttl time.Duration) (PresignedRequest, error)
+	// Open reads a stored object; missing keys return ErrObjectNotFound.
+	Open(ctx context.Context, key string) (StoredObject, error)
+}
+
+type PresignedRequest struct {
+	URL     string
+	Method  string
+	Headers map[string]string
+	// ObjectURL is the URL of the object once uploaded, as Uploader.Upload
+	// would return it.
+	ObjectURL string
+}
+
+type StoredObject struct {
+	Body        io.ReadCloser
+	URL         string
+	Size        int64
+	ContentType string
+}
+
+// PresignRequest describes a file the client is about to upload directly.
+type PresignRequest struct {
+	ContentType string
+	Size        int64
+}
+
+type PresignedUpload struct {
+	Key       string
+	URL       string
+	Method    string
+	Headers   map[string]string
+	ExpiresAt time.Time
 }
 
 type Service interface {
-	UploadImages(ctx context.Context, folder string, files []File) ([]UploadedFile, error)
+	UploadImages(ctx context.Context, ownerID, folder string, files []File) ([]UploadedFile, error)
+	PresignImageUpload(ctx context.Context, folder string, req PresignRequest) (PresignedUpload, error)
+	CompleteImageUpload(ctx context.Context, ownerID, folder, key string) (UploadedFile, error)
+	Tracker
 }
 
 type Usecase struct {
 	uploader     Uploader
+	direct       DirectUploader
+	repo         Repository
+	scanner      Scanner
 	maxImageSize int64
+	renditions   []Rendition
 }
 
 func NewUsecase(uploader Uploader) *Usecase {
-	return &Usecase{
+	return NewUsecaseWithRenditions(uploader, DefaultRenditions)
+}
+
+// NewUsecaseWithRenditions replaces the default rendition set. An empty set
+// falls back to DefaultRenditions.
+func NewUsecaseWithRenditions(uploader Uploader, renditions []Rendition) *Usecase {
+	return NewUsecaseWithRepository(uploader, nil, renditions)
+}
+
+// NewUsecaseWithRepository also records every upload in repo so unused
+// uploads can be swept; a nil repo disables tracking.
+func NewUsecaseWithRepository(uploader Uploader, repo Repository, renditions []Rendition) *Usecase {
+	return NewUsecaseWithScanner(uploader, repo, NopScanner{}, renditions)
+}
+
+// NewUsecaseWithScanner also runs every file through scanner before it is
+// stored; a nil scanner accepts everything.
+func NewUsecaseWithScanner(uploader Uploader, repo Repository, scanner Scanner, renditions []Rendition) *Usecase {
+	if len(renditions) == 0 {
+		renditions = DefaultRenditions
+	}
+
+	if scanner == nil {
+		scanner = NopScanner{}
+	}
+
+	uc := &Usecase{
 		uploader:     uploader,
+		repo:         repo,
+		scanner:      scanner,
 		maxImageSize: DefaultMaxImageSize,
+		renditions:   renditions,
+	}
+
+	if direct, ok := uploader.(DirectUploader); ok {
+		uc.direct = direct
 	}
+
+	return uc
 }
 
-func (uc *Usecase) UploadImages(ctx context.Context, folder string, files []File) ([]UploadedFile, error) {
+// UploadImages stores renditions for each file and records them as pending
+// uploads of ownerID (empty for anonymous uploads).
+func (uc *Usecase) UploadImages(ctx context.Context, ownerID, folder string, files []File) ([]UploadedFile, error) {
 	if uc.uploader == nil {
 		return nil, ErrUploaderUnavailable
 	}
@@ -64,10 +176,7 @@ func (uc *Usecase) UploadImages(ctx context.Context, folder string, files []File
 		return nil, ErrNoImageFile
 	}
 
-	folder = strings.Trim(strings.TrimSpace(folder), "/")
-	if folder == "" {
-		folder = "images"
-	}
+	folder = normalizeFolder(folder)
 
 	uploaded := make([]UploadedFile, 0, len(files))
 
@@ -77,6 +186,10 @@ func (uc *Usecase) UploadImages(ctx context.Context, folder string, files []File
 			return nil, err
 		}
 
+		if err := uc.trackOrDiscard(ctx, ownerID, folder, file); err != nil {
+			return nil, err
+		}
+
 		uploaded = append(uploaded, file)
 	}
 
@@ -103,21 +216,210 @@ func (uc *Usecase) uploadImage(ctx context.Context, folder string, file File) (U
 		return UploadedFile{}, err
 	}
 
-	objectKey := buildImageObjectKey(file.Filename, contentType, folder)
+	data, err := io.ReadAll(io.LimitReader(reader, uc.maxImageSize+1))
+	if err != nil {
+		return UploadedFile{}, fmt.Errorf("read uploaded file: %w", err)
+	}
+
+	if int64(len(data)) > uc.maxImageSize {
+		return UploadedFile{}, ErrImageTooLarge
+	}
+
+	return uc.storeRenditions(ctx, file.Filename, buildImageObjectKey(folder), data, contentType)
+}
+
+// PresignImageUpload validates the declared file and returns a presigned PUT
+// for it. The object lands at <key prefix>/original<ext> and is only turned
+// into renditions by CompleteImageUpload. It is tracked as pending from the
+// start, so the sweeper removes it if the upload is never completed.
+func (uc *Usecase) PresignImageUpload(ctx context.Context, folder string, req PresignRequest) (PresignedUpload, error) {
+	if uc.direct == nil {
+		return PresignedUpload{}, ErrDirectUploadUnavailable
+	}
+
+	if req.Size <= 0 {
+		return PresignedUpload{}, ErrNoImageFile
+	}
+
+	if req.Size > uc.maxImageSize {
+		return PresignedUpload{}, ErrImageTooLarge
+	}
+
+	ext := contentTypeToExtension(req.ContentType)
+	if ext == "" {
+		return PresignedUpload{}, fmt.Errorf("%w: %s", ErrUnsupportedImageType, req.ContentType)
+	}
+
+	key := buildImageObjectKey(normalizeFolder(folder)) + "/" + stagedObjectName + ext
+
+	presigned, err := uc.direct.PresignPut(ctx, key, req.ContentType, req.Size, presignTTL)
+	if err != nil {
+		return PresignedUpload{}, fmt.Errorf("presign upload: %w", err)
+	}
+
+	if err := uc.trackStaged(ctx, folder, presigned.ObjectURL); err != nil {
+		return PresignedUpload{}, fmt.Errorf("record upload: %w", err)
+	}
+
+	return PresignedUpload{
+		Key:       key,
+		URL:       presigned.URL,
+		Method:    presigned.Method,
+		Headers:   presigned.Headers,
+		ExpiresAt: time.Now().UTC().Add(presignTTL),
+	}, nil
+}
+
+// CompleteImageUpload checks an object uploaded with PresignImageUpload,
+// sniffs its real type and stores renditions next to it. The staged original
+// is removed afterwards, as is any upload that fails the checks.
+func (uc *Usecase) CompleteImageUpload(ctx context.Context, ownerID, folder, key string) (UploadedFile, error) {
+	if uc.direct == nil {
+		return UploadedFile{}, ErrDirectUploadUnavailable
+	}
+
+	folder = normalizeFolder(folder)
+
+	baseKey, ok := parseStagedObjectKey(folder, key)
+	if !ok {
+		return UploadedFile{}, ErrInvalidUploadKey
+	}
+
+	obj, err := uc.direct.Open(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return UploadedFile{}, ErrUploadNotFound
+		}
+
+		return UploadedFile{}, fmt.Errorf("open uploaded object: %w", err)
+	}
+	defer obj.Body.Close()
+
+	file, err := uc.processStagedObject(ctx, baseKey, obj)
+	uc.discardStaged(ctx, obj.URL)
+
+	if err != nil {
+		return UploadedFile{}, err
+	}
+
+	if err := uc.trackOrDiscard(ctx, ownerID, folder, file); err != nil {
+		return UploadedFile{}, err
+	}
+
+	return file, nil
+}
+
+// trackOrDiscard records file; an upload that cannot be recorded would never
+// be swept, so its renditions are removed instead.
+func (uc *Usecase) trackOrDiscard(ctx context.Context, ownerID, folder string, file UploadedFile) error {
+	if err := uc.track(ctx, ownerID, folder, file); err != nil {
+		uc.discardVariants(ctx, file.Variants)
+		return fmt.Errorf("record upload: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *Usecase) processStagedObject(ctx context.Context, baseKey string, obj StoredObject) (UploadedFile, error) {
+	if obj.Size > uc.maxImageSize {
+		return UploadedFile{}, ErrImageTooLarge
+	}
+
+	data, err := io.ReadAll(io.LimitReader(obj.Body, uc.maxImageSize+1))
+	if err != nil {
+		return UploadedFile{}, fmt.Errorf("read uploaded object: %w", err)
+	}
+
+	if int64(len(data)) > uc.maxImageSize {
+		return UploadedFile{}, ErrImageTooLarge
+	}
 
-	url, err := uc.uploader.Upload(ctx, objectKey, reader, file.Size, contentType)
+	contentType, err := detectImageContentType(bytes.NewReader(data))
 	if err != nil {
-		return UploadedFile{}, fmt.Errorf("upload image to storage: %w", err)
+		return UploadedFile{}, err
+	}
+
+	if obj.ContentType != "" && obj.ContentType != contentType {
+		return UploadedFile{}, fmt.Errorf("%w: declared %s, got %s", ErrContentTypeMismatch, obj.ContentType, contentType)
+	}
+
+	return uc.storeRenditions(ctx, path.Base(obj.URL), baseKey, data, contentType)
+}
+
+// storeRenditions scans and decodes data and uploads every rendition under
+// baseKey. The original bytes are scanned, not the re-encoded renditions.
+func (uc *Usecase) storeRenditions(ctx context.Context, fileName, baseKey string, data []byte, contentType string) (UploadedFile, error) {
+	result, err := uc.scanner.Scan(ctx, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return UploadedFile{}, fmt.Errorf("scan uploaded file: %w", err)
+	}
+
+	if result.Infected {
+		return UploadedFile{}, infectedFileError(fileName, result)
+	}
+
+	src, err := decodeImage(data, contentType)
+	if err != nil {
+		return UploadedFile{}, err
+	}
+
+	variants := make([]ImageVariant, 0, len(uc.renditions))
+
+	for i := range uc.renditions {
+		variant, err := uc.uploadRendition(ctx, src, baseKey, uc.renditions[i])
+		if err != nil {
+			uc.discardVariants(ctx, variants)
+			return UploadedFile{}, err
+		}
+
+		variants = append(variants, variant)
 	}
 
+	primary := variants[len(variants)-1]
+
 	return UploadedFile{
-		FileName:    file.Filename,
+		FileName:    fileName,
+		URL:         primary.URL,
+		Size:        primary.Size,
+		ContentType: primary.ContentType,
+		Width:       primary.Width,
+		Height:      primary.Height,
+		Variants:    variants,
+	}, nil
+}
+
+func (uc *Usecase) uploadRendition(ctx context.Context, src sourceImage, baseKey string, r Rendition) (ImageVariant, error) {
+	encoded, err := src.render(r)
+	if err != nil {
+		return ImageVariant{}, err
+	}
+
+	key := baseKey + "/" + r.Name + contentTypeToExtension(encoded.contentType)
+	size := int64(len(encoded.body))
+
+	url, err := uc.uploader.Upload(ctx, key, bytes.NewReader(encoded.body), size, encoded.contentType)
+	if err != nil {
+		return ImageVariant{}, fmt.Errorf("upload image to storage: %w", err)
+	}
+
+	return ImageVariant{
+		Name:        r.Name,
 		URL:         url,
-		Size:        file.Size,
-		ContentType: contentType,
+		Size:        size,
+		ContentType: encoded.contentType,
+		Width:       encoded.width,
+		Height:      encoded.height,
 	}, nil
 }
 
+// discardVariants removes renditions already stored for an upload that
+// failed part way. Errors are ignored: the upload has already failed.
+func (uc *Usecase) discardVariants(ctx context.Context, variants []ImageVariant) {
+	for i := range variants {
+		_ = uc.uploader.Delete(ctx, variants[i].URL)
+	}
+}
+
 func detectImageContentType(reader io.ReadSeeker) (string, error) {
 	buf := make([]byte, 512)
 
@@ -139,17 +441,57 @@ func detectImageContentType(reader io.ReadSeeker) (string, error) {
 	}
 }
 
-func buildImageObjectKey(filename, contentType, folder string) string {
-	ext := strings.ToLower(filepath.Ext(filename))
-	if ext == "" {
-		ext = contentTypeToExtension(contentType)
+// stagedObjectName is the object name a presigned upload is stored under
+// until it is completed.
+const stagedObjectName = "original"
+
+func normalizeFolder(folder string) string {
+	folder = strings.Trim(strings.TrimSpace(folder), "/")
+	if folder == "" {
+		return "images"
 	}
 
-	if ext == "" {
-		ext = ".bin"
+	return folder
+}
+
+// parseStagedObjectKey accepts only keys PresignImageUpload can produce for
+// folder, <folder>/<yyyymmdd>/<32 hex>/original<ext>, and returns the prefix
+// renditions are stored under.
+func parseStagedObjectKey(folder, key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, folder+"/")
+	if !ok {
+		return "", false
+	}
+
+	parts := strings.Split(rest, "/")
+	if len(parts) != 3 {
+		return "", false
+	}
+
+	if _, err := time.Parse("20060102", parts[0]); err != nil {
+		return "", false
+	}
+
+	if len(parts[1]) != 32 {
+		return "", false
+	}
+
+	if _, err := hex.DecodeString(parts[1]); err != nil {
+		return "", false
 	}
 
-	return folder + "/" + time.Now().UTC().Format("20060102") + "/" + randomHex(16) + ext
+	name, ext, ok := strings.Cut(parts[2], ".")
+	if !ok || name != stagedObjectName || contentTypeFromExtension("."+ext) == "" {
+		return "", false
+	}
+
+	return folder + "/" + parts[0] + "/" + parts[1], true
+}
+
+// buildImageObjectKey returns the key prefix shared by all renditions of one
+// upload; each rendition is stored as <prefix>/<name><ext>.
+func buildImageObjectKey(folder string) string {
+	return folder + "/" + time.Now().UTC().Format("20060102") + "/" + randomHex(16)
 }
 
 func contentTypeToExtension(contentType string) string {
@@ -167,6 +509,16 @@ func contentTypeToExtension(contentType string) string {
 	}
 }
 
+func contentTypeFromExtension(ext string) string {
+	for _, contentType := range []string{"image/jpeg", "image/png", "image/gif", "image/webp"} {
+		if contentTypeToExtension(contentType) == ext {
+			return contentType
+		}
+	}
+
+	return ""
+}
+
 func randomHex(bytesCount int) string {
 	b := make([]byte, bytesCount)
 	if _, err := rand.Read(b); err != nil {
@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"image"
+	"image/png"
 	"io"
 	"strings"
 	"testing"
 	"time"
 
+	"hexagon/errs"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -24,32 +28,56 @@ func newReadSeekCloser(b []byte) io.ReadSeekCloser {
 }
 
 type fakeUploader struct {
-	lastKey         string
+	keys            []string
 	lastContentType string
 	lastSize        int64
 	lastBody        []byte
-	url             string
+	deleted         []string
+	failOnCall      int
 	err             error
 }
 
 func (f *fakeUploader) Upload(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
-	f.lastKey = key
+	f.keys = append(f.keys, key)
 	f.lastContentType = contentType
 	f.lastSize = size
 	f.lastBody, _ = io.ReadAll(body)
 
-	return f.url, f.err
+	if f.failOnCall > 0 && len(f.keys) == f.failOnCall {
+		return "", f.err
+	}
+
+	return "https://cdn.example.com/" + key, nil
+}
+
+func (f *fakeUploader) Delete(ctx context.Context, url string) error {
+	f.deleted = append(f.deleted, url)
+	return nil
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	return buf.Bytes()
 }
 
 func TestUsecase_UploadImages_UploaderUnavailable(t *testing.T) {
 	uc := NewUsecase(nil)
-	_, err := uc.UploadImages(context.Background(), "", nil)
+	_, err := uc.UploadImages(context.Background(), "", "", nil)
 	assert.ErrorIs(t, err, ErrUploaderUnavailable)
 }
 
 func TestUsecase_UploadImages_NoFiles(t *testing.T) {
 	uc := NewUsecase(&fakeUploader{})
-	_, err := uc.UploadImages(context.Background(), "", nil)
+	_, err := uc.UploadImages(context.Background(), "", "", nil)
 	assert.ErrorIs(t, err, ErrNoImageFile)
 }
 
@@ -59,7 +87,7 @@ func TestUsecase_UploadImages_FileTooLarge(t *testing.T) {
 		return newReadSeekCloser([]byte("data")), nil
 	}}
 
-	_, err := uc.UploadImages(context.Background(), "images", []File{file})
+	_, err := uc.UploadImages(context.Background(), "", "images", []File{file})
 	assert.ErrorIs(t, err, ErrImageTooLarge)
 }
 
@@ -67,7 +95,7 @@ func TestUsecase_UploadImages_OpenFuncNil(t *testing.T) {
 	uc := NewUsecase(&fakeUploader{})
 	file := File{Filename: "a.jpg", Size: 10}
 
-	_, err := uc.UploadImages(context.Background(), "images", []File{file})
+	_, err := uc.UploadImages(context.Background(), "", "images", []File{file})
 	assert.Error(t, err)
 	assert.True(t, strings.Contains(err.Error(), "open function is nil"))
 }
@@ -81,33 +109,236 @@ func TestDetectImageContentType_Unsupported(t *testing.T) {
 
 func TestBuildImageObjectKey(t *testing.T) {
 	date := time.Now().UTC().Format("20060102")
-	key := buildImageObjectKey("photo", "image/jpeg", "uploads")
+	key := buildImageObjectKey("uploads")
 
 	assert.True(t, strings.HasPrefix(key, "uploads/"+date+"/"))
-	assert.True(t, strings.HasSuffix(key, ".jpg"))
+	assert.NotEqual(t, key, buildImageObjectKey("uploads"))
 }
 
 func TestUsecase_UploadImages_Success(t *testing.T) {
-	uploader := &fakeUploader{url: "https://cdn.example.com/uploads/x.jpg"}
+	uploader := &fakeUploader{}
 	uc := NewUsecase(uploader)
 
-	pngHeader := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+	data := encodePNG(t, 1000, 500)
 	file := File{
 		Filename: "image.png",
-		Size:     int64(len(pngHeader)),
+		Size:     int64(len(data)),
 		Open: func() (io.ReadSeekCloser, error) {
-			return newReadSeekCloser(pngHeader), nil
+			return newReadSeekCloser(data), nil
 		},
 	}
 
-	files, err := uc.UploadImages(context.Background(), "avatars", []File{file})
+	files, err := uc.UploadImages(context.Background(), "", "avatars", []File{file})
 	require.NoError(t, err)
 	require.Len(t, files, 1)
+	require.Len(t, files[0].Variants, 3)
+	require.Len(t, uploader.keys, 3)
 
 	assert.Equal(t, "image.png", files[0].FileName)
-	assert.Equal(t, uploader.url, files[0].URL)
 	assert.Equal(t, "image/png", files[0].ContentType)
-	assert.True(t, strings.HasPrefix(uploader.lastKey, "avatars/"))
-	assert.Equal(t, int64(len(pngHeader)), uploader.lastSize)
-	assert.Equal(t, pngHeader, uploader.lastBody)
+	assert.Equal(t, 1000, files[0].Width)
+	assert.Equal(t, 500, files[0].Height)
+	assert.Equal(t, files[0].Variants[2].URL, files[0].URL)
+
+	thumb := files[0].Variants[0]
+	assert.Equal(t, "thumbnail", thumb.Name)
+	assert.Equal(t, 320, thumb.Width)
+	assert.Equal(t, 160, thumb.Height)
+	assert.True(t, strings.HasPrefix(uploader.keys[0], "avatars/"))
+	assert.True(t, strings.HasSuffix(uploader.keys[0], "/thumbnail.png"))
+	assert.Equal(t, int64(len(uploader.lastBody)), uploader.lastSize)
+}
+
+func TestUsecase_UploadImages_DiscardsPartialRenditions(t *testing.T) {
+	uploader := &fakeUploader{failOnCall: 2, err: errors.New("boom")}
+	uc := NewUsecase(uploader)
+
+	data := encodePNG(t, 10, 10)
+	file := File{
+		Filename: "image.png",
+		Size:     int64(len(data)),
+		Open: func() (io.ReadSeekCloser, error) {
+			return newReadSeekCloser(data), nil
+		},
+	}
+
+	_, err := uc.UploadImages(context.Background(), "", "avatars", []File{file})
+	require.Error(t, err)
+	assert.Equal(t, []string{"https://cdn.example.com/" + uploader.keys[0]}, uploader.deleted)
+}
+
+func TestUsecase_UploadImages_CorruptImage(t *testing.T) {
+	uc := NewUsecase(&fakeUploader{})
+
+	pngHeader := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+	file := File{
+		Filename: "image.png",
+		Size:     int64(len(pngHeader)),
+		Open: func() (io.ReadSeekCloser, error) {
+			return newReadSeekCloser(pngHeader), nil
+		},
+	}
+
+	_, err := uc.UploadImages(context.Background(), "", "avatars", []File{file})
+	assert.ErrorIs(t, err, ErrUnsupportedImageType)
+}
+
+type fakeDirectUploader struct {
+	fakeUploader
+	objects     map[string][]byte
+	contentType string
+	presignedTo string
+}
+
+func (f *fakeDirectUploader) PresignPut(ctx context.Context, key, contentType string, size int64, ttl time.Duration) (PresignedRequest, error) {
+	f.presignedTo = key
+	return PresignedRequest{
+		URL:       "https://cdn.example.com/" + key + "?sig",
+		Method:    "PUT",
+		Headers:   map[string]string{"Content-Type": contentType},
+		ObjectURL: "https://cdn.example.com/" + key,
+	}, nil
+}
+
+func (f *fakeDirectUploader) Open(ctx context.Context, key string) (StoredObject, error) {
+	data, ok := f.objects[key]
+	if !ok {
+		return StoredObject{}, ErrObjectNotFound
+	}
+
+	return StoredObject{
+		Body:        io.NopCloser(bytes.NewReader(data)),
+		URL:         "https://cdn.example.com/" + key,
+		Size:        int64(len(data)),
+		ContentType: f.contentType,
+	}, nil
+}
+
+func TestUsecase_PresignImageUpload(t *testing.T) {
+	_, err := NewUsecase(&fakeUploader{}).PresignImageUpload(context.Background(), "hotel-images", PresignRequest{ContentType: "image/png", Size: 10})
+	assert.ErrorIs(t, err, ErrDirectUploadUnavailable)
+
+	direct := &fakeDirectUploader{}
+	uc := NewUsecase(direct)
+
+	_, err = uc.PresignImageUpload(context.Background(), "hotel-images", PresignRequest{ContentType: "image/png", Size: uc.maxImageSize + 1})
+	assert.ErrorIs(t, err, ErrImageTooLarge)
+
+	_, err = uc.PresignImageUpload(context.Background(), "hotel-images", PresignRequest{ContentType: "text/html", Size: 10})
+	assert.ErrorIs(t, err, ErrUnsupportedImageType)
+
+	presigned, err := uc.PresignImageUpload(context.Background(), "hotel-images", PresignRequest{ContentType: "image/png", Size: 10})
+	require.NoError(t, err)
+	assert.Equal(t, direct.presignedTo, presigned.Key)
+	assert.True(t, strings.HasSuffix(presigned.Key, "/original.png"))
+	assert.Equal(t, "PUT", presigned.Method)
+
+	_, ok := parseStagedObjectKey("hotel-images", presigned.Key)
+	assert.True(t, ok)
+}
+
+func TestUsecase_CompleteImageUpload(t *testing.T) {
+	direct := &fakeDirectUploader{objects: map[string][]byte{}, contentType: "image/png"}
+	uc := NewUsecase(direct)
+
+	presigned, err := uc.PresignImageUpload(context.Background(), "hotel-images", PresignRequest{ContentType: "image/png", Size: 10})
+	require.NoError(t, err)
+
+	_, err = uc.CompleteImageUpload(context.Background(), "", "hotel-images", presigned.Key)
+	assert.ErrorIs(t, err, ErrUploadNotFound)
+
+	_, err = uc.CompleteImageUpload(context.Background(), "", "avatars", presigned.Key)
+	assert.ErrorIs(t, err, ErrInvalidUploadKey)
+
+	direct.objects[presigned.Key] = encodePNG(t, 20, 10)
+
+	file, err := uc.CompleteImageUpload(context.Background(), "", "hotel-images", presigned.Key)
+	require.NoError(t, err)
+	assert.Len(t, file.Variants, 3)
+	assert.Equal(t, 20, file.Width)
+	assert.Equal(t, strings.TrimSuffix(presigned.Key, "original.png")+"full.png", direct.keys[2])
+	assert.Equal(t, []string{"https://cdn.example.com/" + presigned.Key}, direct.deleted)
+}
+
+func TestUsecase_CompleteImageUpload_ContentTypeMismatch(t *testing.T) {
+	direct := &fakeDirectUploader{objects: map[string][]byte{}, contentType: "image/png"}
+	uc := NewUsecase(direct)
+
+	presigned, err := uc.PresignImageUpload(context.Background(), "hotel-images", PresignRequest{ContentType: "image/png", Size: 10})
+	require.NoError(t, err)
+
+	direct.objects[presigned.Key] = []byte("<html>not an image</html>")
+
+	_, err = uc.CompleteImageUpload(context.Background(), "", "hotel-images", presigned.Key)
+	assert.ErrorIs(t, err, ErrUnsupportedImageType)
+	assert.Empty(t, direct.keys)
+	assert.Len(t, direct.deleted, 1, "rejected uploads are removed")
+
+	direct.contentType = "image/jpeg"
+	direct.objects[presigned.Key] = encodePNG(t, 2, 2)
+
+	_, err = uc.CompleteImageUpload(context.Background(), "", "hotel-images", presigned.Key)
+	assert.ErrorIs(t, err, ErrContentTypeMismatch)
+}
+
+func TestParseStagedObjectKey(t *testing.T) {
+	base, ok := parseStagedObjectKey("hotel-images", "hotel-images/20260101/0123456789abcdef0123456789abcdef/original.jpg")
+	assert.True(t, ok)
+	assert.Equal(t, "hotel-images/20260101/0123456789abcdef0123456789abcdef", base)
+
+	for _, key := range []string{
+		"other/20260101/0123456789abcdef0123456789abcdef/original.jpg",
+		"hotel-images/20260101/0123456789abcdef0123456789abcdef/full.jpg",
+		"hotel-images/20260101/0123456789abcdef0123456789abcdef/original.exe",
+		"hotel-images/2026-01-01/0123456789abcdef0123456789abcdef/original.jpg",
+		"hotel-images/20260101/../original.jpg",
+		"hotel-images/20260101/x/y/original.jpg",
+	} {
+		_, ok = parseStagedObjectKey("hotel-images", key)
+		assert.False(t, ok, key)
+	}
+}
+
+type fakeScanner struct {
+	scanned []byte
+	result  ScanResult
+	err     error
+}
+
+func (f *fakeScanner) Scan(ctx context.Context, body io.Reader, size int64) (ScanResult, error) {
+	f.scanned, _ = io.ReadAll(body)
+	return f.result, f.err
+}
+
+func TestUsecase_UploadImages_RejectsInfectedFile(t *testing.T) {
+	uploader := &fakeUploader{}
+	scanner := &fakeScanner{result: ScanResult{Infected: true, Signature: "Eicar-Signature"}}
+	uc := NewUsecaseWithScanner(uploader, nil, scanner, nil)
+
+	data := encodePNG(t, 10, 10)
+	_, err := uc.UploadImages(context.Background(), "", "hotels", []File{{
+		Filename: "a.png",
+		Size:     int64(len(data)),
+		Open:     func() (io.ReadSeekCloser, error) { return newReadSeekCloser(data), nil },
+	}})
+	require.Error(t, err)
+	assert.Equal(t, errs.EINFECTED, errs.ErrorCode(err))
+	assert.Contains(t, errs.ErrorMessage(err), "Eicar-Signature")
+	assert.Equal(t, data, scanner.scanned, "the original bytes are scanned")
+	assert.Empty(t, uploader.keys, "nothing is stored")
+}
+
+func TestUsecase_UploadImages_ScannerUnavailable(t *testing.T) {
+	uploader := &fakeUploader{}
+	uc := NewUsecaseWithScanner(uploader, nil, &fakeScanner{err: errors.New("connection refused")}, nil)
+
+	data := encodePNG(t, 10, 10)
+	_, err := uc.UploadImages(context.Background(), "", "hotels", []File{{
+		Filename: "a.png",
+		Size:     int64(len(data)),
+		Open:     func() (io.ReadSeekCloser, error) { return newReadSeekCloser(data), nil },
+	}})
+	require.Error(t, err)
+	assert.Equal(t, errs.EINTERNAL, errs.ErrorCode(err))
+	assert.Empty(t, uploader.keys)
 }
@@ -0,0 +1,181 @@
+package user
+
+import (
+	"context"
+	"strings"
+
+	"hexagon/audit"
+	"hexagon/errs"
+)
+
+var (
+	ErrUserNotInactive = errs.Errorf(errs.ECONFLICT, "user: account is not deactivated")
+	ErrUserDeleted     = errs.Errorf(errs.ECONFLICT, "user: account is deleted")
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchFilter selects users; zero fields match everyone. Query matches
+// part of the email or name, ignoring case.
+type SearchFilter struct {
+	Query  string
+	Status UserStatus
+	Role   UserRole
+	Limit  int
+	Offset int
+}
+
+// SearchResult is one page of users, newest first, and the number of all
+// users matching the filter.
+type SearchResult struct {
+	Users []User
+	Total int
+}
+
+// SearchUsers pages through users. The limit defaults to 20 and is capped
+// at 100.
+func (uc *Usecase) SearchUsers(ctx context.Context, filter SearchFilter) (SearchResult, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return SearchResult{}, ErrInvalidStatus
+	}
+
+	if filter.Role != "" && !filter.Role.IsValid() {
+		return SearchResult{}, ErrInvalidRole
+	}
+
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = defaultSearchLimit
+	case filter.Limit > maxSearchLimit:
+		filter.Limit = maxSearchLimit
+	}
+
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	return uc.r.SearchUsers(ctx, filter)
+}
+
+// ChangeRole gives the user role. The role is part of the access token, so
+// the user is logged out everywhere to pick up the new one.
+func (uc *Usecase) ChangeRole(ctx context.Context, id string, role UserRole) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return ErrUserIDRequired
+	}
+
+	if !role.IsValid() {
+		return ErrInvalidRole
+	}
+
+	existing, err := uc.getManagedUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if existing.Role == role {
+		return nil
+	}
+
+	if err := uc.r.UpdateRole(ctx, id, role); err != nil {
+		return err
+	}
+
+	if err := uc.revokeTokens(ctx, id); err != nil {
+		return err
+	}
+
+	uc.recordAudit(ctx, audit.EventRoleChanged, id, map[string]string{
+		"previous_role": string(existing.Role),
+		"role":          string(role),
+	})
+
+	return nil
+}
+
+// ReactivateUser undoes DeactivateUser.
+func (uc *Usecase) ReactivateUser(ctx context.Context, id string) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return ErrUserIDRequired
+	}
+
+	existing, err := uc.getManagedUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if existing.Status != UserStatusInactive {
+		return ErrUserNotInactive
+	}
+
+	if err := uc.r.UpdateStatus(ctx, id, UserStatusActive); err != nil {
+		return err
+	}
+
+	uc.recordAudit(ctx, audit.EventUserReactivated, id, nil)
+
+	return nil
+}
+
+// UnlockUser lifts a lock from failed logins and forgets the failed
+// attempts, including the escalation that lengthens the next lock.
+func (uc *Usecase) UnlockUser(ctx context.Context, id string) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return ErrUserIDRequired
+	}
+
+	if _, err := uc.getManagedUser(ctx, id); err != nil {
+		return err
+	}
+
+	if err := uc.r.ClearLoginFailures(ctx, id); err != nil {
+		return err
+	}
+
+	uc.recordAudit(ctx, audit.EventUserUnlocked, id, nil)
+
+	return nil
+}
+
+// ForceLogout ends every session of the user and revokes its access tokens.
+func (uc *Usecase) ForceLogout(ctx context.Context, id string) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return ErrUserIDRequired
+	}
+
+	if _, err := uc.getManagedUser(ctx, id); err != nil {
+		return err
+	}
+
+	if err := uc.revokeTokens(ctx, id); err != nil {
+		return err
+	}
+
+	uc.recordAudit(ctx, audit.EventUserLoggedOut, id, nil)
+
+	return nil
+}
+
+// getManagedUser loads a user for an admin action. Deleted accounts are
+// anonymised placeholders, so none of the actions apply to them.
+func (uc *Usecase) getManagedUser(ctx context.Context, id string) (User, error) {
+	u, err := uc.r.GetByID(ctx, id)
+	if err != nil {
+		return User{}, err
+	}
+
+	if u.DeletedAt != nil {
+		return User{}, ErrUserDeleted
+	}
+
+	return u, nil
+}
@@ -0,0 +1,171 @@
+package user_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hexagon/audit"
+	"hexagon/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchUsers_ClampsPaging(t *testing.T) {
+	r := new(MockUserRepository)
+	uc := user.NewUsecase(r, new(MockPasswordHasher))
+
+	r.On("SearchUsers", mock.Anything, user.SearchFilter{Query: "john", Limit: 20}).
+		Return(user.SearchResult{}, nil).Once()
+	r.On("SearchUsers", mock.Anything, user.SearchFilter{Role: user.UserRoleAdmin, Limit: 100}).
+		Return(user.SearchResult{}, nil).Once()
+
+	_, err := uc.SearchUsers(context.Background(), user.SearchFilter{Query: "  john ", Offset: -5})
+	assert.NoError(t, err)
+
+	_, err = uc.SearchUsers(context.Background(), user.SearchFilter{Role: user.UserRoleAdmin, Limit: 500})
+	assert.NoError(t, err)
+
+	r.AssertExpectations(t)
+}
+
+func TestSearchUsers_RejectsUnknownStatusAndRole(t *testing.T) {
+	uc := user.NewUsecase(new(MockUserRepository), new(MockPasswordHasher))
+
+	_, err := uc.SearchUsers(context.Background(), user.SearchFilter{Status: "banned"})
+	assert.Equal(t, user.ErrInvalidStatus, err)
+
+	_, err = uc.SearchUsers(context.Background(), user.SearchFilter{Role: "root"})
+	assert.Equal(t, user.ErrInvalidRole, err)
+}
+
+func TestChangeRole_RevokesTokensAndRecordsAuditEvent(t *testing.T) {
+	r := new(MockUserRepository)
+	s := new(MockSessionRepository)
+	revoker := new(MockTokenRevoker)
+	log := new(MockAuditRecorder)
+	uc := user.NewUsecaseWithSession(r, new(MockPasswordHasher), s).WithTokenRevoker(revoker).WithAuditLog(log)
+
+	r.On("GetByID", mock.Anything, "u-1").Return(user.User{ID: "u-1", Role: user.UserRoleUser}, nil).Once()
+	r.On("UpdateRole", mock.Anything, "u-1", user.UserRoleAdmin).Return(nil).Once()
+	s.On("RevokeAllByUserID", mock.Anything, "u-1", mock.AnythingOfType("time.Time")).Return(nil).Once()
+	revoker.On("RevokeUserTokens", mock.Anything, "u-1", mock.AnythingOfType("time.Time")).Return(nil).Once()
+	log.On("Record", mock.Anything, audit.Event{
+		Type:     audit.EventRoleChanged,
+		UserID:   "u-1",
+		Metadata: map[string]string{"previous_role": "user", "role": "admin"},
+	}).Return(nil).Once()
+
+	err := uc.ChangeRole(context.Background(), "u-1", user.UserRoleAdmin)
+
+	assert.NoError(t, err)
+	r.AssertExpectations(t)
+	s.AssertExpectations(t)
+	revoker.AssertExpectations(t)
+	log.AssertExpectations(t)
+}
+
+func TestChangeRole_SameRoleIsNoOp(t *testing.T) {
+	r := new(MockUserRepository)
+	uc := user.NewUsecase(r, new(MockPasswordHasher))
+
+	r.On("GetByID", mock.Anything, "u-1").Return(user.User{ID: "u-1", Role: user.UserRoleAdmin}, nil).Once()
+
+	err := uc.ChangeRole(context.Background(), "u-1", user.UserRoleAdmin)
+
+	assert.NoError(t, err)
+	r.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReactivateUser(t *testing.T) {
+	t.Run("inactive user", func(t *testing.T) {
+		r := new(MockUserRepository)
+		log := new(MockAuditRecorder)
+		uc := user.NewUsecase(r, new(MockPasswordHasher)).WithAuditLog(log)
+
+		r.On("GetByID", mock.Anything, "u-1").Return(user.User{ID: "u-1", Status: user.UserStatusInactive}, nil).Once()
+		r.On("UpdateStatus", mock.Anything, "u-1", user.UserStatusActive).Return(nil).Once()
+		log.On("Record", mock.Anything, audit.Event{Type: audit.EventUserReactivated, UserID: "u-1"}).Return(nil).Once()
+
+		err := uc.ReactivateUser(context.Background(), "u-1")
+
+		assert.NoError(t, err)
+		r.AssertExpectations(t)
+		log.AssertExpectations(t)
+	})
+
+	t.Run("locked user", func(t *testing.T) {
+		r := new(MockUserRepository)
+		uc := user.NewUsecase(r, new(MockPasswordHasher))
+
+		r.On("GetByID", mock.Anything, "u-1").Return(user.User{ID: "u-1", Status: user.UserStatusLocked}, nil).Once()
+
+		err := uc.ReactivateUser(context.Background(), "u-1")
+
+		assert.Equal(t, user.ErrUserNotInactive, err)
+		r.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUnlockUser_RecordsAuditEvent(t *testing.T) {
+	r := new(MockUserRepository)
+	log := new(MockAuditRecorder)
+	uc := user.NewUsecase(r, new(MockPasswordHasher)).WithAuditLog(log)
+
+	r.On("GetByID", mock.Anything, "u-1").Return(user.User{ID: "u-1", Status: user.UserStatusLocked}, nil).Once()
+	r.On("ClearLoginFailures", mock.Anything, "u-1").Return(nil).Once()
+	log.On("Record", mock.Anything, audit.Event{Type: audit.EventUserUnlocked, UserID: "u-1"}).Return(nil).Once()
+
+	err := uc.UnlockUser(context.Background(), "u-1")
+
+	assert.NoError(t, err)
+	r.AssertExpectations(t)
+	log.AssertExpectations(t)
+}
+
+func TestForceLogout_UnknownUser(t *testing.T) {
+	r := new(MockUserRepository)
+	s := new(MockSessionRepository)
+	uc := user.NewUsecaseWithSession(r, new(MockPasswordHasher), s)
+
+	r.On("GetByID", mock.Anything, "u-404").Return(user.User{}, user.ErrUserNotFound).Once()
+
+	err := uc.ForceLogout(context.Background(), "u-404")
+
+	assert.Equal(t, user.ErrUserNotFound, err)
+	s.AssertNotCalled(t, "RevokeAllByUserID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAdminActions_RejectDeletedUser(t *testing.T) {
+	deletedAt := time.Date(2026, 4, 15, 12, 0, 0, 0, time.UTC)
+	deleted := user.User{ID: "u-1", Role: user.UserRoleUser, Status: user.UserStatusInactive, DeletedAt: &deletedAt}
+
+	tests := []struct {
+		name string
+		act  func(uc *user.Usecase) error
+	}{
+		{"reactivate", func(uc *user.Usecase) error { return uc.ReactivateUser(context.Background(), "u-1") }},
+		{"change role", func(uc *user.Usecase) error { return uc.ChangeRole(context.Background(), "u-1", user.UserRoleAdmin) }},
+		{"unlock", func(uc *user.Usecase) error { return uc.UnlockUser(context.Background(), "u-1") }},
+		{"force logout", func(uc *user.Usecase) error { return uc.ForceLogout(context.Background(), "u-1") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := new(MockUserRepository)
+			s := new(MockSessionRepository)
+			uc := user.NewUsecaseWithSession(r, new(MockPasswordHasher), s)
+
+			r.On("GetByID", mock.Anything, "u-1").Return(deleted, nil).Once()
+
+			err := tt.act(uc)
+
+			assert.Equal(t, user.ErrUserDeleted, err)
+			r.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+			r.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything)
+			r.AssertNotCalled(t, "ClearLoginFailures", mock.Anything, mock.Anything)
+			s.AssertNotCalled(t, "RevokeAllByUserID", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
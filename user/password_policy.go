@@ -0,0 +1,118 @@
+package user
+
+import (
+	"context"
+	"slices"
+
+	"hexagon/errs"
+)
+
+var (
+	ErrPasswordRecentlyUsed = errs.Errorf(errs.EINVALID, "user: password was used recently, choose a different one")
+	ErrPasswordBreached     = errs.Errorf(errs.EINVALID, "user: password appears in a known data breach, choose a different one")
+)
+
+const defaultPasswordHistorySize = 5
+
+// PasswordHistoryRepository keeps the hashes of passwords users have set.
+type PasswordHistoryRepository interface {
+	// RecentPasswordHashes returns up to limit hashes, newest first.
+	RecentPasswordHashes(ctx context.Context, userID string, limit int) ([]string, error)
+	// AddPasswordHash stores hash as the newest and drops all but the newest
+	// keep hashes of the user.
+	AddPasswordHash(ctx context.Context, userID, hash string, keep int) error
+}
+
+// BreachedPasswords tells whether a password is known from a data breach.
+type BreachedPasswords interface {
+	Contains(ctx context.Context, password string) (bool, error)
+}
+
+// PasswordPolicy decides whether a password may be set. Besides the rules of
+// ValidatePassword it optionally rejects breached passwords and the user's
+// last passwords. One policy is shared by every flow that sets a password.
+type PasswordPolicy struct {
+	hasher      PasswordHasher
+	history     PasswordHistoryRepository
+	historySize int
+	breached    BreachedPasswords
+}
+
+func NewPasswordPolicy(h PasswordHasher) *PasswordPolicy {
+	return &PasswordPolicy{hasher: h}
+}
+
+// WithHistory rejects the last size passwords of a user, the current one
+// included. A size of 0 or less uses the default of 5.
+func (p *PasswordPolicy) WithHistory(repo PasswordHistoryRepository, size int) *PasswordPolicy {
+	if size <= 0 {
+		size = defaultPasswordHistorySize
+	}
+
+	p.history = repo
+	p.historySize = size
+
+	return p
+}
+
+// WithBreachedPasswords rejects passwords found in list.
+func (p *PasswordPolicy) WithBreachedPasswords(list BreachedPasswords) *PasswordPolicy {
+	p.breached = list
+
+	return p
+}
+
+// Check validates password as the new password of u. A u without ID is an
+// account being created, which has no history yet.
+func (p *PasswordPolicy) Check(ctx context.Context, u User, password string) error {
+	if err := validatePassword(password); err != nil {
+		return err
+	}
+
+	if p.breached != nil {
+		breached, err := p.breached.Contains(ctx, password)
+		if err != nil {
+			return err
+		}
+
+		if breached {
+			return ErrPasswordBreached
+		}
+	}
+
+	return p.checkHistory(ctx, u, password)
+}
+
+func (p *PasswordPolicy) checkHistory(ctx context.Context, u User, password string) error {
+	if p.history == nil || u.ID == "" {
+		return nil
+	}
+
+	hashes, err := p.history.RecentPasswordHashes(ctx, u.ID, p.historySize)
+	if err != nil {
+		return err
+	}
+
+	// Passwords set before the history was kept are not in it.
+	if u.PasswordHash != "" && !slices.Contains(hashes, u.PasswordHash) {
+		hashes = append(hashes, u.PasswordHash)
+	}
+
+	for _, hash := range hashes {
+		if p.hasher.Compare(hash, password) == nil {
+			return ErrPasswordRecentlyUsed
+		}
+	}
+
+	return nil
+}
+
+// Remember records hash as the newest password of userID. Call it whenever
+// a password passed by Check is stored.
+func (p *PasswordPolicy) Remember(ctx context.Context, userID, hash string) error {
+	if p.history == nil || userID == "" {
+		return nil
+	}
+
+	return p.history.AddPasswordHash(ctx, userID, hash, p.historySize)
+}
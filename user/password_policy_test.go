@@ -0,0 +1,112 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"hexagon/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// plainHasher "hashes" by prefixing, so tests can tell hashes apart.
+type plainHasher struct{}
+
+func (plainHasher) Hash(password string) (string, error) {
+	return "hash:" + password, nil
+}
+
+func (plainHasher) Compare(hashed, plain string) error {
+	if hashed != "hash:"+plain {
+		return errors.New("mismatch")
+	}
+
+	return nil
+}
+
+type memoryPasswordHistory struct {
+	hashes map[string][]string
+}
+
+func (m *memoryPasswordHistory) RecentPasswordHashes(ctx context.Context, userID string, limit int) ([]string, error) {
+	hashes := m.hashes[userID]
+	if len(hashes) > limit {
+		hashes = hashes[:limit]
+	}
+
+	return hashes, nil
+}
+
+func (m *memoryPasswordHistory) AddPasswordHash(ctx context.Context, userID, hash string, keep int) error {
+	hashes := append([]string{hash}, m.hashes[userID]...)
+	if len(hashes) > keep {
+		hashes = hashes[:keep]
+	}
+
+	m.hashes[userID] = hashes
+
+	return nil
+}
+
+type breachedList map[string]bool
+
+func (b breachedList) Contains(ctx context.Context, password string) (bool, error) {
+	return b[password], nil
+}
+
+func TestPasswordPolicy_RejectsLastPasswords(t *testing.T) {
+	history := &memoryPasswordHistory{hashes: map[string][]string{}}
+	policy := user.NewPasswordPolicy(plainHasher{}).WithHistory(history, 3)
+	ctx := context.Background()
+
+	passwords := []string{"First-Pass1", "Second-Pass2", "Third-Pass3", "Fourth-Pass4"}
+	for _, password := range passwords {
+		require.NoError(t, policy.Remember(ctx, "u-1", "hash:"+password))
+	}
+
+	u := user.User{ID: "u-1", PasswordHash: "hash:Fourth-Pass4"}
+
+	for _, password := range passwords[1:] {
+		assert.ErrorIs(t, policy.Check(ctx, u, password), user.ErrPasswordRecentlyUsed, password)
+	}
+
+	assert.NoError(t, policy.Check(ctx, u, "First-Pass1"), "older passwords are forgotten")
+	assert.NoError(t, policy.Check(ctx, user.User{}, "Fourth-Pass4"), "new accounts have no history")
+}
+
+func TestPasswordPolicy_RejectsCurrentPasswordMissingFromHistory(t *testing.T) {
+	history := &memoryPasswordHistory{hashes: map[string][]string{}}
+	policy := user.NewPasswordPolicy(plainHasher{}).WithHistory(history, 0)
+
+	err := policy.Check(context.Background(), user.User{ID: "u-1", PasswordHash: "hash:Legacy-Pass1"}, "Legacy-Pass1")
+
+	assert.ErrorIs(t, err, user.ErrPasswordRecentlyUsed)
+}
+
+func TestPasswordPolicy_RejectsBreachedPasswords(t *testing.T) {
+	policy := user.NewPasswordPolicy(plainHasher{}).WithBreachedPasswords(breachedList{"Password123!": true})
+
+	assert.ErrorIs(t, policy.Check(context.Background(), user.User{}, "Password123!"), user.ErrPasswordBreached)
+	assert.NoError(t, policy.Check(context.Background(), user.User{}, "Correct-Horse-9"))
+	assert.ErrorIs(t, policy.Check(context.Background(), user.User{}, "short"), user.ErrPasswordTooShort)
+}
+
+func TestChangePassword_AppliesPasswordPolicy(t *testing.T) {
+	r := new(MockUserRepository)
+	history := &memoryPasswordHistory{hashes: map[string][]string{"u-1": {"hash:Older-Pass1"}}}
+	uc := user.NewUsecase(r, plainHasher{}).
+		WithPasswordPolicy(user.NewPasswordPolicy(plainHasher{}).WithHistory(history, 5))
+
+	u := user.User{ID: "u-1", PasswordHash: "hash:Current123!"}
+	r.On("GetByID", context.Background(), "u-1").Return(u, nil)
+	r.On("UpdatePasswordHash", context.Background(), "u-1", "hash:NewPassword1!").Return(nil).Once()
+
+	err := uc.ChangePassword(context.Background(), "u-1", "Current123!", "Older-Pass1")
+	assert.ErrorIs(t, err, user.ErrPasswordRecentlyUsed)
+
+	require.NoError(t, uc.ChangePassword(context.Background(), "u-1", "Current123!", "NewPassword1!"))
+	assert.Equal(t, []string{"hash:NewPassword1!", "hash:Older-Pass1"}, history.hashes["u-1"])
+	r.AssertExpectations(t)
+}
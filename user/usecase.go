@@ -4,6 +4,8 @@ import (
 	"context"
 	"strings"
 	"time"
+
+	"hexagon/audit"
 )
 
 type Service interface {
@@ -14,6 +16,11 @@ type Service interface {
 	UpdateProfile(ctx context.Context, id, name, phone string) (User, error)
 	ChangePassword(ctx context.Context, id, currentPassword, newPassword string) error
 	DeactivateUser(ctx context.Context, id string) error
+	SearchUsers(ctx context.Context, filter SearchFilter) (SearchResult, error)
+	ChangeRole(ctx context.Context, id string, role UserRole) error
+	ReactivateUser(ctx context.Context, id string) error
+	UnlockUser(ctx context.Context, id string) error
+	ForceLogout(ctx context.Context, id string) error
 }
 
 type Repository interface {
@@ -24,6 +31,11 @@ type Repository interface {
 	UpdateProfile(ctx context.Context, id, name, phone string) (User, error)
 	UpdatePasswordHash(ctx context.Context, id, passwordHash string) error
 	UpdateStatus(ctx context.Context, id string, status UserStatus) error
+	SearchUsers(ctx context.Context, filter SearchFilter) (SearchResult, error)
+	UpdateRole(ctx context.Context, id string, role UserRole) error
+	// ClearLoginFailures resets the failed login counters and lock, and
+	// makes a locked user active again.
+	ClearLoginFailures(ctx context.Context, id string) error
 }
 
 type PasswordHasher interface {
@@ -32,19 +44,29 @@ type PasswordHasher interface {
 }
 
 type Usecase struct {
-	r           Repository
-	hasher      PasswordHasher
-	sessionRepo SessionRepository
+	r              Repository
+	hasher         PasswordHasher
+	passwordPolicy *PasswordPolicy
+	sessionRepo    SessionRepository
+	tokenRevoker   TokenRevoker
+	auditLog       audit.Recorder
 }
 
 type SessionRepository interface {
 	RevokeAllByUserID(ctx context.Context, userID string, revokedAt time.Time) error
 }
 
+// TokenRevoker rejects the user's access tokens issued up to before, which
+// would otherwise stay valid until they expire.
+type TokenRevoker interface {
+	RevokeUserTokens(ctx context.Context, userID string, before time.Time) error
+}
+
 func NewUsecase(r Repository, h PasswordHasher) *Usecase {
 	return &Usecase{
-		r:      r,
-		hasher: h,
+		r:              r,
+		hasher:         h,
+		passwordPolicy: NewPasswordPolicy(h),
 	}
 }
 
@@ -55,6 +77,31 @@ func NewUsecaseWithSession(r Repository, h PasswordHasher, sessionRepo SessionRe
 	return uc
 }
 
+// WithTokenRevoker makes password changes and deactivation revoke issued
+// access tokens.
+func (uc *Usecase) WithTokenRevoker(revoker TokenRevoker) *Usecase {
+	uc.tokenRevoker = revoker
+
+	return uc
+}
+
+// WithPasswordPolicy replaces the default policy, which only applies
+// ValidatePassword, for password changes.
+func (uc *Usecase) WithPasswordPolicy(policy *PasswordPolicy) *Usecase {
+	uc.passwordPolicy = policy
+
+	return uc
+}
+
+// WithAuditLog records password changes and admin actions such as
+// deactivations in log. The actor and client are taken from the request in
+// ctx.
+func (uc *Usecase) WithAuditLog(log audit.Recorder) *Usecase {
+	uc.auditLog = log
+
+	return uc
+}
+
 func (uc *Usecase) AddUser(ctx context.Context, u User) error {
 	if u.Role == "" {
 		u.Role = UserRoleUser
@@ -140,6 +187,10 @@ func (uc *Usecase) ChangePassword(ctx context.Context, id, currentPassword, newP
 		return ErrCurrentPasswordInvalid
 	}
 
+	if err := uc.passwordPolicy.Check(ctx, existing, newPassword); err != nil {
+		return err
+	}
+
 	hashed, err := uc.hasher.Hash(newPassword)
 	if err != nil {
 		return err
@@ -149,12 +200,16 @@ func (uc *Usecase) ChangePassword(ctx context.Context, id, currentPassword, newP
 		return err
 	}
 
-	if uc.sessionRepo != nil {
-		if err := uc.sessionRepo.RevokeAllByUserID(ctx, id, time.Now().UTC()); err != nil {
-			return err
-		}
+	if err := uc.passwordPolicy.Remember(ctx, id, hashed); err != nil {
+		return err
+	}
+
+	if err := uc.revokeTokens(ctx, id); err != nil {
+		return err
 	}
 
+	uc.recordAudit(ctx, audit.EventPasswordChanged, id, nil)
+
 	return nil
 }
 
@@ -163,5 +218,46 @@ func (uc *Usecase) DeactivateUser(ctx context.Context, id string) error {
 		return ErrUserIDRequired
 	}
 
-	return uc.r.UpdateStatus(ctx, id, UserStatusInactive)
+	if err := uc.r.UpdateStatus(ctx, id, UserStatusInactive); err != nil {
+		return err
+	}
+
+	// Refreshing does not look at the user status, so the sessions have to
+	// go as well as the access tokens.
+	if err := uc.revokeTokens(ctx, id); err != nil {
+		return err
+	}
+
+	uc.recordAudit(ctx, audit.EventUserDeactivated, id, nil)
+
+	return nil
+}
+
+// recordAudit is best-effort: the change it records has already been made.
+func (uc *Usecase) recordAudit(ctx context.Context, eventType audit.EventType, userID string, metadata map[string]string) {
+	if uc.auditLog == nil {
+		return
+	}
+
+	_ = uc.auditLog.Record(ctx, audit.Event{Type: eventType, UserID: userID, Metadata: metadata})
+}
+
+// revokeTokens logs the user out everywhere: refresh tokens and already
+// issued access tokens alike.
+func (uc *Usecase) revokeTokens(ctx context.Context, id string) error {
+	now := time.Now().UTC()
+
+	if uc.sessionRepo != nil {
+		if err := uc.sessionRepo.RevokeAllByUserID(ctx, id, now); err != nil {
+			return err
+		}
+	}
+
+	if uc.tokenRevoker != nil {
+		if err := uc.tokenRevoker.RevokeUserTokens(ctx, id, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"hexagon/audit"
 	"hexagon/user"
 
 	"github.com/stretchr/testify/assert"
@@ -52,6 +53,21 @@ func (m *MockUserRepository) UpdateStatus(ctx context.Context, id string, status
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) SearchUsers(ctx context.Context, filter user.SearchFilter) (user.SearchResult, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(user.SearchResult), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateRole(ctx context.Context, id string, role user.UserRole) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ClearLoginFailures(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 type MockPasswordHasher struct {
 	mock.Mock
 }
@@ -75,6 +91,24 @@ func (m *MockSessionRepository) RevokeAllByUserID(ctx context.Context, userID st
 	return args.Error(0)
 }
 
+type MockTokenRevoker struct {
+	mock.Mock
+}
+
+func (m *MockTokenRevoker) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	args := m.Called(ctx, userID, before)
+	return args.Error(0)
+}
+
+type MockAuditRecorder struct {
+	mock.Mock
+}
+
+func (m *MockAuditRecorder) Record(ctx context.Context, event audit.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
 // TEST AddUser
 func TestAddUser(t *testing.T) {
 	t.Run("should add new user", func(t *testing.T) {
@@ -624,3 +658,56 @@ func TestChangePassword_RevokeSessions(t *testing.T) {
 	h.AssertExpectations(t)
 	s.AssertExpectations(t)
 }
+
+func TestChangePassword_RevokesAccessTokens(t *testing.T) {
+	r := new(MockUserRepository)
+	h := new(MockPasswordHasher)
+	revoker := new(MockTokenRevoker)
+	uc := user.NewUsecase(r, h).WithTokenRevoker(revoker)
+
+	u := user.User{ID: "u-1", PasswordHash: "hashed-current"}
+	r.On("GetByID", mock.Anything, "u-1").Return(u, nil).Once()
+	h.On("Compare", "hashed-current", "Current123!").Return(nil).Once()
+	h.On("Hash", "NewPassword1!").Return("hashed-new", nil).Once()
+	r.On("UpdatePasswordHash", mock.Anything, "u-1", "hashed-new").Return(nil).Once()
+	revoker.On("RevokeUserTokens", mock.Anything, "u-1", mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	err := uc.ChangePassword(context.Background(), "u-1", "Current123!", "NewPassword1!")
+
+	assert.NoError(t, err)
+	revoker.AssertExpectations(t)
+}
+
+func TestDeactivateUser_RevokesSessionsAndAccessTokens(t *testing.T) {
+	r := new(MockUserRepository)
+	h := new(MockPasswordHasher)
+	s := new(MockSessionRepository)
+	revoker := new(MockTokenRevoker)
+	uc := user.NewUsecaseWithSession(r, h, s).WithTokenRevoker(revoker)
+
+	r.On("UpdateStatus", mock.Anything, "u-1", user.UserStatusInactive).Return(nil).Once()
+	s.On("RevokeAllByUserID", mock.Anything, "u-1", mock.AnythingOfType("time.Time")).Return(nil).Once()
+	revoker.On("RevokeUserTokens", mock.Anything, "u-1", mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	err := uc.DeactivateUser(context.Background(), "u-1")
+
+	assert.NoError(t, err)
+	r.AssertExpectations(t)
+	s.AssertExpectations(t)
+	revoker.AssertExpectations(t)
+}
+
+func TestDeactivateUser_RecordsAuditEvent(t *testing.T) {
+	r := new(MockUserRepository)
+	h := new(MockPasswordHasher)
+	log := new(MockAuditRecorder)
+	uc := user.NewUsecase(r, h).WithAuditLog(log)
+
+	r.On("UpdateStatus", mock.Anything, "u-1", user.UserStatusInactive).Return(nil).Once()
+	log.On("Record", mock.Anything, audit.Event{Type: audit.EventUserDeactivated, UserID: "u-1"}).Return(nil).Once()
+
+	err := uc.DeactivateUser(context.Background(), "u-1")
+
+	assert.NoError(t, err)
+	log.AssertExpectations(t)
+}
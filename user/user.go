@@ -61,12 +61,16 @@ type User struct {
 	Password            string
 	PasswordHash        string
 	EmailVerifiedAt     *time.Time
+	PhoneVerifiedAt     *time.Time
+	MustResetPassword   bool
 	Role                UserRole
 	Status              UserStatus
 	FailedLoginAttempts int
 	LockUntil           *time.Time
 	LockEscalationLevel int
 	LastFailedLoginAt   *time.Time
+	DeletionScheduledAt *time.Time
+	DeletedAt           *time.Time
 	CreatedAt           time.Time
 	UpdatedAt           time.Time
 }